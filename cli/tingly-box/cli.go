@@ -112,6 +112,8 @@ func init() {
 	rootCmd.AddCommand(command.StopCommand(appConfig))
 	rootCmd.AddCommand(command.RestartCommand(appConfig))
 	rootCmd.AddCommand(command.StatusCommand(appConfig))
+	rootCmd.AddCommand(command.UsageCommand(appConfig))
+	rootCmd.AddCommand(command.LogsCommand(appConfig))
 }
 
 func main() {
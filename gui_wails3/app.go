@@ -44,6 +44,7 @@ func newApp(port int, debug bool) *application.App {
 		Services: []application.Service{
 			application.NewService(&services2.GreetService{}),
 			application.NewService(tinglyService),
+			application.NewService(tinglyService.NotificationService()),
 		},
 		Assets: application.AssetOptions{
 			Handler: embdHandler,
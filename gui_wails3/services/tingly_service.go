@@ -9,6 +9,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/wailsapp/wails/v3/pkg/application"
+	"github.com/wailsapp/wails/v3/pkg/services/notifications"
 
 	"github.com/tingly-dev/tingly-box/internal/command"
 	"github.com/tingly-dev/tingly-box/internal/config"
@@ -23,6 +24,7 @@ type TinglyService struct {
 	shutdownChan  chan struct{}
 	isRunning     bool
 	app           *application.App
+	notifications *notifications.NotificationService
 }
 
 // NewTinglyService creates a new UI service instance
@@ -47,6 +49,7 @@ func NewTinglyService(configDir string, port int, debug bool) (*TinglyService, e
 		serverManager: serverManager,
 		shutdownChan:  make(chan struct{}),
 		isRunning:     false,
+		notifications: notifications.New(),
 	}
 
 	log.Printf("config file: %s\n", appConfig.GetGlobalConfig().ConfigFile)
@@ -81,6 +84,12 @@ func (s *TinglyService) Stop() error {
 	return err
 }
 
+// NotificationService returns the system notification service desktop alerts are shown with; the
+// app registers it alongside this service
+func (s *TinglyService) NotificationService() *notifications.NotificationService {
+	return s.notifications
+}
+
 func (s *TinglyService) GetGinEngine() *gin.Engine {
 	return s.serverManager.GetGinEngine()
 }
@@ -98,6 +107,11 @@ func (s *TinglyService) ServiceStartup(ctx context.Context, options application.
 	// Store the application instance for later use
 	s.app = application.Get()
 
+	// Show alerts of desktop sinks as system notifications
+	if httpServer := s.serverManager.GetServer(); httpServer != nil {
+		httpServer.GetAlertManager().SetDesktopNotifier(s.notify)
+	}
+
 	// Register an event handler that can be triggered from the frontend
 	s.app.Event.On("gin-api-event", func(event *application.CustomEvent) {
 		// Log the event data
@@ -115,6 +129,24 @@ func (s *TinglyService) ServiceStartup(ctx context.Context, options application.
 	return nil
 }
 
+// notify shows a system notification, asking for permission first where the OS requires it
+func (s *TinglyService) notify(title, message string) error {
+	if authorized, _ := s.notifications.CheckNotificationAuthorization(); !authorized {
+		authorized, err := s.notifications.RequestNotificationAuthorization()
+		if err != nil {
+			return err
+		}
+		if !authorized {
+			return fmt.Errorf("notifications are not allowed for this app")
+		}
+	}
+	return s.notifications.SendNotification(notifications.NotificationOptions{
+		ID:    fmt.Sprintf("tingly-alert-%d", time.Now().UnixNano()),
+		Title: title,
+		Body:  message,
+	})
+}
+
 // ServiceShutdown is called when the service shuts down
 func (s *TinglyService) ServiceShutdown(ctx context.Context) error {
 	// Clean up resources if needed
@@ -37,6 +37,7 @@ func newSlimApp(port int, debug bool) *application.App {
 		Services: []application.Service{
 			application.NewService(&services.GreetService{}),
 			application.NewService(tinglyService),
+			application.NewService(tinglyService.NotificationService()),
 		},
 		// No Assets handler - slim version opens browser instead
 		Mac: application.MacOptions{
@@ -0,0 +1,161 @@
+package accountpool
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Quota is the usage window state reported by an upstream through its rate limit headers.
+// Zero values mean the upstream did not report the field.
+type Quota struct {
+	RequestsLimit     int64         `json:"requests_limit,omitempty"`
+	RequestsRemaining *int64        `json:"requests_remaining,omitempty"`
+	TokensLimit       int64         `json:"tokens_limit,omitempty"`
+	TokensRemaining   *int64        `json:"tokens_remaining,omitempty"`
+	UsedPercent       *float64      `json:"used_percent,omitempty"`
+	Rejected          bool          `json:"rejected,omitempty"` // Upstream reported the window as exhausted
+	ResetAt           time.Time     `json:"reset_at,omitempty"`
+	RetryAfter        time.Duration `json:"-"`
+}
+
+// Exhausted reports whether the quota indicates no request can be served before ResetAt
+func (q *Quota) Exhausted() bool {
+	if q.Rejected {
+		return true
+	}
+	if q.RequestsRemaining != nil && *q.RequestsRemaining <= 0 {
+		return true
+	}
+	if q.TokensRemaining != nil && *q.TokensRemaining <= 0 {
+		return true
+	}
+	if q.UsedPercent != nil && *q.UsedPercent >= 100 {
+		return true
+	}
+	return false
+}
+
+// ParseRateLimitHeaders extracts quota information from the rate limit headers of the
+// supported OAuth upstreams (Anthropic unified and per-window headers, OpenAI/Codex style
+// x-ratelimit-* and x-codex-* headers, and the generic retry-after). It returns nil when
+// none of the headers are present.
+func ParseRateLimitHeaders(h http.Header, now time.Time) *Quota {
+	q := &Quota{}
+	found := false
+
+	// Generic retry-after: delta seconds or HTTP-date
+	if v := h.Get("retry-after"); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil {
+			q.RetryAfter = time.Duration(secs * float64(time.Second))
+			found = true
+		} else if t, err := http.ParseTime(v); err == nil {
+			q.RetryAfter = t.Sub(now)
+			found = true
+		}
+		if q.RetryAfter > 0 {
+			q.extendReset(now.Add(q.RetryAfter))
+		}
+	}
+
+	// Anthropic subscription (unified) window
+	if v := h.Get("anthropic-ratelimit-unified-status"); v != "" {
+		found = true
+		q.Rejected = strings.EqualFold(v, "rejected")
+	}
+	if v := h.Get("anthropic-ratelimit-unified-reset"); v != "" {
+		if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+			found = true
+			q.extendReset(time.Unix(secs, 0))
+		}
+	}
+
+	// Anthropic API per-window headers
+	for _, kind := range []string{"requests", "tokens"} {
+		limit, hasLimit := parseInt(h.Get("anthropic-ratelimit-" + kind + "-limit"))
+		remaining, hasRemaining := parseInt(h.Get("anthropic-ratelimit-" + kind + "-remaining"))
+		if hasLimit || hasRemaining {
+			found = true
+			q.set(kind, limit, remaining, hasRemaining)
+		}
+		if v := h.Get("anthropic-ratelimit-" + kind + "-reset"); v != "" && hasRemaining && remaining <= 0 {
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				q.extendReset(t)
+			}
+		}
+	}
+
+	// OpenAI style headers
+	for _, kind := range []string{"requests", "tokens"} {
+		limit, hasLimit := parseInt(h.Get("x-ratelimit-limit-" + kind))
+		remaining, hasRemaining := parseInt(h.Get("x-ratelimit-remaining-" + kind))
+		if hasLimit || hasRemaining {
+			found = true
+			q.set(kind, limit, remaining, hasRemaining)
+		}
+		if v := h.Get("x-ratelimit-reset-" + kind); v != "" && hasRemaining && remaining <= 0 {
+			if d, err := time.ParseDuration(v); err == nil {
+				q.extendReset(now.Add(d))
+			}
+		}
+	}
+
+	// Codex subscription window
+	if v := h.Get("x-codex-primary-used-percent"); v != "" {
+		if pct, err := strconv.ParseFloat(v, 64); err == nil {
+			found = true
+			q.UsedPercent = &pct
+		}
+	}
+	if v := h.Get("x-codex-primary-reset-after-seconds"); v != "" {
+		if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+			found = true
+			if q.UsedPercent != nil && *q.UsedPercent >= 100 {
+				q.extendReset(now.Add(time.Duration(secs) * time.Second))
+			}
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return q
+}
+
+func (q *Quota) set(kind string, limit int64, remaining int64, hasRemaining bool) {
+	switch kind {
+	case "requests":
+		if limit > 0 {
+			q.RequestsLimit = limit
+		}
+		if hasRemaining {
+			q.RequestsRemaining = &remaining
+		}
+	case "tokens":
+		if limit > 0 {
+			q.TokensLimit = limit
+		}
+		if hasRemaining {
+			q.TokensRemaining = &remaining
+		}
+	}
+}
+
+// extendReset keeps the latest reset time seen across headers
+func (q *Quota) extendReset(t time.Time) {
+	if t.After(q.ResetAt) {
+		q.ResetAt = t
+	}
+}
+
+func parseInt(v string) (int64, bool) {
+	if v == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
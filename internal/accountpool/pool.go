@@ -0,0 +1,169 @@
+// Package accountpool rotates requests across several OAuth subscription accounts of the
+// same provider type, moving away from an account once its usage window is exhausted.
+package accountpool
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+// DefaultCooldown is applied to an account that was rate limited without telling us when it resets
+const DefaultCooldown = time.Minute
+
+// AccountState is the tracked runtime state of one pool member
+type AccountState struct {
+	ProviderUUID  string    `json:"provider_uuid"`
+	Quota         *Quota    `json:"quota,omitempty"`
+	CooldownUntil time.Time `json:"cooldown_until,omitempty"`
+	LastStatus    int       `json:"last_status,omitempty"`
+	LastSelected  time.Time `json:"last_selected,omitempty"`
+	LastObserved  time.Time `json:"last_observed,omitempty"`
+}
+
+// CoolingDown reports whether the account is still waiting for its window to reset
+func (s *AccountState) CoolingDown(now time.Time) bool {
+	return now.Before(s.CooldownUntil)
+}
+
+// Tracker keeps per-account quota state and picks which account serves the next request.
+// It is safe for concurrent use.
+type Tracker struct {
+	mu       sync.Mutex
+	accounts map[string]*AccountState
+	now      func() time.Time
+
+	onLimited func(providerUUID string, statusCode int, until time.Time)
+}
+
+// NewTracker creates an empty tracker
+func NewTracker() *Tracker {
+	return &Tracker{
+		accounts: make(map[string]*AccountState),
+		now:      time.Now,
+	}
+}
+
+// SetOnLimited sets a callback invoked when an account starts cooling down after hitting its rate
+// limit or usage window
+func (t *Tracker) SetOnLimited(fn func(providerUUID string, statusCode int, until time.Time)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onLimited = fn
+}
+
+func (t *Tracker) state(providerUUID string) *AccountState {
+	s, ok := t.accounts[providerUUID]
+	if !ok {
+		s = &AccountState{ProviderUUID: providerUUID}
+		t.accounts[providerUUID] = s
+	}
+	return s
+}
+
+// Select picks the member account to use for the next request: the least recently selected
+// enabled account that is not cooling down. When every account is cooling down it returns an
+// error naming the earliest reset.
+func (t *Tracker) Select(pool *typ.AccountPool, accounts []*typ.Provider) (*typ.Provider, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	var chosen *typ.Provider
+	var chosenState *AccountState
+	var earliest time.Time
+	for _, p := range accounts {
+		if p == nil || !p.Enabled {
+			continue
+		}
+		s := t.state(p.UUID)
+		if s.CoolingDown(now) {
+			if earliest.IsZero() || s.CooldownUntil.Before(earliest) {
+				earliest = s.CooldownUntil
+			}
+			continue
+		}
+		if chosen == nil || s.LastSelected.Before(chosenState.LastSelected) {
+			chosen, chosenState = p, s
+		}
+	}
+
+	if chosen == nil {
+		if earliest.IsZero() {
+			return nil, fmt.Errorf("account pool '%s' has no enabled accounts", pool.Name)
+		}
+		return nil, fmt.Errorf("all accounts in pool '%s' are rate limited until %s", pool.Name, earliest.Format(time.RFC3339))
+	}
+
+	chosenState.LastSelected = now
+	return chosen, nil
+}
+
+// ObserveResponse records the rate limit state an upstream reported for an account.
+// A 429, or an exhausted window, puts the account into cooldown until the reported reset.
+func (t *Tracker) ObserveResponse(providerUUID string, resp *http.Response) {
+	if resp == nil {
+		return
+	}
+
+	// Registered before the unlock, so the callback runs without the lock held
+	var notify func()
+	defer func() {
+		if notify != nil {
+			notify()
+		}
+	}()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	s := t.state(providerUUID)
+	s.LastStatus = resp.StatusCode
+	s.LastObserved = now
+
+	quota := ParseRateLimitHeaders(resp.Header, now)
+	if quota != nil {
+		s.Quota = quota
+	}
+
+	limited := resp.StatusCode == http.StatusTooManyRequests || (quota != nil && quota.Exhausted())
+	if !limited {
+		return
+	}
+
+	until := now.Add(DefaultCooldown)
+	if quota != nil && quota.ResetAt.After(now) {
+		until = quota.ResetAt
+	}
+	if until.After(s.CooldownUntil) {
+		// A new cooldown, not one extended while the account was already cooling down
+		starting := !s.CoolingDown(now)
+		s.CooldownUntil = until
+		logrus.Infof("Account %s rate limited (status %d), cooling down until %s", providerUUID, resp.StatusCode, until.Format(time.RFC3339))
+		if onLimited := t.onLimited; starting && onLimited != nil {
+			notify = func() { onLimited(providerUUID, resp.StatusCode, until) }
+		}
+	}
+}
+
+// Snapshot returns a copy of the state of the given accounts, in the same order
+func (t *Tracker) Snapshot(providerUUIDs []string) []AccountState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]AccountState, 0, len(providerUUIDs))
+	for _, uuid := range providerUUIDs {
+		if s, ok := t.accounts[uuid]; ok {
+			out = append(out, *s)
+		} else {
+			out = append(out, AccountState{ProviderUUID: uuid})
+		}
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package accountpool
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+func newTestTracker(now time.Time) *Tracker {
+	t := NewTracker()
+	t.now = func() time.Time { return now }
+	return t
+}
+
+func testAccounts() (*typ.AccountPool, []*typ.Provider) {
+	pool := &typ.AccountPool{UUID: "pool-1", Name: "claude", ProviderType: "claude_code", Accounts: []string{"a", "b"}, Enabled: true}
+	accounts := []*typ.Provider{
+		{UUID: "a", Name: "account-a", Enabled: true, AuthType: typ.AuthTypeOAuth},
+		{UUID: "b", Name: "account-b", Enabled: true, AuthType: typ.AuthTypeOAuth},
+	}
+	return pool, accounts
+}
+
+func TestTracker_SelectRotates(t *testing.T) {
+	now := time.Now()
+	tracker := newTestTracker(now)
+	pool, accounts := testAccounts()
+
+	first, err := tracker.Select(pool, accounts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tracker.now = func() time.Time { return now.Add(time.Second) }
+	second, err := tracker.Select(pool, accounts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.UUID == second.UUID {
+		t.Errorf("expected rotation between accounts, got %s twice", first.UUID)
+	}
+}
+
+func TestTracker_SkipsRateLimitedAccount(t *testing.T) {
+	now := time.Now()
+	tracker := newTestTracker(now)
+	pool, accounts := testAccounts()
+
+	reset := now.Add(2 * time.Hour)
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("anthropic-ratelimit-unified-status", "rejected")
+	resp.Header.Set("anthropic-ratelimit-unified-reset", strconv.FormatInt(reset.Unix(), 10))
+	tracker.ObserveResponse("a", resp)
+
+	for i := 0; i < 3; i++ {
+		p, err := tracker.Select(pool, accounts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p.UUID != "b" {
+			t.Errorf("expected account b while a cools down, got %s", p.UUID)
+		}
+	}
+
+	// Once b is also exhausted the pool reports the earliest reset
+	resp = &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	tracker.ObserveResponse("b", resp)
+	if _, err := tracker.Select(pool, accounts); err == nil {
+		t.Error("expected error when all accounts are rate limited")
+	}
+
+	// After the default cooldown b becomes available again
+	tracker.now = func() time.Time { return now.Add(DefaultCooldown + time.Second) }
+	p, err := tracker.Select(pool, accounts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.UUID != "b" {
+		t.Errorf("expected account b after its cooldown, got %s", p.UUID)
+	}
+}
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	h := http.Header{}
+	h.Set("x-ratelimit-limit-requests", "100")
+	h.Set("x-ratelimit-remaining-requests", "0")
+	h.Set("x-ratelimit-reset-requests", "6m0s")
+	q := ParseRateLimitHeaders(h, now)
+	if q == nil {
+		t.Fatal("expected quota")
+	}
+	if !q.Exhausted() {
+		t.Error("expected exhausted quota")
+	}
+	if !q.ResetAt.Equal(now.Add(6 * time.Minute)) {
+		t.Errorf("unexpected reset: %v", q.ResetAt)
+	}
+
+	h = http.Header{}
+	h.Set("x-codex-primary-used-percent", "42.5")
+	h.Set("x-codex-primary-reset-after-seconds", "600")
+	q = ParseRateLimitHeaders(h, now)
+	if q == nil || q.UsedPercent == nil || *q.UsedPercent != 42.5 {
+		t.Fatalf("expected used percent, got %+v", q)
+	}
+	if q.Exhausted() {
+		t.Error("quota should not be exhausted")
+	}
+
+	h = http.Header{}
+	h.Set("retry-after", "30")
+	q = ParseRateLimitHeaders(h, now)
+	if q == nil || !q.ResetAt.Equal(now.Add(30*time.Second)) {
+		t.Errorf("unexpected retry-after reset: %+v", q)
+	}
+
+	if ParseRateLimitHeaders(http.Header{}, now) != nil {
+		t.Error("expected nil quota without headers")
+	}
+}
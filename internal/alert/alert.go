@@ -0,0 +1,122 @@
+// Package alert evaluates alert rules over events such as failed provider calls, OAuth refresh
+// failures and token usage, and delivers fired alerts to sinks: webhooks, local commands, desktop
+// notifications and the log.
+package alert
+
+import "time"
+
+// Event kinds
+const (
+	// EventRequest is a completed proxied request; StatusCode is the status returned to the client,
+	// which follows the provider's on upstream errors, and Tokens its input plus output tokens
+	EventRequest = "request"
+	// EventOAuthRefreshFailed is a failed OAuth token refresh of a provider
+	EventOAuthRefreshFailed = "oauth_refresh_failed"
+	// EventQuotaExhausted is an account that hit its rate limit or usage window and cools down
+	EventQuotaExhausted = "quota_exhausted"
+	// EventProbeFailed is a failed background health probe of a provider model
+	EventProbeFailed = "probe_failed"
+	// EventModelRemoved is a model that a model list sync found missing from its provider's list
+	EventModelRemoved = "model_removed"
+	// EventTest is sent by Manager.Test
+	EventTest = "test"
+)
+
+// Event is something rules are evaluated against. Rule conditions are expressions over its
+// fields, e.g. `Kind == "request" && StatusCode in [401, 403]`.
+type Event struct {
+	Kind       string    `json:"kind"`
+	Time       time.Time `json:"time"`
+	Provider   string    `json:"provider,omitempty"`
+	Model      string    `json:"model,omitempty"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Status     string    `json:"status,omitempty"` // Usage status of a request: success, error or partial
+	ErrorCode  string    `json:"error_code,omitempty"`
+	Tokens     int64     `json:"tokens,omitempty"`
+	Message    string    `json:"message,omitempty"`
+}
+
+// Alert is a fired rule, as delivered to sinks
+type Alert struct {
+	Rule      string    `json:"rule"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	Provider  string    `json:"provider,omitempty"`
+	Value     float64   `json:"value"` // Events, or tokens, within the window
+	Threshold float64   `json:"threshold"`
+	Window    string    `json:"window"`
+	Time      time.Time `json:"time"`
+	Event     Event     `json:"event"` // The event that fired the rule
+}
+
+// Rule measures
+const (
+	MeasureCount  = "count"  // Matching events
+	MeasureTokens = "tokens" // Tokens of matching events
+)
+
+// Sink types
+const (
+	SinkWebhook = "webhook"
+	SinkCommand = "command"
+	SinkDesktop = "desktop"
+	SinkLog     = "log"
+)
+
+const (
+	// DefaultWindow is the window a rule sums matching events over
+	DefaultWindow = 5 * time.Minute
+	// DefaultCooldown is the minimum time between two firings of a rule
+	DefaultCooldown = 30 * time.Minute
+	// DefaultSinkTimeout bounds one delivery to a webhook or command sink
+	DefaultSinkTimeout = 10 * time.Second
+)
+
+// Config holds the alert rules and the sinks they deliver to
+type Config struct {
+	Rules []RuleConfig `json:"rules,omitempty" yaml:"rules,omitempty"`
+	Sinks []SinkConfig `json:"sinks,omitempty" yaml:"sinks,omitempty"`
+}
+
+// RuleConfig defines when an alert fires: when the events matching Condition within Window add up
+// to Threshold, at most once per Cooldown
+type RuleConfig struct {
+	Name        string   `json:"name" yaml:"name"`
+	Condition   string   `json:"condition" yaml:"condition"`                           // Expression over the event, e.g. Kind == "request" && StatusCode == 429
+	Measure     string   `json:"measure,omitempty" yaml:"measure,omitempty"`           // What is summed: count (default) or tokens
+	Threshold   float64  `json:"threshold,omitempty" yaml:"threshold,omitempty"`       // Sum that fires the rule (default 1)
+	Window      int      `json:"window,omitempty" yaml:"window,omitempty"`             // Seconds summed over (default 300)
+	Cooldown    int      `json:"cooldown,omitempty" yaml:"cooldown,omitempty"`         // Seconds before the rule fires again (default 1800)
+	PerProvider bool     `json:"per_provider,omitempty" yaml:"per_provider,omitempty"` // Sum and cool down each provider separately
+	Sinks       []string `json:"sinks,omitempty" yaml:"sinks,omitempty"`               // Sink names to deliver to (default all)
+	Disabled    bool     `json:"disabled,omitempty" yaml:"disabled,omitempty"`         // Keep the rule without evaluating it
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`   // Added to the alert message
+}
+
+// SinkConfig defines where alerts are delivered
+type SinkConfig struct {
+	Name     string            `json:"name" yaml:"name"`                             // Referenced by rules; defaults to the type
+	Type     string            `json:"type" yaml:"type"`                             // webhook, command, desktop or log
+	URL      string            `json:"url,omitempty" yaml:"url,omitempty"`           // webhook: receiver URL
+	Headers  map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`   // webhook: extra request headers
+	Template string            `json:"template,omitempty" yaml:"template,omitempty"` // webhook: Go template of the JSON body over the alert (default: the alert as JSON)
+	Command  string            `json:"command,omitempty" yaml:"command,omitempty"`   // command: program run with the alert as JSON on stdin
+	Args     []string          `json:"args,omitempty" yaml:"args,omitempty"`         // command: its arguments
+	Timeout  int               `json:"timeout,omitempty" yaml:"timeout,omitempty"`   // Seconds per delivery (default 10)
+}
+
+// name returns the sink name rules refer to
+func (c SinkConfig) name() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Type
+}
+
+// timeout returns the delivery timeout
+func (c SinkConfig) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultSinkTimeout
+	}
+	return time.Duration(c.Timeout) * time.Second
+}
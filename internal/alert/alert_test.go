@@ -0,0 +1,212 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver collects the webhook requests it receives
+type receiver struct {
+	mu      sync.Mutex
+	bodies  []string
+	headers []http.Header
+}
+
+func newReceiver(t *testing.T) (*receiver, *httptest.Server) {
+	r := &receiver{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.bodies = append(r.bodies, string(body))
+		r.headers = append(r.headers, req.Header.Clone())
+		r.mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+	return r, server
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.bodies)
+}
+
+// testClock is a settable manager clock
+type testClock struct{ now time.Time }
+
+func (c *testClock) Now() time.Time          { return c.now }
+func (c *testClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestManager(t *testing.T, config Config) (*Manager, *testClock) {
+	clock := &testClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	m := NewManager()
+	m.now = clock.Now
+	require.NoError(t, m.Reload(config))
+	return m, clock
+}
+
+func TestManager_WebhookTemplateAndHeaders(t *testing.T) {
+	r, server := newReceiver(t)
+	m, _ := newTestManager(t, Config{
+		Rules: []RuleConfig{{Name: "auth errors", Condition: `Kind == "request" && StatusCode in [401, 403]`}},
+		Sinks: []SinkConfig{{
+			Name:     "slack",
+			Type:     SinkWebhook,
+			URL:      server.URL,
+			Headers:  map[string]string{"X-Token": "secret"},
+			Template: `{"text": {{ json .Message }}, "rule": {{ json .Rule }}}`,
+		}},
+	})
+
+	m.Emit(Event{Kind: EventRequest, Provider: "openai", StatusCode: 200})
+	m.Emit(Event{Kind: EventRequest, Provider: "openai", StatusCode: 401, Message: "bad key"})
+	require.NoError(t, m.Close(context.Background()))
+
+	require.Equal(t, 1, r.count())
+	var body map[string]string
+	require.NoError(t, json.Unmarshal([]byte(r.bodies[0]), &body))
+	assert.Equal(t, "auth errors", body["rule"])
+	assert.Contains(t, body["text"], "bad key")
+	assert.Equal(t, "secret", r.headers[0].Get("X-Token"))
+	assert.Equal(t, "application/json", r.headers[0].Get("Content-Type"))
+
+	recent := m.Recent()
+	require.Len(t, recent, 1)
+	assert.Equal(t, 401, recent[0].Event.StatusCode)
+}
+
+func TestManager_ThresholdWindowAndCooldown(t *testing.T) {
+	m, clock := newTestManager(t, Config{
+		Rules: []RuleConfig{{
+			Name:      "rate limited",
+			Condition: `StatusCode == 429`,
+			Threshold: 3,
+			Window:    60,
+			Cooldown:  600,
+		}},
+	})
+	rateLimited := Event{Kind: EventRequest, StatusCode: 429}
+
+	// Two within the window, the third after the first left it
+	m.Emit(rateLimited)
+	clock.Advance(30 * time.Second)
+	m.Emit(rateLimited)
+	clock.Advance(40 * time.Second)
+	m.Emit(rateLimited)
+	assert.Empty(t, m.Recent())
+
+	m.Emit(rateLimited)
+	require.Len(t, m.Recent(), 1)
+	assert.Equal(t, 3.0, m.Recent()[0].Value)
+
+	// A new breach within the cooldown does not fire again
+	for i := 0; i < 3; i++ {
+		m.Emit(rateLimited)
+	}
+	assert.Len(t, m.Recent(), 1)
+
+	clock.Advance(10 * time.Minute)
+	for i := 0; i < 3; i++ {
+		m.Emit(rateLimited)
+	}
+	assert.Len(t, m.Recent(), 2)
+	require.NoError(t, m.Close(context.Background()))
+}
+
+func TestManager_PerProviderTokens(t *testing.T) {
+	m, _ := newTestManager(t, Config{
+		Rules: []RuleConfig{{
+			Name:        "token spend",
+			Condition:   `Kind == "request"`,
+			Measure:     MeasureTokens,
+			Threshold:   1000,
+			PerProvider: true,
+		}},
+	})
+
+	m.Emit(Event{Kind: EventRequest, Provider: "openai", Tokens: 600})
+	m.Emit(Event{Kind: EventRequest, Provider: "anthropic", Tokens: 600})
+	assert.Empty(t, m.Recent(), "providers are summed separately")
+
+	m.Emit(Event{Kind: EventRequest, Provider: "openai", Tokens: 500})
+	recent := m.Recent()
+	require.Len(t, recent, 1)
+	assert.Equal(t, "openai", recent[0].Provider)
+	assert.Equal(t, 1100.0, recent[0].Value)
+	assert.Contains(t, recent[0].Message, "tokens")
+	require.NoError(t, m.Close(context.Background()))
+}
+
+func TestManager_InvalidConfig(t *testing.T) {
+	m := NewManager()
+	err := m.Reload(Config{
+		Rules: []RuleConfig{
+			{Name: "bad syntax", Condition: `StatusCode ==`},
+			{Name: "not bool", Condition: `StatusCode`},
+			{Name: "bad measure", Condition: `true`, Measure: "dollars"},
+			{Name: "unknown sink", Condition: `Kind == "test"`, Sinks: []string{"pager"}},
+			{Name: "ok", Condition: `Kind == "oauth_refresh_failed"`},
+		},
+		Sinks: []SinkConfig{
+			{Type: SinkWebhook},
+			{Name: "x", Type: "email"},
+			{Type: SinkLog},
+			{Type: SinkLog},
+		},
+	})
+	require.Error(t, err)
+	for _, want := range []string{"bad syntax", "not bool", "dollars", `unknown sink "pager"`, "has no url", `unknown type "email"`, `duplicate sink name "log"`} {
+		assert.Contains(t, err.Error(), want)
+	}
+
+	// Valid rules are still loaded
+	m.Emit(Event{Kind: EventOAuthRefreshFailed, Provider: "claude"})
+	assert.Len(t, m.Recent(), 1)
+	require.NoError(t, m.Close(context.Background()))
+}
+
+func TestManager_Test(t *testing.T) {
+	r, server := newReceiver(t)
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "nope", http.StatusForbidden)
+	}))
+	defer failing.Close()
+
+	m, _ := newTestManager(t, Config{Sinks: []SinkConfig{
+		{Name: "ok", Type: SinkWebhook, URL: server.URL},
+		{Name: "broken", Type: SinkWebhook, URL: failing.URL},
+		{Type: SinkDesktop},
+	}})
+
+	results, err := m.Test(context.Background(), "")
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, DeliveryResult{Sink: "ok", Success: true}, results[0])
+	assert.False(t, results[1].Success)
+	assert.Contains(t, results[1].Error, "403")
+	assert.False(t, results[2].Success, "desktop sinks need a notifier")
+	assert.Equal(t, 1, r.count())
+
+	var shown string
+	m.SetDesktopNotifier(func(title, message string) error {
+		shown = title
+		return nil
+	})
+	results, err = m.Test(context.Background(), "desktop")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "Tingly Box: test alert", shown)
+
+	_, err = m.Test(context.Background(), "missing")
+	assert.Error(t, err)
+}
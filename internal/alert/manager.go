@@ -0,0 +1,395 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/sirupsen/logrus"
+)
+
+// recentAlerts is how many fired alerts Recent returns
+const recentAlerts = 100
+
+// rule is a compiled rule with its per-group window state
+type rule struct {
+	config    RuleConfig
+	program   *vm.Program
+	threshold float64
+	window    time.Duration
+	cooldown  time.Duration
+	groups    map[string]*ruleGroup // by provider for per-provider rules, otherwise ""
+}
+
+// ruleGroup is the window of matching events of a rule, for one provider or all
+type ruleGroup struct {
+	points    []point
+	lastFired time.Time
+}
+
+// point is the value a matching event added to a window
+type point struct {
+	time  time.Time
+	value float64
+}
+
+// DeliveryResult is the outcome of delivering an alert to one sink
+type DeliveryResult struct {
+	Sink    string `json:"sink"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Manager evaluates alert rules over emitted events and delivers the alerts they fire. Emit never
+// blocks on delivery, which happens in the background.
+type Manager struct {
+	mu        sync.Mutex
+	rules     []*rule
+	sinks     map[string]Sink
+	sinkNames []string // in config order
+	recent    []Alert
+	notifier  DesktopNotifier
+
+	deliveries sync.WaitGroup
+	now        func() time.Time
+}
+
+// NewManager creates a manager without rules; call Reload to load a config
+func NewManager() *Manager {
+	return &Manager{
+		sinks: make(map[string]Sink),
+		now:   time.Now,
+	}
+}
+
+// Reload replaces the rules and sinks. Invalid rules and sinks are skipped and reported in the
+// returned error; the valid ones are loaded. Window state of rules whose definition is unchanged
+// is kept.
+func (m *Manager) Reload(config Config) error {
+	var errs []error
+
+	sinks := make(map[string]Sink)
+	var sinkNames []string
+	for _, sinkConfig := range config.Sinks {
+		name := sinkConfig.name()
+		if _, exists := sinks[name]; exists {
+			errs = append(errs, fmt.Errorf("duplicate sink name %q", name))
+			continue
+		}
+		sink, err := newSink(sinkConfig, m)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sinks[name] = sink
+		sinkNames = append(sinkNames, name)
+	}
+
+	var rules []*rule
+	for _, ruleConfig := range config.Rules {
+		if ruleConfig.Disabled {
+			continue
+		}
+		r, err := compileRule(ruleConfig)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, name := range ruleConfig.Sinks {
+			if _, ok := sinks[name]; !ok {
+				errs = append(errs, fmt.Errorf("rule %q refers to unknown sink %q", ruleConfig.Name, name))
+			}
+		}
+		rules = append(rules, r)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range rules {
+		for _, old := range m.rules {
+			if sameRule(old.config, r.config) {
+				r.groups = old.groups
+				break
+			}
+		}
+	}
+	m.rules = rules
+	m.sinks = sinks
+	m.sinkNames = sinkNames
+	return errors.Join(errs...)
+}
+
+// compileRule validates a rule config and fills its defaults
+func compileRule(config RuleConfig) (*rule, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("alert rule without a name")
+	}
+	if strings.TrimSpace(config.Condition) == "" {
+		return nil, fmt.Errorf("rule %q has no condition", config.Name)
+	}
+	switch config.Measure {
+	case "", MeasureCount, MeasureTokens:
+	default:
+		return nil, fmt.Errorf("rule %q has unknown measure %q", config.Name, config.Measure)
+	}
+	program, err := expr.Compile(config.Condition, expr.Env(Event{}), expr.AsBool())
+	if err != nil {
+		return nil, fmt.Errorf("rule %q: invalid condition: %w", config.Name, err)
+	}
+
+	r := &rule{
+		config:    config,
+		program:   program,
+		threshold: config.Threshold,
+		window:    time.Duration(config.Window) * time.Second,
+		cooldown:  time.Duration(config.Cooldown) * time.Second,
+		groups:    make(map[string]*ruleGroup),
+	}
+	if r.threshold <= 0 {
+		r.threshold = 1
+	}
+	if r.window <= 0 {
+		r.window = DefaultWindow
+	}
+	if r.cooldown <= 0 {
+		r.cooldown = DefaultCooldown
+	}
+	return r, nil
+}
+
+// sameRule reports whether two rule configs evaluate identically
+func sameRule(a, b RuleConfig) bool {
+	return a.Name == b.Name && a.Condition == b.Condition && a.Measure == b.Measure &&
+		a.Threshold == b.Threshold && a.Window == b.Window && a.PerProvider == b.PerProvider
+}
+
+// SetDesktopNotifier registers the notifier desktop sinks deliver with
+func (m *Manager) SetDesktopNotifier(notifier DesktopNotifier) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notifier = notifier
+}
+
+func (m *Manager) desktopNotifier() DesktopNotifier {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.notifier
+}
+
+// Emit evaluates the rules against an event and delivers the alerts it fires in the background
+func (m *Manager) Emit(event Event) {
+	if m == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = m.now()
+	}
+
+	type firing struct {
+		alert Alert
+		sinks map[string]Sink
+	}
+	var fired []firing
+
+	m.mu.Lock()
+	now := m.now()
+	for _, r := range m.rules {
+		matched, err := expr.Run(r.program, event)
+		if err != nil {
+			logrus.Debugf("Alert rule %q: %v", r.config.Name, err)
+			continue
+		}
+		if ok, _ := matched.(bool); !ok {
+			continue
+		}
+
+		value := 1.0
+		if r.config.Measure == MeasureTokens {
+			value = float64(event.Tokens)
+		}
+		key := ""
+		if r.config.PerProvider {
+			key = event.Provider
+		}
+		group := r.groups[key]
+		if group == nil {
+			group = &ruleGroup{}
+			r.groups[key] = group
+		}
+
+		group.points = append(prunePoints(group.points, now.Add(-r.window)), point{time: now, value: value})
+		sum := 0.0
+		for _, p := range group.points {
+			sum += p.value
+		}
+		if sum < r.threshold || (!group.lastFired.IsZero() && now.Sub(group.lastFired) < r.cooldown) {
+			continue
+		}
+
+		// Start a new window, so firing again after the cooldown takes a fresh breach
+		group.lastFired = now
+		group.points = nil
+		alert := newAlert(r, event, sum, now)
+		m.recordLocked(alert)
+		fired = append(fired, firing{alert: alert, sinks: m.sinksLocked(r.config.Sinks)})
+	}
+	m.mu.Unlock()
+
+	for _, f := range fired {
+		for name, sink := range f.sinks {
+			m.deliver(name, sink, f.alert)
+		}
+	}
+}
+
+// newAlert describes a fired rule
+func newAlert(r *rule, event Event, value float64, now time.Time) Alert {
+	what := "events"
+	if r.config.Measure == MeasureTokens {
+		what = "tokens"
+	}
+	message := fmt.Sprintf("%g %s within %s (threshold %g)", value, what, r.window, r.threshold)
+	if r.config.PerProvider && event.Provider != "" {
+		message = fmt.Sprintf("%s: %s", event.Provider, message)
+	}
+	if r.config.Description != "" {
+		message = r.config.Description + ". " + message
+	}
+	if event.Message != "" {
+		message += ". Last: " + event.Message
+	}
+
+	return Alert{
+		Rule:      r.config.Name,
+		Title:     "Tingly Box: " + r.config.Name,
+		Message:   message,
+		Provider:  event.Provider,
+		Value:     value,
+		Threshold: r.threshold,
+		Window:    r.window.String(),
+		Time:      now,
+		Event:     event,
+	}
+}
+
+// sinksLocked returns the named sinks, or all sinks when names is empty. Without any configured
+// sink alerts go to the log.
+func (m *Manager) sinksLocked(names []string) map[string]Sink {
+	sinks := make(map[string]Sink)
+	if len(names) == 0 {
+		names = m.sinkNames
+	}
+	for _, name := range names {
+		if sink, ok := m.sinks[name]; ok {
+			sinks[name] = sink
+		}
+	}
+	if len(m.sinks) == 0 {
+		sinks[SinkLog] = logSink{}
+	}
+	return sinks
+}
+
+// deliver sends an alert to a sink in the background
+func (m *Manager) deliver(name string, sink Sink, alert Alert) {
+	m.deliveries.Add(1)
+	go func() {
+		defer m.deliveries.Done()
+		if err := sink.Send(context.Background(), alert); err != nil {
+			logrus.Warnf("Failed to deliver alert %q to sink %q: %v", alert.Rule, name, err)
+		}
+	}()
+}
+
+// recordLocked adds a fired alert to the recent alerts
+func (m *Manager) recordLocked(alert Alert) {
+	m.recent = append(m.recent, alert)
+	if len(m.recent) > recentAlerts {
+		m.recent = m.recent[len(m.recent)-recentAlerts:]
+	}
+}
+
+// Recent returns the most recently fired alerts, newest first
+func (m *Manager) Recent() []Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alerts := make([]Alert, len(m.recent))
+	for i, alert := range m.recent {
+		alerts[len(m.recent)-1-i] = alert
+	}
+	return alerts
+}
+
+// Test delivers a test alert to the named sink, or to every sink when name is empty, and waits
+// for the outcomes
+func (m *Manager) Test(ctx context.Context, name string) ([]DeliveryResult, error) {
+	m.mu.Lock()
+	var names []string
+	if name != "" {
+		if _, ok := m.sinks[name]; !ok {
+			m.mu.Unlock()
+			return nil, fmt.Errorf("unknown sink %q", name)
+		}
+		names = []string{name}
+	}
+	sinks := m.sinksLocked(names)
+	order := append([]string(nil), m.sinkNames...)
+	if len(m.sinks) == 0 {
+		order = []string{SinkLog}
+	}
+	m.mu.Unlock()
+
+	now := m.now()
+	alert := Alert{
+		Rule:    "test",
+		Title:   "Tingly Box: test alert",
+		Message: "Alert delivery works",
+		Time:    now,
+		Event:   Event{Kind: EventTest, Time: now, Message: "test"},
+	}
+
+	var results []DeliveryResult
+	for _, sinkName := range order {
+		sink, ok := sinks[sinkName]
+		if !ok {
+			continue
+		}
+		result := DeliveryResult{Sink: sinkName, Success: true}
+		if err := sink.Send(ctx, alert); err != nil {
+			result.Success = false
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Close waits for pending deliveries until ctx is done
+func (m *Manager) Close(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.deliveries.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// prunePoints drops the leading points before cutoff from points, which is in time order
+func prunePoints(points []point, cutoff time.Time) []point {
+	i := 0
+	for i < len(points) && points[i].time.Before(cutoff) {
+		i++
+	}
+	return points[i:]
+}
@@ -0,0 +1,153 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sink delivers alerts
+type Sink interface {
+	Send(ctx context.Context, alert Alert) error
+}
+
+// DesktopNotifier shows a desktop notification; the desktop app registers one with
+// Manager.SetDesktopNotifier
+type DesktopNotifier func(title, message string) error
+
+// newSink builds the sink a config describes. Desktop sinks look up the notifier at delivery time,
+// since the desktop app registers it after the server is created.
+func newSink(config SinkConfig, m *Manager) (Sink, error) {
+	switch config.Type {
+	case SinkWebhook:
+		if config.URL == "" {
+			return nil, fmt.Errorf("webhook sink %q has no url", config.name())
+		}
+		sink := &webhookSink{config: config, client: &http.Client{}}
+		if config.Template != "" {
+			tmpl, err := template.New(config.name()).Funcs(templateFuncs).Parse(config.Template)
+			if err != nil {
+				return nil, fmt.Errorf("webhook sink %q: invalid template: %w", config.name(), err)
+			}
+			sink.template = tmpl
+		}
+		return sink, nil
+	case SinkCommand:
+		if config.Command == "" {
+			return nil, fmt.Errorf("command sink %q has no command", config.name())
+		}
+		return &commandSink{config: config}, nil
+	case SinkDesktop:
+		return &desktopSink{manager: m}, nil
+	case SinkLog:
+		return logSink{}, nil
+	default:
+		return nil, fmt.Errorf("sink %q has unknown type %q", config.name(), config.Type)
+	}
+}
+
+// templateFuncs are available in webhook templates; json quotes a value for embedding in the body
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// webhookSink posts the alert to a URL, as JSON or rendered by a template
+type webhookSink struct {
+	config   SinkConfig
+	client   *http.Client
+	template *template.Template
+}
+
+func (s *webhookSink) Send(ctx context.Context, alert Alert) error {
+	var body bytes.Buffer
+	if s.template != nil {
+		if err := s.template.Execute(&body, alert); err != nil {
+			return fmt.Errorf("failed to render template: %w", err)
+		}
+	} else if err := json.NewEncoder(&body).Encode(alert); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.timeout())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range s.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// commandSink runs a local program with the alert as JSON on stdin and its main fields in
+// TINGLY_ALERT_* environment variables
+type commandSink struct {
+	config SinkConfig
+}
+
+func (s *commandSink) Send(ctx context.Context, alert Alert) error {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.timeout())
+	defer cancel()
+	cmd := exec.CommandContext(ctx, s.config.Command, s.config.Args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(),
+		"TINGLY_ALERT_RULE="+alert.Rule,
+		"TINGLY_ALERT_TITLE="+alert.Title,
+		"TINGLY_ALERT_MESSAGE="+alert.Message,
+		"TINGLY_ALERT_PROVIDER="+alert.Provider,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// desktopSink shows the alert with the notifier of the desktop app
+type desktopSink struct {
+	manager *Manager
+}
+
+func (s *desktopSink) Send(ctx context.Context, alert Alert) error {
+	notify := s.manager.desktopNotifier()
+	if notify == nil {
+		return fmt.Errorf("desktop notifications are only available in the desktop app")
+	}
+	return notify(alert.Title, alert.Message)
+}
+
+// logSink writes the alert to the log
+type logSink struct{}
+
+func (logSink) Send(ctx context.Context, alert Alert) error {
+	logrus.WithFields(logrus.Fields{"rule": alert.Rule, "provider": alert.Provider}).Warnf("Alert: %s: %s", alert.Title, alert.Message)
+	return nil
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+// AccountFailover picks another account of the same account pool for a request the given
+// account was rate limited on
+type AccountFailover interface {
+	// FailoverAccount returns an available sibling account of providerUUID, or nil when there is none
+	FailoverAccount(providerUUID string) *typ.Provider
+}
+
+// failoverReplayKey marks a request already replayed on another account, so it is not replayed again
+type failoverReplayKey struct{}
+
+// AccountFailoverRoundTripper is an http.RoundTripper that, when an account pool member is rate
+// limited, replays the request once on another available account of the pool. The replay goes
+// through that account's own transport, so its OAuth hooks, proxy and quota tracking apply.
+type AccountFailoverRoundTripper struct {
+	transport    http.RoundTripper
+	failover     AccountFailover
+	providerUUID string
+	transportFor func(account *typ.Provider) http.RoundTripper
+}
+
+// NewAccountFailoverRoundTripper creates a new account failover round tripper. transportFor
+// returns the transport of the client serving the given account.
+func NewAccountFailoverRoundTripper(transport http.RoundTripper, failover AccountFailover, providerUUID string, transportFor func(account *typ.Provider) http.RoundTripper) *AccountFailoverRoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &AccountFailoverRoundTripper{
+		transport:    transport,
+		failover:     failover,
+		providerUUID: providerUUID,
+		transportFor: transportFor,
+	}
+}
+
+// RoundTrip executes the request and replays it once on a sibling account after a 429
+func (a *AccountFailoverRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Context().Value(failoverReplayKey{}) != nil {
+		return a.transport.RoundTrip(req)
+	}
+
+	// Keep an untouched copy for the replay; the OAuth hooks below rewrite headers in place
+	retryReq, err := cloneRequestForRetry(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.transport.RoundTrip(req)
+	if err != nil || retryReq == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return resp, err
+	}
+
+	staleToken := requestToken(retryReq)
+	if staleToken == "" {
+		return resp, nil
+	}
+	account := a.failover.FailoverAccount(a.providerUUID)
+	if account == nil || account.UUID == a.providerUUID {
+		return resp, nil
+	}
+	transport := a.transportFor(account)
+	if transport == nil {
+		return resp, nil
+	}
+
+	logrus.Infof("Account %s rate limited, replaying request on account %s", a.providerUUID, account.UUID)
+	resp.Body.Close()
+
+	for _, name := range authHeaders {
+		if v := retryReq.Header.Get(name); v != "" {
+			retryReq.Header.Set(name, strings.Replace(v, staleToken, account.GetAccessToken(), 1))
+		}
+	}
+	retryReq = retryReq.WithContext(context.WithValue(retryReq.Context(), failoverReplayKey{}, true))
+	return transport.RoundTrip(retryReq)
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+type stubAccountFailover struct {
+	calls   int
+	account *typ.Provider
+}
+
+func (s *stubAccountFailover) FailoverAccount(providerUUID string) *typ.Provider {
+	s.calls++
+	return s.account
+}
+
+func TestAccountFailoverRoundTripper_ReplaysOnSiblingAccount(t *testing.T) {
+	var seen []string
+	var bodies []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		// Every account is rate limited except the sibling
+		if r.Header.Get("Authorization") != "Bearer token-b" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	failover := &stubAccountFailover{account: &typ.Provider{UUID: "account-b", Token: "token-b"}}
+	var replayedOn string
+	transportFor := func(account *typ.Provider) http.RoundTripper {
+		replayedOn = account.UUID
+		// The sibling's own transport, which would replay again if the request were not marked
+		return NewAccountFailoverRoundTripper(http.DefaultTransport, failover, account.UUID, nil)
+	}
+	rt := NewAccountFailoverRoundTripper(http.DefaultTransport, failover, "account-a", transportFor)
+
+	req, _ := http.NewRequest(http.MethodPost, upstream.URL, strings.NewReader(`{"hello":"world"}`))
+	req.Header.Set("Authorization", "Bearer token-a")
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the replay to succeed, got status %d", resp.StatusCode)
+	}
+	if failover.calls != 1 || replayedOn != "account-b" {
+		t.Errorf("expected one failover to account-b, got %d calls to %q", failover.calls, replayedOn)
+	}
+	if len(seen) != 2 || seen[1] != "Bearer token-b" {
+		t.Errorf("unexpected upstream auth headers: %v", seen)
+	}
+	if len(bodies) != 2 || bodies[1] != bodies[0] {
+		t.Errorf("expected the replay to resend the body, got %v", bodies)
+	}
+}
+
+func TestAccountFailoverRoundTripper_ReplaysOnlyOnce(t *testing.T) {
+	calls := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer upstream.Close()
+
+	failover := &stubAccountFailover{account: &typ.Provider{UUID: "account-b", Token: "token-b"}}
+	var transportFor func(account *typ.Provider) http.RoundTripper
+	transportFor = func(account *typ.Provider) http.RoundTripper {
+		return NewAccountFailoverRoundTripper(http.DefaultTransport, failover, account.UUID, transportFor)
+	}
+	rt := NewAccountFailoverRoundTripper(http.DefaultTransport, failover, "account-a", transportFor)
+
+	req, _ := http.NewRequest(http.MethodGet, upstream.URL, nil)
+	req.Header.Set("X-Api-Key", "token-a")
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected the sibling's 429 to reach the caller, got status %d", resp.StatusCode)
+	}
+	if calls != 2 || failover.calls != 1 {
+		t.Errorf("expected one replay, got %d upstream calls and %d failovers", calls, failover.calls)
+	}
+}
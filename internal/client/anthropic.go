@@ -13,7 +13,6 @@ import (
 	"github.com/tingly-dev/tingly-box/internal/obs"
 	"github.com/tingly-dev/tingly-box/internal/protocol"
 	"github.com/tingly-dev/tingly-box/internal/typ"
-	"github.com/tingly-dev/tingly-box/pkg/oauth"
 )
 
 // AnthropicClient wraps the Anthropic SDK client
@@ -23,6 +22,9 @@ type AnthropicClient struct {
 	debugMode  bool
 	httpClient *http.Client
 	recordSink *obs.Sink
+	observer   ResponseObserver
+	refresher  TokenRefresher
+	failover   AccountFailover
 }
 
 // defaultNewAnthropicClient creates a new Anthropic client wrapper
@@ -38,29 +40,15 @@ func defaultNewAnthropicClient(provider *typ.Provider) (*AnthropicClient, error)
 		anthropicOption.WithBaseURL(apiBase),
 	}
 
-	// Create base HTTP client
-	var httpClient *http.Client
-	// Add proxy and/or custom headers if configured
-	if provider.ProxyURL != "" || provider.AuthType == typ.AuthTypeOAuth {
-		var providerType oauth.ProviderType
-		if provider.OAuthDetail != nil {
-			providerType = oauth.ProviderType(provider.OAuthDetail.ProviderType)
-		}
-		httpClient = CreateHTTPClientForProvider(providerType, provider.ProxyURL, provider.AuthType == typ.AuthTypeOAuth)
-
-		if provider.AuthType == typ.AuthTypeOAuth && provider.OAuthDetail != nil {
-			logrus.Infof("Using custom headers/params for OAuth provider type: %s", provider.OAuthDetail.ProviderType)
-		}
-		if provider.ProxyURL != "" {
-			logrus.Infof("Using proxy for Anthropic client: %s", provider.ProxyURL)
-		}
-	} else {
-		httpClient = http.DefaultClient
+	// Create base HTTP client with proxy, OAuth hooks and request hooks if configured
+	httpClient := newProviderHTTPClient(provider)
+	if provider.AuthType == typ.AuthTypeOAuth && provider.OAuthDetail != nil {
+		logrus.Infof("Using custom headers/params for OAuth provider type: %s", provider.OAuthDetail.ProviderType)
 	}
-
-	if provider.ProxyURL != "" || provider.AuthType == typ.AuthTypeOAuth {
-		options = append(options, anthropicOption.WithHTTPClient(httpClient))
+	if provider.ProxyURL != "" {
+		logrus.Infof("Using proxy for Anthropic client: %s", provider.ProxyURL)
 	}
+	options = append(options, anthropicOption.WithHTTPClient(httpClient))
 
 	anthropicClient := anthropic.NewClient(options...)
 
@@ -124,11 +112,39 @@ func (c *AnthropicClient) BetaMessagesNewStreaming(ctx context.Context, req anth
 // SetRecordSink sets the record sink for the client
 func (c *AnthropicClient) SetRecordSink(sink *obs.Sink) {
 	c.recordSink = sink
-	if sink != nil && sink.IsEnabled() {
+	if sink != nil && sink.RecordsHTTP() {
 		c.applyRecordMode()
 	}
 }
 
+// SetResponseObserver reports upstream responses of OAuth providers to the observer
+func (c *AnthropicClient) SetResponseObserver(observer ResponseObserver) {
+	if c.observer != nil || observer == nil || c.provider.AuthType != typ.AuthTypeOAuth || c.httpClient == http.DefaultClient {
+		return
+	}
+	c.observer = observer
+	c.httpClient.Transport = NewObserveRoundTripper(c.httpClient.Transport, observer, c.provider.UUID)
+}
+
+// SetTokenRefresher makes requests of OAuth providers refresh the token and replay once when it is rejected
+func (c *AnthropicClient) SetTokenRefresher(refresher TokenRefresher) {
+	if c.refresher != nil || refresher == nil || c.provider.AuthType != typ.AuthTypeOAuth || c.httpClient == http.DefaultClient {
+		return
+	}
+	c.refresher = refresher
+	c.httpClient.Transport = NewAuthRetryRoundTripper(c.httpClient.Transport, refresher, c.provider.UUID)
+}
+
+// SetAccountFailover makes requests of OAuth providers that were rate limited replay once on
+// another account of the same pool; transportFor returns the transport serving that account
+func (c *AnthropicClient) SetAccountFailover(failover AccountFailover, transportFor func(account *typ.Provider) http.RoundTripper) {
+	if c.failover != nil || failover == nil || c.provider.AuthType != typ.AuthTypeOAuth || c.httpClient == http.DefaultClient {
+		return
+	}
+	c.failover = failover
+	c.httpClient.Transport = NewAccountFailoverRoundTripper(c.httpClient.Transport, failover, c.provider.UUID, transportFor)
+}
+
 // applyRecordMode wraps the HTTP client with a record round tripper
 func (c *AnthropicClient) applyRecordMode() {
 	if c.recordSink == nil {
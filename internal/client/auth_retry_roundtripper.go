@@ -0,0 +1,153 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TokenRefresher refreshes the OAuth access token of a provider on demand.
+// staleToken is the token the upstream rejected; implementations return the token to retry with.
+type TokenRefresher interface {
+	RefreshAccessToken(ctx context.Context, providerUUID, staleToken string) (string, error)
+}
+
+// authHeaders are the headers that may carry the provider access token before OAuth hooks run
+var authHeaders = []string{"Authorization", "X-Api-Key", "X-Goog-Api-Key"}
+
+// maxAuthErrorPeek bounds how much of a 403 body is inspected for an auth error
+const maxAuthErrorPeek = 64 * 1024
+
+// AuthRetryRoundTripper is an http.RoundTripper that, when an OAuth provider rejects its access
+// token, refreshes the token and replays the request once with the new one
+type AuthRetryRoundTripper struct {
+	transport    http.RoundTripper
+	refresher    TokenRefresher
+	providerUUID string
+}
+
+// NewAuthRetryRoundTripper creates a new auth retry round tripper
+func NewAuthRetryRoundTripper(transport http.RoundTripper, refresher TokenRefresher, providerUUID string) *AuthRetryRoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &AuthRetryRoundTripper{
+		transport:    transport,
+		refresher:    refresher,
+		providerUUID: providerUUID,
+	}
+}
+
+// RoundTrip executes the request and replays it once after a token refresh on 401/403 auth errors
+func (a *AuthRetryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Keep an untouched copy for the replay; the OAuth hooks below rewrite headers in place
+	retryReq, err := cloneRequestForRetry(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.transport.RoundTrip(req)
+	if err != nil || retryReq == nil || !isAuthFailure(resp) {
+		return resp, err
+	}
+
+	staleToken := requestToken(retryReq)
+	if staleToken == "" {
+		return resp, nil
+	}
+
+	newToken, refreshErr := a.refresher.RefreshAccessToken(req.Context(), a.providerUUID, staleToken)
+	if refreshErr != nil {
+		logrus.Warnf("OAuth token refresh after %d failed for provider %s: %v", resp.StatusCode, a.providerUUID, refreshErr)
+		return resp, nil
+	}
+	if newToken == "" || newToken == staleToken {
+		return resp, nil
+	}
+
+	logrus.Infof("Replaying request for provider %s with refreshed OAuth token", a.providerUUID)
+	resp.Body.Close()
+
+	for _, name := range authHeaders {
+		if v := retryReq.Header.Get(name); v != "" {
+			retryReq.Header.Set(name, strings.Replace(v, staleToken, newToken, 1))
+		}
+	}
+	return a.transport.RoundTrip(retryReq)
+}
+
+// cloneRequestForRetry returns a copy of req with a fresh body, or nil if the body cannot be replayed
+func cloneRequestForRetry(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return clone, nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, nil
+		}
+		clone.Body = body
+		return clone, nil
+	}
+
+	// Buffer the body so both the original and the replay can read it
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	clone.Body = io.NopCloser(bytes.NewReader(data))
+	return clone, nil
+}
+
+// requestToken extracts the access token sent with the request
+func requestToken(req *http.Request) string {
+	for _, name := range authHeaders {
+		v := req.Header.Get(name)
+		if v == "" {
+			continue
+		}
+		if token, ok := strings.CutPrefix(v, "Bearer "); ok {
+			return token
+		}
+		return v
+	}
+	return ""
+}
+
+// isAuthFailure reports whether the response rejects the credentials. Every 401 qualifies;
+// a 403 only when its body describes an authentication problem rather than a permission one.
+func isAuthFailure(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return true
+	case http.StatusForbidden:
+	default:
+		return false
+	}
+
+	peek, err := io.ReadAll(io.LimitReader(resp.Body, maxAuthErrorPeek))
+	// Restore the body for the caller whatever we decide
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peek), resp.Body), resp.Body}
+	if err != nil {
+		return false
+	}
+
+	body := strings.ToLower(string(peek))
+	for _, marker := range []string{"authentication_error", "invalid_token", "unauthenticated", "token expired", "token has expired", "revoked", "oauth"} {
+		if strings.Contains(body, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type stubTokenRefresher struct {
+	calls      int
+	staleToken string
+	newToken   string
+}
+
+func (s *stubTokenRefresher) RefreshAccessToken(ctx context.Context, providerUUID, staleToken string) (string, error) {
+	s.calls++
+	s.staleToken = staleToken
+	return s.newToken, nil
+}
+
+func TestAuthRetryRoundTripper_ReplaysWithRefreshedToken(t *testing.T) {
+	var seen []string
+	var bodies []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	refresher := &stubTokenRefresher{newToken: "fresh"}
+	rt := NewAuthRetryRoundTripper(http.DefaultTransport, refresher, "provider-1")
+
+	req, _ := http.NewRequest(http.MethodPost, upstream.URL, strings.NewReader(`{"hello":"world"}`))
+	req.Header.Set("Authorization", "Bearer stale")
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected replay to succeed, got status %d", resp.StatusCode)
+	}
+	if refresher.calls != 1 || refresher.staleToken != "stale" {
+		t.Errorf("expected one refresh of the stale token, got %d calls with %q", refresher.calls, refresher.staleToken)
+	}
+	if len(seen) != 2 || seen[1] != "Bearer fresh" {
+		t.Errorf("unexpected upstream auth headers: %v", seen)
+	}
+	if len(bodies) != 2 || bodies[1] != bodies[0] {
+		t.Errorf("expected the replay to resend the body, got %v", bodies)
+	}
+}
+
+func TestAuthRetryRoundTripper_IgnoresPermissionErrors(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":{"type":"permission_error","message":"model not allowed"}}`))
+	}))
+	defer upstream.Close()
+
+	refresher := &stubTokenRefresher{newToken: "fresh"}
+	rt := NewAuthRetryRoundTripper(http.DefaultTransport, refresher, "provider-1")
+
+	req, _ := http.NewRequest(http.MethodGet, upstream.URL, nil)
+	req.Header.Set("X-Api-Key", "stale")
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if refresher.calls != 0 {
+		t.Errorf("expected no refresh for a permission error, got %d", refresher.calls)
+	}
+	if !strings.Contains(string(body), "permission_error") {
+		t.Errorf("expected the original body to be preserved, got %q", body)
+	}
+}
@@ -19,17 +19,15 @@ type GoogleClient struct {
 	debugMode  bool
 	httpClient *http.Client
 	recordSink *obs.Sink
+	observer   ResponseObserver
+	refresher  TokenRefresher
+	failover   AccountFailover
 }
 
 // NewGoogleClient creates a new Google client wrapper
 func NewGoogleClient(provider *typ.Provider) (*GoogleClient, error) {
-	// Create base HTTP client
-	var httpClient *http.Client
-	if provider.ProxyURL != "" {
-		httpClient = CreateHTTPClientWithProxy(provider.ProxyURL)
-	} else {
-		httpClient = http.DefaultClient
-	}
+	// Create base HTTP client with proxy, OAuth hooks and request hooks if configured
+	httpClient := newProviderHTTPClient(provider)
 
 	// Create Google client config
 	config := &genai.ClientConfig{
@@ -83,11 +81,39 @@ func (c *GoogleClient) GenerateContentStream(ctx context.Context, model string,
 // SetRecordSink sets the record sink for the client
 func (c *GoogleClient) SetRecordSink(sink *obs.Sink) {
 	c.recordSink = sink
-	if sink != nil && sink.IsEnabled() {
+	if sink != nil && sink.RecordsHTTP() {
 		c.applyRecordMode()
 	}
 }
 
+// SetResponseObserver reports upstream responses of OAuth providers to the observer
+func (c *GoogleClient) SetResponseObserver(observer ResponseObserver) {
+	if c.observer != nil || observer == nil || c.provider.AuthType != typ.AuthTypeOAuth || c.httpClient == http.DefaultClient {
+		return
+	}
+	c.observer = observer
+	c.httpClient.Transport = NewObserveRoundTripper(c.httpClient.Transport, observer, c.provider.UUID)
+}
+
+// SetTokenRefresher makes requests of OAuth providers refresh the token and replay once when it is rejected
+func (c *GoogleClient) SetTokenRefresher(refresher TokenRefresher) {
+	if c.refresher != nil || refresher == nil || c.provider.AuthType != typ.AuthTypeOAuth || c.httpClient == http.DefaultClient {
+		return
+	}
+	c.refresher = refresher
+	c.httpClient.Transport = NewAuthRetryRoundTripper(c.httpClient.Transport, refresher, c.provider.UUID)
+}
+
+// SetAccountFailover makes requests of OAuth providers that were rate limited replay once on
+// another account of the same pool; transportFor returns the transport serving that account
+func (c *GoogleClient) SetAccountFailover(failover AccountFailover, transportFor func(account *typ.Provider) http.RoundTripper) {
+	if c.failover != nil || failover == nil || c.provider.AuthType != typ.AuthTypeOAuth || c.httpClient == http.DefaultClient {
+		return
+	}
+	c.failover = failover
+	c.httpClient.Transport = NewAccountFailoverRoundTripper(c.httpClient.Transport, failover, c.provider.UUID, transportFor)
+}
+
 // applyRecordMode wraps the HTTP client with a record round tripper
 func (c *GoogleClient) applyRecordMode() {
 	if c.recordSink == nil {
@@ -0,0 +1,256 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/tingly-dev/tingly-box/internal/typ"
+	"github.com/tingly-dev/tingly-box/pkg/oauth"
+)
+
+// HookFunc is a function that can modify the request before it's sent
+type HookFunc func(req *http.Request) error
+
+// defaultOAuthHooks are the built-in request hooks per OAuth provider type. They apply to every
+// client, including ones built before or without provider templates being loaded.
+var defaultOAuthHooks = map[oauth.ProviderType]HookFunc{
+	oauth.ProviderClaudeCode:  claudeCodeHook,
+	oauth.ProviderAntigravity: antigravityHook,
+}
+
+// oauthHooks holds the declarative request hooks per OAuth provider type loaded from provider
+// templates (see SetOAuthHooks). They override the built-in hooks, so upstream client-version
+// changes only need a template update.
+var (
+	oauthHooks   = map[oauth.ProviderType]HookFunc{}
+	oauthHooksMu sync.RWMutex
+)
+
+func antigravityHook(req *http.Request) error {
+	key := req.Header.Get("X-Goog-Api-Key")
+
+	req.Header = http.Header{}
+	req.Header.Set("User-Agent", "antigravity/1.11.3 Darwin/arm64")
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", key))
+	}
+	return nil
+}
+
+// claudeCodeHook applies Claude Code OAuth specific request modifications:
+// - Converts X-Api-Key header to Authorization header
+// - Adds required Claude Code specific headers
+// - Adds beta query parameter
+func claudeCodeHook(req *http.Request) error {
+	// Convert X-Api-Key to Authorization header
+	key := req.Header.Get("X-Api-Key")
+	if key != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", key))
+		req.Header.Del("X-Api-Key")
+	}
+
+	// Set Claude Code specific headers
+	req.Header.Set("accept", "application/json")
+	req.Header.Set("anthropic-beta", "claude-code-20250219,oauth-2025-04-20,interleaved-thinking-2025-05-14")
+	req.Header.Set("anthropic-dangerous-direct-browser-access", "true")
+	req.Header.Set("anthropic-version", "2023-06-01")
+	req.Header.Set("user-agent", "claude-cli/2.0.76 (external, cli)")
+	req.Header.Set("x-app", "cli")
+	req.Header.Set("x-stainless-helper-method", "stream")
+	req.Header.Set("x-stainless-retry-count", "0")
+	req.Header.Set("x-stainless-runtime-version", "v25.2.1")
+	req.Header.Set("x-stainless-package-version", "0.70.0")
+	req.Header.Set("x-stainless-runtime", "node")
+	req.Header.Set("x-stainless-lang", "js")
+	req.Header.Set("x-stainless-arch", "arm64")
+	req.Header.Set("x-stainless-os", "MacOS")
+	req.Header.Set("x-stainless-timeout", "3000")
+
+	// Add beta query parameter if not already present
+	q := req.URL.Query()
+	if !q.Has("beta") {
+		q.Add("beta", "true")
+		req.URL.RawQuery = q.Encode()
+	}
+
+	return nil
+}
+
+// SetOAuthHooks replaces the template request hooks used for OAuth provider types. Provider
+// types without a template hook keep their built-in hook.
+// Hooks that fail to compile are skipped and reported in the returned error.
+func SetOAuthHooks(hooks map[oauth.ProviderType]*typ.RequestHook) error {
+	compiled := make(map[oauth.ProviderType]HookFunc, len(hooks))
+	var errs []string
+	for providerType, hook := range hooks {
+		fn, err := CompileRequestHook(hook)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", providerType, err))
+			continue
+		}
+		if fn != nil {
+			compiled[providerType] = fn
+		}
+	}
+
+	oauthHooksMu.Lock()
+	oauthHooks = compiled
+	oauthHooksMu.Unlock()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid request hooks: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// GetOAuthHook returns the hook function for the given provider type: the template hook when
+// one is loaded, otherwise the built-in hook
+func GetOAuthHook(providerType oauth.ProviderType) HookFunc {
+	oauthHooksMu.RLock()
+	defer oauthHooksMu.RUnlock()
+	if hook, ok := oauthHooks[providerType]; ok {
+		return hook
+	}
+	return defaultOAuthHooks[providerType]
+}
+
+// hookTemplateData is the data available to templated hook values
+type hookTemplateData struct {
+	Token  string
+	header http.Header
+	query  map[string][]string
+}
+
+// Header returns a header of the original request
+func (d hookTemplateData) Header(name string) string { return d.header.Get(name) }
+
+// Query returns a query parameter of the original request
+func (d hookTemplateData) Query(name string) string {
+	if v := d.query[name]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// Env returns an environment variable
+func (d hookTemplateData) Env(name string) string { return os.Getenv(name) }
+
+// hookValue is a literal or templated header/query value
+type hookValue struct {
+	literal string
+	tmpl    *template.Template
+}
+
+func compileHookValue(name, value string) (hookValue, error) {
+	if !strings.Contains(value, "{{") {
+		return hookValue{literal: value}, nil
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(value)
+	if err != nil {
+		return hookValue{}, fmt.Errorf("value of %s: %w", name, err)
+	}
+	return hookValue{tmpl: tmpl}, nil
+}
+
+func (v hookValue) render(data hookTemplateData) (string, error) {
+	if v.tmpl == nil {
+		return v.literal, nil
+	}
+	var sb strings.Builder
+	if err := v.tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// CompileRequestHook turns a declarative hook into a HookFunc. It returns nil for an empty hook.
+func CompileRequestHook(hook *typ.RequestHook) (HookFunc, error) {
+	if hook.IsEmpty() {
+		return nil, nil
+	}
+	if hook.Auth != nil && (hook.Auth.From == "" || hook.Auth.To == "") {
+		return nil, fmt.Errorf("auth rewrite needs both from and to headers")
+	}
+
+	setHeaders := make(map[string]hookValue, len(hook.SetHeaders))
+	for name, value := range hook.SetHeaders {
+		v, err := compileHookValue(name, value)
+		if err != nil {
+			return nil, err
+		}
+		setHeaders[name] = v
+	}
+	addQuery := make(map[string]hookValue, len(hook.AddQuery))
+	for name, value := range hook.AddQuery {
+		v, err := compileHookValue(name, value)
+		if err != nil {
+			return nil, err
+		}
+		addQuery[name] = v
+	}
+
+	return func(req *http.Request) error {
+		original := req.Header.Clone()
+		data := hookTemplateData{
+			Token:  requestToken(req),
+			header: original,
+			query:  req.URL.Query(),
+		}
+
+		if hook.ClearHeaders {
+			req.Header = http.Header{}
+		}
+
+		if auth := hook.Auth; auth != nil {
+			credential := original.Get(auth.From)
+			if scheme, ok := strings.CutPrefix(credential, "Bearer "); ok {
+				credential = scheme
+			}
+			req.Header.Del(auth.From)
+			if credential != "" {
+				if auth.Scheme != "" {
+					credential = auth.Scheme + " " + credential
+				}
+				req.Header.Set(auth.To, credential)
+			}
+		}
+
+		for _, name := range hook.RemoveHeaders {
+			req.Header.Del(name)
+		}
+
+		for name, value := range setHeaders {
+			rendered, err := value.render(data)
+			if err != nil {
+				return fmt.Errorf("request hook header %s: %w", name, err)
+			}
+			req.Header.Set(name, rendered)
+		}
+
+		if len(addQuery) > 0 {
+			q := req.URL.Query()
+			changed := false
+			for name, value := range addQuery {
+				if q.Has(name) {
+					continue
+				}
+				rendered, err := value.render(data)
+				if err != nil {
+					return fmt.Errorf("request hook query %s: %w", name, err)
+				}
+				q.Add(name, rendered)
+				changed = true
+			}
+			if changed {
+				req.URL.RawQuery = q.Encode()
+			}
+		}
+
+		return nil
+	}, nil
+}
@@ -0,0 +1,147 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tingly-dev/tingly-box/internal/typ"
+	"github.com/tingly-dev/tingly-box/pkg/oauth"
+)
+
+func TestCompileRequestHook(t *testing.T) {
+	hook, err := CompileRequestHook(&typ.RequestHook{
+		Auth:          &typ.AuthRewrite{From: "X-Api-Key", To: "Authorization", Scheme: "Bearer"},
+		RemoveHeaders: []string{"X-Stainless-Os"},
+		SetHeaders: map[string]string{
+			"user-agent":  "claude-cli/2.0.76 (external, cli)",
+			"x-session":   `{{.Header "X-Request-Id"}}-{{.Query "trace"}}`,
+			"x-token-len": `{{len .Token}}`,
+		},
+		AddQuery: map[string]string{"beta": "true", "trace": "ignored"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "https://api.example.com/v1/messages?trace=abc", nil)
+	req.Header.Set("X-Api-Key", "secret")
+	req.Header.Set("X-Request-Id", "req-1")
+	req.Header.Set("X-Stainless-Os", "Linux")
+	if err := hook(req); err != nil {
+		t.Fatalf("unexpected hook error: %v", err)
+	}
+
+	if got := req.Header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("expected Authorization 'Bearer secret', got %q", got)
+	}
+	if req.Header.Get("X-Api-Key") != "" {
+		t.Error("expected X-Api-Key to be removed")
+	}
+	if req.Header.Get("X-Stainless-Os") != "" {
+		t.Error("expected X-Stainless-Os to be removed")
+	}
+	if got := req.Header.Get("User-Agent"); got != "claude-cli/2.0.76 (external, cli)" {
+		t.Errorf("unexpected user-agent %q", got)
+	}
+	if got := req.Header.Get("X-Session"); got != "req-1-abc" {
+		t.Errorf("expected templated header 'req-1-abc', got %q", got)
+	}
+	if got := req.Header.Get("X-Token-Len"); got != "6" {
+		t.Errorf("expected token length 6, got %q", got)
+	}
+	q := req.URL.Query()
+	if q.Get("beta") != "true" || q.Get("trace") != "abc" {
+		t.Errorf("unexpected query %q", req.URL.RawQuery)
+	}
+}
+
+func TestCompileRequestHook_ClearHeaders(t *testing.T) {
+	hook, err := CompileRequestHook(&typ.RequestHook{
+		ClearHeaders: true,
+		Auth:         &typ.AuthRewrite{From: "X-Goog-Api-Key", To: "Authorization", Scheme: "Bearer"},
+		SetHeaders:   map[string]string{"Content-Type": "application/json"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "https://example.com", nil)
+	req.Header.Set("X-Goog-Api-Key", "key")
+	req.Header.Set("X-Goog-Api-Client", "genai")
+	if err := hook(req); err != nil {
+		t.Fatalf("unexpected hook error: %v", err)
+	}
+
+	if len(req.Header) != 2 {
+		t.Errorf("expected only Authorization and Content-Type, got %v", req.Header)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer key" {
+		t.Errorf("expected Authorization 'Bearer key', got %q", got)
+	}
+}
+
+func TestCompileRequestHook_Invalid(t *testing.T) {
+	if _, err := CompileRequestHook(&typ.RequestHook{SetHeaders: map[string]string{"x": "{{.Missing"}}); err == nil {
+		t.Error("expected template parse error")
+	}
+	if _, err := CompileRequestHook(&typ.RequestHook{Auth: &typ.AuthRewrite{From: "X-Api-Key"}}); err == nil {
+		t.Error("expected error for auth rewrite without target header")
+	}
+	if hook, err := CompileRequestHook(nil); hook != nil || err != nil {
+		t.Error("expected nil hook for nil definition")
+	}
+}
+
+func TestGetOAuthHook_BuiltInDefaults(t *testing.T) {
+	t.Cleanup(func() { _ = SetOAuthHooks(nil) })
+
+	// Without templates loaded, OAuth clients still get the built-in hook
+	if err := SetOAuthHooks(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := CreateHTTPClientForProvider(oauth.ProviderClaudeCode, "", true)
+	var seen http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, upstream.URL+"/v1/messages", nil)
+	req.Header.Set("X-Api-Key", "secret")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if got := seen.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("expected the built-in hook to set Authorization, got %q", got)
+	}
+	if seen.Get("Anthropic-Beta") == "" {
+		t.Error("expected the built-in hook to set anthropic-beta")
+	}
+
+	// A template hook loaded after the client was built overrides the built-in one
+	err = SetOAuthHooks(map[oauth.ProviderType]*typ.RequestHook{
+		oauth.ProviderClaudeCode: {SetHeaders: map[string]string{"user-agent": "template"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req, _ = http.NewRequest(http.MethodPost, upstream.URL+"/v1/messages", nil)
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if got := seen.Get("User-Agent"); got != "template" {
+		t.Errorf("expected the template hook to apply, got user-agent %q", got)
+	}
+	if seen.Get("Anthropic-Beta") != "" {
+		t.Error("expected the template hook to replace the built-in one")
+	}
+
+	if GetOAuthHook(oauth.ProviderAntigravity) == nil {
+		t.Error("expected the built-in antigravity hook to remain")
+	}
+}
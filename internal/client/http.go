@@ -1,77 +1,16 @@
 package client
 
 import (
-	"fmt"
 	"net/http"
 	"net/url"
 
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/proxy"
 
+	"github.com/tingly-dev/tingly-box/internal/typ"
 	"github.com/tingly-dev/tingly-box/pkg/oauth"
 )
 
-// HookFunc is a function that can modify the request before it's sent
-type HookFunc func(req *http.Request) error
-
-// oauthHookFunctions defines custom hooks for OAuth providers based on provider type
-// Each hook handles custom headers, query params, and any special request modifications
-var oauthHookFunctions = map[oauth.ProviderType]HookFunc{
-	oauth.ProviderClaudeCode:  claudeCodeHook,
-	oauth.ProviderAntigravity: antigravityHook,
-}
-
-func antigravityHook(req *http.Request) error {
-	key := req.Header.Get("X-Goog-Api-Key")
-
-	req.Header = http.Header{}
-	req.Header.Set("User-Agent", "antigravity/1.11.3 Darwin/arm64")
-	req.Header.Set("Content-Type", "application/json")
-	if key != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", key))
-	}
-	return nil
-}
-
-// claudeCodeHook applies Claude Code OAuth specific request modifications:
-// - Converts X-Api-Key header to Authorization header
-// - Adds required Claude Code specific headers
-// - Adds beta query parameter
-func claudeCodeHook(req *http.Request) error {
-	// Convert X-Api-Key to Authorization header
-	key := req.Header.Get("X-Api-Key")
-	if key != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", key))
-		req.Header.Del("X-Api-Key")
-	}
-
-	// Set Claude Code specific headers
-	req.Header.Set("accept", "application/json")
-	req.Header.Set("anthropic-beta", "claude-code-20250219,oauth-2025-04-20,interleaved-thinking-2025-05-14")
-	req.Header.Set("anthropic-dangerous-direct-browser-access", "true")
-	req.Header.Set("anthropic-version", "2023-06-01")
-	req.Header.Set("user-agent", "claude-cli/2.0.76 (external, cli)")
-	req.Header.Set("x-app", "cli")
-	req.Header.Set("x-stainless-helper-method", "stream")
-	req.Header.Set("x-stainless-retry-count", "0")
-	req.Header.Set("x-stainless-runtime-version", "v25.2.1")
-	req.Header.Set("x-stainless-package-version", "0.70.0")
-	req.Header.Set("x-stainless-runtime", "node")
-	req.Header.Set("x-stainless-lang", "js")
-	req.Header.Set("x-stainless-arch", "arm64")
-	req.Header.Set("x-stainless-os", "MacOS")
-	req.Header.Set("x-stainless-timeout", "3000")
-
-	// Add beta query parameter if not already present
-	q := req.URL.Query()
-	if !q.Has("beta") {
-		q.Add("beta", "true")
-		req.URL.RawQuery = q.Encode()
-	}
-
-	return nil
-}
-
 // requestModifier wraps an http.RoundTripper to apply hooks to each request
 type requestModifier struct {
 	http.RoundTripper
@@ -88,15 +27,6 @@ func (t *requestModifier) RoundTrip(req *http.Request) (*http.Response, error) {
 	return t.RoundTripper.RoundTrip(req)
 }
 
-// GetOAuthHook returns the hook function for the given provider type
-func GetOAuthHook(providerType oauth.ProviderType) HookFunc {
-	hook, ok := oauthHookFunctions[providerType]
-	if !ok {
-		return nil
-	}
-	return hook
-}
-
 // CreateHTTPClientWithProxy creates an HTTP client with proxy support
 func CreateHTTPClientWithProxy(proxyURL string) *http.Client {
 	if proxyURL == "" {
@@ -150,20 +80,83 @@ func CreateHTTPClientForProvider(providerType oauth.ProviderType, proxyURL strin
 	client := CreateHTTPClientWithProxy(proxyURL)
 
 	if isOAuth {
-		hook := GetOAuthHook(providerType)
+		// OAuth clients get their own transport chain (hooks, response observers), so never
+		// modify the shared default client
+		if client == http.DefaultClient {
+			client = &http.Client{}
+		}
+
+		// Look the hook up per request, so template hooks loaded after the client was built apply
+		hook := func(req *http.Request) error {
+			if hook := GetOAuthHook(providerType); hook != nil {
+				return hook(req)
+			}
+			return nil
+		}
+
+		// Use the client's transport, or default transport if nil (http.DefaultClient has nil Transport)
+		transport := client.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+
+		client.Transport = &requestModifier{
+			RoundTripper: transport,
+			hooks:        []HookFunc{hook},
+		}
+	}
+
+	return client
+}
+
+// newProviderHTTPClient creates the HTTP client used by the SDK clients of a provider: proxy, OAuth
+// provider type hook, then the provider's own request hook, all inside the upstream call span
+func newProviderHTTPClient(provider *typ.Provider) *http.Client {
+	client := newProviderBaseHTTPClient(provider)
+	if client == http.DefaultClient {
+		client = &http.Client{}
+	}
+	client.Transport = NewTraceRoundTripper(client.Transport, provider.Name)
+	return client
+}
 
-		if hook != nil {
-			// Use the client's transport, or default transport if nil (http.DefaultClient has nil Transport)
+// newProviderBaseHTTPClient applies the proxy and request hooks of a provider. It returns
+// http.DefaultClient when none of these apply.
+func newProviderBaseHTTPClient(provider *typ.Provider) *http.Client {
+	isOAuth := provider.AuthType == typ.AuthTypeOAuth
+	if provider.ProxyURL == "" && !isOAuth && provider.RequestHook.IsEmpty() {
+		return http.DefaultClient
+	}
+
+	var providerType oauth.ProviderType
+	if provider.OAuthDetail != nil {
+		providerType = oauth.ProviderType(provider.OAuthDetail.ProviderType)
+	}
+	client := CreateHTTPClientForProvider(providerType, provider.ProxyURL, isOAuth)
+
+	if !provider.RequestHook.IsEmpty() {
+		hook, err := CompileRequestHook(provider.RequestHook)
+		if err != nil {
+			logrus.Errorf("Ignoring invalid request hook for provider %s: %v", provider.Name, err)
+			return client
+		}
+		if client == http.DefaultClient {
+			client = &http.Client{}
+		}
+		// Run after the provider type hook so per-provider settings win
+		if modifier, ok := client.Transport.(*requestModifier); ok {
+			modifier.hooks = append(modifier.hooks, hook)
+		} else {
 			transport := client.Transport
 			if transport == nil {
 				transport = http.DefaultTransport
 			}
-
 			client.Transport = &requestModifier{
 				RoundTripper: transport,
 				hooks:        []HookFunc{hook},
 			}
 		}
+		logrus.Infof("Using custom request hook for provider: %s", provider.Name)
 	}
 
 	return client
@@ -0,0 +1,252 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Defaults for MediaFetchConfig
+const (
+	DefaultMediaMaxBytes = 20 * 1024 * 1024 // Gemini's inline request limit
+	DefaultMediaTimeout  = 30 * time.Second
+)
+
+// MediaFetchConfig controls which remote media may be downloaded and inlined
+type MediaFetchConfig struct {
+	MaxBytes             int64    `json:"max_bytes,omitempty" yaml:"max_bytes,omitempty"`                           // Largest accepted download (default 20MB)
+	Timeout              int64    `json:"timeout,omitempty" yaml:"timeout,omitempty"`                               // Per-download timeout in seconds (default 30)
+	AllowedSchemes       []string `json:"allowed_schemes,omitempty" yaml:"allowed_schemes,omitempty"`               // Default: https, http
+	AllowedHosts         []string `json:"allowed_hosts,omitempty" yaml:"allowed_hosts,omitempty"`                   // Host allow-list; empty allows any public host. "*.example.com" matches subdomains
+	AllowedMIMETypes     []string `json:"allowed_mime_types,omitempty" yaml:"allowed_mime_types,omitempty"`         // Prefixes such as "image/"; default images and PDF
+	AllowPrivateNetworks bool     `json:"allow_private_networks,omitempty" yaml:"allow_private_networks,omitempty"` // Allow loopback/private addresses (off to prevent SSRF)
+}
+
+// Media is a downloaded media file
+type Media struct {
+	MIMEType string
+	Data     []byte
+}
+
+// MediaFetcher downloads remote media so it can be inlined for backends that reject URLs.
+// Create one per request: downloads are cached for the fetcher's lifetime, so an image
+// referenced several times in a conversation is fetched once.
+type MediaFetcher struct {
+	config MediaFetchConfig
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]*mediaResult
+}
+
+type mediaResult struct {
+	once  sync.Once
+	media *Media
+	err   error
+}
+
+// NewMediaFetcher creates a media fetcher; proxyURL is the provider's ProxyURL and may be empty
+func NewMediaFetcher(config MediaFetchConfig, proxyURL string) *MediaFetcher {
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = DefaultMediaMaxBytes
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = int64(DefaultMediaTimeout / time.Second)
+	}
+	if len(config.AllowedSchemes) == 0 {
+		config.AllowedSchemes = []string{"https", "http"}
+	}
+	if len(config.AllowedMIMETypes) == 0 {
+		config.AllowedMIMETypes = []string{"image/", "application/pdf"}
+	}
+
+	base := CreateHTTPClientWithProxy(proxyURL)
+	client := &http.Client{
+		Transport: base.Transport,
+		Timeout:   time.Duration(config.Timeout) * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return fmt.Errorf("too many redirects")
+			}
+			// Redirects must satisfy the same allow-lists as the original URL
+			return config.checkURL(req.URL)
+		},
+	}
+	if client.Transport == nil && !config.AllowPrivateNetworks {
+		client.Transport = &http.Transport{DialContext: publicOnlyDialContext}
+	}
+
+	return &MediaFetcher{
+		config: config,
+		client: client,
+		cache:  make(map[string]*mediaResult),
+	}
+}
+
+// Fetch downloads the media at rawURL, returning the cached result for repeated URLs
+func (f *MediaFetcher) Fetch(ctx context.Context, rawURL string) (*Media, error) {
+	f.mu.Lock()
+	result, ok := f.cache[rawURL]
+	if !ok {
+		result = &mediaResult{}
+		f.cache[rawURL] = result
+	}
+	f.mu.Unlock()
+
+	result.once.Do(func() {
+		result.media, result.err = f.fetch(ctx, rawURL)
+	})
+	return result.media, result.err
+}
+
+// Resolve is Fetch in the shape expected by the request converters
+func (f *MediaFetcher) Resolve(ctx context.Context, rawURL string) (string, []byte, error) {
+	media, err := f.Fetch(ctx, rawURL)
+	if err != nil {
+		return "", nil, err
+	}
+	return media.MIMEType, media.Data, nil
+}
+
+func (f *MediaFetcher) fetch(ctx context.Context, rawURL string) (*Media, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid media URL: %w", err)
+	}
+	if err := f.config.checkURL(u); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch media %s: %w", u.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch media %s: status %d", u.Redacted(), resp.StatusCode)
+	}
+	if resp.ContentLength > f.config.MaxBytes {
+		return nil, fmt.Errorf("media %s is %d bytes, larger than the %d byte limit", u.Redacted(), resp.ContentLength, f.config.MaxBytes)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.config.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read media %s: %w", u.Redacted(), err)
+	}
+	if int64(len(data)) > f.config.MaxBytes {
+		return nil, fmt.Errorf("media %s is larger than the %d byte limit", u.Redacted(), f.config.MaxBytes)
+	}
+
+	mimeType := sniffMediaType(resp.Header.Get("Content-Type"), data)
+	if !f.config.mimeAllowed(mimeType) {
+		return nil, fmt.Errorf("media %s has unsupported type %s", u.Redacted(), mimeType)
+	}
+
+	return &Media{MIMEType: mimeType, Data: data}, nil
+}
+
+// sniffMediaType prefers the sniffed type over a generic or missing Content-Type header
+func sniffMediaType(header string, data []byte) string {
+	declared, _, _ := mime.ParseMediaType(header)
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	switch {
+	case declared == "" || declared == "application/octet-stream" || declared == "binary/octet-stream":
+		return sniffed
+	case sniffed != "application/octet-stream" && sniffed != "text/plain":
+		// Trust the bytes over a mislabelled header
+		return sniffed
+	default:
+		return declared
+	}
+}
+
+// checkURL validates the scheme and host against the allow-lists
+func (c MediaFetchConfig) checkURL(u *url.URL) error {
+	schemeAllowed := false
+	for _, scheme := range c.AllowedSchemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			schemeAllowed = true
+			break
+		}
+	}
+	if !schemeAllowed {
+		return fmt.Errorf("media URL scheme %q is not allowed", u.Scheme)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return fmt.Errorf("media URL has no host")
+	}
+	if len(c.AllowedHosts) > 0 && !hostAllowed(host, c.AllowedHosts) {
+		return fmt.Errorf("media host %q is not allowed", host)
+	}
+	if !c.AllowPrivateNetworks {
+		if ip := net.ParseIP(host); ip != nil && isPrivateIP(ip) {
+			return fmt.Errorf("media host %q is a private address", host)
+		}
+		if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return fmt.Errorf("media host %q is a private address", host)
+		}
+	}
+	return nil
+}
+
+func (c MediaFetchConfig) mimeAllowed(mimeType string) bool {
+	for _, allowed := range c.AllowedMIMETypes {
+		if mimeType == allowed || (strings.HasSuffix(allowed, "/") && strings.HasPrefix(mimeType, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+func hostAllowed(host string, allowed []string) bool {
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(pattern)
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// publicOnlyDialContext refuses connections to private addresses, catching hostnames that resolve to them
+func publicOnlyDialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip != nil && isPrivateIP(ip) {
+				return fmt.Errorf("refusing to fetch media from private address %s", host)
+			}
+			return nil
+		},
+	}
+	return dialer.DialContext(ctx, network, addr)
+}
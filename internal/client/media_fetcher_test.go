@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// pngHeader is enough for content sniffing to detect image/png
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestMediaFetcher_FetchSniffsAndCaches(t *testing.T) {
+	hits := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		// Mislabelled on purpose: the bytes are a PNG
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(pngHeader)
+	}))
+	defer upstream.Close()
+
+	fetcher := NewMediaFetcher(MediaFetchConfig{AllowPrivateNetworks: true}, "")
+	for i := 0; i < 2; i++ {
+		media, err := fetcher.Fetch(context.Background(), upstream.URL+"/cat")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if media.MIMEType != "image/png" {
+			t.Errorf("expected sniffed image/png, got %s", media.MIMEType)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("expected the second fetch to be served from cache, got %d upstream hits", hits)
+	}
+}
+
+func TestMediaFetcher_Limits(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/big":
+			_, _ = w.Write(append(pngHeader, make([]byte, 1024)...))
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html><body>not an image</body></html>"))
+		}
+	}))
+	defer upstream.Close()
+
+	tests := []struct {
+		name    string
+		config  MediaFetchConfig
+		path    string
+		wantErr string
+	}{
+		{"too large", MediaFetchConfig{AllowPrivateNetworks: true, MaxBytes: 512}, "/big", "larger than"},
+		{"wrong type", MediaFetchConfig{AllowPrivateNetworks: true}, "/html", "unsupported type"},
+		{"private address", MediaFetchConfig{}, "/big", "private address"},
+		{"host not allowed", MediaFetchConfig{AllowPrivateNetworks: true, AllowedHosts: []string{"*.example.com"}}, "/big", "not allowed"},
+		{"scheme not allowed", MediaFetchConfig{AllowPrivateNetworks: true, AllowedSchemes: []string{"https"}}, "/big", "scheme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMediaFetcher(tt.config, "").Fetch(context.Background(), upstream.URL+tt.path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"net/http"
+)
+
+// ResponseObserver is notified of every upstream response of a provider, before the body is read.
+// Implementations must not consume the response body.
+type ResponseObserver interface {
+	ObserveResponse(providerUUID string, resp *http.Response)
+}
+
+// ObserveRoundTripper is an http.RoundTripper that reports responses to a ResponseObserver
+type ObserveRoundTripper struct {
+	transport    http.RoundTripper
+	observer     ResponseObserver
+	providerUUID string
+}
+
+// NewObserveRoundTripper creates a new observing round tripper
+func NewObserveRoundTripper(transport http.RoundTripper, observer ResponseObserver, providerUUID string) *ObserveRoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &ObserveRoundTripper{
+		transport:    transport,
+		observer:     observer,
+		providerUUID: providerUUID,
+	}
+}
+
+// RoundTrip executes a single HTTP transaction and reports the response
+func (o *ObserveRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := o.transport.RoundTrip(req)
+	if err == nil && resp != nil {
+		o.observer.ObserveResponse(o.providerUUID, resp)
+	}
+	return resp, err
+}
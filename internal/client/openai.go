@@ -21,6 +21,9 @@ type OpenAIClient struct {
 	debugMode  bool
 	httpClient *http.Client
 	recordSink *obs.Sink
+	observer   ResponseObserver
+	refresher  TokenRefresher
+	failover   AccountFailover
 }
 
 // defaultNewOpenAIClient creates a new OpenAI client wrapper
@@ -30,16 +33,12 @@ func defaultNewOpenAIClient(provider *typ.Provider) (*OpenAIClient, error) {
 		option.WithBaseURL(provider.APIBase),
 	}
 
-	// Create base HTTP client
-	var httpClient *http.Client
-	// Add proxy if configured
+	// Create base HTTP client with proxy, OAuth hooks and request hooks if configured
+	httpClient := newProviderHTTPClient(provider)
 	if provider.ProxyURL != "" {
-		httpClient = CreateHTTPClientWithProxy(provider.ProxyURL)
-		options = append(options, option.WithHTTPClient(httpClient))
 		logrus.Infof("Using proxy for OpenAI client: %s", provider.ProxyURL)
-	} else {
-		httpClient = http.DefaultClient
 	}
+	options = append(options, option.WithHTTPClient(httpClient))
 
 	openaiClient := openai.NewClient(options...)
 
@@ -84,11 +83,39 @@ func (c *OpenAIClient) ChatCompletionsNewStreaming(ctx context.Context, req open
 // SetRecordSink sets the record sink for the client
 func (c *OpenAIClient) SetRecordSink(sink *obs.Sink) {
 	c.recordSink = sink
-	if sink != nil && sink.IsEnabled() {
+	if sink != nil && sink.RecordsHTTP() {
 		c.applyRecordMode()
 	}
 }
 
+// SetResponseObserver reports upstream responses of OAuth providers to the observer
+func (c *OpenAIClient) SetResponseObserver(observer ResponseObserver) {
+	if c.observer != nil || observer == nil || c.provider.AuthType != typ.AuthTypeOAuth || c.httpClient == http.DefaultClient {
+		return
+	}
+	c.observer = observer
+	c.httpClient.Transport = NewObserveRoundTripper(c.httpClient.Transport, observer, c.provider.UUID)
+}
+
+// SetTokenRefresher makes requests of OAuth providers refresh the token and replay once when it is rejected
+func (c *OpenAIClient) SetTokenRefresher(refresher TokenRefresher) {
+	if c.refresher != nil || refresher == nil || c.provider.AuthType != typ.AuthTypeOAuth || c.httpClient == http.DefaultClient {
+		return
+	}
+	c.refresher = refresher
+	c.httpClient.Transport = NewAuthRetryRoundTripper(c.httpClient.Transport, refresher, c.provider.UUID)
+}
+
+// SetAccountFailover makes requests of OAuth providers that were rate limited replay once on
+// another account of the same pool; transportFor returns the transport serving that account
+func (c *OpenAIClient) SetAccountFailover(failover AccountFailover, transportFor func(account *typ.Provider) http.RoundTripper) {
+	if c.failover != nil || failover == nil || c.provider.AuthType != typ.AuthTypeOAuth || c.httpClient == http.DefaultClient {
+		return
+	}
+	c.failover = failover
+	c.httpClient.Transport = NewAccountFailoverRoundTripper(c.httpClient.Transport, failover, c.provider.UUID, transportFor)
+}
+
 // applyRecordMode wraps the HTTP client with a record round tripper
 func (c *OpenAIClient) applyRecordMode() {
 	if c.recordSink == nil {
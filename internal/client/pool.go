@@ -4,6 +4,8 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"net/http"
+	"strings"
 	"sync"
 
 	"github.com/sirupsen/logrus"
@@ -19,6 +21,9 @@ type ClientPool struct {
 	googleClients    map[string]*GoogleClient
 	mutex            sync.RWMutex
 	recordSink       *obs.Sink
+	observer         ResponseObserver
+	refresher        TokenRefresher
+	failover         AccountFailover
 }
 
 // NewClientPool creates a new client pool
@@ -64,10 +69,30 @@ func (p *ClientPool) GetOpenAIClient(provider *typ.Provider, model string) *Open
 	}
 
 	// Apply record sink if enabled
-	if p.recordSink != nil && p.recordSink.IsEnabled() {
+	if p.recordSink != nil && p.recordSink.RecordsHTTP() {
 		client.SetRecordSink(p.recordSink)
 	}
 
+	// Apply response observer (no-op for non-OAuth providers)
+	if p.observer != nil {
+		client.SetResponseObserver(p.observer)
+	}
+
+	// Apply OAuth refresh-and-retry (no-op for non-OAuth providers)
+	if p.refresher != nil {
+		client.SetTokenRefresher(p.refresher)
+	}
+
+	// Apply account pool failover after a rate limit (no-op for non-OAuth providers)
+	if p.failover != nil {
+		client.SetAccountFailover(p.failover, func(account *typ.Provider) http.RoundTripper {
+			if c := p.GetOpenAIClient(account, model); c != nil {
+				return c.httpClient.Transport
+			}
+			return nil
+		})
+	}
+
 	// Store in pool
 	p.openaiClients[key] = client
 	return client
@@ -107,10 +132,30 @@ func (p *ClientPool) GetAnthropicClient(provider *typ.Provider, model string) *A
 	}
 
 	// Apply record sink if enabled
-	if p.recordSink != nil && p.recordSink.IsEnabled() {
+	if p.recordSink != nil && p.recordSink.RecordsHTTP() {
 		client.SetRecordSink(p.recordSink)
 	}
 
+	// Apply response observer (no-op for non-OAuth providers)
+	if p.observer != nil {
+		client.SetResponseObserver(p.observer)
+	}
+
+	// Apply OAuth refresh-and-retry (no-op for non-OAuth providers)
+	if p.refresher != nil {
+		client.SetTokenRefresher(p.refresher)
+	}
+
+	// Apply account pool failover after a rate limit (no-op for non-OAuth providers)
+	if p.failover != nil {
+		client.SetAccountFailover(p.failover, func(account *typ.Provider) http.RoundTripper {
+			if c := p.GetAnthropicClient(account, model); c != nil {
+				return c.httpClient.Transport
+			}
+			return nil
+		})
+	}
+
 	// Store in pool
 	p.anthropicClients[key] = client
 	return client
@@ -150,10 +195,30 @@ func (p *ClientPool) GetGoogleClient(provider *typ.Provider, model string) *Goog
 	}
 
 	// Apply record sink if enabled
-	if p.recordSink != nil && p.recordSink.IsEnabled() {
+	if p.recordSink != nil && p.recordSink.RecordsHTTP() {
 		client.SetRecordSink(p.recordSink)
 	}
 
+	// Apply response observer (no-op for non-OAuth providers)
+	if p.observer != nil {
+		client.SetResponseObserver(p.observer)
+	}
+
+	// Apply OAuth refresh-and-retry (no-op for non-OAuth providers)
+	if p.refresher != nil {
+		client.SetTokenRefresher(p.refresher)
+	}
+
+	// Apply account pool failover after a rate limit (no-op for non-OAuth providers)
+	if p.failover != nil {
+		client.SetAccountFailover(p.failover, func(account *typ.Provider) http.RoundTripper {
+			if c := p.GetGoogleClient(account, model); c != nil {
+				return c.httpClient.Transport
+			}
+			return nil
+		})
+	}
+
 	// Store in pool
 	p.googleClients[key] = client
 	return client
@@ -212,6 +277,39 @@ func (p *ClientPool) RemoveProvider(provider *typ.Provider, model string) {
 	}
 }
 
+// RemoveProviderClients removes every client of a provider from the pool, whatever the model.
+// Used when the provider credentials change so the next request builds a client with the new ones.
+func (p *ClientPool) RemoveProviderClients(providerUUID string) {
+	prefix := providerUUID + ":"
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	removed := 0
+	for key := range p.openaiClients {
+		if strings.HasPrefix(key, prefix) {
+			delete(p.openaiClients, key)
+			removed++
+		}
+	}
+	for key := range p.anthropicClients {
+		if strings.HasPrefix(key, prefix) {
+			delete(p.anthropicClients, key)
+			removed++
+		}
+	}
+	for key := range p.googleClients {
+		if strings.HasPrefix(key, prefix) {
+			delete(p.googleClients, key)
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		logrus.Infof("Removed %d clients for provider: %s", removed, providerUUID)
+	}
+}
+
 // Size returns the total number of clients currently in both pools
 func (p *ClientPool) Size() int {
 	p.mutex.RLock()
@@ -275,7 +373,7 @@ func (p *ClientPool) SetRecordSink(sink *obs.Sink) {
 		client.SetRecordSink(sink)
 	}
 
-	if sink != nil && sink.IsEnabled() {
+	if sink != nil && sink.RecordsHTTP() {
 		logrus.Info("Record sink enabled for client pool")
 	}
 }
@@ -286,3 +384,47 @@ func (p *ClientPool) GetRecordSink() *obs.Sink {
 	defer p.mutex.RUnlock()
 	return p.recordSink
 }
+
+// SetResponseObserver sets the observer notified of upstream responses for OAuth providers
+func (p *ClientPool) SetResponseObserver(observer ResponseObserver) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.observer = observer
+
+	// Apply observer to all existing clients
+	for _, client := range p.openaiClients {
+		client.SetResponseObserver(observer)
+	}
+	for _, client := range p.anthropicClients {
+		client.SetResponseObserver(observer)
+	}
+	for _, client := range p.googleClients {
+		client.SetResponseObserver(observer)
+	}
+}
+
+// SetTokenRefresher sets the refresher used to recover OAuth clients from rejected tokens
+func (p *ClientPool) SetTokenRefresher(refresher TokenRefresher) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.refresher = refresher
+
+	// Apply refresher to all existing clients
+	for _, client := range p.openaiClients {
+		client.SetTokenRefresher(refresher)
+	}
+	for _, client := range p.anthropicClients {
+		client.SetTokenRefresher(refresher)
+	}
+	for _, client := range p.googleClients {
+		client.SetTokenRefresher(refresher)
+	}
+}
+
+// SetAccountFailover sets the failover that replays rate limited requests of account pool members
+// on a sibling account. It applies to clients created afterwards.
+func (p *ClientPool) SetAccountFailover(failover AccountFailover) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.failover = failover
+}
@@ -36,6 +36,7 @@ func NewRecordRoundTripper(transport http.RoundTripper, recordSink *obs.Sink, pr
 // RoundTrip executes a single HTTP transaction and records request/response
 func (r *RecordRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	startTime := time.Now()
+	requestID := obs.RequestIDFromContext(req.Context())
 
 	// Prepare request record
 	reqRecord := &obs.RecordRequest{
@@ -66,6 +67,7 @@ func (r *RecordRoundTripper) RoundTrip(req *http.Request) (*http.Response, error
 	duration := time.Since(startTime)
 
 	var respRecord *obs.RecordResponse
+	recordOnClose := false
 	if resp != nil {
 		respRecord = &obs.RecordResponse{
 			StatusCode: resp.StatusCode,
@@ -81,9 +83,11 @@ func (r *RecordRoundTripper) RoundTrip(req *http.Request) (*http.Response, error
 				// For streaming responses, use recordingReader to capture data as it's read
 				respRecord.IsStreaming = true
 
-				// Wrap the body with recordingReader
+				// Wrap the body with recordingReader; the entry is written once the
+				// stream reaches EOF or is closed so it includes the full transcript
+				recordOnClose = true
 				resp.Body = newRecordingReader(resp.Body, func(content string) {
-					// This callback is called when the stream is closed
+					// This callback is called once, at EOF or when the stream is closed
 					// Try to parse as JSON for the Body field
 					var jsonObj any
 					if json.Unmarshal([]byte(content), &jsonObj) == nil {
@@ -93,6 +97,7 @@ func (r *RecordRoundTripper) RoundTrip(req *http.Request) (*http.Response, error
 					}
 					// Store raw streamed content
 					respRecord.StreamedContent = content
+					r.record(requestID, reqRecord, respRecord, duration, err)
 				})
 			} else {
 				// For non-streaming responses, read the entire body
@@ -114,27 +119,35 @@ func (r *RecordRoundTripper) RoundTrip(req *http.Request) (*http.Response, error
 	}
 
 	// Record the request/response
-	if r.recordSink != nil && r.recordSink.IsEnabled() {
-		r.recordSink.Record(r.provider, r.model, reqRecord, respRecord, duration, err)
+	if !recordOnClose {
+		r.record(requestID, reqRecord, respRecord, duration, err)
 	}
 
 	return resp, err
 }
 
-// recordingReader wraps an io.ReadCloser and records all data read from it
+// record writes one request/response pair to the sink
+func (r *RecordRoundTripper) record(requestID string, req *obs.RecordRequest, resp *obs.RecordResponse, duration time.Duration, err error) {
+	if r.recordSink != nil && r.recordSink.RecordsHTTP() {
+		r.recordSink.Record(requestID, r.provider, r.model, req, resp, duration, err)
+	}
+}
+
+// recordingReader wraps an io.ReadCloser and records all data read from it. The recorded
+// content is handed to onDone once, when the stream reaches EOF or is closed, whichever
+// comes first; callers that drain a stream without closing it are still recorded.
 type recordingReader struct {
-	source    io.ReadCloser
-	buffer    *bytes.Buffer
-	onClose   func(content string)
-	closeOnce sync.Once
-	closed    bool
+	source   io.ReadCloser
+	buffer   *bytes.Buffer
+	onDone   func(content string)
+	doneOnce sync.Once
 }
 
-func newRecordingReader(source io.ReadCloser, onClose func(string)) *recordingReader {
+func newRecordingReader(source io.ReadCloser, onDone func(string)) *recordingReader {
 	return &recordingReader{
-		source:  source,
-		buffer:  &bytes.Buffer{},
-		onClose: onClose,
+		source: source,
+		buffer: &bytes.Buffer{},
+		onDone: onDone,
 	}
 }
 
@@ -143,18 +156,25 @@ func (r *recordingReader) Read(p []byte) (n int, err error) {
 	if n > 0 {
 		r.buffer.Write(p[:n])
 	}
+	if err == io.EOF {
+		r.finish()
+	}
 	return n, err
 }
 
 func (r *recordingReader) Close() error {
 	err := r.source.Close()
-	r.closeOnce.Do(func() {
-		r.closed = true
-		if r.onClose != nil {
-			r.onClose(r.buffer.String())
+	r.finish()
+	return err
+}
+
+// finish hands the recorded content to onDone the first time it is called
+func (r *recordingReader) finish() {
+	r.doneOnce.Do(func() {
+		if r.onDone != nil {
+			r.onDone(r.buffer.String())
 		}
 	})
-	return err
 }
 
 // headerToMap converts http.Header to map[string]string
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRecordingReader_RecordsOnEOFWithoutClose(t *testing.T) {
+	var recorded []string
+	reader := newRecordingReader(io.NopCloser(strings.NewReader("data: {\"a\":1}\n\ndata: [DONE]\n\n")), func(content string) {
+		recorded = append(recorded, content)
+	})
+
+	if _, err := io.ReadAll(reader); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorded) != 1 || !strings.Contains(recorded[0], "[DONE]") {
+		t.Fatalf("expected the full stream to be recorded at EOF, got %q", recorded)
+	}
+
+	// Closing after EOF must not record the stream a second time
+	reader.Close()
+	if len(recorded) != 1 {
+		t.Errorf("expected one record, got %d", len(recorded))
+	}
+}
+
+func TestRecordingReader_RecordsPartialStreamOnClose(t *testing.T) {
+	var recorded []string
+	reader := newRecordingReader(io.NopCloser(strings.NewReader("data: partial\n\ndata: rest\n\n")), func(content string) {
+		recorded = append(recorded, content)
+	})
+
+	buf := make([]byte, len("data: partial\n\n"))
+	if _, err := io.ReadFull(reader, buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reader.Close()
+	if len(recorded) != 1 || recorded[0] != "data: partial\n\n" {
+		t.Errorf("expected the partial stream to be recorded on close, got %q", recorded)
+	}
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tingly-dev/tingly-box/internal/obs"
+)
+
+// TraceRoundTripper is an http.RoundTripper that records each upstream call as a client span and
+// passes the trace on to the provider in traceparent headers. The span ends when the response
+// headers arrive; reading a streamed body is covered by the stream conversion span. Each call is
+// also added as an attempt to the request detail of the inbound request, if any.
+type TraceRoundTripper struct {
+	transport    http.RoundTripper
+	providerName string
+}
+
+// NewTraceRoundTripper creates a new tracing round tripper
+func NewTraceRoundTripper(transport http.RoundTripper, providerName string) *TraceRoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &TraceRoundTripper{
+		transport:    transport,
+		providerName: providerName,
+	}
+}
+
+// RoundTrip executes a single HTTP transaction inside a client span
+func (t *TraceRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := obs.Tracer().Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.ServerAddress(req.URL.Hostname()),
+			// Without the query, which carries the API key for Gemini
+			semconv.URLFull(req.URL.Scheme+"://"+req.URL.Host+req.URL.Path),
+			obs.AttrProvider.String(t.providerName),
+		))
+	defer span.End()
+
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	attempt := obs.UpstreamAttempt{
+		Time:     time.Now(),
+		Provider: t.providerName,
+		Method:   req.Method,
+		URL:      req.URL.Scheme + "://" + req.URL.Host + req.URL.Path,
+	}
+	resp, err := t.transport.RoundTrip(req)
+	attempt.DurationMs = time.Since(attempt.Time).Milliseconds()
+	detail := obs.RequestDetailFromContext(ctx)
+	if err != nil {
+		attempt.Error = err.Error()
+		detail.AddAttempt(attempt)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+	attempt.StatusCode = resp.StatusCode
+	detail.AddAttempt(attempt)
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tingly-dev/tingly-box/internal/obs"
+)
+
+func TestTraceRoundTripper_PropagatesInboundTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(obs.NewTracerProvider(obs.TracingConfig{}, sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(previous)
+	if _, err := obs.InitTracing(context.Background(), obs.TracingConfig{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var traceparent string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer upstream.Close()
+
+	// The caller's trace, as the tracing middleware extracts it from the inbound request
+	inbound := http.Header{}
+	inbound.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(inbound))
+	ctx, server := obs.Tracer().Start(ctx, "POST /v1/messages", trace.WithSpanKind(trace.SpanKindServer))
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, upstream.URL+"/v1/messages?key=secret", nil)
+	resp, err := (&http.Client{Transport: NewTraceRoundTripper(nil, "provider-1")}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	server.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	upstreamSpan, serverSpan := spans[0], spans[1]
+
+	if got := serverSpan.Parent.SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("server span parent = %s, want the inbound span", got)
+	}
+	if upstreamSpan.Parent.SpanID() != serverSpan.SpanContext.SpanID() {
+		t.Errorf("upstream span is not a child of the server span")
+	}
+	if upstreamSpan.SpanContext.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("upstream span left the inbound trace: %s", upstreamSpan.SpanContext.TraceID())
+	}
+
+	want := "00-4bf92f3577b34da6a3ce929d0e0e4736-" + upstreamSpan.SpanContext.SpanID().String() + "-01"
+	if traceparent != want {
+		t.Errorf("upstream traceparent = %q, want %q", traceparent, want)
+	}
+
+	attrs := map[string]string{}
+	for _, kv := range upstreamSpan.Attributes {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if strings.Contains(attrs[string(semconv.URLFullKey)], "secret") {
+		t.Errorf("url.full leaks the query: %s", attrs[string(semconv.URLFullKey)])
+	}
+	if attrs[string(obs.AttrProvider)] != "provider-1" || attrs[string(semconv.HTTPResponseStatusCodeKey)] != "429" {
+		t.Errorf("unexpected attributes: %v", attrs)
+	}
+	if upstreamSpan.Status.Code.String() != "Error" {
+		t.Errorf("429 response should mark the span failed, got %s", upstreamSpan.Status.Code)
+	}
+}
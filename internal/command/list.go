@@ -1,12 +1,15 @@
 package command
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
 	"github.com/tingly-dev/tingly-box/internal/config"
+	"github.com/tingly-dev/tingly-box/internal/template"
 )
 
 // ListCommand represents the list providers command
@@ -15,7 +18,8 @@ func ListCommand(appConfig *config.AppConfig) *cobra.Command {
 		Use:   "list",
 		Short: "List all configured AI providers",
 		Long: `Display all configured AI providers with their details.
-Shows the provider name, API base URL, API style, and enabled status.`,
+Shows the provider name, API base URL, API style, and enabled status, followed by
+warnings for rules that use models their provider removed or deprecated.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			providers := appConfig.ListProviders()
 
@@ -37,9 +41,34 @@ Shows the provider name, API base URL, API style, and enabled status.`,
 			}
 
 			w.Flush()
+
+			printModelWarnings(cmd.OutOrStdout(), appConfig)
 			return nil
 		},
 	}
 
 	return cmd
 }
+
+// printModelWarnings lists the rule services whose model was removed by its provider, as detected
+// by model list syncs, or is deprecated by the provider template
+func printModelWarnings(out io.Writer, appConfig *config.AppConfig) {
+	globalConfig := appConfig.GetGlobalConfig()
+	if globalConfig.GetTemplateManager() == nil {
+		tm := template.NewEmbeddedOnlyTemplateManager()
+		if err := tm.Initialize(context.Background()); err == nil {
+			globalConfig.SetTemplateManager(tm)
+		}
+	}
+
+	warnings := globalConfig.GetModelWarnings(globalConfig.GetRequestConfigs())
+	if len(warnings) == 0 {
+		return
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Warnings:")
+	for _, warning := range warnings {
+		fmt.Fprintf(out, "  rule %q: %s\n", warning.RequestModel, warning.Message)
+	}
+}
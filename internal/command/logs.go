@@ -0,0 +1,217 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tingly-dev/tingly-box/internal/config"
+)
+
+// logsOptions holds the flags of the logs command
+type logsOptions struct {
+	follow   bool
+	requests bool
+	limit    int
+	level    string
+	provider string
+	model    string
+	status   string
+	url      string
+}
+
+// LogsCommand represents the logs command
+func LogsCommand(appConfig *config.AppConfig) *cobra.Command {
+	opts := &logsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show the request log of the running server",
+		Long: `Show recent entries of the running server's request log.
+With -f, keep the connection open and print entries as they are written, or with
+--requests, a summary of each proxied request as it completes.
+
+Examples:
+  tingly-box logs
+  tingly-box logs -f --level warn
+  tingly-box logs -f --requests --provider openai --status 5xx`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLogs(cmd, appConfig, opts)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.follow, "follow", "f", false, "Stream new entries as they happen")
+	cmd.Flags().BoolVar(&opts.requests, "requests", false, "With -f, stream completed request summaries instead of log entries")
+	cmd.Flags().IntVarP(&opts.limit, "limit", "n", 50, "Recent entries to show without -f")
+	cmd.Flags().StringVar(&opts.level, "level", "", "Least severe level to show: debug, info, warn, error")
+	cmd.Flags().StringVar(&opts.provider, "provider", "", "With -f, only requests routed to this provider")
+	cmd.Flags().StringVar(&opts.model, "model", "", "With -f, only requests sent to this model")
+	cmd.Flags().StringVar(&opts.status, "status", "", "With -f, only this status: an HTTP code, a class such as 5xx, or success, error, partial with --requests")
+	cmd.Flags().StringVar(&opts.url, "url", "", "Server URL (default http://localhost:<configured port>)")
+
+	return cmd
+}
+
+// runLogs prints recent log entries, or follows the live stream
+func runLogs(cmd *cobra.Command, appConfig *config.AppConfig, opts *logsOptions) error {
+	base := opts.url
+	if base == "" {
+		base = fmt.Sprintf("http://localhost:%d", appConfig.GetServerPort())
+	}
+	base = strings.TrimRight(base, "/")
+	token := appConfig.GetGlobalConfig().GetUserToken()
+	out := cmd.OutOrStdout()
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	if !opts.follow {
+		query := url.Values{"limit": {fmt.Sprint(opts.limit)}}
+		if opts.level != "" {
+			query.Set("level", opts.level)
+		}
+		var response struct {
+			Logs []logLine `json:"logs"`
+		}
+		body, err := getServer(ctx, base+"/api/v1/log?"+query.Encode(), token)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+		if err := json.NewDecoder(body).Decode(&response); err != nil {
+			return fmt.Errorf("failed to read logs: %w", err)
+		}
+		for _, line := range response.Logs {
+			line.print(out)
+		}
+		return nil
+	}
+
+	query := url.Values{}
+	for key, value := range map[string]string{"level": opts.level, "provider": opts.provider, "model": opts.model, "status": opts.status} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	path := "/api/v1/log/stream"
+	if opts.requests {
+		path = "/api/v1/requests/stream"
+	}
+	body, err := getServer(ctx, base+path+"?"+query.Encode(), token)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	err = readEvents(body, func(event, data string) {
+		switch event {
+		case "log":
+			var line logLine
+			if json.Unmarshal([]byte(data), &line) == nil {
+				line.print(out)
+			}
+		case "request":
+			var summary requestLine
+			if json.Unmarshal([]byte(data), &summary) == nil {
+				summary.print(out)
+			}
+		case "dropped":
+			var dropped struct {
+				Count int64 `json:"count"`
+			}
+			if json.Unmarshal([]byte(data), &dropped) == nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "... %d entries skipped, the terminal fell behind\n", dropped.Count)
+			}
+		}
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// getServer sends an authenticated GET to the server and returns the body of a 200 response
+func getServer(ctx context.Context, target, token string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the server, is it running? %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return resp.Body, nil
+}
+
+// readEvents parses a Server-Sent event stream, calling handle for each event
+func readEvents(r io.Reader, handle func(event, data string)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	event, data := "", []string{}
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				handle(event, strings.Join(data, "\n"))
+			}
+			event, data = "", data[:0]
+		case strings.HasPrefix(line, ":"):
+			// Comment, e.g. keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	return scanner.Err()
+}
+
+// logLine is a log entry as the server API returns it
+type logLine struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+}
+
+func (l logLine) print(w io.Writer) {
+	fmt.Fprintf(w, "%s %-7s %s\n", l.Time.Local().Format("15:04:05"), strings.ToUpper(l.Level), l.Message)
+}
+
+// requestLine is a completed request summary as the server API returns it
+type requestLine struct {
+	ID           string    `json:"id"`
+	Time         time.Time `json:"time"`
+	StatusCode   int       `json:"status_code"`
+	LatencyMs    int64     `json:"latency_ms"`
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	RequestModel string    `json:"request_model"`
+	InputTokens  int       `json:"input_tokens"`
+	OutputTokens int       `json:"output_tokens"`
+	Status       string    `json:"status"`
+}
+
+func (r requestLine) print(w io.Writer) {
+	fmt.Fprintf(w, "%s %d %-7s %s -> %s/%s in=%d out=%d %dms %s\n",
+		r.Time.Local().Format("15:04:05"), r.StatusCode, r.Status, r.RequestModel,
+		r.Provider, r.Model, r.InputTokens, r.OutputTokens, r.LatencyMs, r.ID)
+}
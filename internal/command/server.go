@@ -107,7 +107,7 @@ func addStartFlags(cmd *cobra.Command, flags *startFlags) {
 	cmd.Flags().BoolVar(&flags.https, "https", false, "Enable HTTPS mode with self-signed certificate (default: false)")
 	cmd.Flags().StringVar(&flags.httpsCertDir, "https-cert-dir", "", "Certificate directory for HTTPS (default: ~/.tingly-box/certs/)")
 	cmd.Flags().BoolVar(&flags.httpsRegen, "https-regen", false, "Regenerate HTTPS certificate (default: false)")
-	cmd.Flags().StringVar(&flags.recordMode, "record-mode", "", "Record mode: empty=disabled, 'all'=record request+response, 'response'=response only (default: disabled)")
+	cmd.Flags().StringVar(&flags.recordMode, "record-mode", "", "Record mode: empty=disabled, 'all'=record request+response, 'response'=response only, 'slim'=one summary line per request (default: disabled)")
 	cmd.Flags().StringVar(&flags.recordDir, "record-dir", "", "Record directory (default: ~/.tingly-box/record/)")
 	cmd.Flags().StringVar(&flags.expr, "expr", "", "Enable experimental features (comma-separated, e.g., compact,other)")
 }
@@ -138,6 +138,11 @@ func (sm *ServerManager) GetGinEngine() *gin.Engine {
 	return sm.server.GetRouter()
 }
 
+// GetServer returns the server created by Setup
+func (sm *ServerManager) GetServer() *server.Server {
+	return sm.server
+}
+
 func (sm *ServerManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// All requests go to the Gin engine
 	sm.server.GetRouter().ServeHTTP(w, r)
@@ -0,0 +1,132 @@
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tingly-dev/tingly-box/internal/config"
+	"github.com/tingly-dev/tingly-box/internal/db"
+)
+
+// UsageCommand represents the usage command group
+func UsageCommand(appConfig *config.AppConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Inspect recorded token usage",
+	}
+	cmd.AddCommand(usageReportCommand(appConfig))
+	return cmd
+}
+
+// usageReportCommand represents the usage report command
+func usageReportCommand(appConfig *config.AppConfig) *cobra.Command {
+	var (
+		from, to, groupBy, format, output string
+		query                             db.UsageStatsQuery
+	)
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Write usage totals as CSV or JSON Lines",
+		Long: `Total the recorded usage over a date range, grouped by any combination of
+day, provider, model, rule and scenario, and write it as CSV or JSON Lines.
+Days are UTC. Rows are streamed from the usage database as they are read.
+
+Examples:
+  tingly-box usage report --from 2026-01-01 --to 2026-01-31 > january.csv
+  tingly-box usage report --group-by provider,model --format jsonl -o usage.jsonl`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store := appConfig.GetGlobalConfig().GetUsageStore()
+			if store == nil {
+				return fmt.Errorf("usage store is not available")
+			}
+
+			dimensions, err := db.ParseReportGroupBy(groupBy)
+			if err != nil {
+				return err
+			}
+			if query.StartTime, err = parseReportTime(from, false); err != nil {
+				return fmt.Errorf("invalid --from: %w", err)
+			}
+			if query.EndTime, err = parseReportTime(to, true); err != nil {
+				return fmt.Errorf("invalid --to: %w", err)
+			}
+			if query.StartTime.IsZero() {
+				query.StartTime = time.Now().AddDate(0, 0, -30)
+			}
+			if query.Provider != "" {
+				// Accept provider names as well as UUIDs
+				if provider, err := appConfig.GetProviderByName(query.Provider); err == nil && provider != nil {
+					query.Provider = provider.UUID
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			if output != "" && output != "-" {
+				file, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("failed to create %s: %w", output, err)
+				}
+				defer file.Close()
+				out = file
+			}
+			writer := bufio.NewWriter(out)
+
+			encoder, err := db.NewUsageReportEncoder(writer, format, dimensions)
+			if err != nil {
+				return err
+			}
+			coverage, err := store.UsageReportCoverage(query, dimensions)
+			if err != nil {
+				return fmt.Errorf("failed to read usage: %w", err)
+			}
+			if coverage.Truncated() {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: records before %s were removed by the retention policy and their daily totals cannot be grouped or filtered this way; the report starts at that day\n",
+					coverage.TruncatedBefore.Format("2006-01-02"))
+			}
+			if err := store.StreamUsageReport(query, dimensions, encoder.Encode); err != nil {
+				return fmt.Errorf("failed to read usage: %w", err)
+			}
+			if err := encoder.Flush(); err != nil {
+				return err
+			}
+			return writer.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "Start date (YYYY-MM-DD or RFC 3339, default 30 days ago)")
+	cmd.Flags().StringVar(&to, "to", "", "End date, inclusive (YYYY-MM-DD or RFC 3339, default now)")
+	cmd.Flags().StringVar(&groupBy, "group-by", "day,provider,model", "Comma-separated dimensions: day, provider, model, rule, scenario")
+	cmd.Flags().StringVar(&format, "format", db.ReportFormatCSV, "Output format: csv or jsonl")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write to a file instead of stdout")
+	cmd.Flags().StringVar(&query.Provider, "provider", "", "Only include a provider, by name or UUID")
+	cmd.Flags().StringVar(&query.Model, "model", "", "Only include a model")
+	cmd.Flags().StringVar(&query.Scenario, "scenario", "", "Only include a scenario")
+	cmd.Flags().StringVar(&query.RuleUUID, "rule", "", "Only include a rule, by UUID")
+	cmd.Flags().StringVar(&query.Status, "status", "", "Only include a status: success, error, partial")
+
+	return cmd
+}
+
+// parseReportTime parses a report bound given as a date or an RFC 3339 time. A date used as an end
+// bound covers the whole day. An empty value gives the zero time.
+func parseReportTime(value string, end bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, time.UTC)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected YYYY-MM-DD or RFC 3339, got %q", value)
+	}
+	if end {
+		return day.Add(24*time.Hour - time.Nanosecond), nil
+	}
+	return day, nil
+}
@@ -83,7 +83,7 @@ func NewModelCapabilityStore(baseDir string) (*ModelCapabilityStore, error) {
 	}
 
 	// Auto-migrate schema
-	if err := db.AutoMigrate(&ModelCapability{}); err != nil {
+	if err := db.AutoMigrate(&ModelCapability{}, &ProbeRecord{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate capabilities database: %w", err)
 	}
 
@@ -255,11 +255,14 @@ func (mcs *ModelCapabilityStore) GetProviderCapabilities(providerUUID string) ma
 	return result
 }
 
-// RemoveProvider removes all capabilities for a provider
+// RemoveProvider removes all capabilities and the probe history of a provider
 func (mcs *ModelCapabilityStore) RemoveProvider(providerUUID string) error {
 	mcs.mu.Lock()
 	defer mcs.mu.Unlock()
 
+	if err := mcs.db.Where("provider_uuid = ?", providerUUID).Delete(&ProbeRecord{}).Error; err != nil {
+		return err
+	}
 	return mcs.db.Where("provider_uuid = ?", providerUUID).Delete(&ModelCapability{}).Error
 }
 
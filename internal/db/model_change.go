@@ -0,0 +1,172 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+// Kinds of model list changes
+const (
+	ModelChangeAdded   = "added"
+	ModelChangeRemoved = "removed"
+)
+
+// ModelChangeRecord is a model that appeared in or disappeared from a provider's model list
+type ModelChangeRecord struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProviderUUID string    `gorm:"column:provider_uuid;index" json:"provider_uuid"`
+	ProviderName string    `gorm:"column:provider_name" json:"provider_name"`
+	ModelID      string    `gorm:"column:model_id" json:"model"`
+	Change       string    `gorm:"column:change" json:"change"` // added or removed
+	DetectedAt   time.Time `gorm:"column:detected_at;index" json:"detected_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ModelChangeRecord) TableName() string {
+	return "model_changes"
+}
+
+// ModelDiff is the difference between a provider's stored and newly fetched model lists
+type ModelDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// Empty reports whether the model list is unchanged
+func (d ModelDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// SyncModels saves a model list fetched from the provider API and records the models added to and
+// removed from the stored list. Only lists that were fetched from the API are diffed: the first
+// sync of a provider, and a sync after a template fallback list, record no changes.
+func (ms *ModelStore) SyncModels(provider *typ.Provider, apiBase string, models []string) (ModelDiff, error) {
+	if provider == nil {
+		return ModelDiff{}, errors.New("provider cannot be nil")
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	var diff ModelDiff
+	err := ms.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var existing ProviderModelRecord
+		err := tx.Where("provider_uuid = ?", provider.UUID).First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to query existing record: %w", err)
+		}
+		if err == nil && !existing.SyncedAt.IsZero() {
+			var previous []string
+			if err := json.Unmarshal([]byte(existing.Models), &previous); err == nil {
+				diff = diffModels(previous, models)
+			}
+		}
+
+		changes := make([]ModelChangeRecord, 0, len(diff.Added)+len(diff.Removed))
+		for _, model := range diff.Added {
+			changes = append(changes, ModelChangeRecord{ProviderUUID: provider.UUID, ProviderName: provider.Name, ModelID: model, Change: ModelChangeAdded, DetectedAt: now})
+		}
+		for _, model := range diff.Removed {
+			changes = append(changes, ModelChangeRecord{ProviderUUID: provider.UUID, ProviderName: provider.Name, ModelID: model, Change: ModelChangeRemoved, DetectedAt: now})
+		}
+		if len(changes) > 0 {
+			if err := tx.Create(&changes).Error; err != nil {
+				return fmt.Errorf("failed to record model changes: %w", err)
+			}
+		}
+
+		return saveModels(tx, provider, apiBase, models, now)
+	})
+	if err != nil {
+		return ModelDiff{}, err
+	}
+	return diff, nil
+}
+
+// diffModels returns the models of current missing from previous, and the reverse, sorted
+func diffModels(previous, current []string) ModelDiff {
+	before := make(map[string]bool, len(previous))
+	for _, model := range previous {
+		before[model] = true
+	}
+	after := make(map[string]bool, len(current))
+	for _, model := range current {
+		after[model] = true
+	}
+
+	var diff ModelDiff
+	for model := range after {
+		if !before[model] {
+			diff.Added = append(diff.Added, model)
+		}
+	}
+	for model := range before {
+		if !after[model] {
+			diff.Removed = append(diff.Removed, model)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	return diff
+}
+
+// GetModelChanges returns the model list changes detected since the given time, newest first. An
+// empty providerUUID returns the changes of all providers.
+func (ms *ModelStore) GetModelChanges(providerUUID string, since time.Time) ([]ModelChangeRecord, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	query := ms.db.Where("detected_at >= ?", since)
+	if providerUUID != "" {
+		query = query.Where("provider_uuid = ?", providerUUID)
+	}
+	var changes []ModelChangeRecord
+	if err := query.Order("detected_at DESC, id DESC").Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// GetRemovedModels returns the models a provider no longer lists, with the time their removal was
+// detected. A model that was listed again after its removal is not included.
+func (ms *ModelStore) GetRemovedModels(providerUUID string) map[string]time.Time {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	var changes []ModelChangeRecord
+	if err := ms.db.Where("provider_uuid = ?", providerUUID).Order("detected_at, id").Find(&changes).Error; err != nil {
+		return map[string]time.Time{}
+	}
+
+	removed := make(map[string]time.Time)
+	for _, change := range changes {
+		if change.Change == ModelChangeRemoved {
+			removed[change.ModelID] = change.DetectedAt
+		} else {
+			delete(removed, change.ModelID)
+		}
+	}
+	return removed
+}
+
+// GetLastSynced returns when the provider's model list was last fetched from its API, or zero if
+// the stored list did not come from the API
+func (ms *ModelStore) GetLastSynced(providerUUID string) time.Time {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	var record ProviderModelRecord
+	if err := ms.db.Where("provider_uuid = ?", providerUUID).First(&record).Error; err != nil {
+		return time.Time{}
+	}
+	return record.SyncedAt
+}
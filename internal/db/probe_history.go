@@ -0,0 +1,106 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProbeRecord is the outcome of one background health probe of a provider model
+type ProbeRecord struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement"`
+	ProviderUUID string    `gorm:"column:provider_uuid;index:idx_probe_service,priority:1"`
+	ModelID      string    `gorm:"column:model_id;index:idx_probe_service,priority:2"`
+	Available    bool      `gorm:"column:available"`
+	LatencyMs    int       `gorm:"column:latency_ms"`
+	ErrorMessage string    `gorm:"column:error_message"`
+	CheckedAt    time.Time `gorm:"column:checked_at;index:idx_probe_checked_at"`
+}
+
+// TableName specifies the table name for ProbeRecord
+func (ProbeRecord) TableName() string {
+	return "probe_history"
+}
+
+// ProbeUptime summarizes the probes of a provider model over a period
+type ProbeUptime struct {
+	ProviderUUID string  `gorm:"column:provider_uuid"`
+	ModelID      string  `gorm:"column:model_id"`
+	Probes       int64   `gorm:"column:probes"`
+	Successes    int64   `gorm:"column:successes"`
+	AvgLatencyMs float64 `gorm:"column:avg_latency_ms"` // Of the successful probes
+}
+
+// Percent returns the share of successful probes in percent
+func (u ProbeUptime) Percent() float64 {
+	if u.Probes == 0 {
+		return 0
+	}
+	return float64(u.Successes) * 100 / float64(u.Probes)
+}
+
+// RecordProbe appends a probe outcome to the history
+func (mcs *ModelCapabilityStore) RecordProbe(record *ProbeRecord) error {
+	mcs.mu.Lock()
+	defer mcs.mu.Unlock()
+
+	if record.CheckedAt.IsZero() {
+		record.CheckedAt = time.Now()
+	}
+	if err := mcs.db.Create(record).Error; err != nil {
+		return fmt.Errorf("failed to record probe: %w", err)
+	}
+	return nil
+}
+
+// GetLatestProbes returns the most recent probe of every provider model, keyed by provider UUID
+// and then model ID
+func (mcs *ModelCapabilityStore) GetLatestProbes() (map[string]map[string]ProbeRecord, error) {
+	mcs.mu.Lock()
+	defer mcs.mu.Unlock()
+
+	var records []ProbeRecord
+	latest := mcs.db.Model(&ProbeRecord{}).Select("MAX(id)").Group("provider_uuid, model_id")
+	if err := mcs.db.Where("id IN (?)", latest).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to query latest probes: %w", err)
+	}
+
+	result := make(map[string]map[string]ProbeRecord)
+	for _, record := range records {
+		if result[record.ProviderUUID] == nil {
+			result[record.ProviderUUID] = make(map[string]ProbeRecord)
+		}
+		result[record.ProviderUUID][record.ModelID] = record
+	}
+	return result, nil
+}
+
+// GetProbeUptime summarizes the probes of every provider model since the given time
+func (mcs *ModelCapabilityStore) GetProbeUptime(since time.Time) ([]ProbeUptime, error) {
+	mcs.mu.Lock()
+	defer mcs.mu.Unlock()
+
+	var results []ProbeUptime
+	err := mcs.db.Model(&ProbeRecord{}).
+		Select(`provider_uuid, model_id, COUNT(*) as probes,
+			SUM(CASE WHEN available THEN 1 ELSE 0 END) as successes,
+			COALESCE(AVG(CASE WHEN available THEN latency_ms END), 0) as avg_latency_ms`).
+		Where("checked_at >= ?", since).
+		Group("provider_uuid, model_id").
+		Scan(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query probe uptime: %w", err)
+	}
+	return results, nil
+}
+
+// PruneProbeHistory deletes probes older than the given time and returns how many were deleted
+func (mcs *ModelCapabilityStore) PruneProbeHistory(before time.Time) (int64, error) {
+	mcs.mu.Lock()
+	defer mcs.mu.Unlock()
+
+	result := mcs.db.Where("checked_at < ?", before).Delete(&ProbeRecord{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune probe history: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -23,6 +23,7 @@ type ProviderModelRecord struct {
 	APIBase      string    `gorm:"column:api_base"`
 	Models       string    `gorm:"column:models;type:text"` // JSON array of model names
 	LastUpdated  time.Time `gorm:"column:last_updated"`
+	SyncedAt     time.Time `gorm:"column:synced_at"` // When Models was last fetched from the provider API; zero for template lists
 	CreatedAt    time.Time `gorm:"column:created_at"`
 	UpdatedAt    time.Time `gorm:"column:updated_at"`
 }
@@ -61,7 +62,7 @@ func NewModelStore(baseDir string) (*ModelStore, error) {
 	}
 
 	// Auto-migrate schema
-	if err := db.AutoMigrate(&ProviderModelRecord{}); err != nil {
+	if err := db.AutoMigrate(&ProviderModelRecord{}, &ModelChangeRecord{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate models database: %w", err)
 	}
 
@@ -77,6 +78,12 @@ func (ms *ModelStore) SaveModels(provider *typ.Provider, apiBase string, models
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
+	return saveModels(ms.db, provider, apiBase, models, time.Time{})
+}
+
+// saveModels creates or updates the model record of a provider. syncedAt is when the list was
+// fetched from the provider API, or zero for lists from other sources such as templates.
+func saveModels(tx *gorm.DB, provider *typ.Provider, apiBase string, models []string, syncedAt time.Time) error {
 	now := time.Now()
 
 	// Marshal models to JSON
@@ -85,31 +92,36 @@ func (ms *ModelStore) SaveModels(provider *typ.Provider, apiBase string, models
 		return fmt.Errorf("failed to marshal models: %w", err)
 	}
 
-	// Use Save to create or update the record
-	record := ProviderModelRecord{
-		ProviderUUID: provider.UUID,
-		ProviderName: provider.Name,
-		APIBase:      apiBase,
-		Models:       string(modelsJSON),
-		LastUpdated:  now,
-		UpdatedAt:    now,
-	}
-
 	// Check if record exists
 	var existing ProviderModelRecord
-	err = ms.db.Where("provider_uuid = ?", provider.UUID).First(&existing).Error
+	err = tx.Where("provider_uuid = ?", provider.UUID).First(&existing).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		// Create new record
-		record.CreatedAt = now
-		if err := ms.db.Create(&record).Error; err != nil {
+		record := ProviderModelRecord{
+			ProviderUUID: provider.UUID,
+			ProviderName: provider.Name,
+			APIBase:      apiBase,
+			Models:       string(modelsJSON),
+			LastUpdated:  now,
+			SyncedAt:     syncedAt,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		if err := tx.Create(&record).Error; err != nil {
 			return fmt.Errorf("failed to create model record: %w", err)
 		}
 	} else if err != nil {
 		return fmt.Errorf("failed to query existing record: %w", err)
 	} else {
-		// Update existing record, preserve CreatedAt
-		record.CreatedAt = existing.CreatedAt
-		if err := ms.db.Model(&existing).Updates(&record).Error; err != nil {
+		// Update existing record, preserve CreatedAt; a map also writes a zero SyncedAt
+		if err := tx.Model(&existing).Updates(map[string]interface{}{
+			"provider_name": provider.Name,
+			"api_base":      apiBase,
+			"models":        string(modelsJSON),
+			"last_updated":  now,
+			"synced_at":     syncedAt,
+			"updated_at":    now,
+		}).Error; err != nil {
 			return fmt.Errorf("failed to update model record: %w", err)
 		}
 	}
@@ -173,7 +185,12 @@ func (ms *ModelStore) RemoveProvider(providerUUID string) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	return ms.db.Where("provider_uuid = ?", providerUUID).Delete(&ProviderModelRecord{}).Error
+	return ms.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("provider_uuid = ?", providerUUID).Delete(&ModelChangeRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("provider_uuid = ?", providerUUID).Delete(&ProviderModelRecord{}).Error
+	})
 }
 
 // GetProviderInfo returns basic info about a provider (apiBase, lastUpdated, exists)
@@ -19,6 +19,9 @@ import (
 
 const defaultServiceTimeWindow = 300
 
+// DefaultStatsFlushInterval is how often queued service stats are written
+const DefaultStatsFlushInterval = 5 * time.Second
+
 // ServiceStatsRecord is the GORM model for persisting service statistics
 type ServiceStatsRecord struct {
 	// Composite primary key: provider + model (stats are global, not per-rule)
@@ -32,6 +35,10 @@ type ServiceStatsRecord struct {
 	WindowTokensConsumed int64     `gorm:"column:window_tokens_consumed"`
 	WindowInputTokens    int64     `gorm:"column:window_input_tokens"`
 	WindowOutputTokens   int64     `gorm:"column:window_output_tokens"`
+	WindowStreamCount    int64     `gorm:"column:window_stream_count"`
+	WindowTTFTMs         int64     `gorm:"column:window_ttft_ms"`
+	WindowStreamMs       int64     `gorm:"column:window_stream_ms"`
+	WindowStreamTokens   int64     `gorm:"column:window_stream_tokens"`
 	TimeWindow           int       `gorm:"column:time_window"`
 }
 
@@ -45,6 +52,12 @@ type StatsStore struct {
 	db     *gorm.DB
 	dbPath string
 	mu     sync.Mutex
+
+	// Updates queued by QueueUpdate, latest per service, written by the flusher
+	pendingMu sync.Mutex
+	pending   map[string]ServiceStatsRecord
+	done      chan struct{}
+	stopped   chan struct{}
 }
 
 // NewStatsStore creates or loads a stats store using SQLite database.
@@ -130,9 +143,100 @@ func (ss *StatsStore) UpdateFromService(service *loadbalance.Service) error {
 		return nil
 	}
 
+	record := recordFromService(service)
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	return ss.db.Save(&record).Error
+}
+
+// QueueUpdate records the current stats of a service for the background flusher started by Start,
+// keeping only the latest stats per service. Without a running flusher it writes them directly.
+func (ss *StatsStore) QueueUpdate(service *loadbalance.Service) error {
+	if service == nil {
+		return nil
+	}
+
+	ss.pendingMu.Lock()
+	if ss.done == nil {
+		ss.pendingMu.Unlock()
+		return ss.UpdateFromService(service)
+	}
+	record := recordFromService(service)
+	ss.pending[ss.ServiceKey(record.Provider, record.Model)] = record
+	ss.pendingMu.Unlock()
+	return nil
+}
+
+// Start writes queued updates every interval until Close
+func (ss *StatsStore) Start(interval time.Duration) {
+	ss.pendingMu.Lock()
+	defer ss.pendingMu.Unlock()
+	if ss.done != nil {
+		return
+	}
+	ss.pending = make(map[string]ServiceStatsRecord)
+	ss.done = make(chan struct{})
+	ss.stopped = make(chan struct{})
+
+	go func(done, stopped chan struct{}) {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := ss.Flush(); err != nil {
+					log.Printf("Failed to write service stats: %v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}(ss.done, ss.stopped)
+}
+
+// Flush writes the queued updates
+func (ss *StatsStore) Flush() error {
+	ss.pendingMu.Lock()
+	records := make([]ServiceStatsRecord, 0, len(ss.pending))
+	for _, record := range ss.pending {
+		records = append(records, record)
+	}
+	clear(ss.pending)
+	ss.pendingMu.Unlock()
+	if len(records) == 0 {
+		return nil
+	}
+
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
 
+	return ss.db.Save(&records).Error
+}
+
+// Close stops the flusher and writes the remaining queued updates
+func (ss *StatsStore) Close() error {
+	ss.pendingMu.Lock()
+	done, stopped := ss.done, ss.stopped
+	ss.pendingMu.Unlock()
+	if done == nil {
+		return nil
+	}
+
+	close(done)
+	<-stopped
+	err := ss.Flush()
+
+	ss.pendingMu.Lock()
+	ss.done, ss.stopped, ss.pending = nil, nil, nil
+	ss.pendingMu.Unlock()
+	return err
+}
+
+// recordFromService snapshots the stats of a service as a record
+func recordFromService(service *loadbalance.Service) ServiceStatsRecord {
 	service.InitializeStats()
 	stat := service.Stats.GetStats()
 
@@ -147,6 +251,10 @@ func (ss *StatsStore) UpdateFromService(service *loadbalance.Service) error {
 		WindowTokensConsumed: stat.WindowTokensConsumed,
 		WindowInputTokens:    stat.WindowInputTokens,
 		WindowOutputTokens:   stat.WindowOutputTokens,
+		WindowStreamCount:    stat.WindowStreamCount,
+		WindowTTFTMs:         stat.WindowTTFTMs,
+		WindowStreamMs:       stat.WindowStreamMs,
+		WindowStreamTokens:   stat.WindowStreamTokens,
 		TimeWindow:           stat.TimeWindow,
 	}
 
@@ -164,8 +272,7 @@ func (ss *StatsStore) UpdateFromService(service *loadbalance.Service) error {
 	if record.WindowStart.IsZero() {
 		record.WindowStart = time.Now()
 	}
-
-	return ss.db.Save(&record).Error
+	return record
 }
 
 // RecordUsage records usage for a service and persists the updated stats.
@@ -208,6 +315,10 @@ func (ss *StatsStore) RecordUsage(service *loadbalance.Service, inputTokens, out
 		record.WindowTokensConsumed = 0
 		record.WindowInputTokens = 0
 		record.WindowOutputTokens = 0
+		record.WindowStreamCount = 0
+		record.WindowTTFTMs = 0
+		record.WindowStreamMs = 0
+		record.WindowStreamTokens = 0
 	}
 
 	record.RequestCount++
@@ -264,6 +375,10 @@ func (ss *StatsStore) HydrateRules(rules []typ.Rule) error {
 					WindowTokensConsumed: statCopy.WindowTokensConsumed,
 					WindowInputTokens:    statCopy.WindowInputTokens,
 					WindowOutputTokens:   statCopy.WindowOutputTokens,
+					WindowStreamCount:    statCopy.WindowStreamCount,
+					WindowTTFTMs:         statCopy.WindowTTFTMs,
+					WindowStreamMs:       statCopy.WindowStreamMs,
+					WindowStreamTokens:   statCopy.WindowStreamTokens,
 					TimeWindow:           statCopy.TimeWindow,
 				}
 				if record.TimeWindow == 0 {
@@ -293,6 +408,10 @@ func (ss *StatsStore) HydrateRules(rules []typ.Rule) error {
 
 // ClearAll removes all persisted stats.
 func (ss *StatsStore) ClearAll() error {
+	ss.pendingMu.Lock()
+	clear(ss.pending)
+	ss.pendingMu.Unlock()
+
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
 
@@ -310,6 +429,10 @@ func (r *ServiceStatsRecord) toServiceStats() loadbalance.ServiceStats {
 		WindowTokensConsumed: r.WindowTokensConsumed,
 		WindowInputTokens:    r.WindowInputTokens,
 		WindowOutputTokens:   r.WindowOutputTokens,
+		WindowStreamCount:    r.WindowStreamCount,
+		WindowTTFTMs:         r.WindowTTFTMs,
+		WindowStreamMs:       r.WindowStreamMs,
+		WindowStreamTokens:   r.WindowStreamTokens,
 		TimeWindow:           r.TimeWindow,
 	}
 }
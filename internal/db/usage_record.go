@@ -4,8 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"gorm.io/driver/sqlite"
@@ -18,6 +22,7 @@ import (
 // UsageRecord is the GORM model for persisting individual usage records
 type UsageRecord struct {
 	ID           uint      `gorm:"primaryKey;autoIncrement;column:id"`
+	RequestID    string    `gorm:"column:request_id;index:idx_request_id"`
 	ProviderUUID string    `gorm:"column:provider_uuid;index:idx_provider_model;not null"`
 	ProviderName string    `gorm:"column:provider_name;not null"`
 	Model        string    `gorm:"column:model;index:idx_provider_model;not null"`
@@ -32,6 +37,12 @@ type UsageRecord struct {
 	ErrorCode    string    `gorm:"column:error_code"`
 	LatencyMs    int       `gorm:"column:latency_ms"`
 	Streamed     bool      `gorm:"column:streamed;type:integer"`
+
+	// Streaming timings, zero for non-streamed requests
+	TTFTMs           int     `gorm:"column:ttft_ms"`            // Request start to the first upstream event
+	StreamDurationMs int     `gorm:"column:stream_duration_ms"` // First to last upstream event
+	TokensPerSecond  float64 `gorm:"column:tokens_per_second"`  // Output tokens over the stream duration
+	ChunkCount       int     `gorm:"column:chunk_count"`        // Upstream events received
 }
 
 // TableName specifies the table name for GORM
@@ -42,10 +53,10 @@ func (UsageRecord) TableName() string {
 // UsageDailyRecord is the GORM model for daily aggregated usage statistics
 type UsageDailyRecord struct {
 	ID           uint      `gorm:"primaryKey;autoIncrement;column:id"`
-	Date         time.Time `gorm:"column:date;index:idx_date;index:idx_date_provider_model;not null"`
-	ProviderUUID string    `gorm:"column:provider_uuid;index:idx_date_provider_model;not null"`
+	Date         time.Time `gorm:"column:date;index:idx_date;uniqueIndex:idx_usage_daily_key;not null"`
+	ProviderUUID string    `gorm:"column:provider_uuid;uniqueIndex:idx_usage_daily_key;not null"`
 	ProviderName string    `gorm:"column:provider_name;not null"`
-	Model        string    `gorm:"column:model;index:idx_date_provider_model;not null"`
+	Model        string    `gorm:"column:model;uniqueIndex:idx_usage_daily_key;not null"`
 	RequestCount int64     `gorm:"column:request_count;not null"`
 	TotalTokens  int64     `gorm:"column:total_tokens;not null"`
 	InputTokens  int64     `gorm:"column:input_tokens;not null"`
@@ -61,11 +72,11 @@ func (UsageDailyRecord) TableName() string {
 // UsageMonthlyRecord is the GORM model for monthly aggregated usage statistics
 type UsageMonthlyRecord struct {
 	ID           uint   `gorm:"primaryKey;autoIncrement;column:id"`
-	Year         int    `gorm:"column:year;not null"`
-	Month        int    `gorm:"column:month;not null"`
-	ProviderUUID string `gorm:"column:provider_uuid;not null"`
+	Year         int    `gorm:"column:year;uniqueIndex:idx_usage_monthly_key;not null"`
+	Month        int    `gorm:"column:month;uniqueIndex:idx_usage_monthly_key;not null"`
+	ProviderUUID string `gorm:"column:provider_uuid;uniqueIndex:idx_usage_monthly_key;not null"`
 	ProviderName string `gorm:"column:provider_name;not null"`
-	Model        string `gorm:"column:model;not null"`
+	Model        string `gorm:"column:model;uniqueIndex:idx_usage_monthly_key;not null"`
 	RequestCount int64  `gorm:"column:request_count;not null"`
 	TotalTokens  int64  `gorm:"column:total_tokens;not null"`
 	InputTokens  int64  `gorm:"column:input_tokens;not null"`
@@ -83,6 +94,7 @@ type UsageStore struct {
 	db     *gorm.DB
 	dbPath string
 	mu     sync.Mutex
+	writer atomic.Pointer[usageWriter] // set between Start and Close
 }
 
 // NewUsageStore creates or loads a usage store using SQLite database.
@@ -108,6 +120,10 @@ func NewUsageStore(baseDir string) (*UsageStore, error) {
 		dbPath: dbPath,
 	}
 
+	if err := migrateRollupKeys(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate usage rollup keys: %w", err)
+	}
+
 	// Auto-migrate schema for all usage-related tables
 	if err := db.AutoMigrate(&UsageRecord{}, &UsageDailyRecord{}, &UsageMonthlyRecord{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate usage database: %w", err)
@@ -117,6 +133,40 @@ func NewUsageStore(baseDir string) (*UsageStore, error) {
 	return store, nil
 }
 
+// migrateRollupKeys prepares rollup tables created before they had unique keys: it drops the old
+// non-unique daily index and removes duplicate rows, keeping the newest, so the unique
+// idx_usage_daily_key and idx_usage_monthly_key indexes can be created. Rollups recompute the
+// kept rows from the raw records.
+func migrateRollupKeys(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if migrator.HasTable(&UsageDailyRecord{}) {
+		if migrator.HasIndex(&UsageDailyRecord{}, "idx_date_provider_model") {
+			if err := migrator.DropIndex(&UsageDailyRecord{}, "idx_date_provider_model"); err != nil {
+				return err
+			}
+		}
+		if !migrator.HasIndex(&UsageDailyRecord{}, "idx_usage_daily_key") {
+			if err := db.Exec(`
+				DELETE FROM usage_daily WHERE id NOT IN (
+					SELECT MAX(id) FROM usage_daily GROUP BY date, provider_uuid, model
+				)
+			`).Error; err != nil {
+				return err
+			}
+		}
+	}
+	if migrator.HasTable(&UsageMonthlyRecord{}) && !migrator.HasIndex(&UsageMonthlyRecord{}, "idx_usage_monthly_key") {
+		if err := db.Exec(`
+			DELETE FROM usage_monthly WHERE id NOT IN (
+				SELECT MAX(id) FROM usage_monthly GROUP BY year, month, provider_uuid, model
+			)
+		`).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // RecordUsage records a single usage event
 func (us *UsageStore) RecordUsage(record *UsageRecord) error {
 	if record == nil {
@@ -126,6 +176,12 @@ func (us *UsageStore) RecordUsage(record *UsageRecord) error {
 	us.mu.Lock()
 	defer us.mu.Unlock()
 
+	normalizeUsageRecord(record)
+	return us.db.Create(record).Error
+}
+
+// normalizeUsageRecord fills the derived and defaulted fields of a record before insert
+func normalizeUsageRecord(record *UsageRecord) {
 	if record.Timestamp.IsZero() {
 		record.Timestamp = time.Now()
 	}
@@ -133,8 +189,6 @@ func (us *UsageStore) RecordUsage(record *UsageRecord) error {
 	if record.Status == "" {
 		record.Status = "success"
 	}
-
-	return us.db.Create(record).Error
 }
 
 // GetAggregatedStats returns aggregated usage statistics based on query parameters
@@ -148,7 +202,7 @@ type UsageStatsQuery struct {
 	RuleUUID  string
 	Status    string
 	Limit     int
-	SortBy    string // total_tokens, request_count, avg_latency
+	SortBy    string // total_tokens, request_count, avg_latency, avg_ttft
 	SortOrder string // asc, desc
 }
 
@@ -170,6 +224,15 @@ type AggregatedStat struct {
 	ErrorRate       float64 `json:"error_rate"`
 	StreamedCount   int64   `json:"streamed_count"`
 	StreamedRate    float64 `json:"streamed_rate"`
+
+	// Streaming timings over the streamed requests that received at least one event; percentiles
+	// are taken over each group's most recent 2000 of them
+	AvgTTFTMs          float64 `json:"avg_ttft_ms"`
+	P50TTFTMs          float64 `json:"p50_ttft_ms"`
+	P95TTFTMs          float64 `json:"p95_ttft_ms"`
+	AvgTokensPerSecond float64 `json:"avg_tokens_per_second"`
+	P50TokensPerSecond float64 `json:"p50_tokens_per_second"`
+	P95TokensPerSecond float64 `json:"p95_tokens_per_second"`
 }
 
 // GetAggregatedStats returns aggregated statistics
@@ -177,59 +240,35 @@ func (us *UsageStore) GetAggregatedStats(query UsageStatsQuery) ([]AggregatedSta
 	us.mu.Lock()
 	defer us.mu.Unlock()
 
-	// Build the base query
-	db := us.db.Model(&UsageRecord{})
-
-	// Apply time filter
-	if !query.StartTime.IsZero() {
-		db = db.Where("timestamp >= ?", query.StartTime)
-	}
-	if !query.EndTime.IsZero() {
-		db = db.Where("timestamp <= ?", query.EndTime)
-	}
-
-	// Apply filters
-	if query.Provider != "" {
-		db = db.Where("provider_uuid = ?", query.Provider)
-	}
-	if query.Model != "" {
-		db = db.Where("model = ?", query.Model)
-	}
-	if query.Scenario != "" {
-		db = db.Where("scenario = ?", query.Scenario)
-	}
-	if query.RuleUUID != "" {
-		db = db.Where("rule_uuid = ?", query.RuleUUID)
-	}
-	if query.Status != "" {
-		db = db.Where("status = ?", query.Status)
-	}
-
 	// Determine grouping and select fields
-	var groupBy string
+	var groupColumns []string
 	var keyField string
 	switch query.GroupBy {
 	case "provider":
-		groupBy = "provider_uuid, provider_name"
+		groupColumns = []string{"provider_uuid", "provider_name"}
 		keyField = "provider_uuid"
 	case "scenario":
-		groupBy = "scenario"
+		groupColumns = []string{"scenario"}
 		keyField = "scenario"
 	case "rule":
-		groupBy = "rule_uuid"
+		groupColumns = []string{"rule_uuid"}
 		keyField = "rule_uuid"
 	case "daily":
-		groupBy = "date(timestamp)"
+		groupColumns = []string{"date(timestamp)"}
 		keyField = "date(timestamp)"
 	case "hourly":
-		groupBy = "strftime('%Y-%m-%d %H:00:00', timestamp)"
+		groupColumns = []string{"strftime('%Y-%m-%d %H:00:00', timestamp)"}
 		keyField = "strftime('%Y-%m-%d %H:00:00', timestamp)"
 	default: // model
-		groupBy = "provider_uuid, provider_name, model"
+		groupColumns = []string{"provider_uuid", "provider_name", "model"}
 		keyField = "model"
 	}
+	groupBy := strings.Join(groupColumns, ", ")
+	// Identifies a group across the aggregate query and the timing percentile query
+	groupKey := "COALESCE(" + strings.Join(groupColumns, ", '') || '|' || COALESCE(") + ", '')"
 
 	type result struct {
+		GroupKey      string
 		Key           string
 		ProviderUUID  string
 		ProviderName  string
@@ -242,10 +281,13 @@ func (us *UsageStore) GetAggregatedStats(query UsageStatsQuery) ([]AggregatedSta
 		ErrorCount    int64
 		StreamedCount int64
 		AvgLatency    float64
+		AvgTTFT       float64
+		AvgTPS        float64
 	}
 
 	var results []result
 	selectClause := fmt.Sprintf(`
+		%s as group_key,
 		%s as key,
 		COALESCE(provider_uuid, '') as provider_uuid,
 		COALESCE(provider_name, '') as provider_name,
@@ -257,10 +299,12 @@ func (us *UsageStore) GetAggregatedStats(query UsageStatsQuery) ([]AggregatedSta
 		COALESCE(SUM(output_tokens), 0) as output_tokens,
 		COALESCE(SUM(CASE WHEN status = 'error' THEN 1 ELSE 0 END), 0) as error_count,
 		COALESCE(SUM(CASE WHEN streamed = true THEN 1 ELSE 0 END), 0) as streamed_count,
-		COALESCE(AVG(latency_ms), 0) as avg_latency
-	`, keyField)
+		COALESCE(AVG(latency_ms), 0) as avg_latency,
+		COALESCE(AVG(CASE WHEN ttft_ms > 0 THEN ttft_ms END), 0) as avg_ttft,
+		COALESCE(AVG(CASE WHEN tokens_per_second > 0 THEN tokens_per_second END), 0) as avg_tps
+	`, groupKey, keyField)
 
-	if err := db.
+	if err := us.filteredRecords(query).
 		Select(selectClause).
 		Group(groupBy).
 		Order(buildOrderBy(query.SortBy, query.SortOrder)).
@@ -269,9 +313,19 @@ func (us *UsageStore) GetAggregatedStats(query UsageStatsQuery) ([]AggregatedSta
 		return nil, err
 	}
 
+	groupKeys := make([]string, len(results))
+	for i, r := range results {
+		groupKeys[i] = r.GroupKey
+	}
+	timings, err := us.streamTimings(query, groupKey, groupKeys)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert to AggregatedStat
 	stats := make([]AggregatedStat, len(results))
 	for i, r := range results {
+		timing := timings[r.GroupKey]
 		stats[i] = AggregatedStat{
 			Key:             r.Key,
 			ProviderUUID:    r.ProviderUUID,
@@ -289,12 +343,100 @@ func (us *UsageStore) GetAggregatedStats(query UsageStatsQuery) ([]AggregatedSta
 			ErrorRate:       rateFloat(r.ErrorCount, r.RequestCount),
 			StreamedCount:   r.StreamedCount,
 			StreamedRate:    rateFloat(r.StreamedCount, r.RequestCount),
+
+			AvgTTFTMs:          r.AvgTTFT,
+			P50TTFTMs:          percentile(timing.ttft, 0.50),
+			P95TTFTMs:          percentile(timing.ttft, 0.95),
+			AvgTokensPerSecond: r.AvgTPS,
+			P50TokensPerSecond: percentile(timing.tps, 0.50),
+			P95TokensPerSecond: percentile(timing.tps, 0.95),
 		}
 	}
 
 	return stats, nil
 }
 
+// filteredRecords starts a usage record query with the time range and filters of query applied
+func (us *UsageStore) filteredRecords(query UsageStatsQuery) *gorm.DB {
+	db := us.db.Model(&UsageRecord{})
+
+	// Apply time filter
+	if !query.StartTime.IsZero() {
+		db = db.Where("timestamp >= ?", query.StartTime)
+	}
+	if !query.EndTime.IsZero() {
+		db = db.Where("timestamp <= ?", query.EndTime)
+	}
+
+	// Apply filters
+	if query.Provider != "" {
+		db = db.Where("provider_uuid = ?", query.Provider)
+	}
+	if query.Model != "" {
+		db = db.Where("model = ?", query.Model)
+	}
+	if query.Scenario != "" {
+		db = db.Where("scenario = ?", query.Scenario)
+	}
+	if query.RuleUUID != "" {
+		db = db.Where("rule_uuid = ?", query.RuleUUID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	return db
+}
+
+// groupTimings holds the sorted streaming timings of one aggregation group
+type groupTimings struct {
+	ttft []float64
+	tps  []float64
+}
+
+// maxTimingSamples bounds the streaming timings loaded per group, so percentiles over a long
+// range do not load every record of the range into memory
+const maxTimingSamples = 2000
+
+// streamTimings loads the TTFT and throughput samples of the streamed records matching query, for
+// the groups in groupKeys, for percentiles SQLite cannot compute itself. Each group is sampled by
+// its most recent maxTimingSamples records.
+func (us *UsageStore) streamTimings(query UsageStatsQuery, groupKey string, groupKeys []string) (map[string]groupTimings, error) {
+	if len(groupKeys) == 0 {
+		return nil, nil
+	}
+	var rows []struct {
+		GroupKey        string
+		TTFTMs          int `gorm:"column:ttft_ms"`
+		TokensPerSecond float64
+	}
+	samples := us.filteredRecords(query).
+		Select(groupKey + " AS group_key, ttft_ms, tokens_per_second, " +
+			"ROW_NUMBER() OVER (PARTITION BY " + groupKey + " ORDER BY timestamp DESC) AS sample").
+		Where("ttft_ms > 0")
+	if err := us.db.Table("(?) AS samples", samples).
+		Select("group_key, ttft_ms, tokens_per_second").
+		Where("sample <= ? AND group_key IN ?", maxTimingSamples, groupKeys).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	timings := make(map[string]groupTimings)
+	for _, row := range rows {
+		t := timings[row.GroupKey]
+		t.ttft = append(t.ttft, float64(row.TTFTMs))
+		if row.TokensPerSecond > 0 {
+			t.tps = append(t.tps, row.TokensPerSecond)
+		}
+		timings[row.GroupKey] = t
+	}
+	for key, t := range timings {
+		sort.Float64s(t.ttft)
+		sort.Float64s(t.tps)
+		timings[key] = t
+	}
+	return timings, nil
+}
+
 // TimeSeriesData represents a single time bucket in time series data
 type TimeSeriesData struct {
 	Timestamp    string  `json:"timestamp"`
@@ -423,43 +565,23 @@ func (us *UsageStore) GetRecords(startTime, endTime time.Time, filters map[strin
 	return records, total, nil
 }
 
-// DeleteOlderThan deletes records older than the specified date
-func (us *UsageStore) DeleteOlderThan(cutoffDate time.Time) (int64, error) {
+// GetRecordsByRequestID returns the usage records of a request, oldest first
+func (us *UsageStore) GetRecordsByRequestID(requestID string) ([]UsageRecord, error) {
 	us.mu.Lock()
 	defer us.mu.Unlock()
 
-	result := us.db.Where("timestamp < ?", cutoffDate).Delete(&UsageRecord{})
-	return result.RowsAffected, result.Error
+	var records []UsageRecord
+	err := us.db.Where("request_id = ?", requestID).Order("timestamp ASC").Find(&records).Error
+	return records, err
 }
 
-// AggregateToDaily aggregates records to daily summaries
-func (us *UsageStore) AggregateToDaily(date time.Time) (int64, error) {
+// DeleteOlderThan deletes records older than the specified date
+func (us *UsageStore) DeleteOlderThan(cutoffDate time.Time) (int64, error) {
 	us.mu.Lock()
 	defer us.mu.Unlock()
 
-	// Aggregate usage records to daily summaries
-	result := us.db.Exec(`
-		INSERT OR REPLACE INTO usage_daily (date, provider_uuid, provider_name, model, request_count, total_tokens, input_tokens, output_tokens, error_count)
-		SELECT
-			date(?) as date,
-			provider_uuid,
-			provider_name,
-			model,
-			COUNT(*) as request_count,
-			SUM(total_tokens) as total_tokens,
-			SUM(input_tokens) as input_tokens,
-			SUM(output_tokens) as output_tokens,
-			SUM(CASE WHEN status = 'error' THEN 1 ELSE 0 END) as error_count
-		FROM usage_records
-		WHERE date(timestamp) = date(?)
-		GROUP BY provider_uuid, provider_name, model
-	`, date, date)
-
-	if result.Error != nil {
-		return 0, result.Error
-	}
-
-	return result.RowsAffected, nil
+	result := us.db.Where("timestamp < ?", cutoffDate).Delete(&UsageRecord{})
+	return result.RowsAffected, result.Error
 }
 
 // Helper functions
@@ -473,6 +595,8 @@ func buildOrderBy(sortBy, sortOrder string) string {
 		return fmt.Sprintf("request_count %s", sortOrder)
 	case "avg_latency":
 		return fmt.Sprintf("avg_latency %s", sortOrder)
+	case "avg_ttft":
+		return fmt.Sprintf("avg_ttft %s", sortOrder)
 	default: // total_tokens
 		return fmt.Sprintf("total_tokens %s", sortOrder)
 	}
@@ -485,6 +609,15 @@ func avgFloat(sum float64, count int64) float64 {
 	return sum / float64(count)
 }
 
+// percentile returns the nearest-rank percentile of sorted values, or 0 without values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
 func rateFloat(numerator, denominator int64) float64 {
 	if denominator == 0 {
 		return 0
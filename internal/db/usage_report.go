@@ -0,0 +1,320 @@
+package db
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Usage report dimensions, the keys a report can be grouped by
+const (
+	ReportByDay      = "day"
+	ReportByProvider = "provider"
+	ReportByModel    = "model"
+	ReportByRule     = "rule"
+	ReportByScenario = "scenario"
+)
+
+// Usage report formats
+const (
+	ReportFormatCSV   = "csv"
+	ReportFormatJSONL = "jsonl"
+)
+
+// DefaultReportGroupBy is the grouping of a report when none is given
+var DefaultReportGroupBy = []string{ReportByDay, ReportByProvider, ReportByModel}
+
+// reportDimension maps a report dimension to its output columns and the SQL producing them from
+// the report's source rows. The first expression of a dimension is also its grouping key.
+// dailyTotals reports whether the dimension is kept in the usage_daily rollups.
+type reportDimension struct {
+	columns     []string
+	exprs       []string
+	dailyTotals bool
+}
+
+var reportDimensions = map[string]reportDimension{
+	ReportByDay:      {columns: []string{"day"}, exprs: []string{"day"}, dailyTotals: true},
+	ReportByProvider: {columns: []string{"provider_uuid", "provider_name"}, exprs: []string{"provider_uuid", "MAX(provider_name)"}, dailyTotals: true},
+	ReportByModel:    {columns: []string{"model"}, exprs: []string{"model"}, dailyTotals: true},
+	ReportByRule:     {columns: []string{"rule_uuid"}, exprs: []string{"rule_uuid"}},
+	ReportByScenario: {columns: []string{"scenario"}, exprs: []string{"scenario"}},
+}
+
+// Report source rows: raw records count once each, daily rollups carry their totals
+const (
+	reportRawColumns   = "date(timestamp) AS day, provider_uuid, provider_name, model, rule_uuid, scenario, 1 AS request_count, input_tokens, output_tokens, total_tokens, CASE WHEN status = 'error' THEN 1 ELSE 0 END AS error_count"
+	reportDailyColumns = "date(date) AS day, provider_uuid, provider_name, model, '' AS rule_uuid, '' AS scenario, request_count, input_tokens, output_tokens, total_tokens, error_count"
+)
+
+// reportTotalColumns are the columns following the dimension columns of every report row
+var reportTotalColumns = []string{"request_count", "input_tokens", "output_tokens", "total_tokens", "error_count"}
+
+// ParseReportGroupBy parses a comma-separated list of report dimensions, returning
+// DefaultReportGroupBy for an empty list
+func ParseReportGroupBy(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultReportGroupBy, nil
+	}
+
+	var groupBy []string
+	seen := map[string]bool{}
+	for _, dimension := range strings.Split(value, ",") {
+		dimension = strings.ToLower(strings.TrimSpace(dimension))
+		if _, ok := reportDimensions[dimension]; !ok {
+			return nil, fmt.Errorf("unknown group_by %q, expected one of day, provider, model, rule, scenario", dimension)
+		}
+		if !seen[dimension] {
+			seen[dimension] = true
+			groupBy = append(groupBy, dimension)
+		}
+	}
+	return groupBy, nil
+}
+
+// UsageReportRow is one group of a usage report. Values holds the dimension columns in the order
+// of ReportColumns; days are UTC.
+type UsageReportRow struct {
+	Values       []string
+	RequestCount int64
+	InputTokens  int64
+	OutputTokens int64
+	TotalTokens  int64
+	ErrorCount   int64
+}
+
+// ReportColumns returns the header of a report grouped by groupBy
+func ReportColumns(groupBy []string) []string {
+	var columns []string
+	for _, dimension := range groupBy {
+		columns = append(columns, reportDimensions[dimension].columns...)
+	}
+	return append(columns, reportTotalColumns...)
+}
+
+// UsageReportCoverage describes which part of a report's range the report can cover
+type UsageReportCoverage struct {
+	// TruncatedBefore is set when raw records before this UTC day were deleted under the
+	// retention policy and the report's grouping or filters cannot be answered from the daily
+	// totals, so the report starts at this day rather than at the requested start
+	TruncatedBefore time.Time
+}
+
+// Truncated reports whether the report misses the start of its range
+func (c UsageReportCoverage) Truncated() bool {
+	return !c.TruncatedBefore.IsZero()
+}
+
+// UsageReportCoverage returns the coverage StreamUsageReport gives query grouped by groupBy.
+// Callers check it before streaming so they can tell readers about a truncated range.
+func (us *UsageStore) UsageReportCoverage(query UsageStatsQuery, groupBy []string) (UsageReportCoverage, error) {
+	rawStart, older, err := us.reportDailyRange(query)
+	if err != nil || !older || reportFromDailyTotals(query, groupBy) {
+		return UsageReportCoverage{}, err
+	}
+	start, _ := time.Parse(dayLayout, rawStart)
+	return UsageReportCoverage{TruncatedBefore: start}, nil
+}
+
+// reportDailyRange returns the first UTC day with raw records ("" when there are none) and
+// whether query's range reaches daily totals before that day, whose raw records were deleted
+func (us *UsageStore) reportDailyRange(query UsageStatsQuery) (string, bool, error) {
+	var rawStart *string
+	if err := us.db.Model(&UsageRecord{}).Select("MIN(date(timestamp))").Scan(&rawStart).Error; err != nil {
+		return "", false, err
+	}
+	start := ""
+	if rawStart != nil {
+		start = *rawStart
+	}
+	if !query.StartTime.IsZero() && start != "" && query.StartTime.UTC().Format(dayLayout) >= start {
+		return start, false, nil
+	}
+
+	var older int64
+	if err := us.dailyReportRows(query, start).Count(&older).Error; err != nil {
+		return "", false, err
+	}
+	return start, older > 0, nil
+}
+
+// reportFromDailyTotals reports whether a report can use the daily totals, which keep only the
+// day, provider and model of the usage
+func reportFromDailyTotals(query UsageStatsQuery, groupBy []string) bool {
+	if query.Scenario != "" || query.RuleUUID != "" || query.Status != "" {
+		return false
+	}
+	for _, dimension := range groupBy {
+		if !reportDimensions[dimension].dailyTotals {
+			return false
+		}
+	}
+	return true
+}
+
+// dailyReportRows selects the daily totals in query's range for the days before rawStart. Days
+// are included whole.
+func (us *UsageStore) dailyReportRows(query UsageStatsQuery, rawStart string) *gorm.DB {
+	db := us.db.Model(&UsageDailyRecord{})
+	if rawStart != "" {
+		db = db.Where("date(date) < ?", rawStart)
+	}
+	if !query.StartTime.IsZero() {
+		db = db.Where("date(date) >= ?", query.StartTime.UTC().Format(dayLayout))
+	}
+	if !query.EndTime.IsZero() {
+		db = db.Where("date(date) <= ?", query.EndTime.UTC().Format(dayLayout))
+	}
+	if query.Provider != "" {
+		db = db.Where("provider_uuid = ?", query.Provider)
+	}
+	if query.Model != "" {
+		db = db.Where("model = ?", query.Model)
+	}
+	return db
+}
+
+// StreamUsageReport totals the records matching query's time range and filters, grouped by
+// groupBy, and passes the groups to fn one at a time without loading the report into memory.
+// Days whose raw records were deleted under the retention policy are taken from the daily totals
+// when the grouping and filters allow it; see UsageReportCoverage. Rows are ordered by their
+// dimensions. An error from fn stops the report and is returned.
+func (us *UsageStore) StreamUsageReport(query UsageStatsQuery, groupBy []string, fn func(UsageReportRow) error) error {
+	var selects, groups []string
+	width := 0
+	for _, dimension := range groupBy {
+		d, ok := reportDimensions[dimension]
+		if !ok {
+			return fmt.Errorf("unknown report dimension %q", dimension)
+		}
+		selects = append(selects, d.exprs...)
+		groups = append(groups, d.exprs[0])
+		width += len(d.columns)
+	}
+	selects = append(selects,
+		"COALESCE(SUM(request_count), 0)",
+		"COALESCE(SUM(input_tokens), 0)",
+		"COALESCE(SUM(output_tokens), 0)",
+		"COALESCE(SUM(total_tokens), 0)",
+		"COALESCE(SUM(error_count), 0)")
+
+	source := us.db.Table("(?) AS usage", us.filteredRecords(query).Select(reportRawColumns))
+	if reportFromDailyTotals(query, groupBy) {
+		rawStart, older, err := us.reportDailyRange(query)
+		if err != nil {
+			return err
+		}
+		if older {
+			source = us.db.Table("(? UNION ALL ?) AS usage",
+				us.filteredRecords(query).Select(reportRawColumns),
+				us.dailyReportRows(query, rawStart).Select(reportDailyColumns))
+		}
+	}
+
+	// Reads go straight to SQLite without the store's write lock, so a slow consumer of the
+	// report does not hold up usage writes
+	db := source.Select(strings.Join(selects, ", "))
+	if len(groups) > 0 {
+		db = db.Group(strings.Join(groups, ", ")).Order(strings.Join(groups, ", "))
+	}
+	rows, err := db.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		values := make([]*string, width)
+		row := UsageReportRow{Values: make([]string, width)}
+		dest := make([]any, 0, width+5)
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		dest = append(dest, &row.RequestCount, &row.InputTokens, &row.OutputTokens, &row.TotalTokens, &row.ErrorCount)
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		for i, value := range values {
+			if value != nil {
+				row.Values[i] = *value
+			}
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// UsageReportEncoder writes usage report rows as CSV or JSON Lines
+type UsageReportEncoder struct {
+	columns []string
+	csv     *csv.Writer
+	json    *json.Encoder
+}
+
+// NewUsageReportEncoder returns an encoder writing a report grouped by groupBy to w in format.
+// CSV output starts with a header row.
+func NewUsageReportEncoder(w io.Writer, format string, groupBy []string) (*UsageReportEncoder, error) {
+	e := &UsageReportEncoder{columns: ReportColumns(groupBy)}
+	switch format {
+	case ReportFormatCSV:
+		e.csv = csv.NewWriter(w)
+		if err := e.csv.Write(e.columns); err != nil {
+			return nil, err
+		}
+	case ReportFormatJSONL:
+		e.json = json.NewEncoder(w)
+	default:
+		return nil, fmt.Errorf("unknown report format %q, expected csv or jsonl", format)
+	}
+	return e, nil
+}
+
+// Encode writes one row
+func (e *UsageReportEncoder) Encode(row UsageReportRow) error {
+	totals := []int64{row.RequestCount, row.InputTokens, row.OutputTokens, row.TotalTokens, row.ErrorCount}
+
+	if e.csv != nil {
+		record := append([]string{}, row.Values...)
+		for _, total := range totals {
+			record = append(record, strconv.FormatInt(total, 10))
+		}
+		return e.csv.Write(record)
+	}
+
+	// Build the object by hand so keys keep the column order
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, column := range e.columns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(column)
+		b.Write(key)
+		b.WriteByte(':')
+		if i < len(row.Values) {
+			value, _ := json.Marshal(row.Values[i])
+			b.Write(value)
+		} else {
+			b.WriteString(strconv.FormatInt(totals[i-len(row.Values)], 10))
+		}
+	}
+	b.WriteByte('}')
+	return e.json.Encode(json.RawMessage(b.String()))
+}
+
+// Flush writes any buffered output
+func (e *UsageReportEncoder) Flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		return e.csv.Error()
+	}
+	return nil
+}
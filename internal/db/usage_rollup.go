@@ -0,0 +1,109 @@
+package db
+
+import (
+	"log"
+	"time"
+)
+
+// dayLayout formats the UTC day buckets of the rollup tables, matching SQLite's date()
+const dayLayout = "2006-01-02"
+
+// Rollup aggregates raw usage records into the daily and monthly tables. Days are UTC days; days
+// on or after since are recomputed from the raw records, a zero since recomputes every day that
+// still has raw records. Months touched by those days are recomputed from the daily table.
+func (us *UsageStore) Rollup(since time.Time) error {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	// Raw timestamps are stored with their local offset, so compare bucket days through date(),
+	// with a day of slack on the indexed column to narrow the scan
+	sinceDay, scanFrom := "", time.Time{}
+	if !since.IsZero() {
+		since = since.UTC().Truncate(24 * time.Hour)
+		sinceDay, scanFrom = since.Format(dayLayout), since.Add(-24*time.Hour)
+	}
+
+	if err := us.db.Exec(`
+		INSERT OR REPLACE INTO usage_daily (date, provider_uuid, provider_name, model, request_count, total_tokens, input_tokens, output_tokens, error_count)
+		SELECT
+			date(timestamp),
+			provider_uuid,
+			MAX(provider_name),
+			model,
+			COUNT(*),
+			SUM(total_tokens),
+			SUM(input_tokens),
+			SUM(output_tokens),
+			SUM(CASE WHEN status = 'error' THEN 1 ELSE 0 END)
+		FROM usage_records
+		WHERE timestamp >= ? AND date(timestamp) >= ?
+		GROUP BY date(timestamp), provider_uuid, model
+	`, scanFrom, sinceDay).Error; err != nil {
+		return err
+	}
+
+	monthStart := ""
+	if sinceDay != "" {
+		monthStart = time.Date(since.Year(), since.Month(), 1, 0, 0, 0, 0, time.UTC).Format(dayLayout)
+	}
+	return us.db.Exec(`
+		INSERT OR REPLACE INTO usage_monthly (year, month, provider_uuid, provider_name, model, request_count, total_tokens, input_tokens, output_tokens, error_count)
+		SELECT
+			CAST(strftime('%Y', date) AS INTEGER),
+			CAST(strftime('%m', date) AS INTEGER),
+			provider_uuid,
+			MAX(provider_name),
+			model,
+			SUM(request_count),
+			SUM(total_tokens),
+			SUM(input_tokens),
+			SUM(output_tokens),
+			SUM(error_count)
+		FROM usage_daily
+		WHERE date >= ?
+		GROUP BY strftime('%Y', date), strftime('%m', date), provider_uuid, model
+	`, monthStart).Error
+}
+
+// deleteRawBefore removes raw records of the UTC days before day, which must already be rolled up
+func (us *UsageStore) deleteRawBefore(day time.Time) (int64, error) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	day = day.UTC().Truncate(24 * time.Hour)
+	result := us.db.Where("timestamp < ? AND date(timestamp) < ?", day.Add(24*time.Hour), day.Format(dayLayout)).
+		Delete(&UsageRecord{})
+	return result.RowsAffected, result.Error
+}
+
+// runRollups rolls up usage every DefaultUsageRollupInterval until done is closed. The first run
+// backfills every day with raw records; later runs recompute yesterday and today. Raw records
+// older than retentionDays are deleted once their days are rolled up.
+func (us *UsageStore) runRollups(done <-chan struct{}, retentionDays int) {
+	var since time.Time
+	rolledUp := false
+	for {
+		now := time.Now()
+		if err := us.Rollup(since); err != nil {
+			log.Printf("Failed to roll up usage records: %v", err)
+		} else {
+			rolledUp = true
+			since = now.Add(-24 * time.Hour)
+		}
+
+		if rolledUp && retentionDays > 0 {
+			deleted, err := us.deleteRawBefore(now.AddDate(0, 0, -retentionDays))
+			if err != nil {
+				log.Printf("Failed to delete expired usage records: %v", err)
+			} else if deleted > 0 {
+				log.Printf("Deleted %d usage records older than %d days", deleted, retentionDays)
+			}
+		}
+
+		select {
+		case <-done:
+			return
+		case <-time.After(DefaultUsageRollupInterval):
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package db
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults for UsageStoreConfig
+const (
+	DefaultUsageBufferSize     = 10000
+	DefaultUsageBatchSize      = 200
+	DefaultUsageFlushInterval  = time.Second
+	DefaultUsageRollupInterval = time.Hour
+)
+
+// UsageStoreConfig controls how usage records are written and kept
+type UsageStoreConfig struct {
+	BufferSize    int `json:"buffer_size,omitempty" yaml:"buffer_size,omitempty"`       // Records held in memory awaiting insert (default 10000); records beyond it are dropped
+	BatchSize     int `json:"batch_size,omitempty" yaml:"batch_size,omitempty"`         // Records per insert (default 200)
+	FlushInterval int `json:"flush_interval,omitempty" yaml:"flush_interval,omitempty"` // Milliseconds before a partial batch is written (default 1000)
+	RetentionDays int `json:"retention_days,omitempty" yaml:"retention_days,omitempty"` // Days raw records are kept once rolled up into daily totals (default 0, keep forever)
+}
+
+// withDefaults fills unset fields
+func (c UsageStoreConfig) withDefaults() UsageStoreConfig {
+	if c.BufferSize <= 0 {
+		c.BufferSize = DefaultUsageBufferSize
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultUsageBatchSize
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = int(DefaultUsageFlushInterval / time.Millisecond)
+	}
+	return c
+}
+
+// usageWriter batches usage records into inserts on a background goroutine, keeping database
+// writes off the request path. The queue is bounded; when it is full records are dropped rather
+// than blocking requests.
+type usageWriter struct {
+	queue   chan *UsageRecord
+	done    chan struct{}
+	stopped chan struct{}
+	dropped atomic.Int64
+
+	// mu orders queueing against Close: Enqueue holds it for reading while it checks closed and
+	// queues, Close holds it for writing while it sets closed, so once done is closed no record
+	// can reach the queue after the writer's final drain
+	mu     sync.RWMutex
+	closed bool
+}
+
+// Start begins batching records passed to Enqueue and rolling raw records up into the daily and
+// monthly tables. Close stops both, writing whatever is still queued.
+func (us *UsageStore) Start(cfg UsageStoreConfig) {
+	cfg = cfg.withDefaults()
+
+	w := &usageWriter{
+		queue:   make(chan *UsageRecord, cfg.BufferSize),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	if !us.writer.CompareAndSwap(nil, w) {
+		return
+	}
+
+	go us.runWriter(w, cfg.BatchSize, time.Duration(cfg.FlushInterval)*time.Millisecond)
+	go us.runRollups(w.done, cfg.RetentionDays)
+}
+
+// Enqueue hands a record to the background writer, or writes it directly when the writer is not
+// running. It reports false when the record was dropped because the queue is full.
+func (us *UsageStore) Enqueue(record *UsageRecord) bool {
+	if record == nil {
+		return false
+	}
+	w := us.writer.Load()
+	if w == nil {
+		if err := us.RecordUsage(record); err != nil {
+			log.Printf("Failed to record usage: %v", err)
+		}
+		return true
+	}
+
+	// Stamp on arrival so a queued record keeps the time of its request
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		// The writer is shutting down; write directly rather than queue behind its final drain
+		if err := us.RecordUsage(record); err != nil {
+			log.Printf("Failed to record usage: %v", err)
+		}
+		return true
+	}
+	select {
+	case w.queue <- record:
+		return true
+	default:
+		if w.dropped.Add(1)%1000 == 1 {
+			log.Printf("Usage write queue full, dropped %d records so far", w.dropped.Load())
+		}
+		return false
+	}
+}
+
+// Dropped returns how many records were dropped because the write queue was full
+func (us *UsageStore) Dropped() int64 {
+	if w := us.writer.Load(); w != nil {
+		return w.dropped.Load()
+	}
+	return 0
+}
+
+// Close stops the background writer and rollups, writing the queued records first. It returns
+// ctx's error when the queue could not be drained in time.
+func (us *UsageStore) Close(ctx context.Context) error {
+	w := us.writer.Load()
+	if w == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.done)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.stopped:
+		us.writer.CompareAndSwap(w, nil)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runWriter collects queued records into batches, writing a batch when it is full or when the
+// flush interval passes
+func (us *UsageStore) runWriter(w *usageWriter, batchSize int, flushInterval time.Duration) {
+	defer close(w.stopped)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*UsageRecord, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := us.RecordUsageBatch(batch); err != nil {
+			log.Printf("Failed to write %d usage records: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case record := <-w.queue:
+			batch = append(batch, record)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-w.done:
+			// Drain what requests queued before shutdown
+			for {
+				select {
+				case record := <-w.queue:
+					batch = append(batch, record)
+					if len(batch) >= batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// usageInsertChunk is how many records one INSERT statement carries, keeping a statement's bound
+// parameters under SQLite's limit whatever the configured batch size
+const usageInsertChunk = 500
+
+// RecordUsageBatch records several usage events in one transaction
+func (us *UsageStore) RecordUsageBatch(records []*UsageRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	for _, record := range records {
+		normalizeUsageRecord(record)
+	}
+	return us.db.CreateInBatches(records, usageInsertChunk).Error
+}
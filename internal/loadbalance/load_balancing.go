@@ -48,12 +48,24 @@ func (s *Service) RecordUsage(inputTokens, outputTokens int) {
 	s.Stats.RecordUsage(inputTokens, outputTokens)
 }
 
+// RecordStreamTiming records the timings of a streamed response from this service
+func (s *Service) RecordStreamTiming(ttft, duration time.Duration, outputTokens int) {
+	s.InitializeStats()
+	s.Stats.RecordStreamTiming(ttft, duration, outputTokens)
+}
+
 // GetWindowStats returns current window statistics for this service
 func (s *Service) GetWindowStats() (requestCount int64, tokensConsumed int64) {
 	s.InitializeStats()
 	return s.Stats.GetWindowStats()
 }
 
+// GetWindowStreamStats returns current window streaming statistics for this service
+func (s *Service) GetWindowStreamStats() (streamCount int64, avgTTFT time.Duration, tokensPerSecond float64) {
+	s.InitializeStats()
+	return s.Stats.GetWindowStreamStats()
+}
+
 // ServiceStats tracks usage statistics for a service
 type ServiceStats struct {
 	ServiceID            string       `json:"service_id"`             // Unique service identifier
@@ -64,6 +76,10 @@ type ServiceStats struct {
 	WindowTokensConsumed int64        `json:"window_tokens_consumed"` // Tokens consumed in current window (input + output)
 	WindowInputTokens    int64        `json:"window_input_tokens"`    // Input tokens in current window
 	WindowOutputTokens   int64        `json:"window_output_tokens"`   // Output tokens in current window
+	WindowStreamCount    int64        `json:"window_stream_count"`    // Streamed responses with timings in current window
+	WindowTTFTMs         int64        `json:"window_ttft_ms"`         // Sum of their times to first token in milliseconds
+	WindowStreamMs       int64        `json:"window_stream_ms"`       // Sum of their stream durations in milliseconds
+	WindowStreamTokens   int64        `json:"window_stream_tokens"`   // Sum of their output tokens
 	TimeWindow           int          `json:"time_window"`            // Copy of service's time window
 	mutex                sync.RWMutex `json:"-"`                      // Thread safety
 }
@@ -82,6 +98,7 @@ func (ss *ServiceStats) RecordUsage(inputTokens, outputTokens int) {
 		ss.WindowTokensConsumed = 0
 		ss.WindowInputTokens = 0
 		ss.WindowOutputTokens = 0
+		ss.resetWindowStreams()
 	}
 
 	ss.RequestCount++
@@ -92,6 +109,47 @@ func (ss *ServiceStats) RecordUsage(inputTokens, outputTokens int) {
 	ss.LastUsed = now
 }
 
+// RecordStreamTiming adds the timings of a streamed response to the current window. Call it after
+// RecordUsage for the same request, which starts a new window when the current one expired.
+func (ss *ServiceStats) RecordStreamTiming(ttft, duration time.Duration, outputTokens int) {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	ss.WindowStreamCount++
+	ss.WindowTTFTMs += ttft.Milliseconds()
+	ss.WindowStreamMs += duration.Milliseconds()
+	ss.WindowStreamTokens += int64(outputTokens)
+}
+
+// GetWindowStreamStats returns the streamed responses in the current window with their average
+// time to first token and output throughput
+func (ss *ServiceStats) GetWindowStreamStats() (streamCount int64, avgTTFT time.Duration, tokensPerSecond float64) {
+	if time.Since(ss.WindowStart) >= time.Duration(ss.TimeWindow)*time.Second {
+		ss.ResetWindow()
+		return 0, 0, 0
+	}
+
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+
+	if ss.WindowStreamCount == 0 {
+		return 0, 0, 0
+	}
+	avgTTFT = time.Duration(ss.WindowTTFTMs/ss.WindowStreamCount) * time.Millisecond
+	if ss.WindowStreamMs > 0 {
+		tokensPerSecond = float64(ss.WindowStreamTokens) / (float64(ss.WindowStreamMs) / 1000)
+	}
+	return ss.WindowStreamCount, avgTTFT, tokensPerSecond
+}
+
+// resetWindowStreams clears the streaming timings of the window; callers hold the lock
+func (ss *ServiceStats) resetWindowStreams() {
+	ss.WindowStreamCount = 0
+	ss.WindowTTFTMs = 0
+	ss.WindowStreamMs = 0
+	ss.WindowStreamTokens = 0
+}
+
 // GetWindowStats returns current window statistics
 func (ss *ServiceStats) GetWindowStats() (requestCount int64, tokensConsumed int64) {
 	// Check if window has expired without locking first
@@ -142,6 +200,7 @@ func (ss *ServiceStats) ResetWindow() {
 	ss.WindowTokensConsumed = 0
 	ss.WindowInputTokens = 0
 	ss.WindowOutputTokens = 0
+	ss.resetWindowStreams()
 }
 
 // GetStats returns a copy of current statistics
@@ -158,6 +217,10 @@ func (ss *ServiceStats) GetStats() ServiceStats {
 		WindowTokensConsumed: ss.WindowTokensConsumed,
 		WindowInputTokens:    ss.WindowInputTokens,
 		WindowOutputTokens:   ss.WindowOutputTokens,
+		WindowStreamCount:    ss.WindowStreamCount,
+		WindowTTFTMs:         ss.WindowTTFTMs,
+		WindowStreamMs:       ss.WindowStreamMs,
+		WindowStreamTokens:   ss.WindowStreamTokens,
 		TimeWindow:           ss.TimeWindow,
 	}
 }
@@ -166,10 +229,11 @@ func (ss *ServiceStats) GetStats() ServiceStats {
 type TacticType int
 
 const (
-	TacticRoundRobin TacticType = iota // Rotate by request count
-	TacticTokenBased                   // Rotate by token consumption
-	TacticHybrid                       // Hybrid: request count or tokens, whichever comes first
-	TacticRandom                       // Random selection with weighted probability
+	TacticRoundRobin   TacticType = iota // Rotate by request count
+	TacticTokenBased                     // Rotate by token consumption
+	TacticHybrid                         // Hybrid: request count or tokens, whichever comes first
+	TacticRandom                         // Random selection with weighted probability
+	TacticLatencyBased                   // Lowest average time to first token in the window
 )
 
 // MarshalJSON implements json.Marshaler for TacticType
@@ -204,6 +268,8 @@ func (tt TacticType) String() string {
 		return "hybrid"
 	case TacticRandom:
 		return "random"
+	case TacticLatencyBased:
+		return "latency_based"
 	default:
 		return "unknown"
 	}
@@ -220,6 +286,8 @@ func ParseTacticType(s string) TacticType {
 		return TacticHybrid
 	case "random":
 		return TacticRandom
+	case "latency_based":
+		return TacticLatencyBased
 	default:
 		return TacticRoundRobin // default
 	}
@@ -239,6 +239,7 @@ func TestParseTacticType(t *testing.T) {
 		{"round_robin", TacticRoundRobin},
 		{"token_based", TacticTokenBased},
 		{"hybrid", TacticHybrid},
+		{"latency_based", TacticLatencyBased},
 		{"invalid", TacticRoundRobin}, // Default fallback
 		{"", TacticRoundRobin},        // Empty string fallback
 	}
@@ -252,10 +253,11 @@ func TestParseTacticType(t *testing.T) {
 
 func TestTacticType_String(t *testing.T) {
 	tests := map[TacticType]string{
-		TacticRoundRobin: "round_robin",
-		TacticTokenBased: "token_based",
-		TacticHybrid:     "hybrid",
-		TacticType(999):  "unknown", // Invalid type
+		TacticRoundRobin:   "round_robin",
+		TacticTokenBased:   "token_based",
+		TacticHybrid:       "hybrid",
+		TacticLatencyBased: "latency_based",
+		TacticType(999):    "unknown", // Invalid type
 	}
 
 	for tacticType, expected := range tests {
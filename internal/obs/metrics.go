@@ -0,0 +1,238 @@
+package obs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLatencyBuckets are histogram upper bounds in seconds, spanning quick completions to long
+// agentic streams
+var DefaultLatencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// MetricsContentType is the content type of the Prometheus text exposition format
+const MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Registry holds metric families and renders them in the Prometheus text exposition format.
+// It covers the counters, gauges and histograms the proxy exports without pulling in a client
+// library.
+type Registry struct {
+	mu       sync.Mutex
+	families []*family
+}
+
+// NewRegistry creates an empty metrics registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+type family struct {
+	name    string
+	help    string
+	kind    string
+	labels  []string
+	buckets []float64
+	series  map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	buckets     []uint64 // histogram observations per bucket, not cumulative
+	sum         float64
+	count       uint64
+}
+
+// CounterVec is a counter partitioned by label values
+type CounterVec struct {
+	r *Registry
+	f *family
+}
+
+// GaugeVec is a gauge partitioned by label values
+type GaugeVec struct {
+	r *Registry
+	f *family
+}
+
+// HistogramVec is a histogram partitioned by label values
+type HistogramVec struct {
+	r *Registry
+	f *family
+}
+
+// Counter registers a counter family with the given label names
+func (r *Registry) Counter(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{r: r, f: r.register(name, help, "counter", labels, nil)}
+}
+
+// Gauge registers a gauge family with the given label names
+func (r *Registry) Gauge(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{r: r, f: r.register(name, help, "gauge", labels, nil)}
+}
+
+// Histogram registers a histogram family with the given bucket upper bounds and label names
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	return &HistogramVec{r: r, f: r.register(name, help, "histogram", labels, sorted)}
+}
+
+func (r *Registry) register(name, help, kind string, labels []string, buckets []float64) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := &family{name: name, help: help, kind: kind, labels: labels, buckets: buckets, series: make(map[string]*series)}
+	r.families = append(r.families, f)
+	return f
+}
+
+// Add increases the counter; negative deltas are ignored since counters only go up
+func (v *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta <= 0 {
+		return
+	}
+	v.r.mu.Lock()
+	defer v.r.mu.Unlock()
+	v.f.get(labelValues).value += delta
+}
+
+// Inc increases the counter by one
+func (v *CounterVec) Inc(labelValues ...string) {
+	v.Add(1, labelValues...)
+}
+
+// Add changes the gauge by delta
+func (v *GaugeVec) Add(delta float64, labelValues ...string) {
+	v.r.mu.Lock()
+	defer v.r.mu.Unlock()
+	v.f.get(labelValues).value += delta
+}
+
+// Inc increases the gauge by one
+func (v *GaugeVec) Inc(labelValues ...string) {
+	v.Add(1, labelValues...)
+}
+
+// Dec decreases the gauge by one
+func (v *GaugeVec) Dec(labelValues ...string) {
+	v.Add(-1, labelValues...)
+}
+
+// Observe records one value in the histogram
+func (v *HistogramVec) Observe(value float64, labelValues ...string) {
+	v.r.mu.Lock()
+	defer v.r.mu.Unlock()
+	s := v.f.get(labelValues)
+	if s.buckets == nil {
+		s.buckets = make([]uint64, len(v.f.buckets))
+	}
+	if i := sort.SearchFloat64s(v.f.buckets, value); i < len(v.f.buckets) {
+		s.buckets[i]++
+	}
+	s.sum += value
+	s.count++
+}
+
+// get returns the series for the label values, creating it on first use. Missing values are
+// recorded as empty labels, extra values are dropped.
+func (f *family) get(labelValues []string) *series {
+	values := make([]string, len(f.labels))
+	copy(values, labelValues)
+	key := strings.Join(values, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: values}
+		f.series[key] = s
+	}
+	return s
+}
+
+// WriteText renders all families in the Prometheus text exposition format, series sorted by
+// label values so scrapes are stable
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, f := range r.families {
+		fmt.Fprintf(bw, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.name, f.kind)
+
+		keys := make([]string, 0, len(f.series))
+		for key := range f.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			s := f.series[key]
+			labels := formatLabels(f.labels, s.labelValues)
+			if f.kind != "histogram" {
+				fmt.Fprintf(bw, "%s%s %s\n", f.name, labels, formatValue(s.value))
+				continue
+			}
+			names := append(append([]string(nil), f.labels...), "le")
+			values := append(append([]string(nil), s.labelValues...), "")
+			var cumulative uint64
+			for i, bound := range f.buckets {
+				if s.buckets != nil {
+					cumulative += s.buckets[i]
+				}
+				values[len(values)-1] = formatValue(bound)
+				fmt.Fprintf(bw, "%s_bucket%s %d\n", f.name, formatLabels(names, values), cumulative)
+			}
+			values[len(values)-1] = "+Inf"
+			fmt.Fprintf(bw, "%s_bucket%s %d\n", f.name, formatLabels(names, values), s.count)
+			fmt.Fprintf(bw, "%s_sum%s %s\n", f.name, labels, formatValue(s.sum))
+			fmt.Fprintf(bw, "%s_count%s %d\n", f.name, labels, s.count)
+		}
+	}
+	return bw.Flush()
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabelValue(values[i]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
+
+func escapeHelp(v string) string {
+	return helpEscaper.Replace(v)
+}
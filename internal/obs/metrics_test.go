@@ -0,0 +1,48 @@
+package obs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistryWriteText(t *testing.T) {
+	r := NewRegistry()
+	requests := r.Counter("requests_total", "Requests served.", "provider", "status")
+	inFlight := r.Gauge("in_flight", "Requests in progress.", "dialect")
+	latency := r.Histogram("latency_seconds", "Request latency.", []float64{1, 0.5}, "provider")
+
+	requests.Inc("openai", "success")
+	requests.Add(2, "openai", "success")
+	requests.Inc(`we"ird`, "error")
+	requests.Add(-1, "openai", "success")
+	inFlight.Inc("anthropic")
+	inFlight.Inc("anthropic")
+	inFlight.Dec("anthropic")
+	latency.Observe(0.2, "openai")
+	latency.Observe(0.7, "openai")
+	latency.Observe(3, "openai")
+
+	var out strings.Builder
+	if err := r.WriteText(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{provider="openai",status="success"} 3
+requests_total{provider="we\"ird",status="error"} 1
+# HELP in_flight Requests in progress.
+# TYPE in_flight gauge
+in_flight{dialect="anthropic"} 1
+# HELP latency_seconds Request latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{provider="openai",le="0.5"} 1
+latency_seconds_bucket{provider="openai",le="1"} 2
+latency_seconds_bucket{provider="openai",le="+Inf"} 3
+latency_seconds_sum{provider="openai"} 3.9
+latency_seconds_count{provider="openai"} 3
+`
+	if out.String() != want {
+		t.Errorf("unexpected exposition:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,214 @@
+package obs
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces values of sensitive headers, keys and query parameters
+const RedactedValue = "[REDACTED]"
+
+// RedactionConfig configures secret and PII redaction of recorded traffic
+type RedactionConfig struct {
+	Disabled bool            `json:"disabled,omitempty" yaml:"disabled,omitempty"` // Turn redaction off entirely (not recommended)
+	Headers  []string        `json:"headers,omitempty" yaml:"headers,omitempty"`   // Extra header names whose values are always masked
+	Rules    []RedactionRule `json:"rules,omitempty" yaml:"rules,omitempty"`       // Extra regex rules applied after the built-in ones
+}
+
+// RedactionRule replaces every match of Pattern in recorded text
+type RedactionRule struct {
+	Name        string `json:"name" yaml:"name"`
+	Pattern     string `json:"pattern" yaml:"pattern"`                             // Go regexp syntax
+	Replacement string `json:"replacement,omitempty" yaml:"replacement,omitempty"` // Defaults to [REDACTED:<name>]
+}
+
+// builtinRedactionRules cover auth tokens and common secret formats. Order matters:
+// the more specific key prefixes run before the generic ones.
+var builtinRedactionRules = []RedactionRule{
+	{Name: "jwt", Pattern: `eyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`},
+	{Name: "bearer", Pattern: `(?i)\bbearer\s+[A-Za-z0-9._~+/=-]{16,}`, Replacement: "Bearer [REDACTED:bearer]"},
+	{Name: "anthropic_key", Pattern: `sk-ant-[A-Za-z0-9_-]{16,}`},
+	{Name: "openai_key", Pattern: `sk-[A-Za-z0-9_-]{16,}`},
+	{Name: "google_key", Pattern: `AIza[0-9A-Za-z_-]{35}`},
+	{Name: "google_oauth", Pattern: `ya29\.[0-9A-Za-z_-]{16,}`},
+	{Name: "github_token", Pattern: `(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})`},
+	{Name: "slack_token", Pattern: `xox[abposr]-[A-Za-z0-9-]{10,}`},
+	{Name: "aws_access_key", Pattern: `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{Name: "email", Pattern: `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`},
+}
+
+// sensitiveHeaders are masked entirely, whatever their value looks like
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"X-Api-Key",
+	"X-Goog-Api-Key",
+	"Api-Key",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// sensitiveKeys are JSON keys and query parameters whose values are masked entirely
+var sensitiveKeys = map[string]bool{
+	"api_key":       true,
+	"apikey":        true,
+	"key":           true,
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"client_secret": true,
+	"password":      true,
+	"secret":        true,
+}
+
+type redactionPattern struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// Redactor masks secrets and PII in recorded headers, bodies and free text
+type Redactor struct {
+	disabled bool
+	headers  map[string]bool // canonical header names
+	patterns []redactionPattern
+}
+
+// NewRedactor creates a redactor with the built-in rules plus the configured ones.
+// A nil config yields the built-in rules only.
+func NewRedactor(cfg *RedactionConfig) (*Redactor, error) {
+	r := &Redactor{headers: make(map[string]bool)}
+	if cfg != nil && cfg.Disabled {
+		r.disabled = true
+		return r, nil
+	}
+
+	for _, h := range sensitiveHeaders {
+		r.headers[http.CanonicalHeaderKey(h)] = true
+	}
+	rules := builtinRedactionRules
+	if cfg != nil {
+		for _, h := range cfg.Headers {
+			r.headers[http.CanonicalHeaderKey(h)] = true
+		}
+		rules = append(append([]RedactionRule{}, rules...), cfg.Rules...)
+	}
+
+	for _, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction rule %q: %w", rule.Name, err)
+		}
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = fmt.Sprintf("[REDACTED:%s]", rule.Name)
+		}
+		r.patterns = append(r.patterns, redactionPattern{re: re, replacement: replacement})
+	}
+	return r, nil
+}
+
+// DefaultRedactor returns a redactor with only the built-in rules
+func DefaultRedactor() *Redactor {
+	r, _ := NewRedactor(nil)
+	return r
+}
+
+// String redacts secrets in free text
+func (r *Redactor) String(s string) string {
+	if r == nil || r.disabled || s == "" {
+		return s
+	}
+	for _, p := range r.patterns {
+		s = p.re.ReplaceAllLiteralString(s, p.replacement)
+	}
+	return s
+}
+
+// Bytes redacts secrets in a raw body
+func (r *Redactor) Bytes(b []byte) []byte {
+	if r == nil || r.disabled || len(b) == 0 {
+		return b
+	}
+	for _, p := range r.patterns {
+		b = p.re.ReplaceAllLiteral(b, []byte(p.replacement))
+	}
+	return b
+}
+
+// Headers returns a copy of the headers with sensitive values masked
+func (r *Redactor) Headers(headers map[string]string) map[string]string {
+	if r == nil || r.disabled || headers == nil {
+		return headers
+	}
+	result := make(map[string]string, len(headers))
+	for k, v := range headers {
+		if r.headers[http.CanonicalHeaderKey(k)] {
+			result[k] = RedactedValue
+			continue
+		}
+		result[k] = r.String(v)
+	}
+	return result
+}
+
+// URL masks credential query parameters and secrets in a URL string
+func (r *Redactor) URL(raw string) string {
+	if r == nil || r.disabled || raw == "" {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return r.String(raw)
+	}
+	if u.User != nil {
+		u.User = url.User(u.User.Username())
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		for k := range q {
+			if sensitiveKeys[strings.ToLower(k)] {
+				q.Set(k, RedactedValue)
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	return r.String(u.String())
+}
+
+// Map returns a redacted deep copy of a decoded JSON object
+func (r *Redactor) Map(m map[string]interface{}) map[string]interface{} {
+	if r == nil || r.disabled || m == nil {
+		return m
+	}
+	redacted, _ := r.value(m).(map[string]interface{})
+	return redacted
+}
+
+// value redacts a decoded JSON value, returning a copy
+func (r *Redactor) value(v interface{}) interface{} {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(node))
+		for k, child := range node {
+			if s, ok := child.(string); ok && s != "" && sensitiveKeys[strings.ToLower(k)] {
+				result[k] = RedactedValue
+				continue
+			}
+			result[k] = r.value(child)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(node))
+		for i, child := range node {
+			result[i] = r.value(child)
+		}
+		return result
+	case string:
+		return r.String(node)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,189 @@
+package obs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID in and out of the proxy
+const RequestIDHeader = "X-Request-Id"
+
+// maxRequestIDLength bounds a request ID accepted from a client
+const maxRequestIDLength = 128
+
+// NewRequestID returns a new random request ID
+func NewRequestID() string {
+	return uuid.New().String()
+}
+
+// ValidRequestID reports whether a client-supplied request ID is short printable ASCII, safe to
+// echo in headers and logs
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// RoutingDecision is one resolution of a request model to a provider service
+type RoutingDecision struct {
+	Time         time.Time `json:"time"`
+	Scenario     string    `json:"scenario"`
+	RequestModel string    `json:"request_model"`
+	RuleUUID     string    `json:"rule_uuid,omitempty"`
+	Tactic       string    `json:"tactic,omitempty"`
+	Candidates   int       `json:"candidates"` // Active services the rule could choose from
+	Provider     string    `json:"provider,omitempty"`
+	Model        string    `json:"model,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// UpstreamAttempt is one HTTP call to a provider; SDK retries show up as further attempts
+type UpstreamAttempt struct {
+	Time       time.Time `json:"time"`
+	Provider   string    `json:"provider"`
+	Method     string    `json:"method"`
+	URL        string    `json:"url"`
+	StatusCode int       `json:"status_code,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// RequestDetail follows one proxied request from ingress through routing to its upstream calls
+type RequestDetail struct {
+	mu sync.Mutex
+
+	ID         string            `json:"id"`
+	Time       time.Time         `json:"time"`
+	Method     string            `json:"method"`
+	Path       string            `json:"path"`
+	StatusCode int               `json:"status_code"`
+	LatencyMs  int64             `json:"latency_ms"`
+	Routing    []RoutingDecision `json:"routing,omitempty"`
+	Attempts   []UpstreamAttempt `json:"attempts,omitempty"`
+}
+
+// AddRouting appends a routing decision
+func (d *RequestDetail) AddRouting(decision RoutingDecision) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.Routing = append(d.Routing, decision)
+}
+
+// AddAttempt appends an upstream attempt
+func (d *RequestDetail) AddAttempt(attempt UpstreamAttempt) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.Attempts = append(d.Attempts, attempt)
+}
+
+// Finish records the response status and latency
+func (d *RequestDetail) Finish(statusCode int, latency time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.StatusCode = statusCode
+	d.LatencyMs = latency.Milliseconds()
+}
+
+// Snapshot returns a copy safe to read while the request is still running
+func (d *RequestDetail) Snapshot() *RequestDetail {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return &RequestDetail{
+		ID:         d.ID,
+		Time:       d.Time,
+		Method:     d.Method,
+		Path:       d.Path,
+		StatusCode: d.StatusCode,
+		LatencyMs:  d.LatencyMs,
+		Routing:    append([]RoutingDecision(nil), d.Routing...),
+		Attempts:   append([]UpstreamAttempt(nil), d.Attempts...),
+	}
+}
+
+type requestDetailKey struct{}
+
+// WithRequestDetail returns a context carrying the request's detail
+func WithRequestDetail(ctx context.Context, detail *RequestDetail) context.Context {
+	return context.WithValue(ctx, requestDetailKey{}, detail)
+}
+
+// RequestDetailFromContext returns the detail of the request ctx belongs to, or nil
+func RequestDetailFromContext(ctx context.Context) *RequestDetail {
+	detail, _ := ctx.Value(requestDetailKey{}).(*RequestDetail)
+	return detail
+}
+
+// RequestIDFromContext returns the ID of the request ctx belongs to, or ""
+func RequestIDFromContext(ctx context.Context) string {
+	if detail := RequestDetailFromContext(ctx); detail != nil {
+		return detail.ID
+	}
+	return ""
+}
+
+// RequestJournal keeps the details of the most recent requests in memory
+type RequestJournal struct {
+	mu      sync.RWMutex
+	max     int
+	order   []string
+	next    int
+	details map[string]*RequestDetail
+}
+
+// NewRequestJournal creates a journal holding up to max requests
+func NewRequestJournal(max int) *RequestJournal {
+	if max <= 0 {
+		max = 1
+	}
+	return &RequestJournal{
+		max:     max,
+		order:   make([]string, 0, max),
+		details: make(map[string]*RequestDetail, max),
+	}
+}
+
+// Add stores a request's detail, evicting the oldest request once the journal is full. A
+// repeated ID replaces the earlier request.
+func (j *RequestJournal) Add(detail *RequestDetail) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, exists := j.details[detail.ID]; exists {
+		j.details[detail.ID] = detail
+		return
+	}
+	if len(j.order) < j.max {
+		j.order = append(j.order, detail.ID)
+	} else {
+		delete(j.details, j.order[j.next])
+		j.order[j.next] = detail.ID
+		j.next = (j.next + 1) % j.max
+	}
+	j.details[detail.ID] = detail
+}
+
+// Get returns a snapshot of a request's detail
+func (j *RequestJournal) Get(id string) (*RequestDetail, bool) {
+	j.mu.RLock()
+	detail, ok := j.details[id]
+	j.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return detail.Snapshot(), true
+}
@@ -1,14 +1,16 @@
 package obs
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
@@ -18,7 +20,7 @@ type RecordMode string
 const (
 	RecordModeAll      RecordMode = "all"      // Record both request and response
 	RecordModeResponse RecordMode = "response" // Record only response
-	RecordModeSlim     RecordMode = "slim"     // TODO: Not implemented yet
+	RecordModeSlim     RecordMode = "slim"     // Record one compact summary line per request
 )
 
 // RecordEntry represents a single recorded request/response pair
@@ -54,10 +56,11 @@ type RecordResponse struct {
 
 // Sink manages recording of HTTP requests/responses to JSONL files
 type Sink struct {
-	mode    RecordMode
-	baseDir string
-	fileMap map[string]*recordFile // provider -> file
-	mutex   sync.RWMutex
+	mode     RecordMode
+	baseDir  string
+	fileMap  map[string]*recordFile // provider -> file
+	redactor *Redactor              // masks secrets and PII before anything is written
+	mutex    sync.RWMutex
 }
 
 // recordFile holds a file handle and its writer
@@ -68,7 +71,7 @@ type recordFile struct {
 }
 
 // NewSink creates a new record sink
-// mode: empty string = disabled, "all" = record all, "response" = response only
+// mode: empty string = disabled, "all" = record all, "response" = response only, "slim" = per-request summary
 func NewSink(baseDir string, mode RecordMode) *Sink {
 	// Empty mode means recording is disabled
 	if mode == "" {
@@ -85,14 +88,6 @@ func NewSink(baseDir string, mode RecordMode) *Sink {
 		}
 	}
 
-	// Check for slim mode (not implemented)
-	if mode == RecordModeSlim {
-		logrus.Warnf("Record mode 'slim' is not implemented yet, please use 'all' or 'response'")
-		return &Sink{
-			mode: "",
-		}
-	}
-
 	// Ensure base directory exists
 	if err := os.MkdirAll(baseDir, 0755); err != nil {
 		logrus.Errorf("Failed to create record directory %s: %v", baseDir, err)
@@ -102,27 +97,70 @@ func NewSink(baseDir string, mode RecordMode) *Sink {
 	}
 
 	return &Sink{
-		mode:    mode,
-		baseDir: baseDir,
-		fileMap: make(map[string]*recordFile),
+		mode:     mode,
+		baseDir:  baseDir,
+		fileMap:  make(map[string]*recordFile),
+		redactor: DefaultRedactor(),
+	}
+}
+
+// SetRedactor replaces the redactor applied to recorded entries
+func (r *Sink) SetRedactor(redactor *Redactor) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.redactor = redactor
+}
+
+// getRedactor returns the current redactor
+func (r *Sink) getRedactor() *Redactor {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.redactor
+}
+
+// redactEntry masks secrets and PII in a record entry before it is written
+func (r *Sink) redactEntry(entry *RecordEntry) {
+	redactor := r.getRedactor()
+	if req := entry.Request; req != nil {
+		entry.Request = &RecordRequest{
+			Method:  req.Method,
+			URL:     redactor.URL(req.URL),
+			Headers: redactor.Headers(req.Headers),
+			Body:    redactor.Map(req.Body),
+		}
+	}
+	if resp := entry.Response; resp != nil {
+		entry.Response = &RecordResponse{
+			StatusCode:      resp.StatusCode,
+			Headers:         redactor.Headers(resp.Headers),
+			Body:            redactor.Map(resp.Body),
+			IsStreaming:     resp.IsStreaming,
+			StreamedContent: redactor.String(resp.StreamedContent),
+		}
 	}
+	entry.Error = redactor.String(entry.Error)
+	entry.Metadata = redactor.Map(entry.Metadata)
 }
 
-// Record records a single request/response pair
-func (r *Sink) Record(provider, model string, req *RecordRequest, resp *RecordResponse, duration time.Duration, err error) {
-	if r.mode == "" {
+// Record records a single request/response pair. An empty requestID gets a new one.
+func (r *Sink) Record(requestID, provider, model string, req *RecordRequest, resp *RecordResponse, duration time.Duration, err error) {
+	if !r.RecordsHTTP() {
 		return
 	}
 
 	entry := &RecordEntry{
 		Timestamp:  time.Now().UTC().Format(time.RFC3339),
-		RequestID:  uuid.New().String(),
+		RequestID:  requestID,
 		Provider:   provider,
 		Model:      model,
 		Response:   resp,
 		DurationMs: duration.Milliseconds(),
 	}
 
+	if entry.RequestID == "" {
+		entry.RequestID = NewRequestID()
+	}
+
 	// Only include request if mode is "all"
 	if r.mode == RecordModeAll {
 		entry.Request = req
@@ -132,18 +170,20 @@ func (r *Sink) Record(provider, model string, req *RecordRequest, resp *RecordRe
 		entry.Error = err.Error()
 	}
 
+	r.redactEntry(entry)
 	r.writeEntry(provider, entry)
 }
 
-// RecordWithMetadata records a request/response with additional metadata
-func (r *Sink) RecordWithMetadata(provider, model string, req *RecordRequest, resp *RecordResponse, duration time.Duration, metadata map[string]interface{}, err error) {
-	if r.mode == "" {
+// RecordWithMetadata records a request/response with additional metadata. An empty requestID gets a
+// new one.
+func (r *Sink) RecordWithMetadata(requestID, provider, model string, req *RecordRequest, resp *RecordResponse, duration time.Duration, metadata map[string]interface{}, err error) {
+	if !r.RecordsHTTP() {
 		return
 	}
 
 	entry := &RecordEntry{
 		Timestamp:  time.Now().UTC().Format(time.RFC3339),
-		RequestID:  uuid.New().String(),
+		RequestID:  requestID,
 		Provider:   provider,
 		Model:      model,
 		Response:   resp,
@@ -151,6 +191,10 @@ func (r *Sink) RecordWithMetadata(provider, model string, req *RecordRequest, re
 		Metadata:   metadata,
 	}
 
+	if entry.RequestID == "" {
+		entry.RequestID = NewRequestID()
+	}
+
 	// Only include request if mode is "all"
 	if r.mode == RecordModeAll {
 		entry.Request = req
@@ -160,11 +204,12 @@ func (r *Sink) RecordWithMetadata(provider, model string, req *RecordRequest, re
 		entry.Error = err.Error()
 	}
 
+	r.redactEntry(entry)
 	r.writeEntry(provider, entry)
 }
 
-// writeEntry writes an entry to the appropriate file
-func (r *Sink) writeEntry(provider string, entry *RecordEntry) {
+// writeEntry writes an entry (RecordEntry or SlimRecord) to the appropriate file
+func (r *Sink) writeEntry(provider string, entry interface{}) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
@@ -201,6 +246,58 @@ func (r *Sink) writeEntry(provider string, entry *RecordEntry) {
 	}
 }
 
+// FindEntries returns the recorded entries of a request from the files of the hours around t,
+// the time the request was made
+func (r *Sink) FindEntries(requestID string, t time.Time) ([]json.RawMessage, error) {
+	if !r.IsEnabled() || requestID == "" {
+		return nil, nil
+	}
+
+	key, _ := json.Marshal(requestID)
+	needle := append([]byte(`"request_id":`), key...)
+
+	var entries []json.RawMessage
+	for _, hour := range []time.Time{t.Add(-time.Hour), t, t.Add(time.Hour)} {
+		pattern := filepath.Join(r.baseDir, "*-"+hour.UTC().Format("2006-01-02-15")+".jsonl")
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return entries, err
+		}
+		for _, name := range files {
+			found, err := findLines(name, needle)
+			if err != nil {
+				return entries, err
+			}
+			entries = append(entries, found...)
+		}
+	}
+	return entries, nil
+}
+
+// findLines returns the JSON lines of a file containing needle
+func findLines(name string, needle []byte) ([]json.RawMessage, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []json.RawMessage
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); bytes.Contains(line, needle) && json.Valid(line) {
+			lines = append(lines, json.RawMessage(line))
+		}
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return lines, err
+		}
+	}
+}
+
 // closeFile closes a record file
 func (r *Sink) closeFile(rf *recordFile) {
 	if rf != nil && rf.file != nil {
@@ -230,6 +327,16 @@ func (r *Sink) IsEnabled() bool {
 	return r.mode != ""
 }
 
+// RecordsHTTP returns whether full upstream HTTP exchanges are recorded ("all" or "response" mode)
+func (r *Sink) RecordsHTTP() bool {
+	return r.mode == RecordModeAll || r.mode == RecordModeResponse
+}
+
+// IsSlim returns whether the sink records per-request summaries
+func (r *Sink) IsSlim() bool {
+	return r.mode == RecordModeSlim
+}
+
 // GetBaseDir returns the base directory for recordings
 func (r *Sink) GetBaseDir() string {
 	return r.baseDir
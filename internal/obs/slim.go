@@ -0,0 +1,284 @@
+package obs
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxSlimText bounds the user text kept in a slim record
+const maxSlimText = 200
+
+// SlimRecord is the compact per-request line written in slim mode
+type SlimRecord struct {
+	Timestamp     string   `json:"timestamp"`
+	RequestID     string   `json:"request_id"`
+	Provider      string   `json:"provider"`
+	Model         string   `json:"model"`
+	RequestModel  string   `json:"request_model,omitempty"`
+	Rule          string   `json:"rule,omitempty"`
+	Scenario      string   `json:"scenario,omitempty"`
+	InputTokens   int      `json:"input_tokens"`
+	OutputTokens  int      `json:"output_tokens"`
+	LatencyMs     int64    `json:"latency_ms"`
+	Status        string   `json:"status"`
+	StatusCode    int      `json:"status_code"`
+	ErrorCode     string   `json:"error_code,omitempty"`
+	Streamed      bool     `json:"streamed,omitempty"`
+	Tools         []string `json:"tools,omitempty"`
+	MessageCount  int      `json:"message_count"`
+	FirstUserText string   `json:"first_user_text,omitempty"`
+	LastUserText  string   `json:"last_user_text,omitempty"`
+	RequestHash   string   `json:"request_hash,omitempty"`
+	ResponseHash  string   `json:"response_hash,omitempty"`
+}
+
+// RecordSlim writes a slim record. It is a no-op unless the sink is in slim mode.
+func (r *Sink) RecordSlim(rec *SlimRecord) {
+	if !r.IsSlim() || rec == nil {
+		return
+	}
+
+	if rec.Timestamp == "" {
+		rec.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	if rec.RequestID == "" {
+		rec.RequestID = NewRequestID()
+	}
+
+	redactor := r.getRedactor()
+	rec.FirstUserText = redactor.String(rec.FirstUserText)
+	rec.LastUserText = redactor.String(rec.LastUserText)
+
+	provider := rec.Provider
+	if provider == "" {
+		provider = "unknown"
+	}
+	r.writeEntry(provider, rec)
+}
+
+// RequestSummary holds what a slim record keeps from a request body
+type RequestSummary struct {
+	Model         string
+	MessageCount  int
+	Tools         []string // tools called earlier in the conversation
+	FirstUserText string
+	LastUserText  string
+	Hash          string
+}
+
+// SummarizeRequest extracts the slim fields from an OpenAI, Anthropic, Gemini or Responses API request body
+func SummarizeRequest(body []byte) RequestSummary {
+	summary := RequestSummary{Hash: HashBody(body)}
+
+	var req map[string]interface{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return summary
+	}
+	summary.Model, _ = req["model"].(string)
+
+	var messages []interface{}
+	switch {
+	case req["messages"] != nil:
+		messages, _ = req["messages"].([]interface{})
+	case req["contents"] != nil:
+		messages, _ = req["contents"].([]interface{})
+	case req["input"] != nil:
+		if text, ok := req["input"].(string); ok {
+			summary.MessageCount = 1
+			summary.FirstUserText = TruncateText(text, maxSlimText)
+			summary.LastUserText = summary.FirstUserText
+			return summary
+		}
+		messages, _ = req["input"].([]interface{})
+	}
+
+	tools := newNameSet()
+	var userTexts []string
+	for _, m := range messages {
+		msg, ok := m.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		summary.MessageCount++
+
+		role, _ := msg["role"].(string)
+		collectToolNames(msg, tools)
+		if role != "user" {
+			continue
+		}
+		if text := messageText(msg); text != "" {
+			userTexts = append(userTexts, text)
+		}
+	}
+
+	summary.Tools = tools.names
+	if len(userTexts) > 0 {
+		summary.FirstUserText = TruncateText(userTexts[0], maxSlimText)
+		summary.LastUserText = TruncateText(userTexts[len(userTexts)-1], maxSlimText)
+	}
+	return summary
+}
+
+// ToolCallNames returns the sorted names of the tools called in a response body, which may be
+// a JSON document or an SSE stream
+func ToolCallNames(body []byte) []string {
+	tools := newNameSet()
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var doc interface{}
+		if err := json.Unmarshal(trimmed, &doc); err == nil {
+			walkToolNames(doc, tools)
+			sort.Strings(tools.names)
+			return tools.names
+		}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := bytes.CutPrefix(bytes.TrimSpace(scanner.Bytes()), []byte("data:"))
+		if !ok {
+			continue
+		}
+		var doc interface{}
+		if err := json.Unmarshal(bytes.TrimSpace(data), &doc); err == nil {
+			walkToolNames(doc, tools)
+		}
+	}
+	sort.Strings(tools.names)
+	return tools.names
+}
+
+// HashBody returns the hex sha256 of a body, or an empty string for an empty body
+func HashBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// TruncateText shortens s to at most max runes, marking the cut with an ellipsis
+func TruncateText(s string, max int) string {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:max]) + "…"
+}
+
+// nameSet keeps unique names in first-seen order
+type nameSet struct {
+	seen  map[string]bool
+	names []string
+}
+
+func newNameSet() *nameSet {
+	return &nameSet{seen: make(map[string]bool)}
+}
+
+func (s *nameSet) add(name string) {
+	if name == "" || s.seen[name] {
+		return
+	}
+	s.seen[name] = true
+	s.names = append(s.names, name)
+}
+
+// messageText concatenates the text parts of a message in any of the supported dialects
+func messageText(msg map[string]interface{}) string {
+	var parts []string
+	appendText := func(v interface{}) {
+		switch c := v.(type) {
+		case string:
+			parts = append(parts, c)
+		case []interface{}:
+			for _, p := range c {
+				part, ok := p.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if text, ok := part["text"].(string); ok {
+					parts = append(parts, text)
+				}
+			}
+		}
+	}
+	appendText(msg["content"])
+	appendText(msg["parts"])
+	return strings.Join(parts, " ")
+}
+
+// collectToolNames adds the names of tool calls made in a request message
+func collectToolNames(msg map[string]interface{}, tools *nameSet) {
+	// OpenAI chat: assistant tool_calls
+	if calls, ok := msg["tool_calls"].([]interface{}); ok {
+		for _, c := range calls {
+			if call, ok := c.(map[string]interface{}); ok {
+				if fn, ok := call["function"].(map[string]interface{}); ok {
+					name, _ := fn["name"].(string)
+					tools.add(name)
+				}
+			}
+		}
+	}
+	// Responses API: function_call input items
+	if msg["type"] == "function_call" {
+		name, _ := msg["name"].(string)
+		tools.add(name)
+	}
+	// Anthropic tool_use blocks and Gemini functionCall parts
+	for _, key := range []string{"content", "parts"} {
+		blocks, ok := msg[key].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, b := range blocks {
+			block, ok := b.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if block["type"] == "tool_use" {
+				name, _ := block["name"].(string)
+				tools.add(name)
+			}
+			if fc, ok := block["functionCall"].(map[string]interface{}); ok {
+				name, _ := fc["name"].(string)
+				tools.add(name)
+			}
+		}
+	}
+}
+
+// walkToolNames finds tool call names anywhere in a decoded response document
+func walkToolNames(v interface{}, tools *nameSet) {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		if t, _ := node["type"].(string); t == "tool_use" || t == "function_call" {
+			name, _ := node["name"].(string)
+			tools.add(name)
+		}
+		for _, key := range []string{"function", "functionCall"} {
+			if fn, ok := node[key].(map[string]interface{}); ok {
+				name, _ := fn["name"].(string)
+				tools.add(name)
+			}
+		}
+		for _, child := range node {
+			walkToolNames(child, tools)
+		}
+	case []interface{}:
+		for _, child := range node {
+			walkToolNames(child, tools)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package obs
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation scope of the proxy's spans
+const TracerName = "github.com/tingly-dev/tingly-box"
+
+// DefaultTracingServiceName is the service.name reported when TracingConfig leaves it empty
+const DefaultTracingServiceName = "tingly-box"
+
+// GenAI semantic convention attributes (https://opentelemetry.io/docs/specs/semconv/gen-ai/)
+const (
+	AttrGenAIOperationName     = attribute.Key("gen_ai.operation.name")
+	AttrGenAISystem            = attribute.Key("gen_ai.system")
+	AttrGenAIRequestModel      = attribute.Key("gen_ai.request.model")
+	AttrGenAIResponseModel     = attribute.Key("gen_ai.response.model")
+	AttrGenAIUsageInputTokens  = attribute.Key("gen_ai.usage.input_tokens")
+	AttrGenAIUsageOutputTokens = attribute.Key("gen_ai.usage.output_tokens")
+)
+
+// Proxy-specific span attributes
+const (
+	AttrRequestID       = attribute.Key("tingly.request_id")
+	AttrScenario        = attribute.Key("tingly.scenario")
+	AttrRule            = attribute.Key("tingly.rule")
+	AttrProvider        = attribute.Key("tingly.provider")
+	AttrDialect         = attribute.Key("tingly.dialect")
+	AttrTranslationFrom = attribute.Key("tingly.translation.from")
+	AttrTranslationTo   = attribute.Key("tingly.translation.to")
+)
+
+// TracingConfig configures span export over OTLP/HTTP. Tracing stays off without an endpoint,
+// though incoming traceparent headers are still passed on to providers.
+type TracingConfig struct {
+	Endpoint    string            `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`         // Collector URL, e.g. http://localhost:4318 (/v1/traces is used when the URL has no path)
+	Headers     map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`           // Extra headers sent to the collector, e.g. authentication
+	SampleRatio float64           `json:"sample_ratio,omitempty" yaml:"sample_ratio,omitempty"` // Fraction of new traces to sample (default 1); sampled parents are always followed
+	ServiceName string            `json:"service_name,omitempty" yaml:"service_name,omitempty"` // service.name resource attribute (default tingly-box)
+}
+
+// Tracer returns the proxy's tracer from the global tracer provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// InitTracing installs the W3C trace context propagator and, when an endpoint is configured, a
+// global tracer provider exporting to it. The returned function flushes and stops the exporter.
+func InitTracing(ctx context.Context, cfg TracingConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpointURL(cfg.Endpoint)}
+	if u, err := url.Parse(cfg.Endpoint); err == nil && strings.Trim(u.Path, "/") == "" {
+		options = append(options, otlptracehttp.WithURLPath("/v1/traces"))
+	}
+	if len(cfg.Headers) > 0 {
+		options = append(options, otlptracehttp.WithHeaders(cfg.Headers))
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := NewTracerProvider(cfg, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider builds a tracer provider with the configured service name and sampling. Tests
+// pass an in-process exporter, e.g. sdktrace.WithSyncer(tracetest.NewInMemoryExporter()).
+func NewTracerProvider(cfg TracingConfig, options ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = DefaultTracingServiceName
+	}
+	ratio := cfg.SampleRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+
+	options = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	}, options...)
+	return sdktrace.NewTracerProvider(options...)
+}
+
+// StartSpan starts an internal span under the span in ctx
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan ends a span, marking it failed when err is set
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,427 @@
+// Package conformance replays recorded upstream responses through the protocol translators
+// and compares the client-dialect output with golden files.
+//
+// A case lives under testdata/<translation>/ and is made of:
+//
+//	<name>.upstream.json | <name>.upstream.sse   the upstream response body or SSE transcript
+//	<name>.request.json                          optional client request, for translators that take one
+//	<name>.golden.json   | <name>.golden.sse     the expected client-dialect output
+//
+// The upstream file extension selects the non-streaming converter or the stream handler.
+//
+// Request translations (testdata/request_<client>_to_<upstream>/) convert a client request into
+// the upstream dialect instead: a case is <name>.request.json and the <name>.golden.json upstream
+// request it should become.
+package conformance
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	anthropicstream "github.com/anthropics/anthropic-sdk-go/packages/ssestream"
+	"github.com/gin-gonic/gin"
+	"github.com/openai/openai-go/v3"
+	openaistream "github.com/openai/openai-go/v3/packages/ssestream"
+	"github.com/openai/openai-go/v3/responses"
+	"google.golang.org/genai"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol/nonstream"
+	"github.com/tingly-dev/tingly-box/internal/protocol/request"
+	"github.com/tingly-dev/tingly-box/internal/protocol/stream"
+)
+
+// Translation names an upstream dialect -> client dialect pair
+type Translation string
+
+const (
+	OpenAIToAnthropic        Translation = "openai_to_anthropic"
+	OpenAIToAnthropicBeta    Translation = "openai_to_anthropic_beta"
+	OpenAIToGoogle           Translation = "openai_to_google"
+	ResponsesToAnthropic     Translation = "responses_to_anthropic"
+	ResponsesToAnthropicBeta Translation = "responses_to_anthropic_beta"
+	ResponsesToGoogle        Translation = "responses_to_google"
+	AnthropicToOpenAI        Translation = "anthropic_to_openai"
+	AnthropicToGoogle        Translation = "anthropic_to_google"
+	GoogleToOpenAI           Translation = "google_to_openai"
+	GoogleToAnthropic        Translation = "google_to_anthropic"
+	GoogleToAnthropicBeta    Translation = "google_to_anthropic_beta"
+	conformanceResponseModel             = "conformance-model"
+)
+
+// Request translations name a client dialect -> upstream dialect request conversion
+const (
+	RequestAnthropicToOpenAI    Translation = "request_anthropic_to_openai"
+	RequestAnthropicToGoogle    Translation = "request_anthropic_to_google"
+	RequestAnthropicToResponses Translation = "request_anthropic_to_responses"
+	RequestOpenAIToAnthropic    Translation = "request_openai_to_anthropic"
+	RequestOpenAIToGoogle       Translation = "request_openai_to_google"
+	RequestGoogleToOpenAI       Translation = "request_google_to_openai"
+	RequestGoogleToAnthropic    Translation = "request_google_to_anthropic"
+	RequestGoogleToResponses    Translation = "request_google_to_responses"
+
+	// conformanceMaxTokens is the default max_tokens of request conversions that need one
+	conformanceMaxTokens = 4096
+)
+
+// Translations lists every translation pair the harness can replay
+var Translations = []Translation{
+	OpenAIToAnthropic, OpenAIToAnthropicBeta, OpenAIToGoogle,
+	ResponsesToAnthropic, ResponsesToAnthropicBeta, ResponsesToGoogle,
+	AnthropicToOpenAI, AnthropicToGoogle, GoogleToOpenAI, GoogleToAnthropic, GoogleToAnthropicBeta,
+}
+
+// RequestTranslations lists every request conversion the harness can replay
+var RequestTranslations = []Translation{
+	RequestAnthropicToOpenAI, RequestAnthropicToGoogle, RequestAnthropicToResponses,
+	RequestOpenAIToAnthropic, RequestOpenAIToGoogle,
+	RequestGoogleToOpenAI, RequestGoogleToAnthropic, RequestGoogleToResponses,
+}
+
+// IsRequest reports whether the translation converts client requests rather than upstream responses
+func (t Translation) IsRequest() bool {
+	return strings.HasPrefix(string(t), "request_")
+}
+
+// googleRequest is the client request of a Google request translation case
+type googleRequest struct {
+	Model    string                       `json:"model"`
+	Contents []*genai.Content             `json:"contents"`
+	Config   *genai.GenerateContentConfig `json:"config,omitempty"`
+}
+
+// Case is one recorded upstream response and its expected translation
+type Case struct {
+	Name        string
+	Translation Translation
+	Stream      bool
+	Request     []byte // client request JSON, nil when the case has none
+	Upstream    []byte
+	GoldenPath  string
+}
+
+// LoadCases reads every case under dir, sorted by translation and name
+func LoadCases(dir string) ([]Case, error) {
+	var cases []Case
+	for _, translation := range Translations {
+		matches, err := filepath.Glob(filepath.Join(dir, string(translation), "*.upstream.*"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		for _, upstreamPath := range matches {
+			base := filepath.Base(upstreamPath)
+			name := strings.TrimSuffix(base, filepath.Ext(base))
+			name = strings.TrimSuffix(name, ".upstream")
+			isStream := filepath.Ext(base) == ".sse"
+
+			upstream, err := os.ReadFile(upstreamPath)
+			if err != nil {
+				return nil, err
+			}
+			tc := Case{
+				Name:        name,
+				Translation: translation,
+				Stream:      isStream,
+				Upstream:    upstream,
+				GoldenPath:  filepath.Join(dir, string(translation), name+".golden"+goldenExt(isStream)),
+			}
+			requestPath := filepath.Join(dir, string(translation), name+".request.json")
+			if req, err := os.ReadFile(requestPath); err == nil {
+				tc.Request = req
+			} else if !os.IsNotExist(err) {
+				return nil, err
+			}
+			cases = append(cases, tc)
+		}
+	}
+
+	for _, translation := range RequestTranslations {
+		matches, err := filepath.Glob(filepath.Join(dir, string(translation), "*.request.json"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		for _, requestPath := range matches {
+			name := strings.TrimSuffix(filepath.Base(requestPath), ".request.json")
+			req, err := os.ReadFile(requestPath)
+			if err != nil {
+				return nil, err
+			}
+			cases = append(cases, Case{
+				Name:        name,
+				Translation: translation,
+				Request:     req,
+				GoldenPath:  filepath.Join(dir, string(translation), name+".golden.json"),
+			})
+		}
+	}
+	return cases, nil
+}
+
+func goldenExt(isStream bool) string {
+	if isStream {
+		return ".sse"
+	}
+	return ".json"
+}
+
+// Run replays the case through its translator and returns the normalized client output
+func Run(tc Case) ([]byte, error) {
+	if tc.Translation.IsRequest() {
+		output, err := runRequest(tc)
+		if err != nil {
+			return nil, err
+		}
+		// Values copied from the client request are kept, as upstream values are for responses
+		return Normalize(output, tc.Request, false), nil
+	}
+
+	var (
+		output []byte
+		err    error
+	)
+	if tc.Stream {
+		output, err = runStream(tc)
+	} else {
+		output, err = runNonStream(tc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Normalize(output, tc.Upstream, tc.Stream), nil
+}
+
+func runNonStream(tc Case) ([]byte, error) {
+	var result interface{}
+	switch tc.Translation {
+	case OpenAIToAnthropic, OpenAIToAnthropicBeta, OpenAIToGoogle:
+		var resp openai.ChatCompletion
+		if err := json.Unmarshal(tc.Upstream, &resp); err != nil {
+			return nil, fmt.Errorf("decode upstream: %w", err)
+		}
+		switch tc.Translation {
+		case OpenAIToAnthropic:
+			result = nonstream.ConvertOpenAIToAnthropicResponse(&resp, conformanceResponseModel)
+		case OpenAIToAnthropicBeta:
+			result = nonstream.ConvertOpenAIToAnthropicBetaResponse(&resp, conformanceResponseModel)
+		default:
+			result = nonstream.ConvertOpenAIToGoogleResponse(&resp)
+		}
+	case ResponsesToAnthropic, ResponsesToAnthropicBeta, ResponsesToGoogle:
+		var resp responses.Response
+		if err := json.Unmarshal(tc.Upstream, &resp); err != nil {
+			return nil, fmt.Errorf("decode upstream: %w", err)
+		}
+		switch tc.Translation {
+		case ResponsesToAnthropic:
+			result = nonstream.ConvertResponsesToAnthropicResponse(&resp, conformanceResponseModel)
+		case ResponsesToAnthropicBeta:
+			result = nonstream.ConvertResponsesToAnthropicBetaResponse(&resp, conformanceResponseModel)
+		default:
+			result = nonstream.ConvertResponsesToGoogleResponse(&resp)
+		}
+	case AnthropicToOpenAI, AnthropicToGoogle:
+		var resp anthropic.Message
+		if err := json.Unmarshal(tc.Upstream, &resp); err != nil {
+			return nil, fmt.Errorf("decode upstream: %w", err)
+		}
+		if tc.Translation == AnthropicToOpenAI {
+			result = nonstream.ConvertAnthropicToOpenAIResponse(&resp, conformanceResponseModel)
+		} else {
+			result = nonstream.ConvertAnthropicToGoogleResponse(&resp)
+		}
+	case GoogleToOpenAI, GoogleToAnthropic, GoogleToAnthropicBeta:
+		var resp genai.GenerateContentResponse
+		if err := json.Unmarshal(tc.Upstream, &resp); err != nil {
+			return nil, fmt.Errorf("decode upstream: %w", err)
+		}
+		switch tc.Translation {
+		case GoogleToOpenAI:
+			result = nonstream.ConvertGoogleToOpenAIResponse(&resp, conformanceResponseModel)
+		case GoogleToAnthropic:
+			result = nonstream.ConvertGoogleToAnthropicResponse(&resp, conformanceResponseModel)
+		default:
+			result = nonstream.ConvertGoogleToAnthropicBetaResponse(&resp, conformanceResponseModel)
+		}
+	default:
+		return nil, fmt.Errorf("unknown translation %q", tc.Translation)
+	}
+	return json.Marshal(result)
+}
+
+func runRequest(tc Case) ([]byte, error) {
+	var result interface{}
+	switch tc.Translation {
+	case RequestAnthropicToOpenAI, RequestAnthropicToGoogle, RequestAnthropicToResponses:
+		var req anthropic.MessageNewParams
+		if err := json.Unmarshal(tc.Request, &req); err != nil {
+			return nil, fmt.Errorf("decode request: %w", err)
+		}
+		switch tc.Translation {
+		case RequestAnthropicToOpenAI:
+			result, _ = request.ConvertAnthropicToOpenAIRequest(&req, true)
+		case RequestAnthropicToGoogle:
+			model, contents, config := request.ConvertAnthropicToGoogleRequest(&req, conformanceMaxTokens)
+			result = googleRequest{Model: model, Contents: contents, Config: config}
+		default:
+			result = request.ConvertAnthropicToResponsesRequest(&req)
+		}
+	case RequestOpenAIToAnthropic, RequestOpenAIToGoogle:
+		var req openai.ChatCompletionNewParams
+		if err := json.Unmarshal(tc.Request, &req); err != nil {
+			return nil, fmt.Errorf("decode request: %w", err)
+		}
+		if tc.Translation == RequestOpenAIToAnthropic {
+			result = request.ConvertOpenAIToAnthropicRequest(&req, conformanceMaxTokens)
+		} else {
+			model, contents, config := request.ConvertOpenAIToGoogleRequest(&req, conformanceMaxTokens)
+			result = googleRequest{Model: model, Contents: contents, Config: config}
+		}
+	case RequestGoogleToOpenAI, RequestGoogleToAnthropic, RequestGoogleToResponses:
+		var req googleRequest
+		if err := json.Unmarshal(tc.Request, &req); err != nil {
+			return nil, fmt.Errorf("decode request: %w", err)
+		}
+		switch tc.Translation {
+		case RequestGoogleToOpenAI:
+			result = request.ConvertGoogleToOpenAIRequest(req.Model, req.Contents, req.Config)
+		case RequestGoogleToAnthropic:
+			result = request.ConvertGoogleToAnthropicRequest(req.Model, req.Contents, req.Config)
+		default:
+			result = request.ConvertGoogleToResponsesRequest(req.Model, req.Contents, req.Config)
+		}
+	default:
+		return nil, fmt.Errorf("unknown translation %q", tc.Translation)
+	}
+	return json.Marshal(result)
+}
+
+func runStream(tc Case) ([]byte, error) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+
+	var err error
+	switch tc.Translation {
+	case OpenAIToAnthropic, OpenAIToAnthropicBeta, OpenAIToGoogle:
+		upstream := openaistream.NewStream[openai.ChatCompletionChunk](openaistream.NewDecoder(sseResponse(tc.Upstream)), nil)
+		var req *openai.ChatCompletionNewParams
+		if tc.Request != nil {
+			req = &openai.ChatCompletionNewParams{}
+			if err := json.Unmarshal(tc.Request, req); err != nil {
+				return nil, fmt.Errorf("decode request: %w", err)
+			}
+		}
+		switch tc.Translation {
+		case OpenAIToAnthropic:
+			err = stream.HandleOpenAIToAnthropicStreamResponse(c, req, upstream, conformanceResponseModel, nil)
+		case OpenAIToAnthropicBeta:
+			err = stream.HandleOpenAIToAnthropicV1BetaStreamResponse(c, req, upstream, conformanceResponseModel, nil)
+		default:
+			err = stream.HandleOpenAIToGoogleStreamResponse(c, upstream, conformanceResponseModel)
+		}
+	case ResponsesToAnthropic, ResponsesToAnthropicBeta, ResponsesToGoogle:
+		upstream := openaistream.NewStream[responses.ResponseStreamEventUnion](openaistream.NewDecoder(sseResponse(tc.Upstream)), nil)
+		switch tc.Translation {
+		case ResponsesToAnthropic:
+			_, _, err = stream.HandleResponsesToAnthropicStreamResponse(c, upstream, conformanceResponseModel)
+		case ResponsesToAnthropicBeta:
+			_, _, err = stream.HandleResponsesToAnthropicV1BetaStreamResponse(c, upstream, conformanceResponseModel)
+		default:
+			_, _, err = stream.HandleResponsesToGoogleStreamResponse(c, upstream, conformanceResponseModel)
+		}
+	case AnthropicToOpenAI, AnthropicToGoogle:
+		upstream := anthropicstream.NewStream[anthropic.MessageStreamEventUnion](anthropicstream.NewDecoder(sseResponse(tc.Upstream)), nil)
+		if tc.Translation == AnthropicToOpenAI {
+			var req *anthropic.MessageNewParams
+			if tc.Request != nil {
+				req = &anthropic.MessageNewParams{}
+				if err := json.Unmarshal(tc.Request, req); err != nil {
+					return nil, fmt.Errorf("decode request: %w", err)
+				}
+			}
+			_, _, err = stream.HandleAnthropicToOpenAIStreamResponse(c, req, upstream, conformanceResponseModel)
+		} else {
+			err = stream.HandleAnthropicToGoogleStreamResponse(c, upstream, conformanceResponseModel)
+		}
+	case GoogleToOpenAI, GoogleToAnthropic, GoogleToAnthropicBeta:
+		upstream := googleStream(tc.Upstream)
+		switch tc.Translation {
+		case GoogleToOpenAI:
+			err = stream.HandleGoogleToOpenAIStreamResponse(c, upstream, conformanceResponseModel)
+		case GoogleToAnthropic:
+			err = stream.HandleGoogleToAnthropicStreamResponse(c, upstream, conformanceResponseModel)
+		default:
+			err = stream.HandleGoogleToAnthropicBetaStreamResponse(c, upstream, conformanceResponseModel)
+		}
+	default:
+		return nil, fmt.Errorf("unknown translation %q", tc.Translation)
+	}
+
+	// Upstream errors are part of the transcript: the handlers report them to the client as
+	// error events, which the golden file captures
+	_ = err
+	return w.Body.Bytes(), nil
+}
+
+// sseResponse wraps an SSE transcript in an HTTP response for the SDK stream decoders
+func sseResponse(transcript []byte) *http.Response {
+	header := http.Header{}
+	header.Set("Content-Type", "text/event-stream")
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader(transcript)),
+	}
+}
+
+// googleStream replays a Gemini streamGenerateContent SSE transcript. A data line carrying an
+// "error" object ends the stream with that error, as the genai client does.
+func googleStream(transcript []byte) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		scanner := bufio.NewScanner(bytes.NewReader(transcript))
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			data, ok := sseData(scanner.Text())
+			if !ok {
+				continue
+			}
+			var payload struct {
+				Error *genai.APIError `json:"error"`
+			}
+			if json.Unmarshal([]byte(data), &payload) == nil && payload.Error != nil {
+				yield(nil, *payload.Error)
+				return
+			}
+			var resp genai.GenerateContentResponse
+			if err := json.Unmarshal([]byte(data), &resp); err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(&resp, nil) {
+				return
+			}
+		}
+	}
+}
+
+// sseData returns the payload of an SSE data line
+func sseData(line string) (string, bool) {
+	data, ok := strings.CutPrefix(line, "data:")
+	if !ok {
+		return "", false
+	}
+	return strings.TrimPrefix(data, " "), true
+}
@@ -0,0 +1,79 @@
+package conformance
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	update      = flag.Bool("update", false, "rewrite golden files from the current translator output")
+	importPath  = flag.String("import", "", "obs.Sink recording (JSONL) to import as conformance cases")
+	translation = flag.String("translation", "", "translation pair of the imported recording, e.g. openai_to_anthropic")
+)
+
+const testdataDir = "testdata"
+
+// TestConformance replays every recorded upstream response through its translator and
+// compares the output with the golden file. Regenerate goldens with
+//
+//	go test ./internal/protocol/conformance -update
+//
+// and import new cases from a recording with
+//
+//	go test ./internal/protocol/conformance -import record.jsonl -translation openai_to_anthropic -update
+func TestConformance(t *testing.T) {
+	if *importPath != "" {
+		require.NotEmpty(t, *translation, "-import needs -translation")
+		names, err := ImportRecording(*importPath, Translation(*translation), testdataDir)
+		require.NoError(t, err)
+		t.Logf("imported %d cases: %v", len(names), names)
+	}
+
+	cases, err := LoadCases(testdataDir)
+	require.NoError(t, err)
+	require.NotEmpty(t, cases)
+
+	for _, tc := range cases {
+		t.Run(string(tc.Translation)+"/"+tc.Name, func(t *testing.T) {
+			got, err := Run(tc)
+			require.NoError(t, err)
+
+			if *update {
+				require.NoError(t, os.WriteFile(tc.GoldenPath, got, 0644))
+				return
+			}
+			want, err := os.ReadFile(tc.GoldenPath)
+			require.NoError(t, err, "missing golden file, run with -update to create it")
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+// TestConformanceCoverage keeps every translation pair covered in both modes
+func TestConformanceCoverage(t *testing.T) {
+	cases, err := LoadCases(testdataDir)
+	require.NoError(t, err)
+
+	covered := make(map[Translation]map[bool]bool)
+	named := make(map[Translation]map[string]bool)
+	for _, tc := range cases {
+		if covered[tc.Translation] == nil {
+			covered[tc.Translation] = make(map[bool]bool)
+			named[tc.Translation] = make(map[string]bool)
+		}
+		covered[tc.Translation][tc.Stream] = true
+		named[tc.Translation][tc.Name] = true
+	}
+	for _, tr := range Translations {
+		assert.True(t, covered[tr][false], "%s has no non-streaming case", tr)
+		assert.True(t, covered[tr][true], "%s has no streaming case", tr)
+	}
+	for _, tr := range RequestTranslations {
+		assert.True(t, named[tr]["text"], "%s has no text case", tr)
+		assert.True(t, named[tr]["image"], "%s has no image case", tr)
+	}
+}
@@ -0,0 +1,112 @@
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// volatileKeys are fields the translators fill with generated values (IDs derived from the
+// clock, timestamps, synthetic signatures). Normalize replaces them unless the value was
+// copied from the upstream response.
+var volatileKeys = map[string]bool{
+	"id":               true,
+	"created":          true,
+	"signature":        true,
+	"thoughtSignature": true,
+	"responseId":       true,
+	"createTime":       true,
+}
+
+const generatedPlaceholder = "<generated>"
+
+// Normalize makes translator output comparable across runs: JSON is re-encoded with sorted
+// keys and generated values are masked. SSE output keeps its framing; only the JSON payload
+// of each data line is normalized.
+func Normalize(output, upstream []byte, isStream bool) []byte {
+	if !isStream {
+		return normalizeJSON(output, upstream, true)
+	}
+
+	var out bytes.Buffer
+	for _, line := range strings.SplitAfter(string(output), "\n") {
+		content := strings.TrimRight(line, "\r\n")
+		prefix := ""
+		payload := ""
+		switch {
+		case strings.HasPrefix(content, "data: "):
+			prefix, payload = "data: ", content[len("data: "):]
+		case strings.HasPrefix(content, "data:"):
+			prefix, payload = "data:", content[len("data:"):]
+		}
+		if prefix == "" || !json.Valid([]byte(payload)) {
+			out.WriteString(line)
+			continue
+		}
+		out.WriteString(prefix)
+		out.Write(normalizeJSON([]byte(payload), upstream, false))
+		out.WriteString(line[len(content):])
+	}
+	return out.Bytes()
+}
+
+func normalizeJSON(raw, upstream []byte, indent bool) []byte {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return raw
+	}
+	value = maskVolatile(value, upstream)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if indent {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(value); err != nil {
+		return raw
+	}
+	if !indent {
+		return bytes.TrimRight(buf.Bytes(), "\n")
+	}
+	return buf.Bytes()
+}
+
+func maskVolatile(value interface{}, upstream []byte) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, v := range typed {
+			if volatileKeys[key] && isGenerated(v) && !fromUpstream(v, upstream) {
+				typed[key] = generatedPlaceholder
+				continue
+			}
+			typed[key] = maskVolatile(v, upstream)
+		}
+	case []interface{}:
+		for i, v := range typed {
+			typed[i] = maskVolatile(v, upstream)
+		}
+	}
+	return value
+}
+
+// isGenerated reports whether value looks like a generated scalar; empty and zero values are kept
+func isGenerated(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return v != ""
+	case float64:
+		return v != 0
+	}
+	return false
+}
+
+// fromUpstream reports whether a value was copied from the upstream response rather than generated
+func fromUpstream(value interface{}, upstream []byte) bool {
+	s := fmt.Sprint(value)
+	if f, ok := value.(float64); ok {
+		s = fmt.Sprintf("%.0f", f)
+	}
+	return bytes.Contains(upstream, []byte(s))
+}
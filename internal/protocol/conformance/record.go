@@ -0,0 +1,78 @@
+package conformance
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/tingly-dev/tingly-box/internal/obs"
+)
+
+var unsafeNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// ImportRecording turns the entries of an obs.Sink recording (one JSONL file written in "all"
+// or "response" mode) into conformance cases for translation under dir. Golden files are not
+// written; run the suite with -update to generate them from the current translators, then
+// review the diff. It returns the names of the imported cases.
+func ImportRecording(recordPath string, translation Translation, dir string) ([]string, error) {
+	file, err := os.Open(recordPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	caseDir := filepath.Join(dir, string(translation))
+	if err := os.MkdirAll(caseDir, 0755); err != nil {
+		return nil, err
+	}
+
+	prefix := strings.TrimSuffix(filepath.Base(recordPath), filepath.Ext(recordPath))
+	var names []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var entry obs.RecordEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return names, fmt.Errorf("%s:%d: %w", recordPath, line, err)
+		}
+		if entry.Response == nil {
+			continue
+		}
+
+		name := unsafeNameChars.ReplaceAllString(fmt.Sprintf("%s-%d", prefix, line), "_")
+		var upstreamFile string
+		var upstream []byte
+		switch {
+		case entry.Response.IsStreaming && entry.Response.StreamedContent != "":
+			upstreamFile = name + ".upstream.sse"
+			upstream = []byte(entry.Response.StreamedContent)
+		case entry.Response.Body != nil:
+			upstreamFile = name + ".upstream.json"
+			if upstream, err = json.MarshalIndent(entry.Response.Body, "", "  "); err != nil {
+				return names, err
+			}
+		default:
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(caseDir, upstreamFile), upstream, 0644); err != nil {
+			return names, err
+		}
+
+		// The recorded request is the one sent upstream, which is what the translators take
+		if entry.Request != nil && entry.Request.Body != nil {
+			req, err := json.MarshalIndent(entry.Request.Body, "", "  ")
+			if err != nil {
+				return names, err
+			}
+			if err := os.WriteFile(filepath.Join(caseDir, name+".request.json"), req, 0644); err != nil {
+				return names, err
+			}
+		}
+		names = append(names, name)
+	}
+	return names, scanner.Err()
+}
@@ -0,0 +1,456 @@
+package protocol
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go/v3"
+	"google.golang.org/genai"
+)
+
+// ErrorKind classifies an upstream error independently of the dialect it was reported in
+type ErrorKind string
+
+const (
+	ErrorKindInvalidRequest  ErrorKind = "invalid_request"
+	ErrorKindAuthentication  ErrorKind = "authentication"
+	ErrorKindPermission      ErrorKind = "permission"
+	ErrorKindNotFound        ErrorKind = "not_found"
+	ErrorKindRequestTooLarge ErrorKind = "request_too_large"
+	ErrorKindRateLimit       ErrorKind = "rate_limit"
+	ErrorKindOverloaded      ErrorKind = "overloaded"
+	ErrorKindTimeout         ErrorKind = "timeout"
+	ErrorKindAPI             ErrorKind = "api_error"
+)
+
+// streamErrorPrefix is how the OpenAI and Anthropic SDKs wrap SSE error events
+const streamErrorPrefix = "received error while streaming: "
+
+// UpstreamError is a provider error parsed from any dialect. It keeps enough of the original
+// to be rendered again in the client's dialect with a status code and type its SDK retries on.
+type UpstreamError struct {
+	Kind       ErrorKind
+	StatusCode int
+	Message    string
+	// Type and Code are the provider's own values, reused when the client speaks the same dialect
+	Type       string
+	Code       string
+	Source     APIStyle
+	RetryAfter time.Duration
+}
+
+func (e *UpstreamError) Error() string {
+	if e.StatusCode > 0 {
+		return fmt.Sprintf("%s (status %d): %s", e.Kind, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Kind, e.Message)
+}
+
+// ParseUpstreamError extracts an UpstreamError from an error returned by a provider SDK, including
+// errors raised by SSE error events in the middle of a stream. Errors that did not come from a
+// provider are reported as api_error.
+func ParseUpstreamError(err error) *UpstreamError {
+	if err == nil {
+		return nil
+	}
+
+	var upstreamErr *UpstreamError
+	if errors.As(err, &upstreamErr) {
+		return upstreamErr
+	}
+
+	var openaiErr *openai.Error
+	if errors.As(err, &openaiErr) {
+		parsed := parseSDKError(APIStyleOpenAI, openaiErr.StatusCode, openaiErr.Response, openaiErr.RawJSON())
+		if parsed.Message == "" {
+			parsed.Message = openaiErr.Message
+		}
+		if parsed.Type == "" && parsed.Code == "" {
+			parsed.Type, parsed.Code = openaiErr.Type, openaiErr.Code
+			parsed.Kind = classifyError(parsed.StatusCode, openaiErr.Type, openaiErr.Code)
+		}
+		return parsed
+	}
+
+	var anthropicErr *anthropic.Error
+	if errors.As(err, &anthropicErr) {
+		return parseSDKError(APIStyleAnthropic, anthropicErr.StatusCode, anthropicErr.Response, anthropicErr.RawJSON())
+	}
+
+	var googleErr genai.APIError
+	if errors.As(err, &googleErr) {
+		return fromGoogleError(googleErr)
+	}
+	var googleErrPtr *genai.APIError
+	if errors.As(err, &googleErrPtr) && googleErrPtr != nil {
+		return fromGoogleError(*googleErrPtr)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &UpstreamError{Kind: ErrorKindTimeout, Message: err.Error()}
+	}
+
+	msg := err.Error()
+	if idx := strings.Index(msg, streamErrorPrefix); idx >= 0 {
+		payload := msg[idx+len(streamErrorPrefix):]
+		if parsed := ParseErrorBody(0, nil, []byte(payload)); parsed.Source != "" {
+			return parsed
+		}
+	}
+	return &UpstreamError{Kind: ErrorKindAPI, Message: msg}
+}
+
+// ParseErrorBody parses a provider error body of any dialect. Both full bodies and the bare
+// inner error object are accepted; status and header may be empty when the error arrived as an
+// SSE event. Source is left empty when the body is not a recognizable error.
+func ParseErrorBody(status int, header http.Header, body []byte) *UpstreamError {
+	result := &UpstreamError{StatusCode: status}
+
+	var envelope struct {
+		Type  string          `json:"type"`
+		Error json.RawMessage `json:"error"`
+	}
+	inner := body
+	if json.Unmarshal(body, &envelope) == nil && len(envelope.Error) > 0 && envelope.Error[0] == '{' {
+		inner = envelope.Error
+		if envelope.Type == "error" {
+			result.Source = APIStyleAnthropic
+		}
+	}
+
+	var detail struct {
+		Type    string           `json:"type"`
+		Message string           `json:"message"`
+		Code    json.RawMessage  `json:"code"`
+		Status  string           `json:"status"`
+		Details []map[string]any `json:"details"`
+	}
+	if json.Unmarshal(inner, &detail) != nil {
+		result.Kind = classifyError(status, "", "")
+		result.Message = strings.TrimSpace(string(body))
+		return result
+	}
+
+	result.Message = detail.Message
+	result.Type = detail.Type
+	var numericCode int
+	if json.Unmarshal(detail.Code, &numericCode) == nil && detail.Status != "" {
+		// Gemini: {"code": 429, "message": ..., "status": "RESOURCE_EXHAUSTED"}
+		result.Source = APIStyleGoogle
+		result.Type = detail.Status
+		if result.StatusCode == 0 {
+			result.StatusCode = numericCode
+		}
+		result.RetryAfter = googleRetryDelay(detail.Details)
+	} else {
+		var code string
+		_ = json.Unmarshal(detail.Code, &code)
+		result.Code = code
+		if result.Source == "" && (detail.Type != "" || detail.Message != "") {
+			result.Source = APIStyleOpenAI
+		}
+	}
+
+	result.Kind = classifyError(result.StatusCode, result.Type, result.Code)
+	if retryAfter := parseRetryAfter(header); retryAfter > 0 {
+		result.RetryAfter = retryAfter
+	}
+	return result
+}
+
+// NewUpstreamError builds an UpstreamError from an OpenAI-style error code and message, as carried
+// by Responses API error and response.failed events
+func NewUpstreamError(code, message string) *UpstreamError {
+	return &UpstreamError{
+		Kind:    classifyError(0, code, code),
+		Message: message,
+		Code:    code,
+		Source:  APIStyleOpenAI,
+	}
+}
+
+func parseSDKError(source APIStyle, status int, resp *http.Response, raw string) *UpstreamError {
+	var header http.Header
+	if resp != nil {
+		header = resp.Header
+	}
+	parsed := ParseErrorBody(status, header, []byte(raw))
+	parsed.Source = source
+	return parsed
+}
+
+func fromGoogleError(err genai.APIError) *UpstreamError {
+	return &UpstreamError{
+		Kind:       classifyError(err.Code, err.Status, ""),
+		StatusCode: err.Code,
+		Message:    err.Message,
+		Type:       err.Status,
+		Source:     APIStyleGoogle,
+		RetryAfter: googleRetryDelay(err.Details),
+	}
+}
+
+// errorKindsByType maps provider error types, Gemini statuses and error codes onto kinds
+var errorKindsByType = map[string]ErrorKind{
+	// Anthropic
+	"invalid_request_error": ErrorKindInvalidRequest,
+	"authentication_error":  ErrorKindAuthentication,
+	"permission_error":      ErrorKindPermission,
+	"not_found_error":       ErrorKindNotFound,
+	"request_too_large":     ErrorKindRequestTooLarge,
+	"rate_limit_error":      ErrorKindRateLimit,
+	"overloaded_error":      ErrorKindOverloaded,
+	"timeout_error":         ErrorKindTimeout,
+	"api_error":             ErrorKindAPI,
+	// OpenAI
+	"invalid_api_key":                      ErrorKindAuthentication,
+	"rate_limit_exceeded":                  ErrorKindRateLimit,
+	"insufficient_quota":                   ErrorKindRateLimit,
+	"context_length_exceeded":              ErrorKindInvalidRequest,
+	"model_not_found":                      ErrorKindNotFound,
+	"server_error":                         ErrorKindAPI,
+	"server_is_overloaded":                 ErrorKindOverloaded,
+	"slow_down":                            ErrorKindOverloaded,
+	"timeout":                              ErrorKindTimeout,
+	"invalid_prompt":                       ErrorKindInvalidRequest,
+	"invalid_image":                        ErrorKindInvalidRequest,
+	"invalid_request":                      ErrorKindInvalidRequest,
+	"unsupported_value":                    ErrorKindInvalidRequest,
+	"insufficient_permissions":             ErrorKindPermission,
+	"unsupported_country_region_territory": ErrorKindPermission,
+	// Gemini
+	"INVALID_ARGUMENT":    ErrorKindInvalidRequest,
+	"FAILED_PRECONDITION": ErrorKindInvalidRequest,
+	"OUT_OF_RANGE":        ErrorKindInvalidRequest,
+	"UNAUTHENTICATED":     ErrorKindAuthentication,
+	"PERMISSION_DENIED":   ErrorKindPermission,
+	"NOT_FOUND":           ErrorKindNotFound,
+	"RESOURCE_EXHAUSTED":  ErrorKindRateLimit,
+	"UNAVAILABLE":         ErrorKindOverloaded,
+	"DEADLINE_EXCEEDED":   ErrorKindTimeout,
+	"INTERNAL":            ErrorKindAPI,
+}
+
+// classifyError picks the most specific kind: the code first (OpenAI reports rate_limit_exceeded
+// under a generic type), then a specific type or Gemini status, then the HTTP status, and only
+// then a generic type such as invalid_request_error
+func classifyError(status int, errType, code string) ErrorKind {
+	if kind, ok := errorKindsByType[code]; ok {
+		return kind
+	}
+	typeKind, hasType := errorKindsByType[errType]
+	if hasType && typeKind != ErrorKindInvalidRequest && typeKind != ErrorKindAPI {
+		return typeKind
+	}
+	if kind := errorKindFromStatus(status); kind != "" {
+		return kind
+	}
+	if hasType {
+		return typeKind
+	}
+	return ErrorKindAPI
+}
+
+func errorKindFromStatus(status int) ErrorKind {
+	switch {
+	case status == http.StatusUnauthorized:
+		return ErrorKindAuthentication
+	case status == http.StatusForbidden:
+		return ErrorKindPermission
+	case status == http.StatusNotFound:
+		return ErrorKindNotFound
+	case status == http.StatusRequestEntityTooLarge:
+		return ErrorKindRequestTooLarge
+	case status == http.StatusTooManyRequests:
+		return ErrorKindRateLimit
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return ErrorKindTimeout
+	case status == http.StatusServiceUnavailable || status == 529:
+		return ErrorKindOverloaded
+	case status >= 400 && status < 500:
+		return ErrorKindInvalidRequest
+	case status >= 500:
+		return ErrorKindAPI
+	}
+	return ""
+}
+
+// parseRetryAfter reads retry-after-ms or retry-after (seconds or an HTTP date)
+func parseRetryAfter(header http.Header) time.Duration {
+	if header == nil {
+		return 0
+	}
+	if ms, err := strconv.ParseFloat(header.Get("retry-after-ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+	value := header.Get("retry-after")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// googleRetryDelay reads the retryDelay of a google.rpc.RetryInfo error detail, e.g. "32s"
+func googleRetryDelay(details []map[string]any) time.Duration {
+	for _, detail := range details {
+		if t, _ := detail["@type"].(string); !strings.HasSuffix(t, "google.rpc.RetryInfo") {
+			continue
+		}
+		if delay, ok := detail["retryDelay"].(string); ok {
+			if d, err := time.ParseDuration(delay); err == nil {
+				return d
+			}
+		}
+	}
+	return 0
+}
+
+// errorDialect holds how a kind is rendered in one dialect
+type errorDialect struct {
+	status int
+	typ    string
+	code   string
+}
+
+var anthropicErrorDialect = map[ErrorKind]errorDialect{
+	ErrorKindInvalidRequest:  {http.StatusBadRequest, "invalid_request_error", ""},
+	ErrorKindAuthentication:  {http.StatusUnauthorized, "authentication_error", ""},
+	ErrorKindPermission:      {http.StatusForbidden, "permission_error", ""},
+	ErrorKindNotFound:        {http.StatusNotFound, "not_found_error", ""},
+	ErrorKindRequestTooLarge: {http.StatusRequestEntityTooLarge, "request_too_large", ""},
+	ErrorKindRateLimit:       {http.StatusTooManyRequests, "rate_limit_error", ""},
+	ErrorKindOverloaded:      {529, "overloaded_error", ""},
+	ErrorKindTimeout:         {http.StatusGatewayTimeout, "timeout_error", ""},
+	ErrorKindAPI:             {http.StatusInternalServerError, "api_error", ""},
+}
+
+var openAIErrorDialect = map[ErrorKind]errorDialect{
+	ErrorKindInvalidRequest:  {http.StatusBadRequest, "invalid_request_error", ""},
+	ErrorKindAuthentication:  {http.StatusUnauthorized, "invalid_request_error", "invalid_api_key"},
+	ErrorKindPermission:      {http.StatusForbidden, "invalid_request_error", "insufficient_permissions"},
+	ErrorKindNotFound:        {http.StatusNotFound, "invalid_request_error", "model_not_found"},
+	ErrorKindRequestTooLarge: {http.StatusRequestEntityTooLarge, "invalid_request_error", "request_too_large"},
+	ErrorKindRateLimit:       {http.StatusTooManyRequests, "rate_limit_error", "rate_limit_exceeded"},
+	ErrorKindOverloaded:      {http.StatusServiceUnavailable, "server_error", "server_is_overloaded"},
+	ErrorKindTimeout:         {http.StatusGatewayTimeout, "server_error", "timeout"},
+	ErrorKindAPI:             {http.StatusInternalServerError, "server_error", ""},
+}
+
+var googleErrorDialect = map[ErrorKind]errorDialect{
+	ErrorKindInvalidRequest:  {http.StatusBadRequest, "INVALID_ARGUMENT", ""},
+	ErrorKindAuthentication:  {http.StatusUnauthorized, "UNAUTHENTICATED", ""},
+	ErrorKindPermission:      {http.StatusForbidden, "PERMISSION_DENIED", ""},
+	ErrorKindNotFound:        {http.StatusNotFound, "NOT_FOUND", ""},
+	ErrorKindRequestTooLarge: {http.StatusRequestEntityTooLarge, "INVALID_ARGUMENT", ""},
+	ErrorKindRateLimit:       {http.StatusTooManyRequests, "RESOURCE_EXHAUSTED", ""},
+	ErrorKindOverloaded:      {http.StatusServiceUnavailable, "UNAVAILABLE", ""},
+	ErrorKindTimeout:         {http.StatusGatewayTimeout, "DEADLINE_EXCEEDED", ""},
+	ErrorKindAPI:             {http.StatusInternalServerError, "INTERNAL", ""},
+}
+
+func (e *UpstreamError) dialect(style APIStyle) errorDialect {
+	table := openAIErrorDialect
+	switch style {
+	case APIStyleAnthropic:
+		table = anthropicErrorDialect
+	case APIStyleGoogle:
+		table = googleErrorDialect
+	}
+	d, ok := table[e.Kind]
+	if !ok {
+		d = table[ErrorKindAPI]
+	}
+	// Keep the provider's own type and code when the client speaks its dialect
+	if e.Source == style {
+		if e.Type != "" {
+			d.typ = e.Type
+		}
+		if e.Code != "" {
+			d.code = e.Code
+		}
+	}
+	return d
+}
+
+// HTTPStatus returns the status code to answer a client of the given dialect with. Statuses
+// without a specific meaning (e.g. 402, 422) are passed through as long as they agree with the kind.
+func (e *UpstreamError) HTTPStatus(style APIStyle) int {
+	status := e.dialect(style).status
+	switch e.Kind {
+	case ErrorKindInvalidRequest:
+		if e.StatusCode >= 400 && e.StatusCode < 500 && e.StatusCode != http.StatusTooManyRequests {
+			return e.StatusCode
+		}
+	case ErrorKindAPI:
+		if e.StatusCode >= 500 && e.StatusCode < 600 && e.StatusCode != 529 {
+			return e.StatusCode
+		}
+	case ErrorKindOverloaded:
+		if style == APIStyleAnthropic && e.StatusCode == http.StatusServiceUnavailable {
+			return e.StatusCode
+		}
+	}
+	return status
+}
+
+// Body returns the error body in the given dialect
+func (e *UpstreamError) Body(style APIStyle) map[string]interface{} {
+	d := e.dialect(style)
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.HTTPStatus(style))
+	}
+	switch style {
+	case APIStyleAnthropic:
+		return map[string]interface{}{
+			"type": "error",
+			"error": map[string]interface{}{
+				"type":    d.typ,
+				"message": message,
+			},
+		}
+	case APIStyleGoogle:
+		return map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":    e.HTTPStatus(style),
+				"message": message,
+				"status":  d.typ,
+			},
+		}
+	}
+	detail := map[string]interface{}{
+		"message": message,
+		"type":    d.typ,
+		"param":   nil,
+		"code":    nil,
+	}
+	if d.code != "" {
+		detail["code"] = d.code
+	}
+	return map[string]interface{}{"error": detail}
+}
+
+// RetryAfterHeader returns the retry-after header value in whole seconds, or "" when the
+// provider gave no hint
+func (e *UpstreamError) RetryAfterHeader() string {
+	if e.RetryAfter <= 0 {
+		return ""
+	}
+	seconds := int((e.RetryAfter + time.Second - 1) / time.Second)
+	return strconv.Itoa(seconds)
+}
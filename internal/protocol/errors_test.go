@@ -0,0 +1,147 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+)
+
+func TestParseUpstreamErrorFromSDKs(t *testing.T) {
+	t.Run("anthropic overloaded", func(t *testing.T) {
+		sdkErr := &anthropic.Error{StatusCode: 529, Response: &http.Response{Header: http.Header{}}}
+		require.NoError(t, sdkErr.UnmarshalJSON([]byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)))
+
+		got := ParseUpstreamError(fmt.Errorf("failed to forward: %w", sdkErr))
+		assert.Equal(t, ErrorKindOverloaded, got.Kind)
+		assert.Equal(t, APIStyleAnthropic, got.Source)
+		assert.Equal(t, "Overloaded", got.Message)
+		assert.Equal(t, 529, got.HTTPStatus(APIStyleAnthropic))
+		assert.Equal(t, http.StatusServiceUnavailable, got.HTTPStatus(APIStyleOpenAI))
+		assert.Equal(t, "UNAVAILABLE", got.Body(APIStyleGoogle)["error"].(map[string]interface{})["status"])
+	})
+
+	t.Run("openai rate limit with retry-after", func(t *testing.T) {
+		sdkErr := &openai.Error{StatusCode: 429, Response: &http.Response{Header: http.Header{"Retry-After": []string{"7"}}}}
+		require.NoError(t, sdkErr.UnmarshalJSON([]byte(`{"message":"Rate limit reached","type":"requests","param":null,"code":"rate_limit_exceeded"}`)))
+
+		got := ParseUpstreamError(fmt.Errorf("failed to create chat completion: %w", sdkErr))
+		assert.Equal(t, ErrorKindRateLimit, got.Kind)
+		assert.Equal(t, 7*time.Second, got.RetryAfter)
+		assert.Equal(t, "7", got.RetryAfterHeader())
+		assert.Equal(t, http.StatusTooManyRequests, got.HTTPStatus(APIStyleAnthropic))
+		assert.Equal(t, map[string]interface{}{
+			"type":  "error",
+			"error": map[string]interface{}{"type": "rate_limit_error", "message": "Rate limit reached"},
+		}, got.Body(APIStyleAnthropic))
+		// Same dialect keeps the provider's own type and code
+		detail := got.Body(APIStyleOpenAI)["error"].(map[string]interface{})
+		assert.Equal(t, "requests", detail["type"])
+		assert.Equal(t, "rate_limit_exceeded", detail["code"])
+	})
+
+	t.Run("gemini resource exhausted", func(t *testing.T) {
+		sdkErr := genai.APIError{
+			Code:    429,
+			Message: "Quota exceeded",
+			Status:  "RESOURCE_EXHAUSTED",
+			Details: []map[string]any{{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "32s"}},
+		}
+		got := ParseUpstreamError(sdkErr)
+		assert.Equal(t, ErrorKindRateLimit, got.Kind)
+		assert.Equal(t, 32*time.Second, got.RetryAfter)
+		assert.Equal(t, "rate_limit_error", got.Body(APIStyleAnthropic)["error"].(map[string]interface{})["type"])
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		got := ParseUpstreamError(fmt.Errorf("request failed: %w", context.DeadlineExceeded))
+		assert.Equal(t, ErrorKindTimeout, got.Kind)
+		assert.Equal(t, http.StatusGatewayTimeout, got.HTTPStatus(APIStyleOpenAI))
+	})
+
+	t.Run("non-provider error", func(t *testing.T) {
+		got := ParseUpstreamError(errors.New("failed to get Google client"))
+		assert.Equal(t, ErrorKindAPI, got.Kind)
+		assert.Equal(t, http.StatusInternalServerError, got.HTTPStatus(APIStyleAnthropic))
+	})
+}
+
+func TestParseUpstreamErrorMidStream(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		kind   ErrorKind
+		source APIStyle
+	}{
+		{
+			name:   "anthropic error event",
+			err:    errors.New(`received error while streaming: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`),
+			kind:   ErrorKindOverloaded,
+			source: APIStyleAnthropic,
+		},
+		{
+			name:   "openai error event",
+			err:    errors.New(`received error while streaming: {"message":"Too many tokens","type":"tokens","code":"rate_limit_exceeded"}`),
+			kind:   ErrorKindRateLimit,
+			source: APIStyleOpenAI,
+		},
+		{
+			name:   "gemini error line",
+			err:    errors.New(`received error while streaming: {"error":{"code":503,"message":"The model is overloaded","status":"UNAVAILABLE"}}`),
+			kind:   ErrorKindOverloaded,
+			source: APIStyleGoogle,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseUpstreamError(tt.err)
+			assert.Equal(t, tt.kind, got.Kind)
+			assert.Equal(t, tt.source, got.Source)
+		})
+	}
+}
+
+func TestParseErrorBody(t *testing.T) {
+	t.Run("retry-after-ms takes precedence", func(t *testing.T) {
+		header := http.Header{"Retry-After-Ms": []string{"1500"}, "Retry-After": []string{"9"}}
+		got := ParseErrorBody(429, header, []byte(`{"error":{"message":"slow down","type":"rate_limit_error"}}`))
+		assert.Equal(t, 1500*time.Millisecond, got.RetryAfter)
+		assert.Equal(t, "2", got.RetryAfterHeader())
+	})
+
+	t.Run("generic type defers to status", func(t *testing.T) {
+		got := ParseErrorBody(401, nil, []byte(`{"error":{"message":"bad key","type":"invalid_request_error","code":null}}`))
+		assert.Equal(t, ErrorKindAuthentication, got.Kind)
+		assert.Equal(t, "authentication_error", got.Body(APIStyleAnthropic)["error"].(map[string]interface{})["type"])
+	})
+
+	t.Run("unspecific client status passes through", func(t *testing.T) {
+		got := ParseErrorBody(402, nil, []byte(`{"error":{"message":"insufficient balance","type":"billing_error"}}`))
+		assert.Equal(t, ErrorKindInvalidRequest, got.Kind)
+		assert.Equal(t, 402, got.HTTPStatus(APIStyleAnthropic))
+	})
+
+	t.Run("non-JSON body", func(t *testing.T) {
+		got := ParseErrorBody(502, nil, []byte("Bad Gateway\n"))
+		assert.Equal(t, ErrorKindAPI, got.Kind)
+		assert.Equal(t, "Bad Gateway", got.Message)
+		assert.Equal(t, 502, got.HTTPStatus(APIStyleOpenAI))
+	})
+}
+
+func TestNewUpstreamError(t *testing.T) {
+	got := NewUpstreamError("rate_limit_exceeded", "Rate limit reached")
+	assert.Equal(t, ErrorKindRateLimit, got.Kind)
+	assert.Equal(t, http.StatusTooManyRequests, got.HTTPStatus(APIStyleGoogle))
+
+	got = NewUpstreamError("server_error", "boom")
+	assert.Equal(t, ErrorKindAPI, got.Kind)
+}
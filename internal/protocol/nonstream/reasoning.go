@@ -0,0 +1,39 @@
+package nonstream
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/openai/openai-go/v3"
+	"google.golang.org/genai"
+)
+
+// OpenAIReasoningContent returns the reasoning_content extension of an OpenAI message (DeepSeek,
+// vLLM, OpenRouter and others), or "" when absent
+func OpenAIReasoningContent(msg openai.ChatCompletionMessage) string {
+	field, ok := msg.JSON.ExtraFields["reasoning_content"]
+	if !ok {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal([]byte(field.Raw()), &text); err != nil {
+		return ""
+	}
+	return text
+}
+
+// syntheticThinkingSignature stands in for the signature of thinking blocks that did not come
+// from Anthropic; Anthropic rejects it if replayed, but clients only need it to be present
+func syntheticThinkingSignature() string {
+	return "thinking-" + uuid.New().String()[0:6]
+}
+
+// GoogleThoughtSignature returns a Gemini thought signature in the base64 form Anthropic
+// clients carry in thinking block signatures
+func GoogleThoughtSignature(part *genai.Part) string {
+	if len(part.ThoughtSignature) == 0 {
+		return syntheticThinkingSignature()
+	}
+	return base64.StdEncoding.EncodeToString(part.ThoughtSignature)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/anthropics/anthropic-sdk-go"
 
+	"github.com/tingly-dev/tingly-box/internal/protocol"
 	"github.com/tingly-dev/tingly-box/internal/protocol/request/transformer"
 	"github.com/tingly-dev/tingly-box/internal/typ"
 )
@@ -20,6 +21,8 @@ func ConvertAnthropicToOpenAIResponse(
 	var toolCalls []map[string]interface{}
 	var textContent string
 	var thinking string
+	var structuredOutput string
+	hasStructuredOutput := false
 
 	// Walk Anthropic content blocks
 	for _, block := range anthropicResp.Content {
@@ -30,6 +33,12 @@ func ConvertAnthropicToOpenAIResponse(
 			textContent += block.Text
 
 		case "tool_use":
+			if block.Name == protocol.StructuredOutputToolName {
+				// Emulated response_format: the tool input is the message content
+				structuredOutput = string(block.Input)
+				hasStructuredOutput = true
+				continue
+			}
 			// Anthropic → OpenAI tool call
 			toolCalls = append(toolCalls, map[string]interface{}{
 				"id":   block.ID,
@@ -42,7 +51,7 @@ func ConvertAnthropicToOpenAIResponse(
 
 		case "thinking":
 			// Collect thinking content for reasoning_content field
-			thinking += block.Text
+			thinking += block.Thinking
 		}
 	}
 
@@ -59,6 +68,10 @@ func ConvertAnthropicToOpenAIResponse(
 	// Set role from Anthropic response (required by OpenAI format)
 	message["role"] = string(anthropicResp.Role)
 
+	if hasStructuredOutput {
+		// Any text around the structured answer would break JSON parsing on the client
+		textContent = structuredOutput
+	}
 	if textContent != "" {
 		message["content"] = textContent
 	}
@@ -74,7 +87,9 @@ func ConvertAnthropicToOpenAIResponse(
 	finishReason := "stop"
 	switch anthropicResp.StopReason {
 	case "tool_use":
-		finishReason = "tool_calls"
+		if len(toolCalls) > 0 {
+			finishReason = "tool_calls"
+		}
 	case "max_tokens":
 		finishReason = "length"
 	}
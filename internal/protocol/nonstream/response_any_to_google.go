@@ -31,6 +31,11 @@ func ConvertOpenAIToGoogleResponse(openaiResp *openai.ChatCompletion) *genai.Gen
 			Index:        0,
 		}
 
+		// Reasoning comes first as a thought part
+		if reasoning := OpenAIReasoningContent(choice.Message); reasoning != "" {
+			candidate.Content.Parts = append(candidate.Content.Parts, &genai.Part{Text: reasoning, Thought: true})
+		}
+
 		// Add text content
 		if choice.Message.Content != "" {
 			candidate.Content.Parts = append(candidate.Content.Parts, genai.NewPartFromText(choice.Message.Content))
@@ -102,7 +107,9 @@ func ConvertAnthropicToGoogleResponse(anthropicResp *anthropic.Message) *genai.G
 
 	// Process content blocks
 	for _, block := range anthropicResp.Content {
-		if block.Type == "text" {
+		if block.Type == "thinking" && block.Thinking != "" {
+			candidate.Content.Parts = append(candidate.Content.Parts, &genai.Part{Text: block.Thinking, Thought: true})
+		} else if block.Type == "text" {
 			candidate.Content.Parts = append(candidate.Content.Parts, genai.NewPartFromText(block.Text))
 		} else if block.Type == "tool_use" {
 			var argsInput map[string]interface{}
@@ -17,6 +17,7 @@ func ConvertGoogleToOpenAIResponse(googleResp *genai.GenerateContentResponse, re
 
 	// Get first candidate's content
 	var textContent string
+	var reasoningContent string
 	var toolCalls []map[string]interface{}
 	finishReason := "stop"
 
@@ -26,7 +27,10 @@ func ConvertGoogleToOpenAIResponse(googleResp *genai.GenerateContentResponse, re
 		// Extract text content
 		if candidate.Content != nil {
 			for _, part := range candidate.Content.Parts {
-				if part.Text != "" {
+				if part.Thought {
+					// Thought summaries (includeThoughts) surface as reasoning_content
+					reasoningContent += part.Text
+				} else if part.Text != "" {
 					textContent += part.Text
 				}
 
@@ -59,6 +63,9 @@ func ConvertGoogleToOpenAIResponse(googleResp *genai.GenerateContentResponse, re
 	if textContent != "" {
 		message["content"] = textContent
 	}
+	if reasoningContent != "" {
+		message["reasoning_content"] = reasoningContent
+	}
 	if len(toolCalls) > 0 {
 		message["tool_calls"] = toolCalls
 		if finishReason == "stop" {
@@ -139,14 +146,27 @@ func ConvertGoogleToAnthropicResponse(googleResp *genai.GenerateContentResponse,
 	}
 
 	var contentBlocks []map[string]interface{}
+	var webSearches int
 
 	// Process first candidate
 	if len(googleResp.Candidates) > 0 {
 		candidate := googleResp.Candidates[0]
 
 		if candidate.Content != nil {
+			codeExecution := NewGoogleCodeExecution(googleResp.ResponseID)
 			for _, part := range candidate.Content.Parts {
-				if part.Text != "" {
+				if block := codeExecution.Block(part); block != nil {
+					contentBlocks = append(contentBlocks, block)
+					continue
+				}
+				if part.Thought {
+					// Thought summaries (includeThoughts) surface as thinking blocks
+					contentBlocks = append(contentBlocks, map[string]interface{}{
+						"type":      "thinking",
+						"thinking":  part.Text,
+						"signature": GoogleThoughtSignature(part),
+					})
+				} else if part.Text != "" {
 					contentBlocks = append(contentBlocks, map[string]interface{}{
 						"type": "text",
 						"text": part.Text,
@@ -164,6 +184,12 @@ func ConvertGoogleToAnthropicResponse(googleResp *genai.GenerateContentResponse,
 			}
 		}
 
+		// Google Search grounding stands in for Anthropic web search, whose blocks lead the answer
+		if grounding := GoogleGroundingBlocks(googleResp.ResponseID, candidate.GroundingMetadata); grounding != nil {
+			contentBlocks = append(grounding, contentBlocks...)
+			webSearches = GoogleWebSearchRequests(candidate.GroundingMetadata)
+		}
+
 		// Map stop reason
 		responseJSON["stop_reason"] = MapGoogleFinishReasonToAnthropic(candidate.FinishReason)
 	}
@@ -177,6 +203,9 @@ func ConvertGoogleToAnthropicResponse(googleResp *genai.GenerateContentResponse,
 			"output_tokens": googleResp.UsageMetadata.CandidatesTokenCount,
 		}
 	}
+	if webSearches > 0 {
+		responseJSON["usage"].(map[string]interface{})["server_tool_use"] = map[string]interface{}{"web_search_requests": webSearches}
+	}
 
 	// Marshal and unmarshal to create proper Message struct
 	jsonBytes, _ := json.Marshal(responseJSON)
@@ -227,14 +256,27 @@ func ConvertGoogleToAnthropicBetaResponse(googleResp *genai.GenerateContentRespo
 	}
 
 	var contentBlocks []map[string]interface{}
+	var webSearches int
 
 	// Process first candidate
 	if len(googleResp.Candidates) > 0 {
 		candidate := googleResp.Candidates[0]
 
 		if candidate.Content != nil {
+			codeExecution := NewGoogleCodeExecution(googleResp.ResponseID)
 			for _, part := range candidate.Content.Parts {
-				if part.Text != "" {
+				if block := codeExecution.Block(part); block != nil {
+					contentBlocks = append(contentBlocks, block)
+					continue
+				}
+				if part.Thought {
+					// Thought summaries (includeThoughts) surface as thinking blocks
+					contentBlocks = append(contentBlocks, map[string]interface{}{
+						"type":      "thinking",
+						"thinking":  part.Text,
+						"signature": GoogleThoughtSignature(part),
+					})
+				} else if part.Text != "" {
 					contentBlocks = append(contentBlocks, map[string]interface{}{
 						"type": "text",
 						"text": part.Text,
@@ -252,6 +294,12 @@ func ConvertGoogleToAnthropicBetaResponse(googleResp *genai.GenerateContentRespo
 			}
 		}
 
+		// Google Search grounding stands in for Anthropic web search, whose blocks lead the answer
+		if grounding := GoogleGroundingBlocks(googleResp.ResponseID, candidate.GroundingMetadata); grounding != nil {
+			contentBlocks = append(grounding, contentBlocks...)
+			webSearches = GoogleWebSearchRequests(candidate.GroundingMetadata)
+		}
+
 		// Map stop reason
 		responseJSON["stop_reason"] = MapGoogleFinishReasonToAnthropicBeta(candidate.FinishReason)
 	}
@@ -265,6 +313,9 @@ func ConvertGoogleToAnthropicBetaResponse(googleResp *genai.GenerateContentRespo
 			"output_tokens": googleResp.UsageMetadata.CandidatesTokenCount,
 		}
 	}
+	if webSearches > 0 {
+		responseJSON["usage"].(map[string]interface{})["server_tool_use"] = map[string]interface{}{"web_search_requests": webSearches}
+	}
 
 	// Marshal and unmarshal to create proper BetaMessage struct
 	jsonBytes, _ := json.Marshal(responseJSON)
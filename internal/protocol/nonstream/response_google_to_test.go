@@ -762,3 +762,30 @@ func TestConvertGoogleToolsToAnthropic(t *testing.T) {
 		}
 	})
 }
+
+func TestConvertGoogleResponseThoughts(t *testing.T) {
+	googleResp := &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content: &genai.Content{
+				Role: "model",
+				Parts: []*genai.Part{
+					{Text: "Weighing the options", Thought: true, ThoughtSignature: []byte("sig")},
+					{Text: "Option B"},
+				},
+			},
+			FinishReason: genai.FinishReasonStop,
+		}},
+	}
+
+	openaiResp := ConvertGoogleToOpenAIResponse(googleResp, "gemini-2.5-pro")
+	message := openaiResp["choices"].([]map[string]interface{})[0]["message"].(map[string]interface{})
+	assert.Equal(t, "Option B", message["content"])
+	assert.Equal(t, "Weighing the options", message["reasoning_content"])
+
+	anthropicResp := ConvertGoogleToAnthropicResponse(googleResp, "gemini-2.5-pro")
+	require.Len(t, anthropicResp.Content, 2)
+	assert.Equal(t, "thinking", anthropicResp.Content[0].Type)
+	assert.Equal(t, "Weighing the options", anthropicResp.Content[0].Thinking)
+	assert.Equal(t, "c2ln", anthropicResp.Content[0].Signature)
+	assert.Equal(t, "Option B", anthropicResp.Content[1].Text)
+}
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
-	"github.com/google/uuid"
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/responses"
 )
@@ -43,22 +42,21 @@ func ConvertOpenAIToAnthropicResponse(openaiResp *openai.ChatCompletion, model s
 			contentBlocks = append(contentBlocks, anthropic.NewTextBlock(choice.Message.Refusal))
 		}
 
+		// Reasoning goes first, matching the order Anthropic returns thinking blocks in
+		if thinking := OpenAIReasoningContent(choice.Message); thinking != "" {
+			// a fake signature for thinking block
+			contentBlocks = append(contentBlocks, anthropic.NewThinkingBlock(syntheticThinkingSignature(), thinking))
+		}
+
 		// Add text content if present
 		if choice.Message.Content != "" {
 			contentBlocks = append(contentBlocks, anthropic.NewTextBlock(choice.Message.Content))
 		}
 
-		if extra := choice.Message.JSON.ExtraFields; extra != nil {
-			if thinking, ok := extra["reasoning_content"]; ok {
-				// a fake signature for thinking block
-				contentBlocks = append(contentBlocks, anthropic.NewThinkingBlock("thinking-"+uuid.New().String()[0:6], fmt.Sprintf("%s", thinking.Raw())))
-			}
-		}
-
 		// Convert tool_calls to tool_use blocks
 		if len(choice.Message.ToolCalls) > 0 {
 			for _, toolCall := range choice.Message.ToolCalls {
-				contentBlocks = append(contentBlocks, anthropic.NewToolUseBlock(toolCall.ID, toolCall.Function.Arguments, toolCall.Function.Name))
+				contentBlocks = append(contentBlocks, anthropic.NewToolUseBlock(toolCall.ID, toolUseInput(toolCall.Function.Arguments), toolCall.Function.Name))
 			}
 
 			// If there were tool calls, set stop_reason to tool_use
@@ -69,7 +67,7 @@ func ConvertOpenAIToAnthropicResponse(openaiResp *openai.ChatCompletion, model s
 		break
 	}
 
-	responseJSON["content"] = contentBlocks
+	responseJSON["content"] = glmWebSearchContent(responseJSON, openaiResp, contentBlocks)
 
 	// Marshal and unmarshal to create proper Message struct
 	jsonBytes, _ := json.Marshal(responseJSON)
@@ -112,22 +110,21 @@ func ConvertOpenAIToAnthropicBetaResponse(openaiResp *openai.ChatCompletion, mod
 			contentBlocks = append(contentBlocks, anthropic.NewBetaTextBlock(choice.Message.Refusal))
 		}
 
+		// Reasoning goes first, matching the order Anthropic returns thinking blocks in
+		if thinking := OpenAIReasoningContent(choice.Message); thinking != "" {
+			// a fake signature for thinking block
+			contentBlocks = append(contentBlocks, anthropic.NewBetaThinkingBlock(syntheticThinkingSignature(), thinking))
+		}
+
 		// Add text content if present
 		if choice.Message.Content != "" {
 			contentBlocks = append(contentBlocks, anthropic.NewBetaTextBlock(choice.Message.Content))
 		}
 
-		if extra := choice.Message.JSON.ExtraFields; extra != nil {
-			if thinking, ok := extra["reasoning_content"]; ok {
-				// a fake signature for thinking block
-				contentBlocks = append(contentBlocks, anthropic.NewBetaThinkingBlock("thinking-"+uuid.New().String()[0:6], fmt.Sprintf("%s", thinking.Raw())))
-			}
-		}
-
 		// Convert tool_calls to tool_use blocks
 		if len(choice.Message.ToolCalls) > 0 {
 			for _, toolCall := range choice.Message.ToolCalls {
-				contentBlocks = append(contentBlocks, anthropic.NewBetaToolUseBlock(toolCall.ID, toolCall.Function.Arguments, toolCall.Function.Name))
+				contentBlocks = append(contentBlocks, anthropic.NewBetaToolUseBlock(toolCall.ID, toolUseInput(toolCall.Function.Arguments), toolCall.Function.Name))
 			}
 
 			// If there were tool calls, set stop_reason to tool_use
@@ -138,7 +135,7 @@ func ConvertOpenAIToAnthropicBetaResponse(openaiResp *openai.ChatCompletion, mod
 		break
 	}
 
-	responseJSON["content"] = contentBlocks
+	responseJSON["content"] = glmWebSearchContent(responseJSON, openaiResp, contentBlocks)
 
 	// Marshal and unmarshal to create proper BetaMessage struct
 	jsonBytes, _ := json.Marshal(responseJSON)
@@ -150,77 +147,19 @@ func ConvertOpenAIToAnthropicBetaResponse(openaiResp *openai.ChatCompletion, mod
 
 // ConvertResponsesToAnthropicBetaResponse converts OpenAI Responses API response to Anthropic beta format
 func ConvertResponsesToAnthropicBetaResponse(responsesResp *responses.Response, model string) anthropic.BetaMessage {
-	// Create the response as JSON first, then unmarshal into BetaMessage
-	responseJSON := map[string]interface{}{
-		"id":            responsesResp.ID,
-		"type":          "message",
-		"role":          "assistant",
-		"content":       []map[string]interface{}{},
-		"model":         model,
-		"stop_reason":   string(anthropic.BetaStopReasonEndTurn),
-		"stop_sequence": "",
-		"usage": map[string]interface{}{
-			"input_tokens":  responsesResp.Usage.InputTokens,
-			"output_tokens": responsesResp.Usage.OutputTokens,
-		},
-	}
-
-	// Add content from Responses API response
-	// The Responses API has a different output structure
-	var contentBlocks []anthropic.BetaContentBlockParamUnion
-
-	// Process the output array from Responses API
-	for _, output := range responsesResp.Output {
-		// Handle text content
-		for _, content := range output.Content {
-			if content.Type == "output_text" {
-				contentBlocks = append(contentBlocks, anthropic.NewBetaTextBlock(content.Text))
-			}
-			// Handle other content types as needed
-		}
-
-		// Handle tool calls (function_call, custom_tool_call, mcp_call)
-		if output.Type == "function_call" || output.Type == "custom_tool_call" || output.Type == "mcp_call" {
-			contentBlocks = append(contentBlocks, anthropic.NewBetaToolUseBlock(
-				output.ID,
-				output.Arguments,
-				output.Name,
-			))
-
-			// If there were tool calls, set stop_reason to tool_use
-			responseJSON["stop_reason"] = string(anthropic.BetaStopReasonToolUse)
-		}
-	}
-
-	// Handle reasoning content if present
-	for _, output := range responsesResp.Output {
-		for _, content := range output.Content {
-			if content.Type == "reasoning_text" && content.Text != "" {
-				contentBlocks = append(contentBlocks, anthropic.NewBetaThinkingBlock(
-					"thinking-"+uuid.New().String()[0:6],
-					content.Text,
-				))
-			}
-		}
-	}
-
-	// Handle refusal if present
-	for _, output := range responsesResp.Output {
-		for _, content := range output.Content {
-			if content.Type == "refusal" && content.Text != "" {
-				contentBlocks = append(contentBlocks, anthropic.NewBetaTextBlock(content.Text))
-				// Set stop_reason to refusal if present
-				responseJSON["stop_reason"] = string(anthropic.BetaStopReasonRefusal)
-			}
-		}
-	}
-
-	responseJSON["content"] = contentBlocks
-
 	// Marshal and unmarshal to create proper BetaMessage struct
-	jsonBytes, _ := json.Marshal(responseJSON)
+	jsonBytes, _ := json.Marshal(responsesToAnthropicMessage(responsesResp, model))
 	var msg anthropic.BetaMessage
 	json.Unmarshal(jsonBytes, &msg)
 
 	return msg
 }
+
+// toolUseInput returns tool call arguments as a tool_use input, embedding them as an object
+// when they are valid JSON rather than as an escaped string
+func toolUseInput(arguments string) interface{} {
+	if json.Valid([]byte(arguments)) {
+		return json.RawMessage(arguments)
+	}
+	return arguments
+}
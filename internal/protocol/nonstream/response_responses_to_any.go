@@ -0,0 +1,318 @@
+package nonstream
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go/v3/responses"
+	"google.golang.org/genai"
+)
+
+// Responses API output item types
+const (
+	responsesItemMessage       = "message"
+	responsesItemReasoning     = "reasoning"
+	responsesItemFunctionCall  = "function_call"
+	responsesItemCustomCall    = "custom_tool_call"
+	responsesItemMCPCall       = "mcp_call"
+	responsesItemWebSearchCall = "web_search_call"
+)
+
+// ConvertResponsesToAnthropicResponse converts an OpenAI Responses API response to Anthropic v1 format
+func ConvertResponsesToAnthropicResponse(responsesResp *responses.Response, model string) anthropic.Message {
+	jsonBytes, _ := json.Marshal(responsesToAnthropicMessage(responsesResp, model))
+	var msg anthropic.Message
+	_ = json.Unmarshal(jsonBytes, &msg)
+	return msg
+}
+
+// responsesToAnthropicMessage builds the Anthropic wire form of a Responses API response, which
+// is the same for v1 and beta. Output items keep their order: reasoning items become thinking
+// blocks, messages text blocks, function/custom/MCP calls tool_use blocks, and web searches
+// server_tool_use plus web_search_tool_result blocks.
+func responsesToAnthropicMessage(resp *responses.Response, model string) map[string]interface{} {
+	var content []map[string]interface{}
+
+	citations := responsesURLCitations(resp)
+	for _, item := range resp.Output {
+		switch item.Type {
+		case responsesItemReasoning:
+			if thinking := responsesReasoningText(item); thinking != "" {
+				content = append(content, map[string]interface{}{
+					"type":      "thinking",
+					"thinking":  thinking,
+					"signature": syntheticThinkingSignature(),
+				})
+			}
+		case responsesItemMessage:
+			for _, part := range item.Content {
+				switch part.Type {
+				case "output_text":
+					if part.Text != "" {
+						content = append(content, map[string]interface{}{"type": "text", "text": part.Text})
+					}
+				case "refusal":
+					text := part.Refusal
+					if text == "" {
+						text = part.Text
+					}
+					if text != "" {
+						content = append(content, map[string]interface{}{"type": "text", "text": text})
+					}
+				}
+			}
+		case responsesItemFunctionCall, responsesItemCustomCall, responsesItemMCPCall:
+			content = append(content, map[string]interface{}{
+				"type":  "tool_use",
+				"id":    ResponsesCallID(item),
+				"name":  item.Name,
+				"input": ResponsesToolInput(item),
+			})
+		case responsesItemWebSearchCall:
+			content = append(content,
+				map[string]interface{}{
+					"type":  "server_tool_use",
+					"id":    item.ID,
+					"name":  "web_search",
+					"input": map[string]interface{}{"query": item.Action.Query},
+				},
+				map[string]interface{}{
+					"type":        "web_search_tool_result",
+					"tool_use_id": item.ID,
+					"content":     ResponsesWebSearchResults(item, citations),
+				},
+			)
+		}
+	}
+
+	if content == nil {
+		content = []map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"id":            resp.ID,
+		"type":          "message",
+		"role":          "assistant",
+		"content":       content,
+		"model":         model,
+		"stop_reason":   ResponsesStopReasonToAnthropic(resp),
+		"stop_sequence": "",
+		"usage":         ResponsesUsageToAnthropic(resp),
+	}
+}
+
+// ResponsesStopReasonToAnthropic derives the Anthropic stop reason of a finished Responses API response
+func ResponsesStopReasonToAnthropic(resp *responses.Response) string {
+	refused := false
+	for _, item := range resp.Output {
+		switch item.Type {
+		case responsesItemFunctionCall, responsesItemCustomCall, responsesItemMCPCall:
+			return string(anthropic.StopReasonToolUse)
+		case responsesItemMessage:
+			for _, part := range item.Content {
+				if part.Type == "refusal" {
+					refused = true
+				}
+			}
+		}
+	}
+	switch {
+	case refused:
+		return string(anthropic.StopReasonRefusal)
+	case resp.Status == responses.ResponseStatusIncomplete && resp.IncompleteDetails.Reason == "max_output_tokens":
+		return string(anthropic.StopReasonMaxTokens)
+	}
+	return string(anthropic.StopReasonEndTurn)
+}
+
+// ResponsesUsageToAnthropic maps Responses API usage onto Anthropic usage. Responses counts cached
+// tokens inside input_tokens while Anthropic reports them separately.
+func ResponsesUsageToAnthropic(resp *responses.Response) map[string]interface{} {
+	cached := resp.Usage.InputTokensDetails.CachedTokens
+	usage := map[string]interface{}{
+		"input_tokens":            resp.Usage.InputTokens - cached,
+		"output_tokens":           resp.Usage.OutputTokens,
+		"cache_read_input_tokens": cached,
+	}
+	var searches int64
+	for _, item := range resp.Output {
+		if item.Type == responsesItemWebSearchCall {
+			searches++
+		}
+	}
+	if searches > 0 {
+		usage["server_tool_use"] = map[string]interface{}{"web_search_requests": searches}
+	}
+	return usage
+}
+
+// ResponsesCallID returns the ID a tool call is referenced by in follow-up requests
+func ResponsesCallID(item responses.ResponseOutputItemUnion) string {
+	if item.CallID != "" {
+		return item.CallID
+	}
+	return item.ID
+}
+
+// ResponsesToolInput returns the input of a function, custom or MCP tool call as a JSON value.
+// Custom tools take free-form text, which is wrapped as {"input": text}.
+func ResponsesToolInput(item responses.ResponseOutputItemUnion) json.RawMessage {
+	if item.Type == responsesItemCustomCall {
+		raw, _ := json.Marshal(map[string]string{"input": item.Input})
+		return raw
+	}
+	if item.Arguments != "" && json.Valid([]byte(item.Arguments)) {
+		return json.RawMessage(item.Arguments)
+	}
+	return json.RawMessage("{}")
+}
+
+// responsesReasoningText returns the raw reasoning text of a reasoning item, falling back to its
+// summaries for models that only expose those
+func responsesReasoningText(item responses.ResponseOutputItemUnion) string {
+	var thinking strings.Builder
+	for _, part := range item.Content {
+		if part.Type == "reasoning_text" {
+			thinking.WriteString(part.Text)
+		}
+	}
+	if thinking.Len() == 0 {
+		for i, summary := range item.Summary {
+			if i > 0 {
+				thinking.WriteString("\n\n")
+			}
+			thinking.WriteString(summary.Text)
+		}
+	}
+	return thinking.String()
+}
+
+// responsesURLCitations maps cited URLs to their titles across all message items
+func responsesURLCitations(resp *responses.Response) map[string]string {
+	citations := make(map[string]string)
+	for _, item := range resp.Output {
+		for _, part := range item.Content {
+			for _, annotation := range part.Annotations {
+				if annotation.Type == "url_citation" && annotation.URL != "" {
+					citations[annotation.URL] = annotation.Title
+				}
+			}
+		}
+	}
+	return citations
+}
+
+// ResponsesWebSearchResults lists the sources of a web search call as Anthropic web search
+// results. Responses does not return result pages, so only URLs and cited titles are known.
+// citations maps URLs to titles and may be nil.
+func ResponsesWebSearchResults(item responses.ResponseOutputItemUnion, citations map[string]string) []map[string]interface{} {
+	results := []map[string]interface{}{}
+	seen := make(map[string]bool)
+	add := func(url, title string) {
+		if url == "" || seen[url] {
+			return
+		}
+		seen[url] = true
+		results = append(results, map[string]interface{}{
+			"type":              "web_search_result",
+			"url":               url,
+			"title":             title,
+			"encrypted_content": "",
+		})
+	}
+	for _, source := range item.Action.Sources {
+		add(source.URL, citations[source.URL])
+	}
+	// Without explicit sources, fall back to the URLs the answer cites
+	if len(item.Action.Sources) == 0 {
+		urls := make([]string, 0, len(citations))
+		for url := range citations {
+			urls = append(urls, url)
+		}
+		sort.Strings(urls)
+		for _, url := range urls {
+			add(url, citations[url])
+		}
+	}
+	return results
+}
+
+// ConvertResponsesToGoogleResponse converts an OpenAI Responses API response to Google format
+func ConvertResponsesToGoogleResponse(responsesResp *responses.Response) *genai.GenerateContentResponse {
+	if responsesResp == nil {
+		return nil
+	}
+
+	candidate := &genai.Candidate{
+		Content: &genai.Content{
+			Role:  "model",
+			Parts: []*genai.Part{},
+		},
+		FinishReason: MapResponsesStatusToGoogle(responsesResp),
+	}
+	for _, item := range responsesResp.Output {
+		switch item.Type {
+		case responsesItemReasoning:
+			if thinking := responsesReasoningText(item); thinking != "" {
+				candidate.Content.Parts = append(candidate.Content.Parts, &genai.Part{Text: thinking, Thought: true})
+			}
+		case responsesItemMessage:
+			for _, part := range item.Content {
+				text := part.Text
+				if part.Type == "refusal" && part.Refusal != "" {
+					text = part.Refusal
+				}
+				if text != "" {
+					candidate.Content.Parts = append(candidate.Content.Parts, genai.NewPartFromText(text))
+				}
+			}
+		case responsesItemFunctionCall, responsesItemCustomCall, responsesItemMCPCall:
+			var args map[string]interface{}
+			_ = json.Unmarshal(ResponsesToolInput(item), &args)
+			candidate.Content.Parts = append(candidate.Content.Parts, &genai.Part{
+				FunctionCall: &genai.FunctionCall{
+					ID:   ResponsesCallID(item),
+					Name: item.Name,
+					Args: args,
+				},
+			})
+		}
+	}
+
+	return &genai.GenerateContentResponse{
+		Candidates:    []*genai.Candidate{candidate},
+		ModelVersion:  responsesResp.Model,
+		ResponseID:    responsesResp.ID,
+		UsageMetadata: ResponsesUsageToGoogle(responsesResp.Usage),
+	}
+}
+
+// MapResponsesStatusToGoogle maps a Responses API completion status to a Google finish reason
+func MapResponsesStatusToGoogle(resp *responses.Response) genai.FinishReason {
+	switch resp.Status {
+	case responses.ResponseStatusIncomplete:
+		switch resp.IncompleteDetails.Reason {
+		case "max_output_tokens":
+			return genai.FinishReasonMaxTokens
+		case "content_filter":
+			return genai.FinishReasonSafety
+		}
+		return genai.FinishReasonOther
+	case responses.ResponseStatusFailed:
+		return genai.FinishReasonOther
+	}
+	return genai.FinishReasonStop
+}
+
+// ResponsesUsageToGoogle maps Responses API usage onto Google usage metadata
+func ResponsesUsageToGoogle(usage responses.ResponseUsage) *genai.GenerateContentResponseUsageMetadata {
+	reasoning := usage.OutputTokensDetails.ReasoningTokens
+	return &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:        int32(usage.InputTokens),
+		CachedContentTokenCount: int32(usage.InputTokensDetails.CachedTokens),
+		CandidatesTokenCount:    int32(usage.OutputTokens - reasoning),
+		ThoughtsTokenCount:      int32(reasoning),
+		TotalTokenCount:         int32(usage.TotalTokens),
+	}
+}
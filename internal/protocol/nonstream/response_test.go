@@ -11,6 +11,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/genai"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
 )
 
 func TestConvertAnthropicToOpenAIResponse(t *testing.T) {
@@ -304,3 +306,28 @@ func TestConvertAnthropicToGoogleResponse(t *testing.T) {
 		}
 	})
 }
+
+func TestConvertAnthropicToOpenAIResponseStructuredOutput(t *testing.T) {
+	anthropicResp := &anthropic.Message{
+		ID:   "msg_so",
+		Role: "assistant",
+		Content: []anthropic.ContentBlockUnion{
+			{Type: "text", Text: "Here is the extracted invoice."},
+			{
+				Type:  "tool_use",
+				ID:    "toolu_so",
+				Name:  protocol.StructuredOutputToolName,
+				Input: json.RawMessage(`{"vendor":"Acme","total":12.5}`),
+			},
+		},
+		StopReason: "tool_use",
+	}
+
+	result := ConvertAnthropicToOpenAIResponse(anthropicResp, "claude-sonnet-4-5")
+	choice := result["choices"].([]map[string]interface{})[0]
+	message := choice["message"].(map[string]interface{})
+
+	assert.Equal(t, `{"vendor":"Acme","total":12.5}`, message["content"])
+	assert.NotContains(t, message, "tool_calls")
+	assert.Equal(t, "stop", choice["finish_reason"])
+}
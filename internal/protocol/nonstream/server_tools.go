@@ -0,0 +1,161 @@
+package nonstream
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/openai/openai-go/v3"
+	"google.golang.org/genai"
+)
+
+// glmWebSearchResult is one entry of the top-level web_search array GLM returns when its
+// built-in web search tool ran
+type glmWebSearchResult struct {
+	Title       string `json:"title"`
+	Link        string `json:"link"`
+	Content     string `json:"content"`
+	Media       string `json:"media"`
+	PublishDate string `json:"publish_date"`
+}
+
+// GLMWebSearchBlocks converts the raw web_search array of a GLM response or stream chunk into
+// Anthropic server_tool_use and web_search_tool_result blocks. GLM does not echo the search
+// query, so the tool input carries an empty one. Returns nil when there are no results.
+func GLMWebSearchBlocks(id string, raw string) []map[string]interface{} {
+	var searchResults []glmWebSearchResult
+	if err := json.Unmarshal([]byte(raw), &searchResults); err != nil || len(searchResults) == 0 {
+		return nil
+	}
+
+	results := make([]map[string]interface{}, 0, len(searchResults))
+	for _, r := range searchResults {
+		if r.Link == "" {
+			continue
+		}
+		result := map[string]interface{}{
+			"type":              "web_search_result",
+			"url":               r.Link,
+			"title":             r.Title,
+			"encrypted_content": "",
+		}
+		if r.PublishDate != "" {
+			result["page_age"] = r.PublishDate
+		}
+		results = append(results, result)
+	}
+	return webSearchBlocks("srvtoolu_"+id, "", results)
+}
+
+// glmWebSearchContent puts the blocks of a GLM web search, when the response carries one, ahead
+// of the converted content and counts the search in usage
+func glmWebSearchContent[T any](responseJSON map[string]interface{}, resp *openai.ChatCompletion, blocks []T) []interface{} {
+	content := make([]interface{}, 0, len(blocks)+2)
+	if field, ok := resp.JSON.ExtraFields["web_search"]; ok {
+		if search := GLMWebSearchBlocks(resp.ID, field.Raw()); search != nil {
+			for _, block := range search {
+				content = append(content, block)
+			}
+			responseJSON["usage"].(map[string]interface{})["server_tool_use"] = map[string]interface{}{"web_search_requests": 1}
+		}
+	}
+	for _, block := range blocks {
+		content = append(content, block)
+	}
+	return content
+}
+
+// GoogleGroundingBlocks converts Gemini Google Search grounding metadata into Anthropic
+// server_tool_use and web_search_tool_result blocks. Gemini may issue several queries for one
+// answer; they are reported as a single search whose results are the grounding sources.
+func GoogleGroundingBlocks(id string, metadata *genai.GroundingMetadata) []map[string]interface{} {
+	if metadata == nil || len(metadata.WebSearchQueries) == 0 {
+		return nil
+	}
+
+	results := []map[string]interface{}{}
+	for _, chunk := range metadata.GroundingChunks {
+		if chunk == nil || chunk.Web == nil || chunk.Web.URI == "" {
+			continue
+		}
+		results = append(results, map[string]interface{}{
+			"type":              "web_search_result",
+			"url":               chunk.Web.URI,
+			"title":             chunk.Web.Title,
+			"encrypted_content": "",
+		})
+	}
+	return webSearchBlocks("srvtoolu_"+id, metadata.WebSearchQueries[0], results)
+}
+
+// GoogleWebSearchRequests counts the searches behind a Gemini answer for Anthropic's
+// usage.server_tool_use.web_search_requests
+func GoogleWebSearchRequests(metadata *genai.GroundingMetadata) int {
+	if metadata == nil {
+		return 0
+	}
+	return len(metadata.WebSearchQueries)
+}
+
+func webSearchBlocks(toolUseID, query string, results []map[string]interface{}) []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"type":  "server_tool_use",
+			"id":    toolUseID,
+			"name":  "web_search",
+			"input": map[string]interface{}{"query": query},
+		},
+		{
+			"type":        "web_search_tool_result",
+			"tool_use_id": toolUseID,
+			"content":     results,
+		},
+	}
+}
+
+// GoogleCodeExecution converts Gemini code execution parts into Anthropic server_tool_use and
+// code_execution_tool_result blocks, pairing each result with the code that produced it
+type GoogleCodeExecution struct {
+	responseID string
+	calls      int
+	pendingID  string
+}
+
+// NewGoogleCodeExecution creates a converter for the code execution parts of one response
+func NewGoogleCodeExecution(responseID string) *GoogleCodeExecution {
+	return &GoogleCodeExecution{responseID: responseID}
+}
+
+// Block returns the Anthropic block of a code execution part, or nil for any other part
+func (g *GoogleCodeExecution) Block(part *genai.Part) map[string]interface{} {
+	switch {
+	case part.ExecutableCode != nil:
+		g.calls++
+		g.pendingID = fmt.Sprintf("srvtoolu_%s_%d", g.responseID, g.calls)
+		return map[string]interface{}{
+			"type":  "server_tool_use",
+			"id":    g.pendingID,
+			"name":  "code_execution",
+			"input": map[string]interface{}{"code": part.ExecutableCode.Code},
+		}
+	case part.CodeExecutionResult != nil:
+		result := map[string]interface{}{
+			"type":        "code_execution_result",
+			"stdout":      "",
+			"stderr":      "",
+			"return_code": 0,
+			"content":     []interface{}{},
+		}
+		if part.CodeExecutionResult.Outcome == genai.OutcomeOK {
+			result["stdout"] = part.CodeExecutionResult.Output
+		} else {
+			result["stderr"] = part.CodeExecutionResult.Output
+			result["return_code"] = 1
+		}
+		return map[string]interface{}{
+			"type":        "code_execution_tool_result",
+			"tool_use_id": g.pendingID,
+			"content":     result,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package nonstream
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+)
+
+func TestGLMWebSearchToAnthropic(t *testing.T) {
+	var resp openai.ChatCompletion
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "2025101812",
+		"object": "chat.completion",
+		"model": "glm-4.6",
+		"choices": [{"index": 0, "finish_reason": "stop", "message": {"role": "assistant", "content": "Go 1.25 is out."}}],
+		"usage": {"prompt_tokens": 120, "completion_tokens": 8, "total_tokens": 128},
+		"web_search": [
+			{"title": "Go 1.25 Release Notes", "link": "https://go.dev/doc/go1.25", "content": "...", "publish_date": "2025-08-12"},
+			{"title": "no link"}
+		]
+	}`), &resp))
+
+	msg := ConvertOpenAIToAnthropicResponse(&resp, "claude-sonnet-4-5")
+	require.Len(t, msg.Content, 3)
+	assert.Equal(t, "server_tool_use", msg.Content[0].Type)
+	assert.Equal(t, "srvtoolu_2025101812", msg.Content[0].ID)
+	assert.Equal(t, "web_search", msg.Content[0].Name)
+
+	result := msg.Content[1]
+	assert.Equal(t, "web_search_tool_result", result.Type)
+	assert.Equal(t, "srvtoolu_2025101812", result.ToolUseID)
+	require.Len(t, result.Content.OfWebSearchResultBlockArray, 1)
+	assert.Equal(t, "https://go.dev/doc/go1.25", result.Content.OfWebSearchResultBlockArray[0].URL)
+	assert.Equal(t, "2025-08-12", result.Content.OfWebSearchResultBlockArray[0].PageAge)
+
+	assert.Equal(t, "Go 1.25 is out.", msg.Content[2].Text)
+	assert.Equal(t, int64(1), msg.Usage.ServerToolUse.WebSearchRequests)
+
+	beta := ConvertOpenAIToAnthropicBetaResponse(&resp, "claude-sonnet-4-5")
+	require.Len(t, beta.Content, 3)
+	assert.Equal(t, "web_search_tool_result", string(beta.Content[1].Type))
+}
+
+func TestGoogleServerToolsToAnthropic(t *testing.T) {
+	resp := &genai.GenerateContentResponse{
+		ResponseID: "resp1",
+		Candidates: []*genai.Candidate{{
+			FinishReason: genai.FinishReasonStop,
+			Content: &genai.Content{Role: "model", Parts: []*genai.Part{
+				{ExecutableCode: &genai.ExecutableCode{Code: "print(2**10)", Language: genai.LanguagePython}},
+				{CodeExecutionResult: &genai.CodeExecutionResult{Outcome: genai.OutcomeOK, Output: "1024\n"}},
+				genai.NewPartFromText("2^10 is 1024, per the docs."),
+			}},
+			GroundingMetadata: &genai.GroundingMetadata{
+				WebSearchQueries: []string{"2 to the power of 10"},
+				GroundingChunks: []*genai.GroundingChunk{
+					{Web: &genai.GroundingChunkWeb{URI: "https://example.com/powers", Title: "example.com"}},
+				},
+			},
+		}},
+	}
+
+	// Code execution is a beta tool, so its blocks only round-trip through the beta message
+	raw, err := json.Marshal(ConvertGoogleToAnthropicBetaResponse(resp, "claude-sonnet-4-5"))
+	require.NoError(t, err)
+	var msg struct {
+		Content []map[string]interface{} `json:"content"`
+		Usage   map[string]interface{}   `json:"usage"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &msg))
+
+	types := make([]string, 0, len(msg.Content))
+	for _, block := range msg.Content {
+		types = append(types, block["type"].(string))
+	}
+	assert.Equal(t, []string{"server_tool_use", "web_search_tool_result", "server_tool_use", "code_execution_tool_result", "text"}, types)
+
+	assert.Equal(t, map[string]interface{}{"query": "2 to the power of 10"}, msg.Content[0]["input"])
+	assert.Equal(t, "code_execution", msg.Content[2]["name"])
+	assert.Equal(t, msg.Content[2]["id"], msg.Content[3]["tool_use_id"])
+	assert.Equal(t, "1024\n", msg.Content[3]["content"].(map[string]interface{})["stdout"])
+	assert.Equal(t, float64(1), msg.Usage["server_tool_use"].(map[string]interface{})["web_search_requests"])
+}
@@ -0,0 +1,228 @@
+package protocol
+
+import (
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"google.golang.org/genai"
+)
+
+// ReasoningEffort is the dialect-neutral reasoning level. Values match OpenAI's reasoning_effort.
+type ReasoningEffort string
+
+const (
+	ReasoningEffortNone    ReasoningEffort = "none"
+	ReasoningEffortMinimal ReasoningEffort = "minimal"
+	ReasoningEffortLow     ReasoningEffort = "low"
+	ReasoningEffortMedium  ReasoningEffort = "medium"
+	ReasoningEffortHigh    ReasoningEffort = "high"
+	ReasoningEffortXHigh   ReasoningEffort = "xhigh"
+)
+
+// MinAnthropicThinkingBudget is the smallest budget_tokens Anthropic accepts
+const MinAnthropicThinkingBudget = 1024
+
+// reasoningLevel maps one effort level onto each provider's native knob
+type reasoningLevel struct {
+	Effort          ReasoningEffort
+	AnthropicBudget int64               // thinking.budget_tokens
+	GeminiBudget    int32               // thinkingConfig.thinkingBudget (Gemini 2.x)
+	GeminiLevel     genai.ThinkingLevel // thinkingConfig.thinkingLevel (Gemini 3+)
+}
+
+// reasoningLevels is ordered from the least to the most reasoning; budgets are upper bounds
+// when mapping a budget back to an effort
+var reasoningLevels = []reasoningLevel{
+	{ReasoningEffortMinimal, 1024, 512, genai.ThinkingLevelMinimal},
+	{ReasoningEffortLow, 4096, 1024, genai.ThinkingLevelLow},
+	{ReasoningEffortMedium, 10000, 8192, genai.ThinkingLevelMedium},
+	{ReasoningEffortHigh, 24576, 24576, genai.ThinkingLevelHigh},
+	{ReasoningEffortXHigh, 32000, 32768, genai.ThinkingLevelHigh},
+}
+
+// extendedEffortModels lists, per effort outside low/medium/high, the model families known to
+// accept it as reasoning_effort
+var extendedEffortModels = map[ReasoningEffort][]string{
+	ReasoningEffortMinimal: {"gpt-5"},
+	ReasoningEffortXHigh:   {"gpt-5.1-codex-max", "gpt-5.2"},
+}
+
+// OpenAIReasoningEffort returns the reasoning_effort to send to an OpenAI-compatible model. Most
+// backends reject minimal and xhigh, so those are clamped to low and high unless model is known
+// to support them.
+func OpenAIReasoningEffort(effort ReasoningEffort, model string) ReasoningEffort {
+	families, ok := extendedEffortModels[effort]
+	if !ok {
+		return effort
+	}
+	model = strings.ToLower(model)
+	for _, family := range families {
+		if strings.Contains(model, family) {
+			return effort
+		}
+	}
+	if effort == ReasoningEffortMinimal {
+		return ReasoningEffortLow
+	}
+	return ReasoningEffortHigh
+}
+
+// Reasoning is a request's reasoning configuration, normalized across OpenAI reasoning_effort,
+// Responses reasoning, Anthropic thinking and Gemini thinkingConfig
+type Reasoning struct {
+	Effort ReasoningEffort
+	// BudgetTokens is the explicit token budget the client asked for, 0 when it only gave an effort
+	BudgetTokens int64
+}
+
+// ParseReasoningEffort normalizes an effort string, reporting whether it was recognized
+func ParseReasoningEffort(s string) (ReasoningEffort, bool) {
+	effort := ReasoningEffort(strings.ToLower(strings.TrimSpace(s)))
+	if effort == ReasoningEffortNone {
+		return effort, true
+	}
+	for _, level := range reasoningLevels {
+		if level.Effort == effort {
+			return effort, true
+		}
+	}
+	return "", false
+}
+
+// ReasoningFromEffort returns the reasoning for an OpenAI/Responses effort, or nil when unset or unknown
+func ReasoningFromEffort(effort string) *Reasoning {
+	parsed, ok := ParseReasoningEffort(effort)
+	if !ok {
+		return nil
+	}
+	return &Reasoning{Effort: parsed}
+}
+
+// ReasoningFromBudget returns the reasoning for a token budget, picking the smallest effort whose
+// Anthropic budget covers it. A zero budget disables reasoning; a negative one (Gemini's
+// "dynamic") maps to medium.
+func ReasoningFromBudget(budget int64) *Reasoning {
+	switch {
+	case budget == 0:
+		return &Reasoning{Effort: ReasoningEffortNone}
+	case budget < 0:
+		return &Reasoning{Effort: ReasoningEffortMedium}
+	}
+	for _, level := range reasoningLevels {
+		if budget <= level.AnthropicBudget {
+			return &Reasoning{Effort: level.Effort, BudgetTokens: budget}
+		}
+	}
+	return &Reasoning{Effort: ReasoningEffortXHigh, BudgetTokens: budget}
+}
+
+// ReasoningFromAnthropic reads an Anthropic thinking config; nil when thinking is not configured
+func ReasoningFromAnthropic(thinking anthropic.ThinkingConfigParamUnion) *Reasoning {
+	switch {
+	case thinking.OfEnabled != nil:
+		return ReasoningFromBudget(thinking.OfEnabled.BudgetTokens)
+	case thinking.OfDisabled != nil:
+		return &Reasoning{Effort: ReasoningEffortNone}
+	}
+	return nil
+}
+
+// ReasoningFromAnthropicBeta reads an Anthropic beta thinking config; nil when thinking is not configured
+func ReasoningFromAnthropicBeta(thinking anthropic.BetaThinkingConfigParamUnion) *Reasoning {
+	switch {
+	case thinking.OfEnabled != nil:
+		return ReasoningFromBudget(thinking.OfEnabled.BudgetTokens)
+	case thinking.OfDisabled != nil:
+		return &Reasoning{Effort: ReasoningEffortNone}
+	}
+	return nil
+}
+
+// ReasoningFromGemini reads a Gemini thinking config; nil when thinking is not configured
+func ReasoningFromGemini(config *genai.ThinkingConfig) *Reasoning {
+	if config == nil {
+		return nil
+	}
+	if config.ThinkingLevel != "" && config.ThinkingLevel != genai.ThinkingLevelUnspecified {
+		return ReasoningFromEffort(string(config.ThinkingLevel))
+	}
+	if config.ThinkingBudget != nil {
+		return ReasoningFromBudget(int64(*config.ThinkingBudget))
+	}
+	return nil
+}
+
+// Enabled reports whether the request asks for any reasoning
+func (r *Reasoning) Enabled() bool {
+	return r != nil && r.Effort != "" && r.Effort != ReasoningEffortNone
+}
+
+func (r *Reasoning) level() reasoningLevel {
+	for _, level := range reasoningLevels {
+		if level.Effort == r.Effort {
+			return level
+		}
+	}
+	return reasoningLevels[1] // low
+}
+
+// AnthropicBudget returns thinking.budget_tokens, keeping it within Anthropic's lower bound
+func (r *Reasoning) AnthropicBudget() int64 {
+	budget := r.BudgetTokens
+	if budget <= 0 {
+		budget = r.level().AnthropicBudget
+	}
+	if budget < MinAnthropicThinkingBudget {
+		budget = MinAnthropicThinkingBudget
+	}
+	return budget
+}
+
+// AnthropicThinking returns the Anthropic thinking config for this reasoning
+func (r *Reasoning) AnthropicThinking() anthropic.ThinkingConfigParamUnion {
+	if !r.Enabled() {
+		return anthropic.ThinkingConfigParamUnion{OfDisabled: &anthropic.ThinkingConfigDisabledParam{}}
+	}
+	return anthropic.ThinkingConfigParamOfEnabled(r.AnthropicBudget())
+}
+
+// GeminiThinkingConfig returns the Gemini thinking config for model. Gemini 2.x models take a
+// token budget, later models a thinking level; Gemini 3 Pro only supports low and high.
+// Returns nil when reasoning is off but the model cannot disable thinking.
+func (r *Reasoning) GeminiThinkingConfig(model string) *genai.ThinkingConfig {
+	isPro := strings.Contains(strings.ToLower(model), "pro")
+	if !r.Enabled() {
+		if usesGeminiThinkingBudget(model) && !isPro {
+			zero := int32(0)
+			return &genai.ThinkingConfig{ThinkingBudget: &zero}
+		}
+		return nil
+	}
+
+	config := &genai.ThinkingConfig{IncludeThoughts: true}
+	if usesGeminiThinkingBudget(model) {
+		budget := r.level().GeminiBudget
+		if r.BudgetTokens > 0 {
+			budget = int32(r.BudgetTokens)
+		}
+		config.ThinkingBudget = &budget
+		return config
+	}
+
+	config.ThinkingLevel = r.level().GeminiLevel
+	if isPro {
+		switch config.ThinkingLevel {
+		case genai.ThinkingLevelMinimal, genai.ThinkingLevelLow:
+			config.ThinkingLevel = genai.ThinkingLevelLow
+		default:
+			config.ThinkingLevel = genai.ThinkingLevelHigh
+		}
+	}
+	return config
+}
+
+// usesGeminiThinkingBudget reports whether model predates thinking levels (Gemini 2.x)
+func usesGeminiThinkingBudget(model string) bool {
+	model = strings.ToLower(model)
+	return strings.Contains(model, "gemini-2") || strings.Contains(model, "2.5")
+}
@@ -0,0 +1,104 @@
+package request
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+)
+
+// Input modalities a Chat Completions request may need besides text
+const (
+	ModalityImage = "image"
+	ModalityFile  = "file"
+)
+
+// UnsupportedModalityError is returned when a request carries content the target model cannot read
+type UnsupportedModalityError struct {
+	Model    string
+	Modality string
+}
+
+func (e *UnsupportedModalityError) Error() string {
+	return fmt.Sprintf("model '%s' does not accept %s input; route requests with %s content to a multimodal model", e.Model, e.Modality, e.Modality)
+}
+
+// visionModelMarkers identify multimodal variants of otherwise text-only families (qwen-vl, glm-4.5v, ...)
+var visionModelMarkers = []string{"vision", "-vl", "vl-", "4.5v", "4.6v", "omni"}
+
+// textOnlyModelPatterns match model families known to reject image input
+var textOnlyModelPatterns = []string{
+	"deepseek-chat", "deepseek-reasoner", "deepseek-coder", "deepseek-v3", "deepseek-r1",
+	"o1-mini", "o3-mini", "gpt-3.5",
+	"qwen-turbo", "qwen-plus", "qwen-max", "qwen3-coder", "qwq",
+	"kimi-k2", "glm-4.5", "glm-4.6", "minimax-m2", "codestral",
+}
+
+// fileInputModelPrefixes are the model families that accept Chat Completions file (PDF) parts
+var fileInputModelPrefixes = []string{"gpt-4o", "gpt-4.1", "gpt-5", "o1", "o3", "o4", "gemini", "claude"}
+
+// ModelSupportsModality reports whether a model accepts the given input modality.
+// Unknown models are assumed to accept images but not files.
+func ModelSupportsModality(model, modality string) bool {
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:] // strip vendor prefixes such as "openai/gpt-4o"
+	}
+
+	switch modality {
+	case ModalityImage:
+		for _, marker := range visionModelMarkers {
+			if strings.Contains(name, marker) {
+				return true
+			}
+		}
+		for _, pattern := range textOnlyModelPatterns {
+			if strings.Contains(name, pattern) {
+				return false
+			}
+		}
+		return true
+	case ModalityFile:
+		for _, prefix := range fileInputModelPrefixes {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// RequiredModalities lists the non-text modalities used by the user messages of a request
+func RequiredModalities(req *openai.ChatCompletionNewParams) []string {
+	var hasImage, hasFile bool
+	for _, msg := range req.Messages {
+		if msg.OfUser == nil {
+			continue
+		}
+		for _, part := range msg.OfUser.Content.OfArrayOfContentParts {
+			hasImage = hasImage || part.OfImageURL != nil
+			hasFile = hasFile || part.OfFile != nil
+		}
+	}
+
+	var modalities []string
+	if hasImage {
+		modalities = append(modalities, ModalityImage)
+	}
+	if hasFile {
+		modalities = append(modalities, ModalityFile)
+	}
+	return modalities
+}
+
+// CheckModelModalities returns an UnsupportedModalityError when the request carries images or
+// files the target model cannot read, instead of letting them fail upstream or be ignored
+func CheckModelModalities(req *openai.ChatCompletionNewParams, model string) error {
+	for _, modality := range RequiredModalities(req) {
+		if !ModelSupportsModality(model, modality) {
+			return &UnsupportedModalityError{Model: model, Modality: modality}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/shared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+func TestReasoningMapping(t *testing.T) {
+	tests := []struct {
+		budget int64
+		effort protocol.ReasoningEffort
+	}{
+		{1024, protocol.ReasoningEffortMinimal},
+		{2000, protocol.ReasoningEffortLow},
+		{10000, protocol.ReasoningEffortMedium},
+		{16000, protocol.ReasoningEffortHigh},
+		{64000, protocol.ReasoningEffortXHigh},
+	}
+	for _, tt := range tests {
+		reasoning := protocol.ReasoningFromBudget(tt.budget)
+		assert.Equal(t, tt.effort, reasoning.Effort, "budget %d", tt.budget)
+		assert.Equal(t, tt.budget, reasoning.AnthropicBudget(), "explicit budgets are kept")
+	}
+
+	medium := protocol.ReasoningFromEffort("medium")
+	assert.Equal(t, int64(10000), medium.AnthropicBudget())
+	assert.Equal(t, int32(8192), *medium.GeminiThinkingConfig("gemini-2.5-flash").ThinkingBudget)
+	assert.Equal(t, genai.ThinkingLevelMedium, medium.GeminiThinkingConfig("gemini-3-flash").ThinkingLevel)
+	assert.Equal(t, genai.ThinkingLevelHigh, medium.GeminiThinkingConfig("gemini-3-pro-preview").ThinkingLevel)
+
+	assert.Nil(t, protocol.ReasoningFromEffort(""))
+	assert.False(t, protocol.ReasoningFromEffort("none").Enabled())
+	assert.Equal(t, protocol.ReasoningEffortMedium, protocol.ReasoningFromBudget(-1).Effort, "Gemini dynamic budget")
+}
+
+func TestConvertOpenAIToAnthropicRequestReasoningEffort(t *testing.T) {
+	req := &openai.ChatCompletionNewParams{
+		Model:           "claude-sonnet-4-5",
+		Messages:        []openai.ChatCompletionMessageParamUnion{openai.UserMessage("Think hard")},
+		MaxTokens:       openai.Int(4096),
+		ReasoningEffort: shared.ReasoningEffortHigh,
+	}
+
+	params := ConvertOpenAIToAnthropicRequest(req, 8192)
+	require.NotNil(t, params.Thinking.OfEnabled)
+	assert.Equal(t, int64(24576), params.Thinking.OfEnabled.BudgetTokens)
+	assert.Greater(t, params.MaxTokens, params.Thinking.OfEnabled.BudgetTokens, "max_tokens must exceed the budget")
+}
+
+func TestConvertAnthropicToOtherDialectsThinking(t *testing.T) {
+	req := &anthropic.MessageNewParams{
+		Model:     "gemini-2.5-pro",
+		MaxTokens: 16000,
+		Thinking:  anthropic.ThinkingConfigParamOfEnabled(8000),
+		Messages:  []anthropic.MessageParam{anthropic.NewUserMessage(anthropic.NewTextBlock("Hi"))},
+	}
+
+	_, config := ConvertAnthropicToOpenAIRequest(req, true)
+	assert.True(t, config.HasThinking)
+	assert.Equal(t, shared.ReasoningEffortMedium, config.ReasoningEffort)
+
+	_, _, googleConfig := ConvertAnthropicToGoogleRequest(req, 16000)
+	require.NotNil(t, googleConfig.ThinkingConfig)
+	assert.True(t, googleConfig.ThinkingConfig.IncludeThoughts)
+	assert.Equal(t, int32(8000), *googleConfig.ThinkingConfig.ThinkingBudget)
+
+	beta := &anthropic.BetaMessageNewParams{
+		Model:     "gpt-5",
+		MaxTokens: 16000,
+		Thinking:  anthropic.BetaThinkingConfigParamOfEnabled(2048),
+		Messages: []anthropic.BetaMessageParam{{
+			Role:    anthropic.BetaMessageParamRoleUser,
+			Content: []anthropic.BetaContentBlockParamUnion{{OfText: &anthropic.BetaTextBlockParam{Text: "Hi"}}},
+		}},
+	}
+	responsesReq := ConvertAnthropicBetaToResponsesRequest(beta)
+	assert.Equal(t, shared.ReasoningEffortLow, responsesReq.Reasoning.Effort)
+	assert.Equal(t, shared.ReasoningSummaryAuto, responsesReq.Reasoning.Summary)
+}
+
+func TestConvertGoogleToOtherDialectsThinking(t *testing.T) {
+	config := &genai.GenerateContentConfig{
+		MaxOutputTokens: 2048,
+		ThinkingConfig:  &genai.ThinkingConfig{ThinkingLevel: genai.ThinkingLevelHigh},
+	}
+	contents := []*genai.Content{genai.NewContentFromText("Hi", genai.RoleUser)}
+
+	openaiReq := ConvertGoogleToOpenAIRequest("gpt-5", contents, config)
+	assert.Equal(t, shared.ReasoningEffortHigh, openaiReq.ReasoningEffort)
+
+	params := ConvertGoogleToAnthropicRequest("claude-sonnet-4-5", contents, config)
+	require.NotNil(t, params.Thinking.OfEnabled)
+	assert.Greater(t, params.MaxTokens, params.Thinking.OfEnabled.BudgetTokens)
+}
+
+func TestConvertAnthropicToOpenAIClampsReasoningEffort(t *testing.T) {
+	// Claude Code's default thinking budget maps to xhigh, which most OpenAI-compatible backends reject
+	req := &anthropic.MessageNewParams{
+		MaxTokens: 32000,
+		Thinking:  anthropic.ThinkingConfigParamOfEnabled(31999),
+		Messages:  []anthropic.MessageParam{anthropic.NewUserMessage(anthropic.NewTextBlock("Hi"))},
+	}
+	provider := &typ.Provider{Name: "compat", APIBase: "https://api.example.com/v1"}
+
+	openaiReq := ConvertAnthropicToOpenAIRequestWithProvider(req, true, provider, "qwen3-max")
+	assert.Equal(t, shared.ReasoningEffortHigh, openaiReq.ReasoningEffort)
+
+	openaiReq = ConvertAnthropicToOpenAIRequestWithProvider(req, true, provider, "gpt-5.2")
+	assert.Equal(t, shared.ReasoningEffortXhigh, openaiReq.ReasoningEffort)
+
+	req.Thinking = anthropic.ThinkingConfigParamOfEnabled(1024)
+	openaiReq = ConvertAnthropicToOpenAIRequestWithProvider(req, true, provider, "qwen3-max")
+	assert.Equal(t, shared.ReasoningEffortLow, openaiReq.ReasoningEffort)
+
+	openaiReq = ConvertAnthropicToOpenAIRequestWithProvider(req, true, provider, "gpt-5-mini")
+	assert.Equal(t, shared.ReasoningEffortMinimal, openaiReq.ReasoningEffort)
+}
@@ -5,18 +5,21 @@ import (
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/openai/openai-go/v3/responses"
+	"github.com/openai/openai-go/v3/shared"
 
+	"github.com/tingly-dev/tingly-box/internal/protocol"
 	"github.com/tingly-dev/tingly-box/internal/typ"
 )
 
 // ConvertAnthropicBetaToResponsesRequestWithProvider converts Anthropic beta request to OpenAI Responses API format
-// and applies provider-specific transformations
+// and applies provider-specific transformations. Only the given server tools are kept; the others are stripped.
 func ConvertAnthropicBetaToResponsesRequestWithProvider(
 	anthropicReq *anthropic.BetaMessageNewParams,
 	provider *typ.Provider,
 	model string,
+	serverTools []protocol.ServerTool,
 ) responses.ResponseNewParams {
-	responsesReq := ConvertAnthropicBetaToResponsesRequest(anthropicReq)
+	responsesReq := convertAnthropicBetaToResponsesRequest(anthropicReq, serverTools)
 	responsesReq.Model = model
 	return responsesReq
 }
@@ -24,6 +27,11 @@ func ConvertAnthropicBetaToResponsesRequestWithProvider(
 // ConvertAnthropicBetaToResponsesRequest converts Anthropic beta request to OpenAI Responses API format
 // The Responses API has a different structure than Chat Completions
 func ConvertAnthropicBetaToResponsesRequest(anthropicReq *anthropic.BetaMessageNewParams) responses.ResponseNewParams {
+	serverTools, _ := protocol.ServerToolBackendResponses.Partition(protocol.AnthropicBetaServerTools(anthropicReq.Tools))
+	return convertAnthropicBetaToResponsesRequest(anthropicReq, serverTools)
+}
+
+func convertAnthropicBetaToResponsesRequest(anthropicReq *anthropic.BetaMessageNewParams, serverTools []protocol.ServerTool) responses.ResponseNewParams {
 	params := responses.ResponseNewParams{}
 
 	// Convert system messages to Instructions (system/developer role)
@@ -72,14 +80,22 @@ func ConvertAnthropicBetaToResponsesRequest(anthropicReq *anthropic.BetaMessageN
 		params.TopP = ParamOpt(anthropicReq.TopP.Value)
 	}
 
+	// Map extended thinking onto reasoning; ask for summaries so they can come back as thinking blocks
+	if reasoning := protocol.ReasoningFromAnthropicBeta(anthropicReq.Thinking); reasoning != nil {
+		params.Reasoning = shared.ReasoningParam{Effort: shared.ReasoningEffort(reasoning.Effort)}
+		if reasoning.Enabled() {
+			params.Reasoning.Summary = shared.ReasoningSummaryAuto
+		}
+	}
+
 	// Convert tools from Anthropic format to Responses API format
 	if len(anthropicReq.Tools) > 0 {
-		params.Tools = ConvertAnthropicBetaToolsToResponses(anthropicReq.Tools)
+		params.Tools = ConvertAnthropicBetaToolsToResponses(anthropicReq.Tools, serverTools)
 	}
 
 	// Convert tool choice
 	if anthropicReq.ToolChoice.OfAuto != nil || anthropicReq.ToolChoice.OfTool != nil ||
-		anthropicReq.ToolChoice.OfAny != nil {
+		anthropicReq.ToolChoice.OfAny != nil || anthropicReq.ToolChoice.OfNone != nil {
 		params.ToolChoice = ConvertAnthropicBetaToolChoiceToResponses(&anthropicReq.ToolChoice)
 	}
 
@@ -98,8 +114,9 @@ func ConvertAnthropicBetaToResponsesRequestWithStreaming(
 	anthropicReq *anthropic.BetaMessageNewParams,
 	provider *typ.Provider,
 	model string,
+	serverTools []protocol.ServerTool,
 ) responses.ResponseNewParams {
-	return ConvertAnthropicBetaToResponsesRequestWithProvider(anthropicReq, provider, model)
+	return ConvertAnthropicBetaToResponsesRequestWithProvider(anthropicReq, provider, model, serverTools)
 }
 
 // convertBetaUserMessageToResponsesInput converts Anthropic beta user message to Responses API input items
@@ -145,6 +162,9 @@ func convertBetaUserMessageToResponsesInput(msg anthropic.BetaMessageParam) []re
 				})
 			}
 		}
+	} else if content := betaUserContentWithImages(msg.Content); content != nil {
+		// Mixed text and image user message
+		items = append(items, googleResponsesMessage(responses.EasyInputMessageRoleUser, content))
 	} else {
 		// Simple text-only user message
 		contentStr := ConvertBetaContentBlocksToString(msg.Content)
@@ -165,6 +185,42 @@ func convertBetaUserMessageToResponsesInput(msg anthropic.BetaMessageParam) []re
 	return items
 }
 
+// betaUserContentWithImages converts user text and image blocks to Responses input content, in order.
+// It returns nil when the message has no images, so text-only messages keep the plain string form.
+func betaUserContentWithImages(blocks []anthropic.BetaContentBlockParamUnion) responses.ResponseInputMessageContentListParam {
+	var content responses.ResponseInputMessageContentListParam
+	var hasImage bool
+	for _, block := range blocks {
+		switch {
+		case block.OfText != nil:
+			content = append(content, responses.ResponseInputContentUnionParam{
+				OfInputText: &responses.ResponseInputTextParam{Text: block.OfText.Text},
+			})
+		case block.OfImage != nil:
+			var url string
+			switch source := block.OfImage.Source; {
+			case source.OfBase64 != nil:
+				url = "data:" + string(source.OfBase64.MediaType) + ";base64," + source.OfBase64.Data
+			case source.OfURL != nil:
+				url = source.OfURL.URL
+			default:
+				continue
+			}
+			content = append(content, responses.ResponseInputContentUnionParam{
+				OfInputImage: &responses.ResponseInputImageParam{
+					ImageURL: ParamOpt(url),
+					Detail:   responses.ResponseInputImageDetailAuto,
+				},
+			})
+			hasImage = true
+		}
+	}
+	if !hasImage {
+		return nil
+	}
+	return content
+}
+
 // convertBetaAssistantMessageToResponsesInput converts Anthropic beta assistant message to Responses API input items
 // Handles text content, tool_use blocks, and thinking blocks
 func convertBetaAssistantMessageToResponsesInput(msg anthropic.BetaMessageParam) []responses.ResponseInputItemUnionParam {
@@ -226,8 +282,9 @@ func convertBetaAssistantMessageToResponsesInput(msg anthropic.BetaMessageParam)
 	return items
 }
 
-// ConvertAnthropicBetaToolsToResponses converts Anthropic beta tools to Responses API format
-func ConvertAnthropicBetaToolsToResponses(tools []anthropic.BetaToolUnionParam) []responses.ToolUnionParam {
+// ConvertAnthropicBetaToolsToResponses converts Anthropic beta tools to Responses API format.
+// Server tools not listed in serverTools are stripped.
+func ConvertAnthropicBetaToolsToResponses(tools []anthropic.BetaToolUnionParam, serverTools []protocol.ServerTool) []responses.ToolUnionParam {
 	if len(tools) == 0 {
 		return nil
 	}
@@ -235,6 +292,21 @@ func ConvertAnthropicBetaToolsToResponses(tools []anthropic.BetaToolUnionParam)
 	out := make([]responses.ToolUnionParam, 0, len(tools))
 
 	for _, t := range tools {
+		if t.OfWebSearchTool20250305 != nil {
+			if hasServerTool(serverTools, string(t.OfWebSearchTool20250305.Type.Default())) {
+				out = append(out, responses.ToolUnionParam{OfWebSearch: convertBetaWebSearchToolToResponses(t.OfWebSearchTool20250305)})
+			}
+			continue
+		}
+		// Decoded client requests carry server tools as plain tools with a versioned type
+		if toolType := t.GetType(); toolType != nil {
+			if kind, ok := protocol.ServerToolKindOf(*toolType); ok {
+				if kind == protocol.ServerToolWebSearch && hasServerTool(serverTools, *toolType) {
+					out = append(out, responses.ToolUnionParam{OfWebSearch: &responses.WebSearchToolParam{Type: responses.WebSearchToolTypeWebSearch}})
+				}
+				continue
+			}
+		}
 		tool := t.OfTool
 		if tool == nil {
 			continue
@@ -270,6 +342,31 @@ func ConvertAnthropicBetaToolsToResponses(tools []anthropic.BetaToolUnionParam)
 	return out
 }
 
+// convertBetaWebSearchToolToResponses maps Anthropic's server-side web search onto the Responses
+// built-in web_search tool. Blocked domains and max_uses have no Responses equivalent.
+func convertBetaWebSearchToolToResponses(tool *anthropic.BetaWebSearchTool20250305Param) *responses.WebSearchToolParam {
+	webSearch := &responses.WebSearchToolParam{Type: responses.WebSearchToolTypeWebSearch}
+	if len(tool.AllowedDomains) > 0 {
+		webSearch.Filters = responses.WebSearchToolFiltersParam{AllowedDomains: tool.AllowedDomains}
+	}
+	if loc := tool.UserLocation; loc.City.Valid() || loc.Country.Valid() || loc.Region.Valid() || loc.Timezone.Valid() {
+		webSearch.UserLocation = responses.WebSearchToolUserLocationParam{Type: "approximate"}
+		if loc.City.Valid() {
+			webSearch.UserLocation.City = ParamOpt(loc.City.Value)
+		}
+		if loc.Country.Valid() {
+			webSearch.UserLocation.Country = ParamOpt(loc.Country.Value)
+		}
+		if loc.Region.Valid() {
+			webSearch.UserLocation.Region = ParamOpt(loc.Region.Value)
+		}
+		if loc.Timezone.Valid() {
+			webSearch.UserLocation.Timezone = ParamOpt(loc.Timezone.Value)
+		}
+	}
+	return webSearch
+}
+
 // ConvertAnthropicBetaToolChoiceToResponses converts Anthropic beta tool_choice to Responses API format
 func ConvertAnthropicBetaToolChoiceToResponses(tc *anthropic.BetaToolChoiceUnionParam) responses.ResponseNewParamsToolChoiceUnion {
 	if tc.OfAuto != nil {
@@ -288,10 +385,16 @@ func ConvertAnthropicBetaToolChoiceToResponses(tc *anthropic.BetaToolChoiceUnion
 		}
 	}
 
-	// OfAny (Anthropic's "required") - map to auto as Responses API doesn't have direct equivalent
+	// OfAny (Anthropic's "use some tool") is Responses' "required"
 	if tc.OfAny != nil {
 		return responses.ResponseNewParamsToolChoiceUnion{
-			OfToolChoiceMode: ParamOpt(responses.ToolChoiceOptions("auto")),
+			OfToolChoiceMode: ParamOpt(responses.ToolChoiceOptionsRequired),
+		}
+	}
+
+	if tc.OfNone != nil {
+		return responses.ResponseNewParamsToolChoiceUnion{
+			OfToolChoiceMode: ParamOpt(responses.ToolChoiceOptionsNone),
 		}
 	}
 
@@ -9,6 +9,7 @@ import (
 	"github.com/openai/openai-go/v3/packages/param"
 	"github.com/openai/openai-go/v3/shared"
 
+	"github.com/tingly-dev/tingly-box/internal/protocol"
 	"github.com/tingly-dev/tingly-box/internal/protocol/request/transformer"
 	"github.com/tingly-dev/tingly-box/internal/typ"
 )
@@ -68,6 +69,10 @@ func ConvertAnthropicToolsToOpenAI(tools []anthropic.ToolUnionParam) []openai.Ch
 		if tool == nil {
 			continue
 		}
+		// Server tools have no function schema; backends with a native equivalent get it separately
+		if _, ok := protocol.ServerToolKindOf(string(tool.Type)); ok {
+			continue
+		}
 
 		// Convert Anthropic input schema to OpenAI function parameters
 		var parameters map[string]interface{}
@@ -185,23 +190,13 @@ func ConvertAnthropicToOpenAIRequest(anthropicReq *anthropic.MessageNewParams, c
 		HasThinking:     isThinking,
 		ReasoningEffort: "low", // Default to "low" for OpenAI-compatible APIs
 	}
+	if reasoning := protocol.ReasoningFromAnthropic(anthropicReq.Thinking); reasoning.Enabled() {
+		config.ReasoningEffort = shared.ReasoningEffort(reasoning.Effort)
+	}
 
 	return openaiReq, config
 }
 
-// convertToolResultContent extracts the content from a tool result block
-// The content is a list of content blocks (typically just one text block)
-func convertToolResultContent(content []anthropic.ToolResultBlockParamContentUnion) string {
-	var result strings.Builder
-	for _, c := range content {
-		// Handle text content
-		if c.OfText != nil {
-			result.WriteString(c.OfText.Text)
-		}
-	}
-	return result.String()
-}
-
 // ConvertContentBlocksToString converts Anthropic content blocks to string
 func ConvertContentBlocksToString(blocks []anthropic.ContentBlockParamUnion) string {
 	var result strings.Builder
@@ -301,54 +296,11 @@ func convertAnthropicAssistantMessageToOpenAI(msg anthropic.MessageParam) openai
 }
 
 // convertAnthropicUserMessageToOpenAI converts Anthropic user message to OpenAI format
-// This handles text content and tool_result blocks
+// This handles text, image and document content and tool_result blocks
 // tool_result blocks in Anthropic become separate role="tool" messages in OpenAI
 // Returns a slice of messages because tool results become separate messages
 func convertAnthropicUserMessageToOpenAI(msg anthropic.MessageParam) []openai.ChatCompletionMessageParamUnion {
-	var result []openai.ChatCompletionMessageParamUnion
-	var textContent string
-	var hasToolResult bool
-
-	// First, check if there are any tool_result blocks
-	for _, block := range msg.Content {
-		if block.OfToolResult != nil {
-			hasToolResult = true
-			break
-		}
-	}
-
-	// Process content blocks
-	if hasToolResult {
-		// When there are tool_result blocks, we need to create separate messages
-		for _, block := range msg.Content {
-			if block.OfText != nil {
-				textContent += block.OfText.Text
-			} else if block.OfToolResult != nil {
-				// Convert tool_result to OpenAI role="tool" message
-				toolMsg := map[string]interface{}{
-					"role":         "tool",
-					"tool_call_id": block.OfToolResult.ToolUseID,
-					"content":      convertToolResultContent(block.OfToolResult.Content),
-				}
-				msgBytes, _ := json.Marshal(toolMsg)
-				var toolResultMsg openai.ChatCompletionMessageParamUnion
-				_ = json.Unmarshal(msgBytes, &toolResultMsg)
-				result = append(result, toolResultMsg)
-			}
-		}
-		// If there was text content alongside tool results, add it as a user message
-		if textContent != "" {
-			result = append(result, openai.UserMessage(textContent))
-		}
-	} else {
-		// Simple text-only user message
-		contentStr := ConvertContentBlocksToString(msg.Content)
-		if contentStr != "" {
-			result = append(result, openai.UserMessage(contentStr))
-		}
-	}
-
-	return result
+	return convertAnthropicUserBlocksToOpenAI(decodeAnthropicBlocks(msg.Content))
 }
 
 // IsThinkingEnabled checks if thinking mode is enabled in the Anthropic request
@@ -431,6 +383,9 @@ func ConvertAnthropicBetaToOpenAIRequest(anthropicReq *anthropic.BetaMessageNewP
 		HasThinking:     isThinking,
 		ReasoningEffort: "low", // Default to "low" for OpenAI-compatible APIs
 	}
+	if reasoning := protocol.ReasoningFromAnthropicBeta(anthropicReq.Thinking); reasoning.Enabled() {
+		config.ReasoningEffort = shared.ReasoningEffort(reasoning.Effort)
+	}
 
 	return openaiReq, config
 }
@@ -448,6 +403,10 @@ func ConvertAnthropicBetaToolsToOpenAI(tools []anthropic.BetaToolUnionParam) []o
 		if tool == nil {
 			continue
 		}
+		// Server tools have no function schema; backends with a native equivalent get it separately
+		if _, ok := protocol.ServerToolKindOf(string(tool.Type)); ok {
+			continue
+		}
 
 		// Convert Anthropic input schema to OpenAI function parameters
 		var parameters map[string]interface{}
@@ -592,50 +551,7 @@ func convertAnthropicBetaAssistantMessageToOpenAI(msg anthropic.BetaMessageParam
 
 // convertAnthropicBetaUserMessageToOpenAI converts Anthropic beta user message to OpenAI format
 func convertAnthropicBetaUserMessageToOpenAI(msg anthropic.BetaMessageParam) []openai.ChatCompletionMessageParamUnion {
-	var result []openai.ChatCompletionMessageParamUnion
-	var textContent string
-	var hasToolResult bool
-
-	// First, check if there are any tool_result blocks
-	for _, block := range msg.Content {
-		if block.OfToolResult != nil {
-			hasToolResult = true
-			break
-		}
-	}
-
-	// Process content blocks
-	if hasToolResult {
-		// When there are tool_result blocks, we need to create separate messages
-		for _, block := range msg.Content {
-			if block.OfText != nil {
-				textContent += block.OfText.Text
-			} else if block.OfToolResult != nil {
-				// Convert tool_result to OpenAI role="tool" message
-				toolMsg := map[string]interface{}{
-					"role":         "tool",
-					"tool_call_id": block.OfToolResult.ToolUseID,
-					"content":      convertBetaToolResultContent(block.OfToolResult.Content),
-				}
-				msgBytes, _ := json.Marshal(toolMsg)
-				var toolResultMsg openai.ChatCompletionMessageParamUnion
-				_ = json.Unmarshal(msgBytes, &toolResultMsg)
-				result = append(result, toolResultMsg)
-			}
-		}
-		// If there was text content alongside tool results, add it as a user message
-		if textContent != "" {
-			result = append(result, openai.UserMessage(textContent))
-		}
-	} else {
-		// Simple text-only user message
-		contentStr := ConvertBetaContentBlocksToString(msg.Content)
-		if contentStr != "" {
-			result = append(result, openai.UserMessage(contentStr))
-		}
-	}
-
-	return result
+	return convertAnthropicUserBlocksToOpenAI(decodeAnthropicBlocks(msg.Content))
 }
 
 // convertBetaToolResultContent extracts the content from a beta tool result block
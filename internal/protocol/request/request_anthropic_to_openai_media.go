@@ -0,0 +1,218 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+)
+
+// anthropicBlock is a dialect-neutral view of an Anthropic content block. Both the v1 and the
+// beta SDK params marshal to the same wire format, so user messages of either are decoded into
+// this shape and converted by a single code path.
+type anthropicBlock struct {
+	Type      string               `json:"type"`
+	Text      string               `json:"text,omitempty"`
+	Title     string               `json:"title,omitempty"`
+	Source    *anthropicSource     `json:"source,omitempty"`
+	ToolUseID string               `json:"tool_use_id,omitempty"`
+	Content   anthropicBlockOrText `json:"content,omitempty"`
+}
+
+// anthropicSource is the source of an image or document block
+type anthropicSource struct {
+	Type      string               `json:"type"` // base64, url, text, content, file
+	MediaType string               `json:"media_type,omitempty"`
+	Data      string               `json:"data,omitempty"`
+	URL       string               `json:"url,omitempty"`
+	FileID    string               `json:"file_id,omitempty"`
+	Content   anthropicBlockOrText `json:"content,omitempty"`
+}
+
+// anthropicBlockOrText is content given either as a plain string or as a list of blocks
+type anthropicBlockOrText []anthropicBlock
+
+func (c *anthropicBlockOrText) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = anthropicBlockOrText{{Type: "text", Text: text}}
+		return nil
+	}
+	var blocks []anthropicBlock
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return err
+	}
+	*c = blocks
+	return nil
+}
+
+// decodeAnthropicBlocks converts SDK content block params into the neutral block view
+func decodeAnthropicBlocks(content interface{}) []anthropicBlock {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil
+	}
+	var blocks []anthropicBlock
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return nil
+	}
+	return blocks
+}
+
+// convertAnthropicUserBlocksToOpenAI converts the blocks of an Anthropic user message to OpenAI messages.
+// tool_result blocks become role="tool" messages. OpenAI tool messages only carry text, so images and
+// documents returned by tools are forwarded in a user message right after the tool messages, together
+// with any content the user sent alongside the results.
+func convertAnthropicUserBlocksToOpenAI(blocks []anthropicBlock) []openai.ChatCompletionMessageParamUnion {
+	var result []openai.ChatCompletionMessageParamUnion
+	var userParts []openai.ChatCompletionContentPartUnionParam
+
+	for _, block := range blocks {
+		if block.Type != "tool_result" {
+			userParts = append(userParts, anthropicBlockToOpenAIParts(block)...)
+			continue
+		}
+
+		var text strings.Builder
+		var media []openai.ChatCompletionContentPartUnionParam
+		for _, part := range anthropicBlockToOpenAIParts(anthropicBlock{Type: "content", Content: block.Content}) {
+			if part.OfText != nil {
+				text.WriteString(part.OfText.Text)
+			} else {
+				media = append(media, part)
+			}
+		}
+
+		toolMsg := map[string]interface{}{
+			"role":         "tool",
+			"tool_call_id": block.ToolUseID,
+			"content":      text.String(),
+		}
+		msgBytes, _ := json.Marshal(toolMsg)
+		var toolResultMsg openai.ChatCompletionMessageParamUnion
+		_ = json.Unmarshal(msgBytes, &toolResultMsg)
+		result = append(result, toolResultMsg)
+
+		if len(media) > 0 {
+			userParts = append(userParts, openai.TextContentPart(fmt.Sprintf("Attachments returned by tool call %s:", block.ToolUseID)))
+			userParts = append(userParts, media...)
+		}
+	}
+
+	if msg, ok := openAIUserMessageFromParts(userParts); ok {
+		result = append(result, msg)
+	}
+	return result
+}
+
+// openAIUserMessageFromParts builds a user message, keeping plain string content when there is no media
+func openAIUserMessageFromParts(parts []openai.ChatCompletionContentPartUnionParam) (openai.ChatCompletionMessageParamUnion, bool) {
+	var text strings.Builder
+	textOnly := true
+	for _, part := range parts {
+		if part.OfText == nil {
+			textOnly = false
+			break
+		}
+		text.WriteString(part.OfText.Text)
+	}
+
+	if textOnly {
+		if text.Len() == 0 {
+			return openai.ChatCompletionMessageParamUnion{}, false
+		}
+		return openai.UserMessage(text.String()), true
+	}
+	return openai.UserMessage(parts), true
+}
+
+// anthropicBlockToOpenAIParts maps one Anthropic block to OpenAI content parts.
+// Blocks without an OpenAI equivalent (thinking, tool_use in a user turn) are dropped.
+func anthropicBlockToOpenAIParts(block anthropicBlock) []openai.ChatCompletionContentPartUnionParam {
+	switch block.Type {
+	case "text":
+		if block.Text == "" {
+			return nil
+		}
+		return []openai.ChatCompletionContentPartUnionParam{openai.TextContentPart(block.Text)}
+	case "content":
+		var parts []openai.ChatCompletionContentPartUnionParam
+		for _, child := range block.Content {
+			parts = append(parts, anthropicBlockToOpenAIParts(child)...)
+		}
+		return parts
+	case "image":
+		return anthropicImageToOpenAIParts(block.Source)
+	case "document":
+		return anthropicDocumentToOpenAIParts(block)
+	case "search_result":
+		var parts []openai.ChatCompletionContentPartUnionParam
+		if block.Title != "" {
+			parts = append(parts, openai.TextContentPart(block.Title+"\n"))
+		}
+		return append(parts, anthropicBlockToOpenAIParts(anthropicBlock{Type: "content", Content: block.Content})...)
+	}
+	return nil
+}
+
+// anthropicImageToOpenAIParts maps an image source to an image_url part
+func anthropicImageToOpenAIParts(source *anthropicSource) []openai.ChatCompletionContentPartUnionParam {
+	if source == nil {
+		return nil
+	}
+	var url string
+	switch source.Type {
+	case "base64":
+		url = fmt.Sprintf("data:%s;base64,%s", source.MediaType, source.Data)
+	case "url":
+		url = source.URL
+	case "file":
+		// Anthropic Files API ids cannot be resolved by other providers
+		return []openai.ChatCompletionContentPartUnionParam{openai.TextContentPart(fmt.Sprintf("[image file %s is not available to this model]", source.FileID))}
+	default:
+		return nil
+	}
+	return []openai.ChatCompletionContentPartUnionParam{
+		openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: url}),
+	}
+}
+
+// anthropicDocumentToOpenAIParts maps a document block: PDFs become file parts, text sources become text
+func anthropicDocumentToOpenAIParts(block anthropicBlock) []openai.ChatCompletionContentPartUnionParam {
+	source := block.Source
+	if source == nil {
+		return nil
+	}
+
+	var parts []openai.ChatCompletionContentPartUnionParam
+	if block.Title != "" && source.Type != "base64" {
+		parts = append(parts, openai.TextContentPart(block.Title+"\n"))
+	}
+
+	switch source.Type {
+	case "base64":
+		mediaType := source.MediaType
+		if mediaType == "" {
+			mediaType = "application/pdf"
+		}
+		filename := block.Title
+		if filename == "" {
+			filename = "document.pdf"
+		}
+		parts = append(parts, openai.FileContentPart(openai.ChatCompletionContentPartFileFileParam{
+			FileData: openai.String(fmt.Sprintf("data:%s;base64,%s", mediaType, source.Data)),
+			Filename: openai.String(filename),
+		}))
+	case "text":
+		parts = append(parts, openai.TextContentPart(source.Data))
+	case "content":
+		parts = append(parts, anthropicBlockToOpenAIParts(anthropicBlock{Type: "content", Content: source.Content})...)
+	case "url":
+		// Chat Completions file parts only accept inline data; pass the link on as text
+		parts = append(parts, openai.TextContentPart(fmt.Sprintf("[document: %s]", source.URL)))
+	case "file":
+		parts = append(parts, openai.TextContentPart(fmt.Sprintf("[document file %s is not available to this model]", source.FileID)))
+	}
+	return parts
+}
@@ -0,0 +1,127 @@
+package request
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertAnthropicToOpenAIRequestImagesAndDocuments(t *testing.T) {
+	req := &anthropic.MessageNewParams{
+		Model:     "gpt-4o",
+		MaxTokens: 1024,
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(
+				anthropic.NewTextBlock("What is in this screenshot?"),
+				anthropic.NewImageBlockBase64("image/png", "iVBORw0KGgo="),
+				anthropic.NewDocumentBlock(anthropic.Base64PDFSourceParam{Data: "JVBERi0xLjQ="}),
+			),
+		},
+	}
+
+	openaiReq, _ := ConvertAnthropicToOpenAIRequest(req, true)
+	require.Len(t, openaiReq.Messages, 1)
+
+	parts := openaiReq.Messages[0].OfUser.Content.OfArrayOfContentParts
+	require.Len(t, parts, 3)
+	assert.Equal(t, "What is in this screenshot?", parts[0].OfText.Text)
+	require.NotNil(t, parts[1].OfImageURL)
+	assert.Equal(t, "data:image/png;base64,iVBORw0KGgo=", parts[1].OfImageURL.ImageURL.URL)
+	require.NotNil(t, parts[2].OfFile)
+	assert.Equal(t, "data:application/pdf;base64,JVBERi0xLjQ=", parts[2].OfFile.File.FileData.Value)
+
+	assert.Equal(t, []string{ModalityImage, ModalityFile}, RequiredModalities(openaiReq))
+	assert.NoError(t, CheckModelModalities(openaiReq, "gpt-4o"))
+}
+
+func TestConvertAnthropicToOpenAIRequestToolResultScreenshot(t *testing.T) {
+	toolResult := anthropic.ToolResultBlockParam{
+		ToolUseID: "toolu_1",
+		Content: []anthropic.ToolResultBlockParamContentUnion{
+			{OfText: &anthropic.TextBlockParam{Text: "captured"}},
+			{OfImage: &anthropic.ImageBlockParam{Source: anthropic.ImageBlockParamSourceUnion{
+				OfBase64: &anthropic.Base64ImageSourceParam{Data: "AAAA", MediaType: "image/jpeg"},
+			}}},
+		},
+	}
+	req := &anthropic.MessageNewParams{
+		Model:     "gpt-4o",
+		MaxTokens: 1024,
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.ContentBlockParamUnion{OfToolResult: &toolResult}),
+		},
+	}
+
+	openaiReq, _ := ConvertAnthropicToOpenAIRequest(req, true)
+	require.Len(t, openaiReq.Messages, 2)
+
+	require.NotNil(t, openaiReq.Messages[0].OfTool)
+	toolJSON, err := json.Marshal(openaiReq.Messages[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"role":"tool","tool_call_id":"toolu_1","content":"captured"}`, string(toolJSON))
+
+	require.NotNil(t, openaiReq.Messages[1].OfUser)
+	parts := openaiReq.Messages[1].OfUser.Content.OfArrayOfContentParts
+	require.Len(t, parts, 2)
+	require.NotNil(t, parts[1].OfImageURL)
+	assert.Equal(t, "data:image/jpeg;base64,AAAA", parts[1].OfImageURL.ImageURL.URL)
+}
+
+func TestConvertAnthropicBetaToOpenAIRequestImages(t *testing.T) {
+	req := &anthropic.BetaMessageNewParams{
+		Model:     "gpt-4o",
+		MaxTokens: 1024,
+		Messages: []anthropic.BetaMessageParam{{
+			Role: anthropic.BetaMessageParamRoleUser,
+			Content: []anthropic.BetaContentBlockParamUnion{
+				{OfImage: &anthropic.BetaImageBlockParam{Source: anthropic.BetaImageBlockParamSourceUnion{
+					OfURL: &anthropic.BetaURLImageSourceParam{URL: "https://example.com/cat.png"},
+				}}},
+			},
+		}},
+	}
+
+	openaiReq, _ := ConvertAnthropicBetaToOpenAIRequest(req, true)
+	require.Len(t, openaiReq.Messages, 1)
+	parts := openaiReq.Messages[0].OfUser.Content.OfArrayOfContentParts
+	require.Len(t, parts, 1)
+	assert.Equal(t, "https://example.com/cat.png", parts[0].OfImageURL.ImageURL.URL)
+}
+
+func TestConvertAnthropicToOpenAIRequestTextOnlyKeepsString(t *testing.T) {
+	req := &anthropic.MessageNewParams{
+		Model:     "deepseek-chat",
+		MaxTokens: 1024,
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock("Hello"), anthropic.NewTextBlock(" world")),
+		},
+	}
+
+	openaiReq, _ := ConvertAnthropicToOpenAIRequest(req, true)
+	require.Len(t, openaiReq.Messages, 1)
+	assert.Equal(t, "Hello world", openaiReq.Messages[0].OfUser.Content.OfString.Value)
+	assert.NoError(t, CheckModelModalities(openaiReq, "deepseek-chat"))
+}
+
+func TestCheckModelModalities(t *testing.T) {
+	req := &anthropic.MessageNewParams{
+		Model:     "deepseek-chat",
+		MaxTokens: 1024,
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewImageBlockBase64("image/png", "AAAA")),
+		},
+	}
+	openaiReq, _ := ConvertAnthropicToOpenAIRequest(req, true)
+
+	err := CheckModelModalities(openaiReq, "deepseek-chat")
+	var modalityErr *UnsupportedModalityError
+	require.ErrorAs(t, err, &modalityErr)
+	assert.Equal(t, ModalityImage, modalityErr.Modality)
+
+	assert.NoError(t, CheckModelModalities(openaiReq, "qwen-vl-max"))
+	assert.True(t, ModelSupportsModality("openai/gpt-4.1-mini", ModalityFile))
+	assert.False(t, ModelSupportsModality("llama-3.2-90b-vision", ModalityFile))
+}
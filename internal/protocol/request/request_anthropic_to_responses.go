@@ -0,0 +1,39 @@
+package request
+
+import (
+	"encoding/json"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go/v3/responses"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+// ConvertAnthropicToResponsesRequestWithProvider converts Anthropic v1 request to OpenAI Responses API format
+// and applies provider-specific transformations. Only the given server tools are kept; the others are stripped.
+func ConvertAnthropicToResponsesRequestWithProvider(
+	anthropicReq *anthropic.MessageNewParams,
+	provider *typ.Provider,
+	model string,
+	serverTools []protocol.ServerTool,
+) responses.ResponseNewParams {
+	responsesReq := convertAnthropicBetaToResponsesRequest(toBetaMessageNewParams(anthropicReq), serverTools)
+	responsesReq.Model = model
+	return responsesReq
+}
+
+// ConvertAnthropicToResponsesRequest converts Anthropic v1 request to OpenAI Responses API format.
+// The v1 request is a subset of the beta one on the wire, so it is converted through the beta converter.
+func ConvertAnthropicToResponsesRequest(anthropicReq *anthropic.MessageNewParams) responses.ResponseNewParams {
+	return ConvertAnthropicBetaToResponsesRequest(toBetaMessageNewParams(anthropicReq))
+}
+
+// toBetaMessageNewParams re-decodes a v1 request as a beta one
+func toBetaMessageNewParams(anthropicReq *anthropic.MessageNewParams) *anthropic.BetaMessageNewParams {
+	var betaReq anthropic.BetaMessageNewParams
+	if raw, err := json.Marshal(anthropicReq); err == nil {
+		_ = json.Unmarshal(raw, &betaReq)
+	}
+	return &betaReq
+}
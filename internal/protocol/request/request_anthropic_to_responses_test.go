@@ -0,0 +1,71 @@
+package request
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+)
+
+func TestConvertAnthropicToResponsesRequest(t *testing.T) {
+	var req anthropic.MessageNewParams
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"model": "claude-sonnet-4-5",
+		"max_tokens": 1024,
+		"system": [{"type": "text", "text": "Be brief."}],
+		"thinking": {"type": "enabled", "budget_tokens": 4096},
+		"tool_choice": {"type": "any"},
+		"tools": [
+			{"name": "get_weather", "description": "Weather by city", "input_schema": {"type": "object", "properties": {"city": {"type": "string"}}, "required": ["city"]}},
+			{"type": "web_search_20250305", "name": "web_search", "allowed_domains": ["example.com"], "user_location": {"type": "approximate", "city": "Paris"}}
+		],
+		"messages": [
+			{"role": "user", "content": "Weather in Paris?"},
+			{"role": "assistant", "content": [{"type": "tool_use", "id": "toolu_1", "name": "get_weather", "input": {"city": "Paris"}}]},
+			{"role": "user", "content": [{"type": "tool_result", "tool_use_id": "toolu_1", "content": "Sunny"}]}
+		]
+	}`), &req))
+
+	params := ConvertAnthropicToResponsesRequestWithProvider(&req, nil, "gpt-5-codex", protocol.AnthropicServerTools(req.Tools))
+	raw, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &got))
+	assert.Equal(t, "gpt-5-codex", got["model"])
+	assert.Equal(t, "Be brief.", got["instructions"])
+	assert.EqualValues(t, 1024, got["max_output_tokens"])
+	assert.Equal(t, "required", got["tool_choice"])
+	assert.Equal(t, map[string]interface{}{"effort": "low", "summary": "auto"}, got["reasoning"])
+
+	tools := got["tools"].([]interface{})
+	require.Len(t, tools, 2)
+	assert.Equal(t, "function", tools[0].(map[string]interface{})["type"])
+	assert.Equal(t, "web_search", tools[1].(map[string]interface{})["type"])
+
+	input := got["input"].([]interface{})
+	require.Len(t, input, 3)
+	assert.Equal(t, "function_call", input[1].(map[string]interface{})["type"])
+	assert.Equal(t, "toolu_1", input[1].(map[string]interface{})["call_id"])
+	assert.JSONEq(t, `{"city":"Paris"}`, input[1].(map[string]interface{})["arguments"].(string))
+	assert.Equal(t, "function_call_output", input[2].(map[string]interface{})["type"])
+	assert.Equal(t, "toolu_1", input[2].(map[string]interface{})["call_id"])
+	assert.Equal(t, "Sunny", input[2].(map[string]interface{})["output"])
+}
+
+func TestConvertAnthropicBetaWebSearchToolToResponses(t *testing.T) {
+	tools := ConvertAnthropicBetaToolsToResponses([]anthropic.BetaToolUnionParam{{
+		OfWebSearchTool20250305: &anthropic.BetaWebSearchTool20250305Param{
+			AllowedDomains: []string{"example.com"},
+			UserLocation:   anthropic.BetaWebSearchTool20250305UserLocationParam{City: anthropic.String("Paris")},
+		},
+	}}, []protocol.ServerTool{{Kind: protocol.ServerToolWebSearch, Type: "web_search_20250305"}})
+	require.Len(t, tools, 1)
+	require.NotNil(t, tools[0].OfWebSearch)
+	assert.Equal(t, []string{"example.com"}, tools[0].OfWebSearch.Filters.AllowedDomains)
+	assert.Equal(t, "Paris", tools[0].OfWebSearch.UserLocation.City.Value)
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/openai/openai-go/v3"
 	"google.golang.org/genai"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
 )
 
 // normalizeSchemaTypes converts lowercase JSON Schema types to Google's uppercase format
@@ -117,7 +119,11 @@ func ConvertOpenAIToGoogleRequest(req *openai.ChatCompletionNewParams, defaultMa
 						if text, ok := partMap["text"].(string); ok {
 							content.Parts = append(content.Parts, genai.NewPartFromText(text))
 						}
-						// Handle images or other content types if needed
+						if imageURL, ok := partMap["image_url"].(map[string]interface{}); ok {
+							if url, ok := imageURL["url"].(string); ok && url != "" {
+								content.Parts = append(content.Parts, googleImageURLPart(url))
+							}
+						}
 					}
 				}
 			}
@@ -235,6 +241,14 @@ func ConvertOpenAIToGoogleRequest(req *openai.ChatCompletionNewParams, defaultMa
 		config.ToolConfig = ConvertOpenAIToGoogleToolChoice(&req.ToolChoice)
 	}
 
+	// Map response_format onto Gemini's JSON mode
+	ApplyStructuredOutputToGoogle(config, StructuredOutputFromOpenAI(req))
+
+	// Map reasoning_effort onto Gemini's thinking config
+	if reasoning := protocol.ReasoningFromEffort(string(req.ReasoningEffort)); reasoning != nil {
+		config.ThinkingConfig = reasoning.GeminiThinkingConfig(model)
+	}
+
 	return model, contents, config
 }
 
@@ -320,6 +334,11 @@ func ConvertAnthropicToGoogleRequest(anthropicReq *anthropic.MessageNewParams, d
 	// Set max_tokens
 	config.MaxOutputTokens = int32(anthropicReq.MaxTokens)
 
+	// Map extended thinking onto Gemini's thinking config
+	if reasoning := protocol.ReasoningFromAnthropic(anthropicReq.Thinking); reasoning != nil {
+		config.ThinkingConfig = reasoning.GeminiThinkingConfig(model)
+	}
+
 	// Convert system message
 	if len(anthropicReq.System) > 0 {
 		var systemText string
@@ -363,16 +382,10 @@ func ConvertAnthropicToGoogleRequest(anthropicReq *anthropic.MessageNewParams, d
 					// Convert image to inline data
 					// For Google API, images need to be passed as inline data with MIME type
 					if block.OfImage.Source.OfBase64 != nil {
-						content.Parts = append(content.Parts, &genai.Part{
-							InlineData: &genai.Blob{
-								MIMEType: string(block.OfImage.Source.OfBase64.MediaType),
-								Data:     []byte(block.OfImage.Source.OfBase64.Data),
-							},
-						})
+						content.Parts = append(content.Parts, googleInlineBlob(string(block.OfImage.Source.OfBase64.MediaType), block.OfImage.Source.OfBase64.Data))
 					} else if block.OfImage.Source.OfURL != nil {
-						// For URL images, we'd need to fetch them first
-						// For now, skip or handle as text reference
-						content.Parts = append(content.Parts, genai.NewPartFromText("[Image: "+block.OfImage.Source.OfURL.URL+"]"))
+						// Gemini needs inline data; InlineGoogleRemoteMedia fetches the URL before sending
+						content.Parts = append(content.Parts, googleRemoteMediaPart(block.OfImage.Source.OfURL.URL))
 					}
 				case block.OfToolResult != nil:
 					// Convert tool_result to function_response
@@ -446,11 +459,7 @@ func ConvertAnthropicToGoogleRequest(anthropicReq *anthropic.MessageNewParams, d
 
 	// Convert tools from Anthropic format to Google format
 	if len(anthropicReq.Tools) > 0 {
-		config.Tools = []*genai.Tool{
-			{
-				FunctionDeclarations: ConvertAnthropicToGoogleTools(anthropicReq.Tools),
-			},
-		}
+		config.Tools = googleTools(ConvertAnthropicToGoogleTools(anthropicReq.Tools), protocol.AnthropicServerTools(anthropicReq.Tools))
 	}
 
 	// Convert tool choice
@@ -474,6 +483,10 @@ func ConvertAnthropicToGoogleTools(tools []anthropic.ToolUnionParam) []*genai.Fu
 		if tool == nil {
 			continue
 		}
+		// Server tools map to Gemini built-in tools instead of function declarations
+		if _, ok := protocol.ServerToolKindOf(string(tool.Type)); ok {
+			continue
+		}
 
 		// Convert Anthropic input schema to Google parameters
 		var parameters *genai.Schema
@@ -531,6 +544,11 @@ func ConvertAnthropicBetaToGoogleRequest(anthropicReq *anthropic.BetaMessageNewP
 	// Set max_tokens
 	config.MaxOutputTokens = int32(anthropicReq.MaxTokens)
 
+	// Map extended thinking onto Gemini's thinking config
+	if reasoning := protocol.ReasoningFromAnthropicBeta(anthropicReq.Thinking); reasoning != nil {
+		config.ThinkingConfig = reasoning.GeminiThinkingConfig(model)
+	}
+
 	// Convert system message
 	if len(anthropicReq.System) > 0 {
 		var systemText string
@@ -574,16 +592,10 @@ func ConvertAnthropicBetaToGoogleRequest(anthropicReq *anthropic.BetaMessageNewP
 					// Convert image to inline data
 					// For Google API, images need to be passed as inline data with MIME type
 					if block.OfImage.Source.OfBase64 != nil {
-						content.Parts = append(content.Parts, &genai.Part{
-							InlineData: &genai.Blob{
-								MIMEType: string(block.OfImage.Source.OfBase64.MediaType),
-								Data:     []byte(block.OfImage.Source.OfBase64.Data),
-							},
-						})
+						content.Parts = append(content.Parts, googleInlineBlob(string(block.OfImage.Source.OfBase64.MediaType), block.OfImage.Source.OfBase64.Data))
 					} else if block.OfImage.Source.OfURL != nil {
-						// For URL images, we'd need to fetch them first
-						// For now, skip or handle as text reference
-						content.Parts = append(content.Parts, genai.NewPartFromText("[Image: "+block.OfImage.Source.OfURL.URL+"]"))
+						// Gemini needs inline data; InlineGoogleRemoteMedia fetches the URL before sending
+						content.Parts = append(content.Parts, googleRemoteMediaPart(block.OfImage.Source.OfURL.URL))
 					}
 				case block.OfToolResult != nil:
 					// Convert tool_result to function_response
@@ -657,11 +669,7 @@ func ConvertAnthropicBetaToGoogleRequest(anthropicReq *anthropic.BetaMessageNewP
 
 	// Convert tools from Anthropic format to Google format
 	if len(anthropicReq.Tools) > 0 {
-		config.Tools = []*genai.Tool{
-			{
-				FunctionDeclarations: ConvertAnthropicBetaToGoogleTools(anthropicReq.Tools),
-			},
-		}
+		config.Tools = googleTools(ConvertAnthropicBetaToGoogleTools(anthropicReq.Tools), protocol.AnthropicBetaServerTools(anthropicReq.Tools))
 	}
 
 	// Convert tool choice
@@ -685,6 +693,10 @@ func ConvertAnthropicBetaToGoogleTools(tools []anthropic.BetaToolUnionParam) []*
 		if tool == nil {
 			continue
 		}
+		// Server tools map to Gemini built-in tools instead of function declarations
+		if _, ok := protocol.ServerToolKindOf(string(tool.Type)); ok {
+			continue
+		}
 
 		// Convert Anthropic beta input schema to Google parameters
 		var parameters *genai.Schema
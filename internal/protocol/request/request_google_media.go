@@ -0,0 +1,82 @@
+package request
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"path"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// MediaResolver downloads remote media, returning its MIME type and raw bytes
+type MediaResolver func(ctx context.Context, url string) (mimeType string, data []byte, err error)
+
+// googleInlineBlob builds an inline image part from base64 data sent by a client
+func googleInlineBlob(mimeType, data string) *genai.Part {
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		// Not valid base64; pass the bytes through rather than dropping the image
+		raw = []byte(data)
+	}
+	return &genai.Part{InlineData: &genai.Blob{MIMEType: mimeType, Data: raw}}
+}
+
+// googleRemoteMediaPart references a remote URL. Gemini rejects arbitrary URLs, so these parts
+// are replaced with inline data by InlineGoogleRemoteMedia before the request is sent.
+func googleRemoteMediaPart(url string) *genai.Part {
+	return &genai.Part{FileData: &genai.FileData{
+		FileURI:  url,
+		MIMEType: mime.TypeByExtension(path.Ext(strings.SplitN(url, "?", 2)[0])),
+	}}
+}
+
+// googleImageURLPart converts an OpenAI image_url value (a data URL or a remote URL)
+func googleImageURLPart(url string) *genai.Part {
+	if mimeType, data, ok := splitDataURL(url); ok {
+		return googleInlineBlob(mimeType, data)
+	}
+	return googleRemoteMediaPart(url)
+}
+
+// isRemoteMediaURI reports whether a file URI points at the web rather than Gemini file storage
+func isRemoteMediaURI(uri string) bool {
+	return strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://")
+}
+
+// InlineGoogleRemoteMedia downloads remote media parts and inlines them as blobs. Parts that cannot
+// be fetched (or every part, when resolve is nil) fall back to an "[Image: <url>]" text reference so
+// the request still succeeds; the returned error describes those failures.
+func InlineGoogleRemoteMedia(ctx context.Context, contents []*genai.Content, resolve MediaResolver) error {
+	var errs []error
+	for _, content := range contents {
+		if content == nil {
+			continue
+		}
+		for i, part := range content.Parts {
+			if part == nil || part.FileData == nil || !isRemoteMediaURI(part.FileData.FileURI) {
+				continue
+			}
+			uri := part.FileData.FileURI
+
+			if resolve == nil {
+				content.Parts[i] = genai.NewPartFromText("[Image: " + uri + "]")
+				continue
+			}
+			mimeType, data, err := resolve(ctx, uri)
+			if err != nil {
+				errs = append(errs, err)
+				content.Parts[i] = genai.NewPartFromText("[Image: " + uri + "]")
+				continue
+			}
+			content.Parts[i] = &genai.Part{InlineData: &genai.Blob{MIMEType: mimeType, Data: data}}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to inline remote media: %w", errors.Join(errs...))
+	}
+	return nil
+}
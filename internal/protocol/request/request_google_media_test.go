@@ -0,0 +1,77 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInlineGoogleRemoteMedia(t *testing.T) {
+	req := &anthropic.MessageNewParams{
+		Model:     "gemini-2.5-flash",
+		MaxTokens: 1024,
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(
+				anthropic.NewTextBlock("Compare these"),
+				anthropic.NewImageBlock(anthropic.URLImageSourceParam{URL: "https://example.com/a.png"}),
+				anthropic.NewImageBlock(anthropic.URLImageSourceParam{URL: "https://example.com/missing.png"}),
+				anthropic.NewImageBlockBase64("image/png", "aGVsbG8="),
+			),
+		},
+	}
+
+	_, contents, _ := ConvertAnthropicToGoogleRequest(req, 1024)
+	require.Len(t, contents, 1)
+	parts := contents[0].Parts
+	require.Len(t, parts, 4)
+	require.NotNil(t, parts[1].FileData)
+	assert.Equal(t, "image/png", parts[1].FileData.MIMEType)
+	require.NotNil(t, parts[3].InlineData)
+	assert.Equal(t, []byte("hello"), parts[3].InlineData.Data, "base64 data should be decoded to raw bytes")
+
+	resolve := func(ctx context.Context, url string) (string, []byte, error) {
+		if url == "https://example.com/a.png" {
+			return "image/png", []byte("png-bytes"), nil
+		}
+		return "", nil, errors.New("not found")
+	}
+
+	err := InlineGoogleRemoteMedia(context.Background(), contents, resolve)
+	assert.Error(t, err)
+
+	require.NotNil(t, parts[1].InlineData)
+	assert.Equal(t, "image/png", parts[1].InlineData.MIMEType)
+	assert.Equal(t, []byte("png-bytes"), parts[1].InlineData.Data)
+	assert.Equal(t, "[Image: https://example.com/missing.png]", parts[2].Text)
+}
+
+func TestConvertOpenAIToGoogleRequestImages(t *testing.T) {
+	req := &openai.ChatCompletionNewParams{
+		Model: "gemini-2.5-flash",
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.UserMessage([]openai.ChatCompletionContentPartUnionParam{
+				openai.TextContentPart("Describe"),
+				openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: "data:image/jpeg;base64,aGVsbG8="}),
+				openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: "https://example.com/b.jpg"}),
+			}),
+		},
+	}
+
+	_, contents, _ := ConvertOpenAIToGoogleRequest(req, 1024)
+	require.Len(t, contents, 1)
+	parts := contents[0].Parts
+	require.Len(t, parts, 3)
+	require.NotNil(t, parts[1].InlineData)
+	assert.Equal(t, "image/jpeg", parts[1].InlineData.MIMEType)
+	assert.Equal(t, []byte("hello"), parts[1].InlineData.Data)
+	require.NotNil(t, parts[2].FileData)
+
+	// Without a resolver remote images degrade to text references
+	require.NoError(t, InlineGoogleRemoteMedia(context.Background(), contents, nil))
+	assert.Equal(t, "[Image: https://example.com/b.jpg]", parts[2].Text)
+}
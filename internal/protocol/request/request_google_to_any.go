@@ -1,14 +1,19 @@
 package request
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/packages/param"
+	"github.com/openai/openai-go/v3/responses"
 	"github.com/openai/openai-go/v3/shared"
 	"google.golang.org/genai"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
 )
 
 // ConvertGoogleToOpenAIRequest converts Google Content and config to OpenAI format
@@ -32,6 +37,13 @@ func ConvertGoogleToOpenAIRequest(model string, contents []*genai.Content, confi
 		openaiReq.TopP = openai.Opt(float64(*config.TopP))
 	}
 
+	// Map thinkingConfig onto reasoning_effort
+	if config != nil {
+		if reasoning := protocol.ReasoningFromGemini(config.ThinkingConfig); reasoning != nil {
+			openaiReq.ReasoningEffort = shared.ReasoningEffort(reasoning.Effort)
+		}
+	}
+
 	// Convert contents to messages
 	for _, content := range contents {
 		if content.Role == "system" {
@@ -74,11 +86,27 @@ func ConvertGoogleToOpenAIRequest(model string, contents []*genai.Content, confi
 func convertGoogleContentToOpenAI(content *genai.Content) openai.ChatCompletionMessageParamUnion {
 	var textContent string
 	var toolCalls []map[string]interface{}
+	// Ordered text and image parts, used for user messages that carry images
+	var userParts []openai.ChatCompletionContentPartUnionParam
+	var hasImage bool
 
 	for _, part := range content.Parts {
 		// Handle text parts
 		if part.Text != "" {
 			textContent += part.Text
+			userParts = append(userParts, openai.TextContentPart(part.Text))
+		}
+
+		// Handle inline and referenced images
+		if part.InlineData != nil || part.FileData != nil {
+			url := ""
+			if part.InlineData != nil {
+				url = blobDataURL(part.InlineData)
+			} else {
+				url = part.FileData.FileURI
+			}
+			userParts = append(userParts, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: url}))
+			hasImage = true
 		}
 
 		// Handle function calls
@@ -136,6 +164,9 @@ func convertGoogleContentToOpenAI(content *genai.Content) openai.ChatCompletionM
 
 	// Build the message based on role
 	if content.Role == "user" {
+		if hasImage {
+			return openai.UserMessage(userParts)
+		}
 		// User message with text only
 		if textContent != "" {
 			return openai.UserMessage(textContent)
@@ -279,6 +310,16 @@ func ConvertGoogleToAnthropicRequest(model string, contents []*genai.Content, co
 		params.MaxTokens = int64(config.MaxOutputTokens)
 	}
 
+	// Map thinkingConfig onto extended thinking; max_tokens must exceed the thinking budget
+	if config != nil {
+		if reasoning := protocol.ReasoningFromGemini(config.ThinkingConfig); reasoning.Enabled() {
+			params.Thinking = reasoning.AnthropicThinking()
+			if budget := reasoning.AnthropicBudget(); params.MaxTokens <= budget {
+				params.MaxTokens += budget
+			}
+		}
+	}
+
 	// Convert contents
 	var systemParts []string
 
@@ -335,6 +376,15 @@ func convertGoogleContentToAnthropic(content *genai.Content) anthropic.MessagePa
 			blocks = append(blocks, anthropic.NewTextBlock(part.Text))
 		}
 
+		// Handle inline and referenced images; Anthropic only accepts images from the user
+		if content.Role != "model" {
+			if part.InlineData != nil {
+				blocks = append(blocks, anthropic.NewImageBlockBase64(part.InlineData.MIMEType, base64.StdEncoding.EncodeToString(part.InlineData.Data)))
+			} else if part.FileData != nil {
+				blocks = append(blocks, anthropic.NewImageBlock(anthropic.URLImageSourceParam{URL: part.FileData.FileURI}))
+			}
+		}
+
 		// Handle function calls
 		if part.FunctionCall != nil {
 			blocks = append(blocks,
@@ -449,3 +499,258 @@ func ConvertGooglePartsToString(parts []*genai.Part) string {
 	}
 	return result.String()
 }
+
+// ConvertGoogleToResponsesRequest converts Google Content and config to OpenAI Responses API format
+func ConvertGoogleToResponsesRequest(model string, contents []*genai.Content, config *genai.GenerateContentConfig) responses.ResponseNewParams {
+	params := responses.ResponseNewParams{
+		Model: model,
+	}
+
+	var inputItems []responses.ResponseInputItemUnionParam
+	var systemParts []string
+	// Gemini function calls often carry no ID; pair calls and responses by name in order
+	pendingCallIDs := make(map[string][]string)
+	callCount := 0
+
+	if config != nil && config.SystemInstruction != nil {
+		if systemText := ConvertGooglePartsToString(config.SystemInstruction.Parts); systemText != "" {
+			systemParts = append(systemParts, systemText)
+		}
+	}
+
+	for _, content := range contents {
+		if content.Role == "system" {
+			if systemText := ConvertGooglePartsToString(content.Parts); systemText != "" {
+				systemParts = append(systemParts, systemText)
+			}
+			continue
+		}
+
+		role := responses.EasyInputMessageRoleUser
+		if content.Role == "model" {
+			role = responses.EasyInputMessageRoleAssistant
+		}
+		var messageContent responses.ResponseInputMessageContentListParam
+		flushMessage := func() {
+			if len(messageContent) == 0 {
+				return
+			}
+			inputItems = append(inputItems, googleResponsesMessage(role, messageContent))
+			messageContent = nil
+		}
+
+		for _, part := range content.Parts {
+			switch {
+			case part.Thought:
+				// Thoughts are model-internal and cannot be replayed as Responses input
+			case part.Text != "":
+				messageContent = append(messageContent, responses.ResponseInputContentUnionParam{
+					OfInputText: &responses.ResponseInputTextParam{Text: part.Text},
+				})
+			case part.InlineData != nil && role == responses.EasyInputMessageRoleUser:
+				messageContent = append(messageContent, responses.ResponseInputContentUnionParam{
+					OfInputImage: &responses.ResponseInputImageParam{
+						ImageURL: ParamOpt(blobDataURL(part.InlineData)),
+						Detail:   responses.ResponseInputImageDetailAuto,
+					},
+				})
+			case part.FileData != nil && role == responses.EasyInputMessageRoleUser:
+				messageContent = append(messageContent, responses.ResponseInputContentUnionParam{
+					OfInputImage: &responses.ResponseInputImageParam{
+						ImageURL: ParamOpt(part.FileData.FileURI),
+						Detail:   responses.ResponseInputImageDetailAuto,
+					},
+				})
+			case part.FunctionCall != nil:
+				flushMessage()
+				callID := part.FunctionCall.ID
+				if callID == "" {
+					callCount++
+					callID = fmt.Sprintf("call_%s_%d", part.FunctionCall.Name, callCount)
+				}
+				pendingCallIDs[part.FunctionCall.Name] = append(pendingCallIDs[part.FunctionCall.Name], callID)
+				argsJSON, _ := json.Marshal(part.FunctionCall.Args)
+				if part.FunctionCall.Args == nil {
+					argsJSON = []byte("{}")
+				}
+				inputItems = append(inputItems, responses.ResponseInputItemUnionParam{
+					OfFunctionCall: &responses.ResponseFunctionToolCallParam{
+						CallID:    callID,
+						Name:      part.FunctionCall.Name,
+						Arguments: string(argsJSON),
+					},
+				})
+			case part.FunctionResponse != nil:
+				flushMessage()
+				name := part.FunctionResponse.Name
+				callID := part.FunctionResponse.ID
+				if callID == "" {
+					if pending := pendingCallIDs[name]; len(pending) > 0 {
+						callID, pendingCallIDs[name] = pending[0], pending[1:]
+					} else {
+						callID = name
+					}
+				}
+				inputItems = append(inputItems, responses.ResponseInputItemUnionParam{
+					OfFunctionCallOutput: &responses.ResponseInputItemFunctionCallOutputParam{
+						CallID: callID,
+						Output: responses.ResponseInputItemFunctionCallOutputOutputUnionParam{
+							OfString: ParamOpt(googleFunctionResponseText(part.FunctionResponse)),
+						},
+					},
+				})
+			}
+		}
+		flushMessage()
+	}
+
+	if len(systemParts) > 0 {
+		params.Instructions = ParamOpt(strings.Join(systemParts, "\n\n"))
+	}
+	if len(inputItems) > 0 {
+		params.Input = responses.ResponseNewParamsInputUnion{OfInputItemList: inputItems}
+	} else {
+		params.Input = responses.ResponseNewParamsInputUnion{OfString: ParamOpt("")}
+	}
+
+	if config == nil {
+		return params
+	}
+
+	if config.MaxOutputTokens > 0 {
+		params.MaxOutputTokens = ParamOpt(int64(config.MaxOutputTokens))
+	}
+	if config.Temperature != nil {
+		params.Temperature = ParamOpt(float64(*config.Temperature))
+	}
+	if config.TopP != nil {
+		params.TopP = ParamOpt(float64(*config.TopP))
+	}
+
+	// Map thinkingConfig onto reasoning; ask for summaries so they can come back as thought parts
+	if reasoning := protocol.ReasoningFromGemini(config.ThinkingConfig); reasoning != nil {
+		params.Reasoning = shared.ReasoningParam{Effort: shared.ReasoningEffort(reasoning.Effort)}
+		if reasoning.Enabled() {
+			params.Reasoning.Summary = shared.ReasoningSummaryAuto
+		}
+	}
+
+	// Function declarations become function tools; Google Search grounding the built-in web search
+	for _, tool := range config.Tools {
+		for _, fn := range ConvertGoogleToolsToOpenAI(tool.FunctionDeclarations) {
+			def := fn.GetFunction()
+			params.Tools = append(params.Tools, responses.ToolUnionParam{
+				OfFunction: &responses.FunctionToolParam{
+					Name:        def.Name,
+					Description: def.Description,
+					Parameters:  def.Parameters,
+				},
+			})
+		}
+		if tool.GoogleSearch != nil || tool.GoogleSearchRetrieval != nil {
+			params.Tools = append(params.Tools, responses.ToolUnionParam{
+				OfWebSearch: &responses.WebSearchToolParam{Type: responses.WebSearchToolTypeWebSearch},
+			})
+		}
+	}
+
+	if config.ToolConfig != nil && config.ToolConfig.FunctionCallingConfig != nil {
+		params.ToolChoice = ConvertGoogleToolChoiceToResponses(config.ToolConfig.FunctionCallingConfig)
+	}
+
+	// Gemini JSON mode maps onto Responses structured output
+	if format, ok := googleResponseFormatToResponses(config); ok {
+		params.Text = responses.ResponseTextConfigParam{Format: format}
+	}
+
+	return params
+}
+
+// ConvertGoogleToolChoiceToResponses converts a Gemini function calling config to Responses API tool_choice
+func ConvertGoogleToolChoiceToResponses(config *genai.FunctionCallingConfig) responses.ResponseNewParamsToolChoiceUnion {
+	switch config.Mode {
+	case genai.FunctionCallingConfigModeAny:
+		if len(config.AllowedFunctionNames) == 1 {
+			return responses.ResponseNewParamsToolChoiceUnion{
+				OfFunctionTool: &responses.ToolChoiceFunctionParam{Name: config.AllowedFunctionNames[0]},
+			}
+		}
+		return responses.ResponseNewParamsToolChoiceUnion{
+			OfToolChoiceMode: ParamOpt(responses.ToolChoiceOptionsRequired),
+		}
+	case genai.FunctionCallingConfigModeNone:
+		return responses.ResponseNewParamsToolChoiceUnion{
+			OfToolChoiceMode: ParamOpt(responses.ToolChoiceOptionsNone),
+		}
+	default:
+		return responses.ResponseNewParamsToolChoiceUnion{
+			OfToolChoiceMode: ParamOpt(responses.ToolChoiceOptionsAuto),
+		}
+	}
+}
+
+// googleResponsesMessage builds a Responses API input message from collected content parts
+func googleResponsesMessage(role responses.EasyInputMessageRole, content responses.ResponseInputMessageContentListParam) responses.ResponseInputItemUnionParam {
+	message := responses.EasyInputMessageParam{
+		Type: responses.EasyInputMessageTypeMessage,
+		Role: role,
+	}
+	// Assistant history only accepts text, so collapse it to a plain string
+	if role == responses.EasyInputMessageRoleAssistant {
+		var text strings.Builder
+		for _, part := range content {
+			if part.OfInputText != nil {
+				text.WriteString(part.OfInputText.Text)
+			}
+		}
+		message.Content = responses.EasyInputMessageContentUnionParam{OfString: ParamOpt(text.String())}
+	} else {
+		message.Content = responses.EasyInputMessageContentUnionParam{OfInputItemContentList: content}
+	}
+	return responses.ResponseInputItemUnionParam{OfMessage: &message}
+}
+
+// googleFunctionResponseText returns a function response as tool output text: its "output"
+// value when that is a string, otherwise the whole response as JSON
+func googleFunctionResponseText(resp *genai.FunctionResponse) string {
+	if resp.Response == nil {
+		return ""
+	}
+	if output, ok := resp.Response["output"].(string); ok {
+		return output
+	}
+	responseBytes, _ := json.Marshal(resp.Response)
+	return string(responseBytes)
+}
+
+// googleResponseFormatToResponses maps Gemini's response MIME type and schema onto a Responses
+// text format, reporting false when the client asked for plain text
+func googleResponseFormatToResponses(config *genai.GenerateContentConfig) (responses.ResponseFormatTextConfigUnionParam, bool) {
+	if config.ResponseMIMEType != "application/json" {
+		return responses.ResponseFormatTextConfigUnionParam{}, false
+	}
+
+	var schema map[string]interface{}
+	switch {
+	case config.ResponseJsonSchema != nil:
+		if raw, err := json.Marshal(config.ResponseJsonSchema); err == nil {
+			_ = json.Unmarshal(raw, &schema)
+		}
+	case config.ResponseSchema != nil:
+		if raw, err := json.Marshal(config.ResponseSchema); err == nil {
+			_ = json.Unmarshal(raw, &schema)
+			schema = normalizeGoogleSchemaTypes(schema)
+		}
+	}
+	if schema == nil {
+		return responses.ResponseFormatTextConfigUnionParam{
+			OfJSONObject: &shared.ResponseFormatJSONObjectParam{},
+		}, true
+	}
+	return responses.ResponseFormatTextConfigUnionParam{
+		OfJSONSchema: &responses.ResponseFormatTextJSONSchemaConfigParam{
+			Name:   "response",
+			Schema: schema,
+		},
+	}, true
+}
@@ -703,3 +703,68 @@ func TestConvertGoogleToAnthropicRequest(t *testing.T) {
 		}
 	})
 }
+
+func TestConvertGoogleToResponsesRequest(t *testing.T) {
+	contents := []*genai.Content{
+		{Role: "user", Parts: []*genai.Part{
+			genai.NewPartFromText("What is in this image, and what's the weather there?"),
+			{InlineData: &genai.Blob{MIMEType: "image/png", Data: []byte("png")}},
+		}},
+		{Role: "model", Parts: []*genai.Part{
+			{Text: "Looking it up", Thought: true},
+			{FunctionCall: &genai.FunctionCall{Name: "get_weather", Args: map[string]interface{}{"city": "Paris"}}},
+		}},
+		{Role: "user", Parts: []*genai.Part{
+			{FunctionResponse: &genai.FunctionResponse{Name: "get_weather", Response: map[string]interface{}{"output": "Sunny"}}},
+		}},
+	}
+	budget := int32(2048)
+	config := &genai.GenerateContentConfig{
+		SystemInstruction: &genai.Content{Parts: []*genai.Part{genai.NewPartFromText("Be brief.")}},
+		MaxOutputTokens:   512,
+		ThinkingConfig:    &genai.ThinkingConfig{ThinkingBudget: &budget},
+		Tools: []*genai.Tool{
+			{FunctionDeclarations: []*genai.FunctionDeclaration{{
+				Name:       "get_weather",
+				Parameters: &genai.Schema{Type: genai.TypeObject, Properties: map[string]*genai.Schema{"city": {Type: genai.TypeString}}},
+			}}},
+			{GoogleSearch: &genai.GoogleSearch{}},
+		},
+		ToolConfig:       &genai.ToolConfig{FunctionCallingConfig: &genai.FunctionCallingConfig{Mode: genai.FunctionCallingConfigModeAny}},
+		ResponseMIMEType: "application/json",
+	}
+
+	params := ConvertGoogleToResponsesRequest("gpt-5-codex", contents, config)
+	raw, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &got))
+	assert.Equal(t, "gpt-5-codex", got["model"])
+	assert.Equal(t, "Be brief.", got["instructions"])
+	assert.EqualValues(t, 512, got["max_output_tokens"])
+	assert.Equal(t, "required", got["tool_choice"])
+	assert.Equal(t, "auto", got["reasoning"].(map[string]interface{})["summary"])
+	assert.Equal(t, map[string]interface{}{"format": map[string]interface{}{"type": "json_object"}}, got["text"])
+
+	tools := got["tools"].([]interface{})
+	require.Len(t, tools, 2)
+	fn := tools[0].(map[string]interface{})
+	assert.Equal(t, "get_weather", fn["name"])
+	assert.Equal(t, "object", fn["parameters"].(map[string]interface{})["type"])
+	assert.Equal(t, "web_search", tools[1].(map[string]interface{})["type"])
+
+	input := got["input"].([]interface{})
+	require.Len(t, input, 3)
+	userContent := input[0].(map[string]interface{})["content"].([]interface{})
+	require.Len(t, userContent, 2)
+	assert.Equal(t, "data:image/png;base64,cG5n", userContent[1].(map[string]interface{})["image_url"])
+
+	// The thought is dropped and the unnamed call is paired with its response
+	call := input[1].(map[string]interface{})
+	output := input[2].(map[string]interface{})
+	assert.Equal(t, "function_call", call["type"])
+	assert.NotEmpty(t, call["call_id"])
+	assert.Equal(t, call["call_id"], output["call_id"])
+	assert.Equal(t, "Sunny", output["output"])
+}
@@ -0,0 +1,35 @@
+package request
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"google.golang.org/genai"
+)
+
+// splitDataURL splits a base64 data URL into its MIME type and encoded payload
+func splitDataURL(url string) (mimeType, data string, ok bool) {
+	rest, ok := strings.CutPrefix(url, "data:")
+	if !ok {
+		return "", "", false
+	}
+	meta, data, found := strings.Cut(rest, ",")
+	if !found || !strings.HasSuffix(meta, ";base64") {
+		return "", "", false
+	}
+	return strings.TrimSuffix(meta, ";base64"), data, true
+}
+
+// blobDataURL encodes an inline Gemini blob as a data URL
+func blobDataURL(blob *genai.Blob) string {
+	return "data:" + blob.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(blob.Data)
+}
+
+// anthropicImageBlockFromURL converts an image URL (a data URL or a remote URL) to an image block
+func anthropicImageBlockFromURL(url string) anthropic.ContentBlockParamUnion {
+	if mimeType, data, ok := splitDataURL(url); ok {
+		return anthropic.NewImageBlockBase64(mimeType, data)
+	}
+	return anthropic.NewImageBlock(anthropic.URLImageSourceParam{URL: url})
+}
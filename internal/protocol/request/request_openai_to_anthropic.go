@@ -5,6 +5,8 @@ import (
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/openai/openai-go/v3"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
 )
 
 // ConvertOpenAIToAnthropicRequest converts OpenAI ChatCompletionNewParams to Anthropic SDK format
@@ -43,6 +45,10 @@ func ConvertOpenAIToAnthropicRequest(req *openai.ChatCompletionNewParams, defaul
 					if partMap, ok := part.(map[string]interface{}); ok {
 						if text, ok := partMap["text"].(string); ok {
 							blocks = append(blocks, anthropic.NewTextBlock(text))
+						} else if imageURL, ok := partMap["image_url"].(map[string]interface{}); ok {
+							if url, ok := imageURL["url"].(string); ok && url != "" {
+								blocks = append(blocks, anthropicImageBlockFromURL(url))
+							}
 						}
 					}
 				}
@@ -118,6 +124,14 @@ func ConvertOpenAIToAnthropicRequest(req *openai.ChatCompletionNewParams, defaul
 		}
 	}
 
+	// Map reasoning_effort onto extended thinking; max_tokens must exceed the thinking budget
+	if reasoning := protocol.ReasoningFromEffort(string(req.ReasoningEffort)); reasoning.Enabled() {
+		params.Thinking = reasoning.AnthropicThinking()
+		if budget := reasoning.AnthropicBudget(); params.MaxTokens <= budget {
+			params.MaxTokens = budget + maxTokens
+		}
+	}
+
 	// Convert tools from OpenAI format to Anthropic format
 	if len(req.Tools) > 0 {
 		params.Tools = ConvertOpenAIToAnthropicTools(req.Tools)
@@ -130,6 +144,9 @@ func ConvertOpenAIToAnthropicRequest(req *openai.ChatCompletionNewParams, defaul
 		params.ToolChoice = ConvertOpenAIToAnthropicToolChoice(&req.ToolChoice)
 	}
 
+	// Emulate response_format with a forced tool call; this overrides the tool choice above
+	ApplyStructuredOutputToAnthropic(&params, StructuredOutputFromOpenAI(req))
+
 	return params
 }
 
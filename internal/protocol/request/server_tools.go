@@ -0,0 +1,60 @@
+package request
+
+import (
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/packages/param"
+	"google.golang.org/genai"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+)
+
+// googleTools builds the Gemini tool list: the function declarations plus a built-in tool standing
+// in for each Anthropic server tool (Google Search grounding, URL context, code execution).
+// Gemini takes each built-in as its own Tool entry.
+func googleTools(decls []*genai.FunctionDeclaration, serverTools []protocol.ServerTool) []*genai.Tool {
+	var tools []*genai.Tool
+	if len(decls) > 0 {
+		tools = append(tools, &genai.Tool{FunctionDeclarations: decls})
+	}
+
+	seen := make(map[protocol.ServerToolKind]bool)
+	for _, serverTool := range serverTools {
+		if seen[serverTool.Kind] {
+			continue
+		}
+		seen[serverTool.Kind] = true
+
+		switch serverTool.Kind {
+		case protocol.ServerToolWebSearch:
+			tools = append(tools, &genai.Tool{GoogleSearch: &genai.GoogleSearch{}})
+		case protocol.ServerToolWebFetch:
+			tools = append(tools, &genai.Tool{URLContext: &genai.URLContext{}})
+		case protocol.ServerToolCodeExecution:
+			tools = append(tools, &genai.Tool{CodeExecution: &genai.ToolCodeExecution{}})
+		}
+	}
+	return tools
+}
+
+// hasServerTool reports whether the server tool of the given versioned type is in the list
+func hasServerTool(serverTools []protocol.ServerTool, toolType string) bool {
+	for _, serverTool := range serverTools {
+		if serverTool.Type == toolType {
+			return true
+		}
+	}
+	return false
+}
+
+// AddGLMWebSearchTool enables GLM's built-in web search on a chat completions request, standing in
+// for Anthropic's web_search server tool. GLM returns the results in a top-level web_search array.
+func AddGLMWebSearchTool(req *openai.ChatCompletionNewParams) {
+	webSearch := param.Override[openai.ChatCompletionFunctionToolParam](map[string]interface{}{
+		"type": "web_search",
+		"web_search": map[string]interface{}{
+			"enable":        true,
+			"search_result": true,
+		},
+	})
+	req.Tools = append(req.Tools, openai.ChatCompletionToolUnionParam{OfFunction: &webSearch})
+}
@@ -0,0 +1,78 @@
+package request
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serverToolRequest decodes a client request declaring a function tool next to web search,
+// web fetch and code execution server tools
+func serverToolRequest(t *testing.T) anthropic.MessageNewParams {
+	var req anthropic.MessageNewParams
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"model": "claude-sonnet-4-5",
+		"max_tokens": 1024,
+		"messages": [{"role": "user", "content": "What's new in Go?"}],
+		"tools": [
+			{"name": "get_weather", "description": "Weather", "input_schema": {"type": "object", "properties": {"city": {"type": "string"}}}},
+			{"type": "web_search_20250305", "name": "web_search", "max_uses": 3},
+			{"type": "web_fetch_20250910", "name": "web_fetch"},
+			{"type": "code_execution_20250825", "name": "code_execution"}
+		]
+	}`), &req))
+	return req
+}
+
+func TestServerToolsToOpenAI(t *testing.T) {
+	req := serverToolRequest(t)
+
+	tools := ConvertAnthropicToolsToOpenAI(req.Tools)
+	require.Len(t, tools, 1, "server tools must not become empty function tools")
+	assert.Equal(t, "get_weather", tools[0].OfFunction.Function.Name)
+
+	openaiReq := &openai.ChatCompletionNewParams{Tools: tools}
+	AddGLMWebSearchTool(openaiReq)
+	raw, err := json.Marshal(openaiReq.Tools)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"type": "function", "function": {"name": "get_weather", "description": "Weather", "parameters": {"type": "object", "properties": {"city": {"type": "string"}}}}},
+		{"type": "web_search", "web_search": {"enable": true, "search_result": true}}
+	]`, string(raw))
+}
+
+func TestServerToolsToGoogle(t *testing.T) {
+	req := serverToolRequest(t)
+
+	_, _, config := ConvertAnthropicToGoogleRequest(&req, 0)
+	require.Len(t, config.Tools, 4)
+	require.Len(t, config.Tools[0].FunctionDeclarations, 1)
+	assert.Equal(t, "get_weather", config.Tools[0].FunctionDeclarations[0].Name)
+	assert.NotNil(t, config.Tools[1].GoogleSearch)
+	assert.NotNil(t, config.Tools[2].URLContext)
+	assert.NotNil(t, config.Tools[3].CodeExecution)
+
+	// Server tools alone must not leave an empty function declaration tool behind
+	req.Tools = req.Tools[1:2]
+	_, _, config = ConvertAnthropicToGoogleRequest(&req, 0)
+	require.Len(t, config.Tools, 1)
+	assert.NotNil(t, config.Tools[0].GoogleSearch)
+}
+
+func TestServerToolsToResponses(t *testing.T) {
+	req := serverToolRequest(t)
+
+	tools := ConvertAnthropicToResponsesRequest(&req).Tools
+	require.Len(t, tools, 2)
+	assert.Equal(t, "get_weather", tools[0].OfFunction.Name)
+	assert.NotNil(t, tools[1].OfWebSearch)
+
+	// Server tools missing from the supported list are stripped
+	tools = ConvertAnthropicToResponsesRequestWithProvider(&req, nil, "gpt-4o", nil).Tools
+	require.Len(t, tools, 1)
+	assert.Equal(t, "get_weather", tools[0].OfFunction.Name)
+}
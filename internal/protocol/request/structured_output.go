@@ -0,0 +1,99 @@
+package request
+
+import (
+	"encoding/json"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go/v3"
+	"google.golang.org/genai"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+)
+
+// StructuredOutput is a JSON-schema response format extracted from an OpenAI request
+type StructuredOutput struct {
+	Name        string
+	Description string
+	Schema      map[string]interface{}
+	Strict      bool
+}
+
+// StructuredOutputFromOpenAI returns the request's json_schema or json_object response
+// format, or nil when the client asked for plain text
+func StructuredOutputFromOpenAI(req *openai.ChatCompletionNewParams) *StructuredOutput {
+	switch {
+	case req.ResponseFormat.OfJSONSchema != nil:
+		js := req.ResponseFormat.OfJSONSchema.JSONSchema
+		so := &StructuredOutput{
+			Name:        js.Name,
+			Description: js.Description.Value,
+			Strict:      js.Strict.Value,
+		}
+		if js.Schema != nil {
+			if raw, err := json.Marshal(js.Schema); err == nil {
+				_ = json.Unmarshal(raw, &so.Schema)
+			}
+		}
+		if so.Schema == nil {
+			so.Schema = map[string]interface{}{"type": "object"}
+		}
+		return so
+	case req.ResponseFormat.OfJSONObject != nil:
+		return &StructuredOutput{
+			Name:   "json_object",
+			Schema: map[string]interface{}{"type": "object"},
+		}
+	}
+	return nil
+}
+
+// ApplyStructuredOutputToAnthropic emulates a response format with a synthetic tool the model
+// is forced to call. When the client also sent its own tools the model must call one of
+// them ("any"), so it can keep using tools and give its final answer through the synthetic one.
+func ApplyStructuredOutputToAnthropic(params *anthropic.MessageNewParams, so *StructuredOutput) {
+	if so == nil {
+		return
+	}
+
+	var schemaParam anthropic.ToolInputSchemaParam
+	if raw, err := json.Marshal(so.Schema); err == nil {
+		_ = json.Unmarshal(raw, &schemaParam)
+	}
+	tool := &anthropic.ToolParam{
+		Name:        protocol.StructuredOutputToolName,
+		InputSchema: schemaParam,
+	}
+	description := "Respond with the final answer by calling this tool. Its input must match the " + so.Name + " schema."
+	if so.Description != "" {
+		description += " " + so.Description
+	}
+	tool.Description = anthropic.Opt(description)
+
+	hasOtherTools := len(params.Tools) > 0
+	params.Tools = append(params.Tools, anthropic.ToolUnionParam{OfTool: tool})
+
+	switch {
+	case params.Thinking.OfEnabled != nil:
+		// Extended thinking rejects forced tool use
+		params.ToolChoice = anthropic.ToolChoiceUnionParam{OfAuto: &anthropic.ToolChoiceAutoParam{}}
+	case hasOtherTools:
+		params.ToolChoice = anthropic.ToolChoiceUnionParam{OfAny: &anthropic.ToolChoiceAnyParam{}}
+	default:
+		params.ToolChoice = anthropic.ToolChoiceParamOfTool(protocol.StructuredOutputToolName)
+	}
+}
+
+// ApplyStructuredOutputToGoogle maps a response format onto Gemini's native JSON mode
+func ApplyStructuredOutputToGoogle(config *genai.GenerateContentConfig, so *StructuredOutput) {
+	if so == nil {
+		return
+	}
+	config.ResponseMIMEType = "application/json"
+
+	var schema *genai.Schema
+	if raw, err := json.Marshal(so.Schema); err == nil {
+		_ = json.Unmarshal(raw, &schema)
+	}
+	normalizeSchemaTypes(schema)
+	config.ResponseSchema = schema
+}
@@ -0,0 +1,142 @@
+package request
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/shared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+)
+
+var invoiceSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"vendor": map[string]interface{}{"type": "string"},
+		"total":  map[string]interface{}{"type": "number", "minimum": 0},
+		"lines": map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"$ref": "#/$defs/line"},
+		},
+	},
+	"required":             []interface{}{"vendor", "total"},
+	"additionalProperties": false,
+	"$defs": map[string]interface{}{
+		"line": map[string]interface{}{
+			"type":     "object",
+			"required": []interface{}{"sku"},
+			"properties": map[string]interface{}{
+				"sku": map[string]interface{}{"type": "string", "enum": []interface{}{"A1", "B2"}},
+			},
+		},
+	},
+}
+
+func newStructuredOutputRequest() *openai.ChatCompletionNewParams {
+	return &openai.ChatCompletionNewParams{
+		Model:    "claude-sonnet-4-5",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("Extract the invoice")},
+		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+				JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   "invoice",
+					Schema: invoiceSchema,
+					Strict: openai.Bool(true),
+				},
+			},
+		},
+	}
+}
+
+func TestConvertOpenAIToAnthropicRequestStructuredOutput(t *testing.T) {
+	req := newStructuredOutputRequest()
+
+	params := ConvertOpenAIToAnthropicRequest(req, 1024)
+	require.Len(t, params.Tools, 1)
+	require.NotNil(t, params.Tools[0].OfTool)
+	assert.Equal(t, protocol.StructuredOutputToolName, params.Tools[0].OfTool.Name)
+	require.NotNil(t, params.ToolChoice.OfTool)
+	assert.Equal(t, protocol.StructuredOutputToolName, params.ToolChoice.OfTool.Name)
+
+	schema, ok := protocol.StructuredOutputToolSchema(params.Tools)
+	require.True(t, ok)
+	assert.Equal(t, []interface{}{"vendor", "total"}, schema["required"])
+
+	// With client tools the model may call them before answering
+	req.Tools = []openai.ChatCompletionToolUnionParam{
+		openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
+			Name:       "lookup_vendor",
+			Parameters: shared.FunctionParameters{"type": "object"},
+		}),
+	}
+	params = ConvertOpenAIToAnthropicRequest(req, 1024)
+	require.Len(t, params.Tools, 2)
+	assert.NotNil(t, params.ToolChoice.OfAny)
+}
+
+func TestConvertOpenAIToGoogleRequestStructuredOutput(t *testing.T) {
+	_, _, config := ConvertOpenAIToGoogleRequest(newStructuredOutputRequest(), 1024)
+
+	assert.Equal(t, "application/json", config.ResponseMIMEType)
+	require.NotNil(t, config.ResponseSchema)
+	assert.Equal(t, genai.TypeObject, config.ResponseSchema.Type)
+	assert.Equal(t, genai.TypeNumber, config.ResponseSchema.Properties["total"].Type)
+	assert.Equal(t, []string{"vendor", "total"}, config.ResponseSchema.Required)
+}
+
+func TestStructuredOutputFromOpenAIJSONObject(t *testing.T) {
+	req := &openai.ChatCompletionNewParams{
+		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{OfJSONObject: &shared.ResponseFormatJSONObjectParam{}},
+	}
+	so := StructuredOutputFromOpenAI(req)
+	require.NotNil(t, so)
+	assert.Equal(t, map[string]interface{}{"type": "object"}, so.Schema)
+
+	req.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{OfText: &shared.ResponseFormatTextParam{}}
+	assert.Nil(t, StructuredOutputFromOpenAI(req))
+}
+
+func TestValidateStructuredOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantPath string
+	}{
+		{"valid", `{"vendor":"Acme","total":12.5,"lines":[{"sku":"A1"}]}`, ""},
+		{"not json", `Here is the invoice: {"vendor":"Acme"}`, "$"},
+		{"missing required", `{"vendor":"Acme"}`, "$"},
+		{"wrong type", `{"vendor":"Acme","total":"12"}`, "$.total"},
+		{"below minimum", `{"vendor":"Acme","total":-1}`, "$.total"},
+		{"extra property", `{"vendor":"Acme","total":1,"note":"x"}`, "$"},
+		{"ref enum", `{"vendor":"Acme","total":1,"lines":[{"sku":"C3"}]}`, "$.lines[0].sku"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := protocol.ValidateStructuredOutput(tt.content, invoiceSchema)
+			if tt.wantPath == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var schemaErr *protocol.SchemaValidationError
+			require.ErrorAs(t, err, &schemaErr)
+			assert.Equal(t, tt.wantPath, schemaErr.Path)
+		})
+	}
+}
+
+func TestStructuredOutputToolRoundTrip(t *testing.T) {
+	params := ConvertOpenAIToAnthropicRequest(newStructuredOutputRequest(), 1024)
+	raw, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	var decoded anthropic.MessageNewParams
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	_, ok := protocol.StructuredOutputToolSchema(decoded.Tools)
+	assert.True(t, ok)
+}
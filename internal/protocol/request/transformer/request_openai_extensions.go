@@ -6,6 +6,7 @@ import (
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/shared"
 
+	"github.com/tingly-dev/tingly-box/internal/protocol"
 	"github.com/tingly-dev/tingly-box/internal/typ"
 )
 
@@ -87,11 +88,13 @@ func GetProviderTransform(provider *typ.Provider, model string) ProviderTransfor
 
 // applyDefaultTransform applies default transformations for OpenAI-compatible requests
 // This handles standard fields like reasoning_effort that are widely supported
-func applyDefaultTransform(req *openai.ChatCompletionNewParams, config *OpenAIConfig) *openai.ChatCompletionNewParams {
+func applyDefaultTransform(req *openai.ChatCompletionNewParams, model string, config *OpenAIConfig) *openai.ChatCompletionNewParams {
 	if config.HasThinking && config.ReasoningEffort != "" {
 		// Set reasoning_effort from config for OpenAI-compatible APIs
-		// This is widely supported by many providers (OpenAI, Azure, etc.)
-		req.ReasoningEffort = config.ReasoningEffort
+		// This is widely supported by many providers (OpenAI, Azure, etc.), though only for
+		// low/medium/high unless the model is known to accept minimal or xhigh
+		effort := protocol.OpenAIReasoningEffort(protocol.ReasoningEffort(config.ReasoningEffort), model)
+		req.ReasoningEffort = shared.ReasoningEffort(effort)
 	} else if config.HasThinking {
 		extra := req.ExtraFields()
 		if extra == nil {
@@ -117,5 +120,5 @@ func ApplyProviderTransforms(req *openai.ChatCompletionNewParams, provider *typ.
 		return transform(req, provider, model, config)
 	}
 	// Default: apply standard OpenAI-compatible transformations
-	return applyDefaultTransform(req, config)
+	return applyDefaultTransform(req, model, config)
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/openai/openai-go/v3"
 
+	"github.com/tingly-dev/tingly-box/internal/protocol"
 	"github.com/tingly-dev/tingly-box/internal/typ"
 )
 
@@ -87,27 +88,29 @@ func applyGeminiSubsetTransform(req *openai.ChatCompletionNewParams, model strin
 // applyGeminiThinkingConfig converts Anthropic thinking to Gemini's thinking_config.
 // ref: https://ai.google.dev/gemini-api/docs/openai?hl=zh-cn#thinking
 //
-// Mapping for thinking_level (Gemini 3):
-//   - minimal -> minimal
-//   - low -> low
-//   - medium -> medium (not supported on Gemini 3 Pro)
-//   - high -> high
-//
-// Mapping for thinking_budget (Gemini 2.5):
-//   - minimal -> 1024
-//   - low -> 1024
-//   - medium -> 8192
-//   - high -> 24576
+// The effort comes from config.ReasoningEffort (default "low") and is mapped through the
+// shared reasoning table: Gemini 2.5 models get a thinking_budget, Gemini 3 models a
+// thinking_level (Gemini 3 Pro only supports low and high).
 func applyGeminiThinkingConfig(req *openai.ChatCompletionNewParams, model string, config *OpenAIConfig) *openai.ChatCompletionNewParams {
 	extraFields := req.ExtraFields()
 
 	thinkingConfig, hasThinking := extraFields["thinking"].(map[string]interface{})
-	if !hasThinking || thinkingConfig == nil {
+	if (!hasThinking || thinkingConfig == nil) && (config == nil || !config.HasThinking) {
 		return req
 	}
 
-	modelLower := strings.ToLower(model)
-	googleConfig := buildGeminiThinkingConfig(modelLower)
+	reasoning := &protocol.Reasoning{Effort: protocol.ReasoningEffortLow}
+	if config != nil {
+		if parsed := protocol.ReasoningFromEffort(string(config.ReasoningEffort)); parsed != nil {
+			reasoning = parsed
+		}
+	}
+	googleConfig := buildGeminiThinkingConfig(reasoning, model)
+	if googleConfig == nil {
+		delete(extraFields, "thinking")
+		req.SetExtraFields(extraFields)
+		return req
+	}
 
 	// Add include_thoughts if specified
 	if includeThoughts, ok := thinkingConfig["include_thoughts"].(bool); ok && includeThoughts {
@@ -117,6 +120,9 @@ func applyGeminiThinkingConfig(req *openai.ChatCompletionNewParams, model string
 	}
 
 	// Set the extra_body with Google config and remove the original thinking field
+	if extraFields == nil {
+		extraFields = map[string]interface{}{}
+	}
 	extraFields["extra_body"] = map[string]interface{}{"google": googleConfig}
 	delete(extraFields, "thinking")
 
@@ -125,34 +131,20 @@ func applyGeminiThinkingConfig(req *openai.ChatCompletionNewParams, model string
 }
 
 // buildGeminiThinkingConfig builds the thinking_config based on model version.
-func buildGeminiThinkingConfig(modelLower string) map[string]interface{} {
-	// Check if it's Gemini 2.5 (use thinking_budget)
-	if strings.Contains(modelLower, "2.5") || strings.Contains(modelLower, "gemini-2") {
-		return map[string]interface{}{
-			"thinking_config": map[string]interface{}{
-				"thinking_budget": getThinkingBudget(modelLower),
-			},
-		}
+// Returns nil when the model should keep its default thinking behaviour.
+func buildGeminiThinkingConfig(reasoning *protocol.Reasoning, model string) map[string]interface{} {
+	native := reasoning.GeminiThinkingConfig(model)
+	if native == nil {
+		return nil
 	}
 
-	// Gemini 3 uses thinking_level
-	return map[string]interface{}{
-		"thinking_config": map[string]interface{}{
-			"thinking_level": getThinkingLevel(modelLower),
-		},
+	thinkingConfig := map[string]interface{}{}
+	if native.ThinkingBudget != nil {
+		thinkingConfig["thinking_budget"] = int(*native.ThinkingBudget)
+	} else if native.ThinkingLevel != "" {
+		thinkingConfig["thinking_level"] = strings.ToLower(string(native.ThinkingLevel))
 	}
-}
-
-// getThinkingLevel returns the appropriate thinking_level for Gemini 3 models.
-// Default is "low" - can be extended to parse from request parameters if needed.
-func getThinkingLevel(model string) string {
-	return "low"
-}
-
-// getThinkingBudget returns the appropriate thinking_budget for Gemini 2.5 models.
-// Default is 1024 (low) - can be extended to parse from request parameters if needed.
-func getThinkingBudget(model string) int {
-	return 1024
+	return map[string]interface{}{"thinking_config": thinkingConfig}
 }
 
 // ============================================================================
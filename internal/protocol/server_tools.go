@@ -0,0 +1,108 @@
+package protocol
+
+import (
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// ServerToolKind identifies an Anthropic server tool: a tool the provider runs itself, returning
+// server_tool_use and *_tool_result blocks instead of handing a tool_use to the client
+type ServerToolKind string
+
+const (
+	ServerToolWebSearch     ServerToolKind = "web_search"
+	ServerToolWebFetch      ServerToolKind = "web_fetch"
+	ServerToolCodeExecution ServerToolKind = "code_execution"
+)
+
+// serverToolKinds are matched against the versioned tool type, e.g. web_search_20250305
+var serverToolKinds = []ServerToolKind{ServerToolWebSearch, ServerToolWebFetch, ServerToolCodeExecution}
+
+// ServerToolKindOf reports the server tool kind of an Anthropic tool type
+func ServerToolKindOf(toolType string) (ServerToolKind, bool) {
+	for _, kind := range serverToolKinds {
+		if strings.HasPrefix(toolType, string(kind)+"_") {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// ServerTool is an Anthropic server tool declared in a request
+type ServerTool struct {
+	Kind ServerToolKind
+	Type string // versioned tool type, e.g. web_search_20250305
+}
+
+// AnthropicServerTools lists the server tools declared in an Anthropic v1 request
+func AnthropicServerTools(tools []anthropic.ToolUnionParam) []ServerTool {
+	var out []ServerTool
+	for _, tool := range tools {
+		if toolType := tool.GetType(); toolType != nil {
+			if kind, ok := ServerToolKindOf(*toolType); ok {
+				out = append(out, ServerTool{Kind: kind, Type: *toolType})
+			}
+		}
+	}
+	return out
+}
+
+// AnthropicBetaServerTools lists the server tools declared in an Anthropic beta request
+func AnthropicBetaServerTools(tools []anthropic.BetaToolUnionParam) []ServerTool {
+	var out []ServerTool
+	for _, tool := range tools {
+		if toolType := tool.GetType(); toolType != nil {
+			if kind, ok := ServerToolKindOf(*toolType); ok {
+				out = append(out, ServerTool{Kind: kind, Type: *toolType})
+			}
+		}
+	}
+	return out
+}
+
+// ServerToolBackend is the kind of backend a request with server tools is sent to, which decides
+// which server tools have a native equivalent
+type ServerToolBackend string
+
+const (
+	// ServerToolBackendNone is a plain chat completions backend with no built-in tools
+	ServerToolBackendNone      ServerToolBackend = ""
+	ServerToolBackendAnthropic ServerToolBackend = "anthropic"
+	// ServerToolBackendGoogle maps to Google Search grounding, URL context and code execution
+	ServerToolBackendGoogle ServerToolBackend = "google"
+	// ServerToolBackendResponses maps to the Responses API built-in web_search tool
+	ServerToolBackendResponses ServerToolBackend = "responses"
+	// ServerToolBackendGLM maps to the web_search tool of Zhipu GLM chat completions
+	ServerToolBackendGLM ServerToolBackend = "glm"
+)
+
+// Template capability schemas naming a provider's web search flavour
+const (
+	WebSearchSchemaAnthropic = "web_search_anthropic"
+	WebSearchSchemaGLM       = "web_search_glm"
+)
+
+// Supports reports whether the backend has a native equivalent of the server tool
+func (b ServerToolBackend) Supports(kind ServerToolKind) bool {
+	switch b {
+	case ServerToolBackendAnthropic, ServerToolBackendGoogle:
+		return true
+	case ServerToolBackendResponses, ServerToolBackendGLM:
+		return kind == ServerToolWebSearch
+	default:
+		return false
+	}
+}
+
+// Partition splits server tools into those the backend runs natively and those that are stripped
+func (b ServerToolBackend) Partition(tools []ServerTool) (supported, unsupported []ServerTool) {
+	for _, tool := range tools {
+		if b.Supports(tool.Kind) {
+			supported = append(supported, tool)
+		} else {
+			unsupported = append(unsupported, tool)
+		}
+	}
+	return supported, unsupported
+}
@@ -0,0 +1,39 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnthropicServerTools(t *testing.T) {
+	var tools []anthropic.BetaToolUnionParam
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"name": "get_weather", "input_schema": {"type": "object"}},
+		{"type": "web_search_20250305", "name": "web_search"},
+		{"type": "web_fetch_20250910", "name": "web_fetch"},
+		{"type": "code_execution_20250825", "name": "code_execution"},
+		{"type": "bash_20250124", "name": "bash"}
+	]`), &tools))
+
+	serverTools := AnthropicBetaServerTools(tools)
+	assert.Equal(t, []ServerTool{
+		{Kind: ServerToolWebSearch, Type: "web_search_20250305"},
+		{Kind: ServerToolWebFetch, Type: "web_fetch_20250910"},
+		{Kind: ServerToolCodeExecution, Type: "code_execution_20250825"},
+	}, serverTools)
+
+	supported, unsupported := ServerToolBackendGLM.Partition(serverTools)
+	assert.Equal(t, serverTools[:1], supported)
+	assert.Equal(t, serverTools[1:], unsupported)
+
+	supported, unsupported = ServerToolBackendGoogle.Partition(serverTools)
+	assert.Equal(t, serverTools, supported)
+	assert.Empty(t, unsupported)
+
+	supported, _ = ServerToolBackendNone.Partition(serverTools)
+	assert.Empty(t, supported)
+}
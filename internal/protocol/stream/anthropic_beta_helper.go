@@ -30,6 +30,12 @@ func sendBetaStopEvents(c *gin.Context, state *streamState, flusher http.Flusher
 
 	// Send stop events in sorted order and mark as stopped
 	for _, idx := range blockIndices {
+		if input, ok := repairedToolInput(state, idx); ok {
+			sendBetaContentBlockDelta(c, idx, map[string]interface{}{
+				"type":         deltaTypeInputJSONDelta,
+				"partial_json": input,
+			}, flusher)
+		}
 		sendBetaContentBlockStop(c, idx, flusher)
 		state.stoppedBlocks[idx] = true
 	}
@@ -50,10 +56,7 @@ func sendBetaMessageDelta(c *gin.Context, state *streamState, stopReason string,
 	event := map[string]interface{}{
 		"type":  eventTypeMessageDelta,
 		"delta": deltaMap,
-		"usage": map[string]interface{}{
-			"output_tokens": state.outputTokens,
-			"input_tokens":  state.inputTokens,
-		},
+		"usage": messageDeltaUsage(state),
 	}
 	sendAnthropicBetaStreamEvent(c, eventTypeMessageDelta, event, flusher)
 }
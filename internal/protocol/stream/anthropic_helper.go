@@ -26,10 +26,38 @@ func sendStopEvents(c *gin.Context, state *streamState, flusher http.Flusher) {
 
 	// Send stop events in sorted order
 	for _, idx := range blockIndices {
+		if input, ok := repairedToolInput(state, idx); ok {
+			sendContentBlockDelta(c, idx, map[string]interface{}{
+				"type":         deltaTypeInputJSONDelta,
+				"partial_json": input,
+			}, flusher)
+		}
 		sendContentBlockStop(c, idx, flusher)
 	}
 }
 
+// repairedToolInput returns the repaired arguments buffered for the tool block at index,
+// reporting false when tool repair is off or the block is not a tool call
+func repairedToolInput(state *streamState, index int) (string, bool) {
+	if state.toolRepair == nil {
+		return "", false
+	}
+	call, ok := state.pendingToolCalls[index]
+	if !ok {
+		return "", false
+	}
+	input, _ := state.toolRepair.Repair(call.name, call.input)
+	return input, input != ""
+}
+
+// streamEndStopReason is the stop reason for a stream that ended without a finish_reason
+func streamEndStopReason(state *streamState) string {
+	if len(state.pendingToolCalls) > 0 {
+		return anthropicStopReasonToolUse
+	}
+	return anthropicStopReasonEndTurn
+}
+
 // sendMessageDelta sends message_delta event
 func sendMessageDelta(c *gin.Context, state *streamState, stopReason string, flusher http.Flusher) {
 	// Build delta with accumulated extras
@@ -45,10 +73,7 @@ func sendMessageDelta(c *gin.Context, state *streamState, stopReason string, flu
 	event := map[string]interface{}{
 		"type":  eventTypeMessageDelta,
 		"delta": deltaMap,
-		"usage": map[string]interface{}{
-			"output_tokens": state.outputTokens,
-			"input_tokens":  state.inputTokens,
-		},
+		"usage": messageDeltaUsage(state),
 	}
 	sendAnthropicStreamEvent(c, eventTypeMessageDelta, event, flusher)
 }
@@ -115,3 +140,31 @@ func sendContentBlockStop(c *gin.Context, index int, flusher http.Flusher) {
 	}
 	sendAnthropicStreamEvent(c, eventTypeContentBlockStop, event, flusher)
 }
+
+// messageDeltaUsage builds the usage of the final message_delta, counting emulated web searches
+func messageDeltaUsage(state *streamState) map[string]interface{} {
+	usage := map[string]interface{}{
+		"output_tokens": state.outputTokens,
+		"input_tokens":  state.inputTokens,
+	}
+	if state.webSearchRequests > 0 {
+		usage["server_tool_use"] = map[string]interface{}{"web_search_requests": state.webSearchRequests}
+	}
+	return usage
+}
+
+// sendServerToolBlocks streams server tool blocks (server_tool_use and their results) produced by
+// a backend's native tool. Their content is known up front, so each block is sent whole as a
+// start/stop pair without deltas.
+func sendServerToolBlocks(c *gin.Context, state *streamState, blocks []map[string]interface{}, flusher http.Flusher) {
+	for _, block := range blocks {
+		index := state.nextBlockIndex
+		state.nextBlockIndex++
+		sendAnthropicStreamEvent(c, eventTypeContentBlockStart, map[string]interface{}{
+			"type":          eventTypeContentBlockStart,
+			"index":         index,
+			"content_block": block,
+		}, flusher)
+		sendContentBlockStop(c, index, flusher)
+	}
+}
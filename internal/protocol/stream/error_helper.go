@@ -0,0 +1,47 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+)
+
+// anthropicErrorEvent builds the Anthropic error event for an upstream error, keeping the error
+// type the client SDK decides retries on (e.g. overloaded_error, rate_limit_error)
+func anthropicErrorEvent(err error) map[string]interface{} {
+	return protocol.ParseUpstreamError(err).Body(protocol.APIStyleAnthropic)
+}
+
+// sendAnthropicErrorEvent reports an upstream error as an Anthropic SSE error event
+func sendAnthropicErrorEvent(c *gin.Context, err error, flusher http.Flusher) {
+	sendAnthropicStreamEvent(c, eventTypeError, anthropicErrorEvent(err), flusher)
+}
+
+// sendOpenAIErrorChunk ends an OpenAI stream on an upstream error: a data-only error chunk, then
+// [DONE] so clients waiting for the terminator stop reading
+func sendOpenAIErrorChunk(c *gin.Context, err error, flusher http.Flusher) {
+	c.SSEvent("", protocol.ParseUpstreamError(err).Body(protocol.APIStyleOpenAI))
+	c.SSEvent("", "[DONE]")
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+// sendGoogleErrorChunk reports an upstream error the way Gemini does mid-stream: a data line
+// holding an {"error": {code, message, status}} object
+func sendGoogleErrorChunk(c *gin.Context, err error, flusher http.Flusher) {
+	errJSON, marshalErr := json.Marshal(protocol.ParseUpstreamError(err).Body(protocol.APIStyleGoogle))
+	if marshalErr != nil {
+		logrus.Errorf("Failed to marshal Google stream error: %v", marshalErr)
+		return
+	}
+	c.Writer.Write([]byte(fmt.Sprintf("data: %s\n\n", errJSON)))
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,307 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tingly-dev/tingly-box/internal/obs"
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+)
+
+// Defaults for KeepAliveConfig
+const (
+	DefaultPingInterval = 15 * time.Second
+	DefaultIdleTimeout  = 5 * time.Minute
+)
+
+// KeepAliveContextKey is the gin context key holding the KeepAliveConfig of a request
+const KeepAliveContextKey = "stream_keep_alive"
+
+// StatsContextKey is the gin context key holding the *Stats of a request's upstream stream
+const StatsContextKey = "stream_stats"
+
+// Stats records when upstream events of a stream arrived, for time-to-first-token and throughput
+// measurement. Keep-alive pings are not events.
+type Stats struct {
+	FirstEvent time.Time // zero until the first event
+	LastEvent  time.Time
+	Chunks     int
+}
+
+// Duration returns the time from the first to the last upstream event
+func (s *Stats) Duration() time.Duration {
+	if s == nil || s.FirstEvent.IsZero() {
+		return 0
+	}
+	return s.LastEvent.Sub(s.FirstEvent)
+}
+
+// StatsFromContext returns the stream stats of a request, or nil when it was not streamed
+func StatsFromContext(c *gin.Context) *Stats {
+	stats, _ := c.Value(StatsContextKey).(*Stats)
+	return stats
+}
+
+// observe records an upstream event
+func (s *Stats) observe(now time.Time) {
+	if s.FirstEvent.IsZero() {
+		s.FirstEvent = now
+	}
+	s.LastEvent = now
+	s.Chunks++
+}
+
+// KeepAliveConfig controls how streams keep a quiet client connection open (long thinking phases
+// send nothing, and proxies cut idle connections) and when they give up on a stalled upstream
+type KeepAliveConfig struct {
+	PingInterval int64 `json:"ping_interval,omitempty" yaml:"ping_interval,omitempty"` // Seconds of upstream silence between pings (default 15, negative disables)
+	IdleTimeout  int64 `json:"idle_timeout,omitempty" yaml:"idle_timeout,omitempty"`   // Seconds of upstream silence before the stream is aborted (default 300, negative disables)
+}
+
+// durations resolves the configured intervals; a zero duration turns that clock off
+func (k KeepAliveConfig) durations() (ping, idle time.Duration) {
+	ping, idle = DefaultPingInterval, DefaultIdleTimeout
+	if k.PingInterval != 0 {
+		ping = time.Duration(max(k.PingInterval, 0)) * time.Second
+	}
+	if k.IdleTimeout != 0 {
+		idle = time.Duration(max(k.IdleTimeout, 0)) * time.Second
+	}
+	return ping, idle
+}
+
+// SSEStream is the part of the OpenAI and Anthropic SDK SSE streams the keep-alive wrapper reads
+type SSEStream[T any] interface {
+	Next() bool
+	Current() T
+	Err() error
+	Close() error
+}
+
+// KeepAliveStream reads an upstream stream in its own goroutine and hands events to the handler
+// goroutine, which pings the client while upstream is silent and aborts when the silence outlasts
+// the idle timeout. All writes to the client stay on the handler goroutine.
+type KeepAliveStream[T any] struct {
+	events   chan T
+	stop     chan struct{}
+	abort    func()
+	ping     func()
+	stats    *Stats
+	ctx      context.Context // client request context, nil in tests without a request
+	span     trace.Span      // covers reading and converting the stream, nil without a request
+	interval time.Duration
+	pingTick *time.Ticker
+	idle     time.Duration
+	idleTime *time.Timer
+
+	current     T
+	upstreamErr error // set by the reader before events is closed
+	err         error
+	finished    bool
+}
+
+// KeepAlive wraps an SDK SSE stream, pinging in the client's dialect
+func KeepAlive[T any](c *gin.Context, s SSEStream[T], style protocol.APIStyle) *KeepAliveStream[T] {
+	return newKeepAliveStream(c, style, func(emit func(T) bool) error {
+		for s.Next() {
+			if !emit(s.Current()) {
+				return nil
+			}
+		}
+		return s.Err()
+	}, func() { s.Close() })
+}
+
+// KeepAliveSeq wraps an iterator stream such as the Gemini SDK's, pinging in the client's dialect.
+// An aborted iterator cannot be interrupted; its reader exits with the next upstream event.
+func KeepAliveSeq[T any](c *gin.Context, seq iter.Seq2[T, error], style protocol.APIStyle) *KeepAliveStream[T] {
+	return newKeepAliveStream(c, style, func(emit func(T) bool) error {
+		for event, err := range seq {
+			if err != nil {
+				return err
+			}
+			if !emit(event) {
+				return nil
+			}
+		}
+		return nil
+	}, nil)
+}
+
+func newKeepAliveStream[T any](c *gin.Context, style protocol.APIStyle, read func(emit func(T) bool) error, abort func()) *KeepAliveStream[T] {
+	config, _ := c.Get(KeepAliveContextKey)
+	keepAlive, _ := config.(KeepAliveConfig)
+	pingInterval, idle := keepAlive.durations()
+
+	k := &KeepAliveStream[T]{
+		events:   make(chan T),
+		stop:     make(chan struct{}),
+		abort:    abort,
+		ping:     keepAlivePing(c, style),
+		stats:    &Stats{},
+		interval: pingInterval,
+		idle:     idle,
+	}
+	c.Set(StatsContextKey, k.stats)
+	if c.Request != nil {
+		k.ctx = c.Request.Context()
+		_, k.span = obs.StartSpan(k.ctx, "stream", obs.AttrDialect.String(string(style)))
+	}
+	if pingInterval > 0 {
+		k.pingTick = time.NewTicker(pingInterval)
+	}
+	if idle > 0 {
+		k.idleTime = time.NewTimer(idle)
+	}
+
+	go func() {
+		defer close(k.events)
+		k.upstreamErr = read(func(event T) bool {
+			select {
+			case k.events <- event:
+				return true
+			case <-k.stop:
+				return false
+			}
+		})
+	}()
+	return k
+}
+
+// Next waits for the next upstream event, reporting false when the stream ended, failed, stalled
+// or the client went away
+func (k *KeepAliveStream[T]) Next() bool {
+	if k.finished {
+		return false
+	}
+	var pingC, idleC <-chan time.Time
+	var done <-chan struct{}
+	if k.ctx != nil {
+		done = k.ctx.Done()
+	}
+	if k.pingTick != nil {
+		pingC = k.pingTick.C
+	}
+	if k.idleTime != nil {
+		idleC = k.idleTime.C
+	}
+
+	for {
+		select {
+		case event, ok := <-k.events:
+			if !ok {
+				k.err = k.upstreamErr
+				k.finish(false)
+				return false
+			}
+			if k.stats.Chunks == 0 && k.span != nil {
+				k.span.AddEvent("first_event")
+			}
+			k.stats.observe(time.Now())
+			k.current = event
+			k.quiet()
+			return true
+		case <-pingC:
+			k.ping()
+		case <-idleC:
+			k.err = &protocol.UpstreamError{
+				Kind:       protocol.ErrorKindTimeout,
+				StatusCode: http.StatusGatewayTimeout,
+				Message:    fmt.Sprintf("upstream sent nothing for %s", k.idle),
+			}
+			k.finish(true)
+			return false
+		case <-done:
+			k.err = fmt.Errorf("client disconnected: %w", k.ctx.Err())
+			k.finish(true)
+			return false
+		}
+	}
+}
+
+// Current returns the event read by the last successful Next
+func (k *KeepAliveStream[T]) Current() T {
+	return k.current
+}
+
+// Err returns the upstream error, the idle timeout or the client disconnect that ended the stream
+func (k *KeepAliveStream[T]) Err() error {
+	return k.err
+}
+
+// All iterates the events, ending with the error that stopped the stream, if any
+func (k *KeepAliveStream[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer k.Close()
+		for k.Next() {
+			if !yield(k.current, nil) {
+				return
+			}
+		}
+		if k.err != nil {
+			var zero T
+			yield(zero, k.err)
+		}
+	}
+}
+
+// Close stops reading upstream. Handlers defer it so returning before the end of the stream does
+// not strand the reader goroutine.
+func (k *KeepAliveStream[T]) Close() {
+	k.finish(true)
+}
+
+// quiet restarts the ping and idle clocks after upstream sent something
+func (k *KeepAliveStream[T]) quiet() {
+	if k.pingTick != nil {
+		k.pingTick.Reset(k.interval)
+	}
+	if k.idleTime != nil {
+		k.idleTime.Reset(k.idle)
+	}
+}
+
+// finish stops the clocks; abort also stops the reader and closes the upstream
+func (k *KeepAliveStream[T]) finish(abort bool) {
+	if k.finished {
+		return
+	}
+	k.finished = true
+	if k.pingTick != nil {
+		k.pingTick.Stop()
+	}
+	if k.idleTime != nil {
+		k.idleTime.Stop()
+	}
+	if abort {
+		close(k.stop)
+		if k.abort != nil {
+			k.abort()
+		}
+	}
+	if k.span != nil {
+		obs.EndSpan(k.span, k.err)
+	}
+}
+
+// keepAlivePing writes a keep-alive the client's SDK ignores: Anthropic's own ping event, or an
+// SSE comment line for OpenAI and Gemini clients
+func keepAlivePing(c *gin.Context, style protocol.APIStyle) func() {
+	flusher, _ := c.Writer.(http.Flusher)
+	return func() {
+		if style == protocol.APIStyleAnthropic {
+			c.SSEvent(eventTypePing, map[string]interface{}{"type": eventTypePing})
+		} else {
+			c.Writer.Write([]byte(": ping\n\n"))
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	anthropicOption "github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/gin-gonic/gin"
+	"github.com/openai/openai-go/v3"
+	openaiOption "github.com/openai/openai-go/v3/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+)
+
+// stallingUpstream serves the given SSE frames, then either pauses for a while and sends the rest
+// or hangs until the client goes away
+func stallingUpstream(t *testing.T, before []string, pause time.Duration, after []string) *httptest.Server {
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		for _, frame := range before {
+			fmt.Fprint(w, frame)
+		}
+		flusher.Flush()
+
+		if after == nil {
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+			return
+		}
+		time.Sleep(pause)
+		for _, frame := range after {
+			fmt.Fprint(w, frame)
+		}
+	}))
+	t.Cleanup(func() {
+		close(release)
+		upstream.Close()
+	})
+	return upstream
+}
+
+func keepAliveContext(config KeepAliveConfig) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set(KeepAliveContextKey, config)
+	return c, w
+}
+
+func TestKeepAlivePingsAnthropicClientDuringSilence(t *testing.T) {
+	chunk := func(delta string) string {
+		return fmt.Sprintf("data: {\"id\":\"c1\",\"object\":\"chat.completion.chunk\",\"model\":\"gpt-4o\",\"choices\":[{\"index\":0,\"delta\":%s}]}\n\n", delta)
+	}
+	upstream := stallingUpstream(t,
+		[]string{chunk(`{"role":"assistant"}`)},
+		1500*time.Millisecond,
+		[]string{chunk(`{"content":"done thinking"}`), chunk(`{},"finish_reason":"stop"`), "data: [DONE]\n\n"},
+	)
+
+	req := openai.ChatCompletionNewParams{
+		Model:    "gpt-4o",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("Think hard")},
+	}
+	client := openai.NewClient(openaiOption.WithAPIKey("test"), openaiOption.WithBaseURL(upstream.URL))
+	stream := client.Chat.Completions.NewStreaming(context.Background(), req)
+
+	c, w := keepAliveContext(KeepAliveConfig{PingInterval: 1, IdleTimeout: 10})
+	require.NoError(t, HandleOpenAIToAnthropicStreamResponse(c, &req, stream, "claude-sonnet-4-5", nil))
+
+	body := w.Body.String()
+	ping := strings.Index(body, "event:ping\ndata:{\"type\":\"ping\"}")
+	require.GreaterOrEqual(t, ping, 0, "expected a ping while upstream was silent")
+	assert.Greater(t, strings.Index(body, "done thinking"), ping)
+	assert.Contains(t, body, "event:message_stop")
+
+	stats := StatsFromContext(c)
+	require.NotNil(t, stats)
+	assert.Equal(t, 3, stats.Chunks, "pings are not upstream events")
+	assert.GreaterOrEqual(t, stats.Duration(), time.Second, "the stream spans the silence")
+}
+
+func TestKeepAliveIdleTimeoutEndsOpenAIStream(t *testing.T) {
+	upstream := stallingUpstream(t, []string{
+		"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\",\"type\":\"message\",\"role\":\"assistant\",\"model\":\"claude-sonnet-4-5\",\"content\":[],\"usage\":{\"input_tokens\":12,\"output_tokens\":1}}}\n\n",
+	}, 0, nil)
+
+	req := anthropic.MessageNewParams{
+		Model:     "claude-sonnet-4-5",
+		MaxTokens: 1024,
+		Messages:  []anthropic.MessageParam{anthropic.NewUserMessage(anthropic.NewTextBlock("Hello"))},
+	}
+	client := anthropic.NewClient(anthropicOption.WithAPIKey("test"), anthropicOption.WithBaseURL(upstream.URL))
+	stream := client.Messages.NewStreaming(context.Background(), req)
+
+	c, w := keepAliveContext(KeepAliveConfig{PingInterval: -1, IdleTimeout: 1})
+	start := time.Now()
+	inputTokens, _, err := HandleAnthropicToOpenAIStreamResponse(c, &req, stream, "gpt-4o")
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, protocol.ErrorKindTimeout, protocol.ParseUpstreamError(err).Kind)
+	assert.Equal(t, 12, inputTokens, "usage seen before the stall is kept for partial accounting")
+
+	body := w.Body.String()
+	assert.NotContains(t, body, ": ping")
+	assert.Contains(t, body, `"code":"timeout"`)
+	assert.True(t, strings.HasSuffix(body, "data:[DONE]\n\n"), "stream must end with [DONE], got %q", body)
+}
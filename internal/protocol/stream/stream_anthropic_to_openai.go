@@ -12,6 +12,8 @@ import (
 	anthropicstream "github.com/anthropics/anthropic-sdk-go/packages/ssestream"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
 )
 
 // HandleAnthropicToOpenAIStreamResponse processes Anthropic streaming events and converts them to OpenAI format
@@ -56,15 +58,29 @@ func HandleAnthropicToOpenAIStreamResponse(c *gin.Context, req *anthropic.Messag
 		usage        *anthropic.MessageDeltaUsage
 		inputTokens  int
 		outputTokens int
+
+		// Emulated response_format: the synthetic tool's input is streamed as content
+		structuredSchema    map[string]interface{}
+		hasStructuredOutput bool
+		structuredIndex     = int64(-1)
+		structuredJSON      = strings.Builder{}
 	)
+	if req != nil {
+		structuredSchema, hasStructuredOutput = protocol.StructuredOutputToolSchema(req.Tools)
+	}
 
 	// Process the stream
-	for stream.Next() {
-		event := stream.Current()
+	events := KeepAlive(c, stream, protocol.APIStyleOpenAI)
+	defer events.Close()
+	for events.Next() {
+		event := events.Current()
 
 		// Handle different event types
 		switch event.Type {
 		case "message_start":
+			// Input usage arrives up front; keep it in case the stream breaks before message_delta
+			inputTokens = int(event.Message.Usage.InputTokens)
+
 			// Send initial chat completion chunk
 			chunk := map[string]interface{}{
 				"id":      chatID,
@@ -87,6 +103,9 @@ func HandleAnthropicToOpenAIStreamResponse(c *gin.Context, req *anthropic.Messag
 				// Reset content builder for new block
 				contentText.Reset()
 			}
+			if hasStructuredOutput && event.ContentBlock.Type == "tool_use" && event.ContentBlock.Name == protocol.StructuredOutputToolName {
+				structuredIndex = event.Index
+			}
 
 		case "content_block_delta":
 			// Text delta - send as OpenAI chunk
@@ -109,6 +128,40 @@ func HandleAnthropicToOpenAIStreamResponse(c *gin.Context, req *anthropic.Messag
 				}
 				sendOpenAIStreamChunk(c, chunk)
 			}
+			if event.Delta.Type == "thinking_delta" && event.Delta.Thinking != "" {
+				// Extended thinking surfaces as reasoning_content, as DeepSeek-style clients expect
+				chunk := map[string]interface{}{
+					"id":      chatID,
+					"object":  "chat.completion.chunk",
+					"created": created,
+					"model":   responseModel,
+					"choices": []map[string]interface{}{
+						{
+							"index":         0,
+							"delta":         map[string]interface{}{OpenaiFieldReasoningContent: event.Delta.Thinking},
+							"finish_reason": nil,
+						},
+					},
+				}
+				sendOpenAIStreamChunk(c, chunk)
+			}
+			if event.Delta.Type == "input_json_delta" && event.Index == structuredIndex && event.Delta.PartialJSON != "" {
+				structuredJSON.WriteString(event.Delta.PartialJSON)
+				chunk := map[string]interface{}{
+					"id":      chatID,
+					"object":  "chat.completion.chunk",
+					"created": created,
+					"model":   responseModel,
+					"choices": []map[string]interface{}{
+						{
+							"index":         0,
+							"delta":         map[string]interface{}{"content": event.Delta.PartialJSON},
+							"finish_reason": nil,
+						},
+					},
+				}
+				sendOpenAIStreamChunk(c, chunk)
+			}
 
 		case "content_block_stop":
 			// Content block finished - no specific action needed
@@ -122,6 +175,19 @@ func HandleAnthropicToOpenAIStreamResponse(c *gin.Context, req *anthropic.Messag
 			}
 
 		case "message_stop":
+			if structuredIndex >= 0 {
+				if err := protocol.ValidateStructuredOutput(structuredJSON.String(), structuredSchema); err != nil {
+					logrus.Warnf("Structured output validation failed: %v", err)
+					c.SSEvent("", map[string]interface{}{
+						"error": map[string]interface{}{
+							"message": err.Error(),
+							"type":    "api_error",
+							"code":    "structured_output_mismatch",
+						},
+					})
+				}
+			}
+
 			// Send final chunk with finish_reason and usage
 			chunk := map[string]interface{}{
 				"id":      chatID,
@@ -154,22 +220,16 @@ func HandleAnthropicToOpenAIStreamResponse(c *gin.Context, req *anthropic.Messag
 	}
 
 	// Check for stream errors
-	if err := stream.Err(); err != nil {
+	if err := events.Err(); err != nil {
 		// EOF is expected when stream ends normally
 		if errors.Is(err, io.EOF) {
 			logrus.Info("Anthropic stream ended normally (EOF)")
 			return inputTokens, outputTokens, nil
 		}
 		logrus.Errorf("Anthropic stream error: %v", err)
-		// Send error event
-		c.SSEvent("", map[string]interface{}{
-			"error": map[string]interface{}{
-				"message": err.Error(),
-				"type":    "stream_error",
-				"code":    "stream_failed",
-			},
-		})
-		return inputTokens, outputTokens, nil
+		flusher, _ := c.Writer.(http.Flusher)
+		sendOpenAIErrorChunk(c, err, flusher)
+		return inputTokens, outputTokens, err
 	}
 
 	return inputTokens, outputTokens, nil
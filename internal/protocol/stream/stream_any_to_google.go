@@ -15,6 +15,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"google.golang.org/genai"
 
+	"github.com/tingly-dev/tingly-box/internal/protocol"
 	"github.com/tingly-dev/tingly-box/internal/protocol/nonstream"
 )
 
@@ -63,8 +64,10 @@ func HandleOpenAIToGoogleStreamResponse(c *gin.Context, stream *openaistream.Str
 	)
 
 	// Process the stream
-	for stream.Next() {
-		chunk := stream.Current()
+	events := KeepAlive(c, stream, protocol.APIStyleGoogle)
+	defer events.Close()
+	for events.Next() {
+		chunk := events.Current()
 
 		// Check if we have choices
 		if len(chunk.Choices) == 0 {
@@ -77,6 +80,13 @@ func HandleOpenAIToGoogleStreamResponse(c *gin.Context, stream *openaistream.Str
 		choice := chunk.Choices[0]
 		delta := choice.Delta
 
+		// reasoning_content (DeepSeek-style extension) streams as thought parts
+		if extras := parseRawJSON(delta.RawJSON()); extras != nil {
+			if reasoning, ok := extras[OpenaiFieldReasoningContent].(string); ok && reasoning != "" {
+				sendGoogleThoughtChunk(c, reasoning, flusher)
+			}
+		}
+
 		// Handle content delta
 		if delta.Content != "" {
 			currentContent.WriteString(delta.Content)
@@ -201,8 +211,9 @@ func HandleOpenAIToGoogleStreamResponse(c *gin.Context, stream *openaistream.Str
 	}
 
 	// Check for stream errors
-	if err := stream.Err(); err != nil {
+	if err := events.Err(); err != nil {
 		logrus.Errorf("OpenAI stream error: %v", err)
+		sendGoogleErrorChunk(c, err, flusher)
 		return nil
 	}
 
@@ -250,11 +261,18 @@ func HandleAnthropicToGoogleStreamResponse(c *gin.Context, stream *anthropicstre
 	)
 
 	// Process the stream
-	for stream.Next() {
-		event := stream.Current()
+	events := KeepAlive(c, stream, protocol.APIStyleGoogle)
+	defer events.Close()
+	for events.Next() {
+		event := events.Current()
 
 		switch event.Type {
 		case "content_block_delta":
+			// Extended thinking streams as thought parts
+			if event.Delta.Type == "thinking_delta" && event.Delta.Thinking != "" {
+				sendGoogleThoughtChunk(c, event.Delta.Thinking, flusher)
+			}
+
 			// Text delta - send as Google chunk
 			if event.Delta.Type == "text_delta" && event.Delta.Text != "" {
 				currentContent.WriteString(event.Delta.Text)
@@ -370,8 +388,9 @@ func HandleAnthropicToGoogleStreamResponse(c *gin.Context, stream *anthropicstre
 	}
 
 	// Check for stream errors
-	if err := stream.Err(); err != nil {
+	if err := events.Err(); err != nil {
 		logrus.Errorf("Anthropic stream error: %v", err)
+		sendGoogleErrorChunk(c, err, flusher)
 		return nil
 	}
 
@@ -389,3 +408,18 @@ func sendGoogleStreamChunk(c *gin.Context, resp *genai.GenerateContentResponse,
 	c.Writer.Write([]byte(fmt.Sprintf("data: %s\n\n", string(chunkJSON))))
 	flusher.Flush()
 }
+
+// sendGoogleThoughtChunk sends a piece of reasoning as an incremental Gemini thought part
+func sendGoogleThoughtChunk(c *gin.Context, text string, flusher http.Flusher) {
+	sendGoogleStreamChunk(c, &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{
+			{
+				Content: &genai.Content{
+					Role:  "model",
+					Parts: []*genai.Part{{Text: text, Thought: true}},
+				},
+				Index: 0,
+			},
+		},
+	}, flusher)
+}
@@ -12,6 +12,7 @@ import (
 	"google.golang.org/genai"
 	"iter"
 
+	"github.com/tingly-dev/tingly-box/internal/protocol"
 	"github.com/tingly-dev/tingly-box/internal/protocol/nonstream"
 )
 
@@ -52,9 +53,10 @@ func HandleGoogleToOpenAIStreamResponse(c *gin.Context, stream iter.Seq2[*genai.
 	)
 
 	// Process the stream
-	for googleResp, err := range stream {
+	for googleResp, err := range KeepAliveSeq(c, stream, protocol.APIStyleOpenAI).All() {
 		if err != nil {
 			logrus.Errorf("Google stream error: %v", err)
+			sendOpenAIErrorChunk(c, err, flusher)
 			return nil
 		}
 
@@ -84,6 +86,26 @@ func HandleGoogleToOpenAIStreamResponse(c *gin.Context, stream iter.Seq2[*genai.
 			// Extract content
 			if candidate.Content != nil {
 				for _, part := range candidate.Content.Parts {
+					// Thought summaries (includeThoughts) stream as reasoning_content
+					if part.Thought {
+						if part.Text != "" {
+							sendOpenAIStreamChunk(c, map[string]interface{}{
+								"id":      chatID,
+								"object":  "chat.completion.chunk",
+								"created": created,
+								"model":   responseModel,
+								"choices": []map[string]interface{}{
+									{
+										"index":         0,
+										"delta":         map[string]interface{}{OpenaiFieldReasoningContent: part.Text},
+										"finish_reason": nil,
+									},
+								},
+							})
+						}
+						continue
+					}
+
 					// Handle text parts
 					if part.Text != "" {
 						// Send text delta
@@ -219,11 +241,44 @@ func HandleGoogleToAnthropicStreamResponse(c *gin.Context, stream iter.Seq2[*gen
 
 	// Track streaming state
 	var (
-		textBlockIndex = -1
-		toolBlockIndex = -1
-		outputTokens   int64
+		textBlockIndex     = -1
+		thinkingBlockIndex = -1
+		nextBlockIndex     = 0
+		outputTokens       int64
+		webSearchRequests  int
+		codeExecution      = nonstream.NewGoogleCodeExecution(messageID)
 	)
 
+	// closeBlock sends content_block_stop for an open block and marks it closed
+	closeBlock := func(index *int) {
+		if *index == -1 {
+			return
+		}
+		sendAnthropicStreamEventFromG(c, "content_block_stop", map[string]interface{}{
+			"type":  "content_block_stop",
+			"index": *index,
+		}, flusher)
+		*index = -1
+	}
+
+	// sendServerToolBlock sends a server tool block Gemini ran natively (code execution, Google
+	// Search grounding) whole, as a start/stop pair after closing any open block
+	sendServerToolBlock := func(block map[string]interface{}) {
+		closeBlock(&thinkingBlockIndex)
+		closeBlock(&textBlockIndex)
+		index := nextBlockIndex
+		nextBlockIndex++
+		sendAnthropicStreamEventFromG(c, "content_block_start", map[string]interface{}{
+			"type":          "content_block_start",
+			"index":         index,
+			"content_block": block,
+		}, flusher)
+		sendAnthropicStreamEventFromG(c, "content_block_stop", map[string]interface{}{
+			"type":  "content_block_stop",
+			"index": index,
+		}, flusher)
+	}
+
 	// Send message_start event first
 	messageStartEvent := map[string]interface{}{
 		"type": "message_start",
@@ -244,18 +299,10 @@ func HandleGoogleToAnthropicStreamResponse(c *gin.Context, stream iter.Seq2[*gen
 	sendAnthropicStreamEventFromG(c, "message_start", messageStartEvent, flusher)
 
 	// Process the stream
-	for googleResp, err := range stream {
+	for googleResp, err := range KeepAliveSeq(c, stream, protocol.APIStyleAnthropic).All() {
 		if err != nil {
 			logrus.Errorf("Google stream error: %v", err)
-			errorEvent := map[string]interface{}{
-				"type": "error",
-				"error": map[string]interface{}{
-					"message": err.Error(),
-					"type":    "stream_error",
-					"code":    "stream_failed",
-				},
-			}
-			sendAnthropicStreamEventFromG(c, "error", errorEvent, flusher)
+			sendAnthropicStreamEventFromG(c, eventTypeError, anthropicErrorEvent(err), flusher)
 			return nil
 		}
 
@@ -266,11 +313,55 @@ func HandleGoogleToAnthropicStreamResponse(c *gin.Context, stream iter.Seq2[*gen
 			// Extract content
 			if candidate.Content != nil {
 				for _, part := range candidate.Content.Parts {
+					if block := codeExecution.Block(part); block != nil {
+						sendServerToolBlock(block)
+						continue
+					}
+
+					// Thought summaries (includeThoughts) stream as a thinking block
+					if part.Thought {
+						if thinkingBlockIndex == -1 {
+							thinkingBlockIndex = nextBlockIndex
+							nextBlockIndex++
+							sendAnthropicStreamEventFromG(c, "content_block_start", map[string]interface{}{
+								"type":  "content_block_start",
+								"index": thinkingBlockIndex,
+								"content_block": map[string]interface{}{
+									"type":     "thinking",
+									"thinking": "",
+								},
+							}, flusher)
+						}
+						if part.Text != "" {
+							sendAnthropicStreamEventFromG(c, "content_block_delta", map[string]interface{}{
+								"type":  "content_block_delta",
+								"index": thinkingBlockIndex,
+								"delta": map[string]interface{}{
+									"type":     "thinking_delta",
+									"thinking": part.Text,
+								},
+							}, flusher)
+						}
+						if len(part.ThoughtSignature) > 0 {
+							sendAnthropicStreamEventFromG(c, "content_block_delta", map[string]interface{}{
+								"type":  "content_block_delta",
+								"index": thinkingBlockIndex,
+								"delta": map[string]interface{}{
+									"type":      "signature_delta",
+									"signature": nonstream.GoogleThoughtSignature(part),
+								},
+							}, flusher)
+						}
+						continue
+					}
+
 					// Handle text parts
 					if part.Text != "" {
 						// Send content_block_start for text on first occurrence
 						if textBlockIndex == -1 {
-							textBlockIndex = 0
+							closeBlock(&thinkingBlockIndex)
+							textBlockIndex = nextBlockIndex
+							nextBlockIndex++
 							contentBlockStartEvent := map[string]interface{}{
 								"type":  "content_block_start",
 								"index": textBlockIndex,
@@ -296,7 +387,11 @@ func HandleGoogleToAnthropicStreamResponse(c *gin.Context, stream iter.Seq2[*gen
 
 					// Handle function calls
 					if part.FunctionCall != nil {
-						toolBlockIndex++
+						// Tool calls follow any open thinking or text block, which must be closed first
+						closeBlock(&thinkingBlockIndex)
+						closeBlock(&textBlockIndex)
+						toolBlockIndex := nextBlockIndex
+						nextBlockIndex++
 						// Send content_block_start for tool_use
 						contentBlockStartEvent := map[string]interface{}{
 							"type":  "content_block_start",
@@ -320,10 +415,21 @@ func HandleGoogleToAnthropicStreamResponse(c *gin.Context, stream iter.Seq2[*gen
 				}
 			}
 
+			// Google Search grounding arrives with the final chunks; report it once as a web search
+			if webSearchRequests == 0 {
+				for _, block := range nonstream.GoogleGroundingBlocks(messageID, candidate.GroundingMetadata) {
+					sendServerToolBlock(block)
+				}
+				webSearchRequests = nonstream.GoogleWebSearchRequests(candidate.GroundingMetadata)
+			}
+
 			// Check for finish reason
 			if candidate.FinishReason != "" {
 				stopReason := nonstream.MapGoogleFinishReasonToAnthropic(candidate.FinishReason)
 
+				// Close any open thinking block
+				closeBlock(&thinkingBlockIndex)
+
 				// Send content_block_stop for text if applicable
 				if textBlockIndex != -1 {
 					contentBlockStopEvent := map[string]interface{}{
@@ -349,6 +455,9 @@ func HandleGoogleToAnthropicStreamResponse(c *gin.Context, stream iter.Seq2[*gen
 						"output_tokens": outputTokens,
 					},
 				}
+				if webSearchRequests > 0 {
+					messageDeltaEvent["usage"].(map[string]interface{})["server_tool_use"] = map[string]interface{}{"web_search_requests": webSearchRequests}
+				}
 				sendAnthropicStreamEventFromG(c, "message_delta", messageDeltaEvent, flusher)
 
 				// Send message_stop
@@ -415,11 +524,44 @@ func HandleGoogleToAnthropicBetaStreamResponse(c *gin.Context, stream iter.Seq2[
 
 	// Track streaming state
 	var (
-		textBlockIndex = -1
-		toolBlockIndex = -1
-		outputTokens   int64
+		textBlockIndex     = -1
+		thinkingBlockIndex = -1
+		nextBlockIndex     = 0
+		outputTokens       int64
+		webSearchRequests  int
+		codeExecution      = nonstream.NewGoogleCodeExecution(messageID)
 	)
 
+	// closeBlock sends content_block_stop for an open block and marks it closed
+	closeBlock := func(index *int) {
+		if *index == -1 {
+			return
+		}
+		sendAnthropicBetaStreamEventFromG(c, eventTypeContentBlockStop, map[string]interface{}{
+			"type":  eventTypeContentBlockStop,
+			"index": *index,
+		}, flusher)
+		*index = -1
+	}
+
+	// sendServerToolBlock sends a server tool block Gemini ran natively (code execution, Google
+	// Search grounding) whole, as a start/stop pair after closing any open block
+	sendServerToolBlock := func(block map[string]interface{}) {
+		closeBlock(&thinkingBlockIndex)
+		closeBlock(&textBlockIndex)
+		index := nextBlockIndex
+		nextBlockIndex++
+		sendAnthropicBetaStreamEventFromG(c, eventTypeContentBlockStart, map[string]interface{}{
+			"type":          eventTypeContentBlockStart,
+			"index":         index,
+			"content_block": block,
+		}, flusher)
+		sendAnthropicBetaStreamEventFromG(c, eventTypeContentBlockStop, map[string]interface{}{
+			"type":  eventTypeContentBlockStop,
+			"index": index,
+		}, flusher)
+	}
+
 	// Send message_start event first
 	messageStartEvent := map[string]interface{}{
 		"type": eventTypeMessageStart,
@@ -440,18 +582,10 @@ func HandleGoogleToAnthropicBetaStreamResponse(c *gin.Context, stream iter.Seq2[
 	sendAnthropicBetaStreamEventFromG(c, eventTypeMessageStart, messageStartEvent, flusher)
 
 	// Process the stream
-	for googleResp, err := range stream {
+	for googleResp, err := range KeepAliveSeq(c, stream, protocol.APIStyleAnthropic).All() {
 		if err != nil {
 			logrus.Errorf("Google stream error: %v", err)
-			errorEvent := map[string]interface{}{
-				"type": "error",
-				"error": map[string]interface{}{
-					"message": err.Error(),
-					"type":    "stream_error",
-					"code":    "stream_failed",
-				},
-			}
-			sendAnthropicBetaStreamEventFromG(c, "error", errorEvent, flusher)
+			sendAnthropicBetaStreamEventFromG(c, eventTypeError, anthropicErrorEvent(err), flusher)
 			return nil
 		}
 
@@ -462,11 +596,55 @@ func HandleGoogleToAnthropicBetaStreamResponse(c *gin.Context, stream iter.Seq2[
 			// Extract content
 			if candidate.Content != nil {
 				for _, part := range candidate.Content.Parts {
+					if block := codeExecution.Block(part); block != nil {
+						sendServerToolBlock(block)
+						continue
+					}
+
+					// Thought summaries (includeThoughts) stream as a thinking block
+					if part.Thought {
+						if thinkingBlockIndex == -1 {
+							thinkingBlockIndex = nextBlockIndex
+							nextBlockIndex++
+							sendAnthropicBetaStreamEventFromG(c, eventTypeContentBlockStart, map[string]interface{}{
+								"type":  eventTypeContentBlockStart,
+								"index": thinkingBlockIndex,
+								"content_block": map[string]interface{}{
+									"type":     "thinking",
+									"thinking": "",
+								},
+							}, flusher)
+						}
+						if part.Text != "" {
+							sendAnthropicBetaStreamEventFromG(c, eventTypeContentBlockDelta, map[string]interface{}{
+								"type":  eventTypeContentBlockDelta,
+								"index": thinkingBlockIndex,
+								"delta": map[string]interface{}{
+									"type":     "thinking_delta",
+									"thinking": part.Text,
+								},
+							}, flusher)
+						}
+						if len(part.ThoughtSignature) > 0 {
+							sendAnthropicBetaStreamEventFromG(c, eventTypeContentBlockDelta, map[string]interface{}{
+								"type":  eventTypeContentBlockDelta,
+								"index": thinkingBlockIndex,
+								"delta": map[string]interface{}{
+									"type":      "signature_delta",
+									"signature": nonstream.GoogleThoughtSignature(part),
+								},
+							}, flusher)
+						}
+						continue
+					}
+
 					// Handle text parts
 					if part.Text != "" {
 						// Send content_block_start for text on first occurrence
 						if textBlockIndex == -1 {
-							textBlockIndex = 0
+							closeBlock(&thinkingBlockIndex)
+							textBlockIndex = nextBlockIndex
+							nextBlockIndex++
 							contentBlockStartEvent := map[string]interface{}{
 								"type":  eventTypeContentBlockStart,
 								"index": textBlockIndex,
@@ -492,7 +670,11 @@ func HandleGoogleToAnthropicBetaStreamResponse(c *gin.Context, stream iter.Seq2[
 
 					// Handle function calls
 					if part.FunctionCall != nil {
-						toolBlockIndex++
+						// Tool calls follow any open thinking or text block, which must be closed first
+						closeBlock(&thinkingBlockIndex)
+						closeBlock(&textBlockIndex)
+						toolBlockIndex := nextBlockIndex
+						nextBlockIndex++
 						// Send content_block_start for tool_use
 						contentBlockStartEvent := map[string]interface{}{
 							"type":  eventTypeContentBlockStart,
@@ -516,10 +698,21 @@ func HandleGoogleToAnthropicBetaStreamResponse(c *gin.Context, stream iter.Seq2[
 				}
 			}
 
+			// Google Search grounding arrives with the final chunks; report it once as a web search
+			if webSearchRequests == 0 {
+				for _, block := range nonstream.GoogleGroundingBlocks(messageID, candidate.GroundingMetadata) {
+					sendServerToolBlock(block)
+				}
+				webSearchRequests = nonstream.GoogleWebSearchRequests(candidate.GroundingMetadata)
+			}
+
 			// Check for finish reason
 			if candidate.FinishReason != "" {
 				stopReason := nonstream.MapGoogleFinishReasonToAnthropicBeta(candidate.FinishReason)
 
+				// Close any open thinking block
+				closeBlock(&thinkingBlockIndex)
+
 				// Send content_block_stop for text if applicable
 				if textBlockIndex != -1 {
 					contentBlockStopEvent := map[string]interface{}{
@@ -545,6 +738,9 @@ func HandleGoogleToAnthropicBetaStreamResponse(c *gin.Context, stream iter.Seq2[
 						"output_tokens": outputTokens,
 					},
 				}
+				if webSearchRequests > 0 {
+					messageDeltaEvent["usage"].(map[string]interface{})["server_tool_use"] = map[string]interface{}{"web_search_requests": webSearchRequests}
+				}
 				sendAnthropicBetaStreamEventFromG(c, eventTypeMessageDelta, messageDeltaEvent, flusher)
 
 				// Send message_stop
@@ -365,7 +365,7 @@ func TestHandleGoogleToAnthropicStreamResponse(t *testing.T) {
 		assert.NoError(t, err)
 		body := w.Body.String()
 		assert.Contains(t, body, "event: error")
-		assert.Contains(t, body, "stream_error")
+		assert.Contains(t, body, `"type":"api_error"`)
 	})
 
 	t.Run("max tokens stop reason", func(t *testing.T) {
@@ -12,6 +12,9 @@ import (
 	"github.com/openai/openai-go/v3"
 	openaistream "github.com/openai/openai-go/v3/packages/ssestream"
 	"github.com/sirupsen/logrus"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+	"github.com/tingly-dev/tingly-box/internal/protocol/nonstream"
 )
 
 const (
@@ -35,6 +38,7 @@ const (
 	eventTypeMessageDelta      = "message_delta"
 	eventTypeMessageStop       = "message_stop"
 	eventTypeError             = "error"
+	eventTypePing              = "ping"
 
 	// Anthropic block types
 	blockTypeText     = "text"
@@ -48,7 +52,7 @@ const (
 )
 
 // HandleOpenAIToAnthropicStreamResponse processes OpenAI streaming events and converts them to Anthropic format
-func HandleOpenAIToAnthropicStreamResponse(c *gin.Context, req *openai.ChatCompletionNewParams, stream *openaistream.Stream[openai.ChatCompletionChunk], responseModel string) error {
+func HandleOpenAIToAnthropicStreamResponse(c *gin.Context, req *openai.ChatCompletionNewParams, stream *openaistream.Stream[openai.ChatCompletionChunk], responseModel string, toolRepair *protocol.ToolRepairer) error {
 	logrus.Info("Starting OpenAI to Anthropic streaming response handler")
 	defer func() {
 		if r := recover(); r != nil {
@@ -87,6 +91,7 @@ func HandleOpenAIToAnthropicStreamResponse(c *gin.Context, req *openai.ChatCompl
 
 	// Initialize streaming state
 	state := newStreamState()
+	state.toolRepair = toolRepair
 
 	// Send message_start event first
 	messageStartEvent := map[string]interface{}{
@@ -109,9 +114,19 @@ func HandleOpenAIToAnthropicStreamResponse(c *gin.Context, req *openai.ChatCompl
 
 	// Process the stream
 	chunkCount := 0
-	for stream.Next() {
+	events := KeepAlive(c, stream, protocol.APIStyleAnthropic)
+	defer events.Close()
+	for events.Next() {
 		chunkCount++
-		chunk := stream.Current()
+		chunk := events.Current()
+
+		// GLM reports its built-in web search once, in a top-level web_search field
+		if webSearch, exists := chunk.JSON.ExtraFields["web_search"]; exists && state.webSearchRequests == 0 {
+			if blocks := nonstream.GLMWebSearchBlocks(chunk.ID, webSearch.Raw()); blocks != nil {
+				sendServerToolBlocks(c, state, blocks, flusher)
+				state.webSearchRequests++
+			}
+		}
 
 		// Skip empty chunks (no choices)
 		if len(chunk.Choices) == 0 {
@@ -251,15 +266,18 @@ func HandleOpenAIToAnthropicStreamResponse(c *gin.Context, req *openai.ChatCompl
 					}, flusher)
 				}
 
-				// Accumulate arguments and send delta
+				// Accumulate arguments and send delta; with tool repair the arguments are
+				// buffered and sent repaired when the block stops
 				if toolCall.Function.Arguments != "" {
 					state.pendingToolCalls[anthropicIndex].input += toolCall.Function.Arguments
 
-					// Send content_block_delta with input_json_delta
-					sendContentBlockDelta(c, anthropicIndex, map[string]interface{}{
-						"type":         deltaTypeInputJSONDelta,
-						"partial_json": toolCall.Function.Arguments,
-					}, flusher)
+					if state.toolRepair == nil {
+						// Send content_block_delta with input_json_delta
+						sendContentBlockDelta(c, anthropicIndex, map[string]interface{}{
+							"type":         deltaTypeInputJSONDelta,
+							"partial_json": toolCall.Function.Arguments,
+						}, flusher)
+					}
 				}
 			}
 		}
@@ -280,19 +298,18 @@ func HandleOpenAIToAnthropicStreamResponse(c *gin.Context, req *openai.ChatCompl
 	}
 
 	// Check for stream errors
-	if err := stream.Err(); err != nil {
+	if err := events.Err(); err != nil {
 		logrus.Errorf("OpenAI stream error: %v", err)
-		errorEvent := map[string]interface{}{
-			"type": "error",
-			"error": map[string]interface{}{
-				"message": err.Error(),
-				"type":    "stream_error",
-				"code":    "stream_failed",
-			},
-		}
-		sendAnthropicStreamEvent(c, "error", errorEvent, flusher)
+		sendAnthropicErrorEvent(c, err, flusher)
 		return err
 	}
+
+	// The stream ended ([DONE] or EOF) without a finish_reason; close the open blocks so
+	// buffered tool arguments are still sent
+	stopReason := streamEndStopReason(state)
+	sendStopEvents(c, state, flusher)
+	sendMessageDelta(c, state, stopReason, flusher)
+	sendMessageStop(c, messageID, responseModel, state, stopReason, flusher)
 	return nil
 }
 
@@ -353,7 +370,9 @@ type streamState struct {
 	deltaExtras           map[string]interface{}
 	outputTokens          int64
 	inputTokens           int64
-	stoppedBlocks         map[int]bool // Tracks blocks that have already sent content_block_stop
+	stoppedBlocks         map[int]bool           // Tracks blocks that have already sent content_block_stop
+	webSearchRequests     int                    // Searches run by the backend's native web search
+	toolRepair            *protocol.ToolRepairer // Buffers and repairs tool arguments when set
 }
 
 // newStreamState creates a new streamState
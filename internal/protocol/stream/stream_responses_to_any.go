@@ -0,0 +1,411 @@
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	openaistream "github.com/openai/openai-go/v3/packages/ssestream"
+	"github.com/openai/openai-go/v3/responses"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genai"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+	"github.com/tingly-dev/tingly-box/internal/protocol/nonstream"
+)
+
+const (
+	blockTypeServerToolUse       = "server_tool_use"
+	blockTypeWebSearchToolResult = "web_search_tool_result"
+)
+
+// HandleResponsesToAnthropicStreamResponse processes OpenAI Responses API streaming events and converts them to Anthropic format
+// Returns inputTokens, outputTokens, and error for usage tracking
+func HandleResponsesToAnthropicStreamResponse(c *gin.Context, stream *openaistream.Stream[responses.ResponseStreamEventUnion], responseModel string) (int, int, error) {
+	return handleResponsesToAnthropicStream(c, stream, responseModel, "Anthropic")
+}
+
+// HandleResponsesToAnthropicV1BetaStreamResponse processes OpenAI Responses API streaming events and converts them to Anthropic beta format
+// Returns inputTokens, outputTokens, and error for usage tracking
+func HandleResponsesToAnthropicV1BetaStreamResponse(c *gin.Context, stream *openaistream.Stream[responses.ResponseStreamEventUnion], responseModel string) (int, int, error) {
+	return handleResponsesToAnthropicStream(c, stream, responseModel, "Anthropic beta")
+}
+
+// responsesToolCall tracks a function, custom or MCP tool call streamed as a tool_use block
+type responsesToolCall struct {
+	blockIndex int
+	custom     bool
+	input      string
+}
+
+// handleResponsesToAnthropicStream writes a Responses API stream as Anthropic SSE events. The
+// v1 and beta wire formats are identical; dialect only labels the logs.
+func handleResponsesToAnthropicStream(c *gin.Context, stream *openaistream.Stream[responses.ResponseStreamEventUnion], responseModel, dialect string) (int, int, error) {
+	logrus.Infof("Starting Responses API to %s streaming response handler", dialect)
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.Errorf("Panic in Responses API to %s streaming handler: %v", dialect, r)
+			if c.Writer != nil {
+				c.SSEvent("error", "{\"error\":{\"message\":\"Internal streaming error\",\"type\":\"internal_error\"}}")
+				if flusher, ok := c.Writer.(http.Flusher); ok {
+					flusher.Flush()
+				}
+			}
+		}
+		if stream != nil {
+			if err := stream.Close(); err != nil {
+				logrus.Errorf("Error closing Responses API stream: %v", err)
+			}
+		}
+		logrus.Infof("Finished Responses API to %s streaming response handler", dialect)
+	}()
+
+	// Set SSE headers
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Access-Control-Allow-Headers", "Cache-Control")
+
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		return 0, 0, errors.New("Streaming not supported by this connection")
+	}
+
+	messageID := fmt.Sprintf("msg_%d", time.Now().Unix())
+	state := newStreamState()
+	toolCalls := make(map[string]*responsesToolCall) // key: item ID
+
+	sendAnthropicStreamEvent(c, eventTypeMessageStart, map[string]interface{}{
+		"type": eventTypeMessageStart,
+		"message": map[string]interface{}{
+			"id":            messageID,
+			"type":          "message",
+			"role":          "assistant",
+			"content":       []interface{}{},
+			"model":         responseModel,
+			"stop_reason":   nil,
+			"stop_sequence": nil,
+			"usage": map[string]interface{}{
+				"input_tokens":  0,
+				"output_tokens": 0,
+			},
+		},
+	}, flusher)
+
+	// startBlock opens a new content block and returns its index
+	startBlock := func(blockType string, initialContent map[string]interface{}) int {
+		index := state.nextBlockIndex
+		state.nextBlockIndex++
+		sendContentBlockStart(c, index, blockType, initialContent, flusher)
+		return index
+	}
+	stopBlock := func(index *int) {
+		if *index == -1 {
+			return
+		}
+		sendContentBlockStop(c, *index, flusher)
+		state.stoppedBlocks[*index] = true
+		*index = -1
+	}
+	textDelta := func(text string) {
+		if state.textBlockIndex == -1 {
+			state.textBlockIndex = startBlock(blockTypeText, map[string]interface{}{"text": ""})
+		}
+		if text != "" {
+			sendContentBlockDelta(c, state.textBlockIndex, map[string]interface{}{
+				"type": deltaTypeTextDelta,
+				"text": text,
+			}, flusher)
+		}
+	}
+	thinkingDelta := func(thinking string) {
+		if state.thinkingBlockIndex == -1 {
+			state.thinkingBlockIndex = startBlock(blockTypeThinking, map[string]interface{}{"thinking": ""})
+		}
+		sendContentBlockDelta(c, state.thinkingBlockIndex, map[string]interface{}{
+			"type":     deltaTypeThinkingDelta,
+			"thinking": thinking,
+		}, flusher)
+	}
+	toolInputDelta := func(itemID, delta string) {
+		call, exists := toolCalls[itemID]
+		if !exists {
+			return
+		}
+		call.input += delta
+		// Custom tools take free-form text, which is only wrapped as JSON once complete
+		if call.custom {
+			return
+		}
+		sendContentBlockDelta(c, call.blockIndex, map[string]interface{}{
+			"type":         deltaTypeInputJSONDelta,
+			"partial_json": delta,
+		}, flusher)
+	}
+	toolDone := func(itemID string) {
+		call, exists := toolCalls[itemID]
+		if !exists {
+			return
+		}
+		if call.custom {
+			input, _ := json.Marshal(map[string]string{"input": call.input})
+			sendContentBlockDelta(c, call.blockIndex, map[string]interface{}{
+				"type":         deltaTypeInputJSONDelta,
+				"partial_json": string(input),
+			}, flusher)
+		}
+		stopBlock(&call.blockIndex)
+		delete(toolCalls, itemID)
+	}
+	// finish closes any open blocks and ends the message with the final response's stop reason and usage
+	finish := func(resp *responses.Response) {
+		stopBlock(&state.thinkingBlockIndex)
+		stopBlock(&state.textBlockIndex)
+		for itemID := range toolCalls {
+			toolDone(itemID)
+		}
+
+		usage := nonstream.ResponsesUsageToAnthropic(resp)
+		state.inputTokens, _ = usage["input_tokens"].(int64)
+		state.outputTokens = resp.Usage.OutputTokens
+		stopReason := nonstream.ResponsesStopReasonToAnthropic(resp)
+
+		sendAnthropicStreamEvent(c, eventTypeMessageDelta, map[string]interface{}{
+			"type": eventTypeMessageDelta,
+			"delta": map[string]interface{}{
+				"stop_reason":   stopReason,
+				"stop_sequence": nil,
+			},
+			"usage": usage,
+		}, flusher)
+		sendMessageStop(c, messageID, responseModel, state, stopReason, flusher)
+	}
+
+	// ref: check all event type in libs/openai-go/responses/response.go:13798
+	events := KeepAlive(c, stream, protocol.APIStyleAnthropic)
+	defer events.Close()
+	for events.Next() {
+		event := events.Current()
+		logrus.Debugf("Processing Responses API event: type=%s", event.Type)
+
+		switch event.Type {
+		case "response.content_part.added":
+			if part := event.AsResponseContentPartAdded().Part; part.Type == "output_text" {
+				textDelta(part.Text)
+			}
+
+		case "response.output_text.delta", "response.refusal.delta":
+			textDelta(event.Delta)
+
+		case "response.output_text.done", "response.refusal.done", "response.content_part.done":
+			stopBlock(&state.textBlockIndex)
+
+		case "response.reasoning_text.delta", "response.reasoning_summary_text.delta":
+			// Models that hide raw reasoning only stream summaries; both surface as thinking
+			thinkingDelta(event.Delta)
+
+		case "response.reasoning_text.done", "response.reasoning_summary_text.done":
+			stopBlock(&state.thinkingBlockIndex)
+
+		case "response.output_item.added":
+			item := event.AsResponseOutputItemAdded().Item
+			switch item.Type {
+			case "function_call", "custom_tool_call", "mcp_call":
+				stopBlock(&state.textBlockIndex)
+				toolCalls[item.ID] = &responsesToolCall{
+					blockIndex: startBlock(blockTypeToolUse, map[string]interface{}{
+						"id":    nonstream.ResponsesCallID(item),
+						"name":  item.Name,
+						"input": map[string]interface{}{},
+					}),
+					custom: item.Type == "custom_tool_call",
+				}
+			}
+
+		case "response.function_call_arguments.delta", "response.custom_tool_call_input.delta", "response.mcp_call_arguments.delta":
+			toolInputDelta(event.ItemID, event.Delta)
+
+		case "response.function_call_arguments.done", "response.custom_tool_call_input.done", "response.mcp_call_arguments.done":
+			toolDone(event.ItemID)
+
+		case "response.output_item.done":
+			item := event.AsResponseOutputItemDone().Item
+			if item.Type != "web_search_call" {
+				continue
+			}
+			// Built-in web search runs upstream; report it as an Anthropic server tool round trip
+			stopBlock(&state.textBlockIndex)
+			query, _ := json.Marshal(map[string]string{"query": item.Action.Query})
+			index := startBlock(blockTypeServerToolUse, map[string]interface{}{
+				"id":    item.ID,
+				"name":  "web_search",
+				"input": map[string]interface{}{},
+			})
+			sendContentBlockDelta(c, index, map[string]interface{}{
+				"type":         deltaTypeInputJSONDelta,
+				"partial_json": string(query),
+			}, flusher)
+			stopBlock(&index)
+			index = startBlock(blockTypeWebSearchToolResult, map[string]interface{}{
+				"tool_use_id": item.ID,
+				"content":     nonstream.ResponsesWebSearchResults(item, nil),
+			})
+			stopBlock(&index)
+
+		case "response.completed", "response.incomplete":
+			resp := event.Response
+			finish(&resp)
+			return int(resp.Usage.InputTokens), int(resp.Usage.OutputTokens), nil
+
+		case "response.failed":
+			message := event.Response.Error.Message
+			if message == "" {
+				message = "response failed"
+			}
+			logrus.Errorf("Responses API response failed: %s", message)
+			upstreamErr := protocol.NewUpstreamError(string(event.Response.Error.Code), message)
+			sendAnthropicErrorEvent(c, upstreamErr, flusher)
+			return 0, 0, upstreamErr
+
+		case "error":
+			logrus.Errorf("Responses API error event: %s %s", event.Code, event.Message)
+			upstreamErr := protocol.NewUpstreamError(event.Code, event.Message)
+			sendAnthropicErrorEvent(c, upstreamErr, flusher)
+			return 0, 0, upstreamErr
+
+		default:
+			logrus.Debugf("Unhandled Responses API event type: %s", event.Type)
+		}
+	}
+
+	if err := events.Err(); err != nil {
+		logrus.Errorf("Responses API stream error: %v", err)
+		sendAnthropicErrorEvent(c, err, flusher)
+		return 0, 0, err
+	}
+	return 0, 0, nil
+}
+
+// HandleResponsesToGoogleStreamResponse processes OpenAI Responses API streaming events and converts them to Google format
+// Returns inputTokens, outputTokens, and error for usage tracking
+func HandleResponsesToGoogleStreamResponse(c *gin.Context, stream *openaistream.Stream[responses.ResponseStreamEventUnion], responseModel string) (int, int, error) {
+	logrus.Info("Starting Responses API to Google streaming response handler")
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.Errorf("Panic in Responses API to Google streaming handler: %v", r)
+			if c.Writer != nil {
+				c.Writer.WriteHeader(http.StatusInternalServerError)
+				c.Writer.Write([]byte("error: Internal streaming error\n"))
+				if flusher, ok := c.Writer.(http.Flusher); ok {
+					flusher.Flush()
+				}
+			}
+		}
+		if stream != nil {
+			if err := stream.Close(); err != nil {
+				logrus.Errorf("Error closing Responses API stream: %v", err)
+			}
+		}
+		logrus.Info("Finished Responses API to Google streaming response handler")
+	}()
+
+	// Set SSE headers
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		return 0, 0, errors.New("Streaming not supported by this connection")
+	}
+
+	sendParts := func(parts ...*genai.Part) {
+		sendGoogleStreamChunk(c, &genai.GenerateContentResponse{
+			Candidates: []*genai.Candidate{
+				{
+					Content: &genai.Content{Role: "model", Parts: parts},
+					Index:   0,
+				},
+			},
+			ModelVersion: responseModel,
+		}, flusher)
+	}
+
+	events := KeepAlive(c, stream, protocol.APIStyleGoogle)
+	defer events.Close()
+	for events.Next() {
+		event := events.Current()
+
+		switch event.Type {
+		case "response.output_text.delta", "response.refusal.delta":
+			if event.Delta != "" {
+				sendParts(genai.NewPartFromText(event.Delta))
+			}
+
+		case "response.reasoning_text.delta", "response.reasoning_summary_text.delta":
+			if event.Delta != "" {
+				sendGoogleThoughtChunk(c, event.Delta, flusher)
+			}
+
+		case "response.output_item.done":
+			// Gemini delivers function calls whole, so wait for the completed item
+			item := event.AsResponseOutputItemDone().Item
+			switch item.Type {
+			case "function_call", "custom_tool_call", "mcp_call":
+				var args map[string]interface{}
+				_ = json.Unmarshal(nonstream.ResponsesToolInput(item), &args)
+				sendParts(&genai.Part{
+					FunctionCall: &genai.FunctionCall{
+						ID:   nonstream.ResponsesCallID(item),
+						Name: item.Name,
+						Args: args,
+					},
+				})
+			}
+
+		case "response.completed", "response.incomplete":
+			resp := event.Response
+			sendGoogleStreamChunk(c, &genai.GenerateContentResponse{
+				Candidates: []*genai.Candidate{
+					{
+						Content:      &genai.Content{Role: "model", Parts: []*genai.Part{}},
+						FinishReason: nonstream.MapResponsesStatusToGoogle(&resp),
+						Index:        0,
+					},
+				},
+				ModelVersion:  responseModel,
+				ResponseID:    resp.ID,
+				UsageMetadata: nonstream.ResponsesUsageToGoogle(resp.Usage),
+			}, flusher)
+			return int(resp.Usage.InputTokens), int(resp.Usage.OutputTokens), nil
+
+		case "response.failed":
+			message := event.Response.Error.Message
+			if message == "" {
+				message = "response failed"
+			}
+			logrus.Errorf("Responses API response failed: %s", message)
+			upstreamErr := protocol.NewUpstreamError(string(event.Response.Error.Code), message)
+			sendGoogleErrorChunk(c, upstreamErr, flusher)
+			return 0, 0, upstreamErr
+
+		case "error":
+			logrus.Errorf("Responses API error event: %s %s", event.Code, event.Message)
+			upstreamErr := protocol.NewUpstreamError(event.Code, event.Message)
+			sendGoogleErrorChunk(c, upstreamErr, flusher)
+			return 0, 0, upstreamErr
+		}
+	}
+
+	if err := events.Err(); err != nil {
+		logrus.Errorf("Responses API stream error: %v", err)
+		sendGoogleErrorChunk(c, err, flusher)
+		return 0, 0, err
+	}
+	return 0, 0, nil
+}
@@ -0,0 +1,101 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openai/openai-go/v3"
+	openaiOption "github.com/openai/openai-go/v3/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+)
+
+func TestHandleOpenAIToAnthropicStreamResponseGLMWebSearch(t *testing.T) {
+	chunks := []string{
+		`{"id":"glm1","object":"chat.completion.chunk","model":"glm-4.6","choices":[{"index":0,"delta":{"role":"assistant"}}],"web_search":[{"title":"Go 1.25","link":"https://go.dev/doc/go1.25","content":"..."}]}`,
+		`{"id":"glm1","object":"chat.completion.chunk","model":"glm-4.6","choices":[{"index":0,"delta":{"content":"Go 1.25 is out."}}]}`,
+		`{"id":"glm1","object":"chat.completion.chunk","model":"glm-4.6","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+	}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer upstream.Close()
+
+	req := openai.ChatCompletionNewParams{
+		Model:    "glm-4.6",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("What's new in Go?")},
+	}
+	client := openai.NewClient(openaiOption.WithAPIKey("test"), openaiOption.WithBaseURL(upstream.URL))
+	stream := client.Chat.Completions.NewStreaming(context.Background(), req)
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	require.NoError(t, HandleOpenAIToAnthropicStreamResponse(c, &req, stream, "claude-sonnet-4-5", nil))
+
+	body := w.Body.String()
+	assert.Contains(t, body, `"content_block":{"id":"srvtoolu_glm1","input":{"query":""},"name":"web_search","type":"server_tool_use"},"index":0`)
+	assert.Contains(t, body, `"index":1,"type":"content_block_start"`)
+	assert.Contains(t, body, `"url":"https://go.dev/doc/go1.25"`)
+	// Text follows the search results
+	assert.Contains(t, body, `"content_block":{"text":"","type":"text"},"index":2`)
+	assert.Contains(t, body, `"server_tool_use":{"web_search_requests":1}`)
+}
+
+func TestHandleGoogleToAnthropicStreamResponseServerTools(t *testing.T) {
+	chunks := []*genai.GenerateContentResponse{
+		{Candidates: []*genai.Candidate{{Content: &genai.Content{Role: "model", Parts: []*genai.Part{
+			{ExecutableCode: &genai.ExecutableCode{Code: "print(2**10)", Language: genai.LanguagePython}},
+			{CodeExecutionResult: &genai.CodeExecutionResult{Outcome: genai.OutcomeOK, Output: "1024\n"}},
+		}}}}},
+		{Candidates: []*genai.Candidate{{
+			Content:      &genai.Content{Role: "model", Parts: []*genai.Part{genai.NewPartFromText("It is 1024.")}},
+			FinishReason: genai.FinishReasonStop,
+			GroundingMetadata: &genai.GroundingMetadata{
+				WebSearchQueries: []string{"2 to the power of 10"},
+				GroundingChunks:  []*genai.GroundingChunk{{Web: &genai.GroundingChunkWeb{URI: "https://example.com/powers", Title: "example.com"}}},
+			},
+		}}},
+	}
+	stream := func(yield func(*genai.GenerateContentResponse, error) bool) {
+		for _, chunk := range chunks {
+			if !yield(chunk, nil) {
+				return
+			}
+		}
+	}
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	require.NoError(t, HandleGoogleToAnthropicBetaStreamResponse(c, stream, "claude-sonnet-4-5"))
+
+	body := w.Body.String()
+	order := []string{
+		`"name":"code_execution","type":"server_tool_use"},"index":0`,
+		`"type":"code_execution_tool_result"},"index":1`,
+		`"content_block":{"text":"","type":"text"},"index":2`,
+		`"name":"web_search","type":"server_tool_use"},"index":3`,
+		`"type":"web_search_tool_result"},"index":4`,
+		`"server_tool_use":{"web_search_requests":1}`,
+	}
+	last := -1
+	for _, want := range order {
+		pos := strings.Index(body, want)
+		require.Greater(t, pos, last, "expected %s after the previous event", want)
+		last = pos
+	}
+	assert.Contains(t, body, `"stdout":"1024\n"`)
+}
@@ -0,0 +1,110 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openai/openai-go/v3"
+	openaiOption "github.com/openai/openai-go/v3/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+)
+
+func TestHandleOpenAIToAnthropicStreamResponseToolRepair(t *testing.T) {
+	chunks := []string{
+		`{"id":"c1","object":"chat.completion.chunk","model":"m","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"search","arguments":"{'query': 'go',"}}]}}]}`,
+		`{"id":"c1","object":"chat.completion.chunk","model":"m","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":" 'limit': '3'"}}]}}]}`,
+		`{"id":"c1","object":"chat.completion.chunk","model":"m","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+	}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer upstream.Close()
+
+	req := openai.ChatCompletionNewParams{
+		Model:    "m",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("search")},
+		Tools: []openai.ChatCompletionToolUnionParam{
+			openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+				Name: "search",
+				Parameters: openai.FunctionParameters{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"query": map[string]interface{}{"type": "string"},
+						"limit": map[string]interface{}{"type": "integer"},
+					},
+				},
+			}),
+		},
+	}
+	client := openai.NewClient(openaiOption.WithAPIKey("test"), openaiOption.WithBaseURL(upstream.URL))
+	stream := client.Chat.Completions.NewStreaming(context.Background(), req)
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	err := HandleOpenAIToAnthropicStreamResponse(c, &req, stream, "m", protocol.NewToolRepairer("test", req.Tools))
+	require.NoError(t, err)
+
+	body := w.Body.String()
+	// The malformed fragments are buffered and only the repaired arguments are sent
+	assert.Equal(t, 1, strings.Count(body, `"input_json_delta"`))
+	assert.Contains(t, body, `"partial_json":"{\"limit\":3,\"query\":\"go\"}"`)
+	assert.Less(t, strings.Index(body, `"input_json_delta"`), strings.Index(body, `"content_block_stop"`))
+	assert.Contains(t, body, `"stop_reason":"tool_use"`)
+}
+
+func TestHandleOpenAIToAnthropicStreamResponseToolRepairWithoutFinishReason(t *testing.T) {
+	// Some backends end the stream with [DONE] and never send a finish_reason
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"c1","object":"chat.completion.chunk","model":"m","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"search","arguments":"{'query': 'go'"}}]}}]}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer upstream.Close()
+
+	req := openai.ChatCompletionNewParams{
+		Model:    "m",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("search")},
+		Tools: []openai.ChatCompletionToolUnionParam{
+			openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{Name: "search"}),
+		},
+	}
+	client := openai.NewClient(openaiOption.WithAPIKey("test"), openaiOption.WithBaseURL(upstream.URL))
+
+	for name, handle := range map[string]func(*gin.Context) error{
+		"v1": func(c *gin.Context) error {
+			stream := client.Chat.Completions.NewStreaming(context.Background(), req)
+			return HandleOpenAIToAnthropicStreamResponse(c, &req, stream, "m", protocol.NewToolRepairer("test", req.Tools))
+		},
+		"beta": func(c *gin.Context) error {
+			stream := client.Chat.Completions.NewStreaming(context.Background(), req)
+			return HandleOpenAIToAnthropicV1BetaStreamResponse(c, &req, stream, "m", protocol.NewToolRepairer("test", req.Tools))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			require.NoError(t, handle(c))
+
+			body := w.Body.String()
+			assert.Contains(t, body, `"partial_json":"{\"query\":\"go\"}"`)
+			assert.Less(t, strings.Index(body, `"input_json_delta"`), strings.Index(body, `"content_block_stop"`))
+			assert.Contains(t, body, `"stop_reason":"tool_use"`)
+			assert.Contains(t, body, "message_stop")
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// StructuredOutputToolName is the synthetic tool used to emulate OpenAI json_schema
+// response formats on backends (Anthropic) that only support schemas on tools. The
+// tool input is unwrapped back into message content on the way out.
+const StructuredOutputToolName = "structured_output"
+
+// StructuredOutputToolSchema returns the input schema of the synthetic structured output
+// tool, reporting whether the request contains it
+func StructuredOutputToolSchema(tools []anthropic.ToolUnionParam) (map[string]interface{}, bool) {
+	for _, tool := range tools {
+		if tool.OfTool == nil || tool.OfTool.Name != StructuredOutputToolName {
+			continue
+		}
+		var schema map[string]interface{}
+		if raw, err := json.Marshal(tool.OfTool.InputSchema); err == nil {
+			_ = json.Unmarshal(raw, &schema)
+		}
+		return schema, true
+	}
+	return nil, false
+}
+
+// SchemaValidationError describes where a JSON value violates its schema
+type SchemaValidationError struct {
+	Path    string // JSON pointer-like path, "$" for the root
+	Message string
+}
+
+func (e *SchemaValidationError) Error() string {
+	return fmt.Sprintf("structured output does not match schema at %s: %s", e.Path, e.Message)
+}
+
+// ValidateStructuredOutput parses content as JSON and validates it against schema.
+// A nil schema only requires content to be valid JSON.
+func ValidateStructuredOutput(content string, schema map[string]interface{}) error {
+	var value interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &value); err != nil {
+		return &SchemaValidationError{Path: "$", Message: "response is not valid JSON: " + err.Error()}
+	}
+	if schema == nil {
+		return nil
+	}
+	return ValidateJSONSchema(value, schema)
+}
+
+// ValidateJSONSchema validates a decoded JSON value against a JSON Schema. It covers the
+// subset used by structured output (type, properties, required, additionalProperties,
+// items, enum, const, length/size/range bounds, anyOf/oneOf/allOf and local $ref).
+// Unknown keywords are ignored.
+func ValidateJSONSchema(value interface{}, schema map[string]interface{}) error {
+	v := schemaValidator{root: schema}
+	return v.validate(value, schema, "$", 0)
+}
+
+type schemaValidator struct {
+	root map[string]interface{}
+}
+
+// maxSchemaDepth guards against recursive $ref definitions
+const maxSchemaDepth = 64
+
+func (v schemaValidator) validate(value interface{}, schema map[string]interface{}, path string, depth int) error {
+	if schema == nil {
+		return nil
+	}
+	if depth > maxSchemaDepth {
+		return &SchemaValidationError{Path: path, Message: "schema nesting too deep"}
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := v.resolveRef(ref)
+		if err != nil {
+			return &SchemaValidationError{Path: path, Message: err.Error()}
+		}
+		if err := v.validate(value, resolved, path, depth+1); err != nil {
+			return err
+		}
+	}
+
+	if err := v.validateType(value, schema["type"], path); err != nil {
+		return err
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, candidate := range enum {
+			if jsonEqual(value, candidate) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &SchemaValidationError{Path: path, Message: fmt.Sprintf("value %s is not one of %s", compactJSON(value), compactJSON(enum))}
+		}
+	}
+	if constValue, ok := schema["const"]; ok && !jsonEqual(value, constValue) {
+		return &SchemaValidationError{Path: path, Message: fmt.Sprintf("value %s must equal %s", compactJSON(value), compactJSON(constValue))}
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		if err := v.validateObject(typed, schema, path, depth); err != nil {
+			return err
+		}
+	case []interface{}:
+		if err := v.validateArray(typed, schema, path, depth); err != nil {
+			return err
+		}
+	case string:
+		length := len([]rune(typed))
+		if min, ok := schemaNumber(schema["minLength"]); ok && float64(length) < min {
+			return &SchemaValidationError{Path: path, Message: fmt.Sprintf("string is shorter than %v characters", min)}
+		}
+		if max, ok := schemaNumber(schema["maxLength"]); ok && float64(length) > max {
+			return &SchemaValidationError{Path: path, Message: fmt.Sprintf("string is longer than %v characters", max)}
+		}
+	case float64:
+		if min, ok := schemaNumber(schema["minimum"]); ok && typed < min {
+			return &SchemaValidationError{Path: path, Message: fmt.Sprintf("%v is less than the minimum %v", typed, min)}
+		}
+		if max, ok := schemaNumber(schema["maximum"]); ok && typed > max {
+			return &SchemaValidationError{Path: path, Message: fmt.Sprintf("%v is greater than the maximum %v", typed, max)}
+		}
+	}
+
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			if err := v.validate(value, asSchema(sub), path, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		if err := v.validateAnyOf(value, anyOf, path, depth); err != nil {
+			return err
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		matches := 0
+		for _, sub := range oneOf {
+			if v.validate(value, asSchema(sub), path, depth+1) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return &SchemaValidationError{Path: path, Message: fmt.Sprintf("value must match exactly one schema in oneOf, matched %d", matches)}
+		}
+	}
+
+	return nil
+}
+
+func (v schemaValidator) validateObject(obj map[string]interface{}, schema map[string]interface{}, path string, depth int) error {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			key, _ := name.(string)
+			if _, present := obj[key]; !present {
+				return &SchemaValidationError{Path: path, Message: fmt.Sprintf("missing required property %q", key)}
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	for key, propValue := range obj {
+		if propSchema, ok := properties[key]; ok {
+			if err := v.validate(propValue, asSchema(propSchema), path+"."+key, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				return &SchemaValidationError{Path: path, Message: fmt.Sprintf("unexpected property %q", key)}
+			}
+		case map[string]interface{}:
+			if err := v.validate(propValue, additional, path+"."+key, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v schemaValidator) validateArray(arr []interface{}, schema map[string]interface{}, path string, depth int) error {
+	if min, ok := schemaNumber(schema["minItems"]); ok && float64(len(arr)) < min {
+		return &SchemaValidationError{Path: path, Message: fmt.Sprintf("array has fewer than %v items", min)}
+	}
+	if max, ok := schemaNumber(schema["maxItems"]); ok && float64(len(arr)) > max {
+		return &SchemaValidationError{Path: path, Message: fmt.Sprintf("array has more than %v items", max)}
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range arr {
+			if err := v.validate(item, items, fmt.Sprintf("%s[%d]", path, i), depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v schemaValidator) validateAnyOf(value interface{}, anyOf []interface{}, path string, depth int) error {
+	var firstErr error
+	for _, sub := range anyOf {
+		err := v.validate(value, asSchema(sub), path, depth+1)
+		if err == nil {
+			return nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		return nil
+	}
+	return &SchemaValidationError{Path: path, Message: "value does not match any schema in anyOf (first mismatch: " + firstErr.Error() + ")"}
+}
+
+func (v schemaValidator) validateType(value interface{}, typeSpec interface{}, path string) error {
+	var types []string
+	switch t := typeSpec.(type) {
+	case nil:
+		return nil
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+	if len(types) == 0 {
+		return nil
+	}
+
+	actual := jsonTypeOf(value)
+	for _, expected := range types {
+		expected = strings.ToLower(expected)
+		if expected == actual || (expected == "number" && actual == "integer") {
+			return nil
+		}
+	}
+	return &SchemaValidationError{Path: path, Message: fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), actual)}
+}
+
+// resolveRef resolves local references such as "#/$defs/Item" or "#/definitions/Item"
+func (v schemaValidator) resolveRef(ref string) (map[string]interface{}, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("unsupported $ref %q: only local references are allowed", ref)
+	}
+	var current interface{} = v.root
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		node, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+		if current, ok = node[token]; !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+	}
+	resolved, ok := current.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unresolvable $ref %q", ref)
+	}
+	return resolved, nil
+}
+
+func jsonTypeOf(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if typed == math.Trunc(typed) && !math.IsInf(typed, 0) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func asSchema(value interface{}) map[string]interface{} {
+	schema, _ := value.(map[string]interface{})
+	return schema
+}
+
+func schemaNumber(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func jsonEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(normalizeJSON(a), normalizeJSON(b))
+}
+
+// normalizeJSON round-trips a value so schema literals and decoded values compare equal
+func normalizeJSON(value interface{}) interface{} {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var out interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return value
+	}
+	return out
+}
+
+func compactJSON(value interface{}) string {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(raw)
+}
@@ -0,0 +1,542 @@
+package protocol
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/openai/openai-go/v3"
+)
+
+// ToolRepairer fixes tool-call arguments produced by weak backends: it repairs malformed JSON
+// (trailing commas, single quotes, unterminated strings and brackets, code fences, Python
+// literals) and coerces values to the types declared in the tool's input schema.
+type ToolRepairer struct {
+	provider string
+	schemas  map[string]map[string]interface{} // tool name -> input schema
+}
+
+// NewToolRepairer returns a repairer for the tools of an OpenAI request. Outcomes are
+// recorded in the tool repair stats under provider.
+func NewToolRepairer(provider string, tools []openai.ChatCompletionToolUnionParam) *ToolRepairer {
+	r := &ToolRepairer{provider: provider, schemas: make(map[string]map[string]interface{})}
+	for _, tool := range tools {
+		fn := tool.GetFunction()
+		if fn == nil {
+			continue
+		}
+		var schema map[string]interface{}
+		if raw, err := json.Marshal(fn.Parameters); err == nil {
+			_ = json.Unmarshal(raw, &schema)
+		}
+		r.schemas[fn.Name] = schema
+	}
+	return r
+}
+
+// ToolRepairOutcome describes what Repair did to one tool call's arguments
+type ToolRepairOutcome string
+
+const (
+	ToolRepairUnchanged ToolRepairOutcome = "unchanged"
+	ToolRepairRepaired  ToolRepairOutcome = "repaired" // the JSON was malformed and had to be fixed
+	ToolRepairCoerced   ToolRepairOutcome = "coerced"  // the JSON was valid but values were converted to the schema types
+	ToolRepairFailed    ToolRepairOutcome = "failed"   // the arguments could not be turned into a JSON object
+)
+
+// Repair returns the corrected arguments of a call to tool. When the arguments cannot be
+// repaired they are returned unchanged so the client still sees what the model produced.
+func (r *ToolRepairer) Repair(tool, arguments string) (string, ToolRepairOutcome) {
+	if r == nil {
+		return arguments, ToolRepairUnchanged
+	}
+
+	outcome := ToolRepairUnchanged
+	var value interface{}
+	if err := json.Unmarshal([]byte(arguments), &value); err != nil || strings.TrimSpace(arguments) == "" {
+		repaired, ok := RepairJSON(arguments)
+		if !ok || json.Unmarshal([]byte(repaired), &value) != nil {
+			recordToolRepair(r.provider, ToolRepairFailed)
+			return arguments, ToolRepairFailed
+		}
+		outcome = ToolRepairRepaired
+	}
+
+	// Some backends double-encode the arguments object as a JSON string
+	if s, ok := value.(string); ok {
+		var inner interface{}
+		if json.Unmarshal([]byte(s), &inner) == nil {
+			value = inner
+			outcome = ToolRepairRepaired
+		}
+	}
+	if _, ok := value.(map[string]interface{}); !ok {
+		recordToolRepair(r.provider, ToolRepairFailed)
+		return arguments, ToolRepairFailed
+	}
+
+	if schema := r.schemas[tool]; schema != nil {
+		var changed bool
+		value, changed = coerceToSchema(value, schema, schema, 0)
+		if changed && outcome == ToolRepairUnchanged {
+			outcome = ToolRepairCoerced
+		}
+	}
+
+	recordToolRepair(r.provider, outcome)
+	if outcome == ToolRepairUnchanged {
+		return arguments, outcome
+	}
+	fixed, err := json.Marshal(value)
+	if err != nil {
+		return arguments, ToolRepairFailed
+	}
+	return string(fixed), outcome
+}
+
+// RepairOpenAIToolCalls repairs the arguments of every tool call in a chat completion in place
+func (r *ToolRepairer) RepairOpenAIToolCalls(resp *openai.ChatCompletion) {
+	if r == nil || resp == nil {
+		return
+	}
+	for i := range resp.Choices {
+		calls := resp.Choices[i].Message.ToolCalls
+		for j := range calls {
+			calls[j].Function.Arguments, _ = r.Repair(calls[j].Function.Name, calls[j].Function.Arguments)
+		}
+	}
+}
+
+// OpenAIToolCallBuffer holds back the arguments of streamed Chat Completions tool calls so they
+// can be sent once, repaired, after the model has finished the calls. A nil buffer passes the
+// deltas through untouched.
+type OpenAIToolCallBuffer struct {
+	repairer *ToolRepairer
+	calls    map[int64]*bufferedToolCall
+	order    []int64
+}
+
+type bufferedToolCall struct {
+	name      string
+	arguments string
+}
+
+// NewOpenAIToolCallBuffer returns a buffer that repairs with r, or nil when r is nil
+func (r *ToolRepairer) NewOpenAIToolCallBuffer() *OpenAIToolCallBuffer {
+	if r == nil {
+		return nil
+	}
+	return &OpenAIToolCallBuffer{repairer: r, calls: make(map[int64]*bufferedToolCall)}
+}
+
+// Hold buffers the arguments of the given deltas and returns the deltas to send now: the ones
+// that start a call (carrying its id and name) with their arguments removed
+func (b *OpenAIToolCallBuffer) Hold(deltas []openai.ChatCompletionChunkChoiceDeltaToolCall) []openai.ChatCompletionChunkChoiceDeltaToolCall {
+	if b == nil {
+		return deltas
+	}
+	var out []openai.ChatCompletionChunkChoiceDeltaToolCall
+	for _, delta := range deltas {
+		call, ok := b.calls[delta.Index]
+		if !ok {
+			call = &bufferedToolCall{}
+			b.calls[delta.Index] = call
+			b.order = append(b.order, delta.Index)
+		}
+		call.name += delta.Function.Name
+		call.arguments += delta.Function.Arguments
+
+		if delta.ID == "" && delta.Function.Name == "" {
+			continue
+		}
+		delta.Function.Arguments = ""
+		out = append(out, delta)
+	}
+	return out
+}
+
+// Flush returns one delta per buffered call carrying its repaired arguments, in the order the
+// calls started, and empties the buffer
+func (b *OpenAIToolCallBuffer) Flush() []openai.ChatCompletionChunkChoiceDeltaToolCall {
+	if b == nil || len(b.order) == 0 {
+		return nil
+	}
+	out := make([]openai.ChatCompletionChunkChoiceDeltaToolCall, 0, len(b.order))
+	for _, index := range b.order {
+		call := b.calls[index]
+		arguments, _ := b.repairer.Repair(call.name, call.arguments)
+		out = append(out, openai.ChatCompletionChunkChoiceDeltaToolCall{
+			Index:    index,
+			Function: openai.ChatCompletionChunkChoiceDeltaToolCallFunction{Arguments: arguments},
+		})
+	}
+	b.calls = make(map[int64]*bufferedToolCall)
+	b.order = nil
+	return out
+}
+
+// RepairJSON makes a best-effort attempt at turning almost-JSON into valid JSON. It strips
+// markdown code fences, converts single-quoted strings (unescaping \' inside them), drops trailing commas, maps Python
+// literals (True/False/None) and closes unterminated strings, arrays and objects. An empty
+// input becomes "{}". It reports false when the result is still not valid JSON.
+func RepairJSON(input string) (string, bool) {
+	s := strings.TrimSpace(input)
+	if s == "" {
+		return "{}", true
+	}
+	if strings.HasPrefix(s, "```") {
+		s = strings.TrimPrefix(s, "```")
+		if nl := strings.IndexByte(s, '\n'); nl >= 0 && !strings.ContainsAny(s[:nl], "{[") {
+			s = s[nl+1:] // drop the language tag
+		}
+		s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "```"))
+	}
+
+	var out strings.Builder
+	var stack []byte // open brackets
+	inString := false
+	var quote byte
+	escaped := false
+
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if inString {
+			switch {
+			case escaped:
+				// The backslash is held back until the escaped character is seen; \' is valid
+				// in single-quoted strings but not in JSON, so it becomes a plain quote
+				escaped = false
+				if ch != '\'' {
+					out.WriteByte('\\')
+				}
+				out.WriteByte(ch)
+			case ch == '\\':
+				escaped = true
+			case ch == quote:
+				inString = false
+				out.WriteByte('"')
+			case ch == '"': // a double quote inside a single-quoted string
+				out.WriteString(`\"`)
+			case ch == '\n':
+				out.WriteString(`\n`)
+			case ch == '\t':
+				out.WriteString(`\t`)
+			default:
+				out.WriteByte(ch)
+			}
+			continue
+		}
+
+		switch ch {
+		case '"', '\'':
+			inString = true
+			quote = ch
+			out.WriteByte('"')
+		case '{', '[':
+			stack = append(stack, ch)
+			out.WriteByte(ch)
+		case '}', ']':
+			trimTrailingComma(&out)
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			out.WriteByte(ch)
+		default:
+			if word, ok := pythonLiteral(s[i:]); ok {
+				out.WriteString(word.json)
+				i += len(word.python) - 1
+				continue
+			}
+			out.WriteByte(ch)
+		}
+	}
+
+	// A dangling backslash was never written, so the closing quote is not escaped
+	if inString {
+		out.WriteByte('"')
+	}
+	trimTrailingComma(&out)
+	trimDanglingKey(&out)
+	for i := len(stack) - 1; i >= 0; i-- {
+		trimTrailingComma(&out)
+		if stack[i] == '{' {
+			out.WriteByte('}')
+		} else {
+			out.WriteByte(']')
+		}
+	}
+
+	result := out.String()
+	return result, json.Valid([]byte(result))
+}
+
+type literalMapping struct{ python, json string }
+
+var pythonLiterals = []literalMapping{{"True", "true"}, {"False", "false"}, {"None", "null"}}
+
+// pythonLiteral matches a Python literal at the start of s when it stands alone as a token
+func pythonLiteral(s string) (literalMapping, bool) {
+	for _, lit := range pythonLiterals {
+		if strings.HasPrefix(s, lit.python) {
+			if len(s) == len(lit.python) || !isIdentByte(s[len(lit.python)]) {
+				return lit, true
+			}
+		}
+	}
+	return literalMapping{}, false
+}
+
+func isIdentByte(b byte) bool {
+	return b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// trimTrailingComma drops a comma (and the whitespace after it) at the end of the output
+func trimTrailingComma(out *strings.Builder) {
+	str := strings.TrimRight(out.String(), " \t\r\n")
+	if strings.HasSuffix(str, ",") {
+		out.Reset()
+		out.WriteString(str[:len(str)-1])
+	}
+}
+
+// trimDanglingKey drops an object key without a value left behind by a truncated stream,
+// e.g. `{"a": 1, "b"` or `{"a": 1, "b":`
+func trimDanglingKey(out *strings.Builder) {
+	str := strings.TrimRight(out.String(), " \t\r\n")
+	str = strings.TrimRight(strings.TrimSuffix(str, ":"), " \t\r\n")
+	if !strings.HasSuffix(str, `"`) {
+		return
+	}
+	start := -1
+	for i := len(str) - 2; i >= 0 && start < 0; i-- {
+		if str[i] != '"' {
+			continue
+		}
+		backslashes := 0
+		for j := i - 1; j >= 0 && str[j] == '\\'; j-- {
+			backslashes++
+		}
+		if backslashes%2 == 0 {
+			start = i
+		}
+	}
+	if start < 0 {
+		return
+	}
+	// Inside an object a string that follows "{" or "," can only be a key
+	before := strings.TrimRight(str[:start], " \t\r\n")
+	if (strings.HasSuffix(before, ",") || strings.HasSuffix(before, "{")) && isInObject(before) {
+		out.Reset()
+		out.WriteString(strings.TrimSuffix(before, ","))
+	}
+}
+
+// isInObject reports whether the innermost open bracket at the end of s is an object
+func isInObject(s string) bool {
+	depth := 0
+	inString := false
+	for i := len(s) - 1; i >= 0; i-- {
+		ch := s[i]
+		if ch == '"' && (i == 0 || s[i-1] != '\\') {
+			inString = !inString
+			continue
+		}
+		if inString {
+			continue
+		}
+		switch ch {
+		case '}', ']':
+			depth++
+		case '{', '[':
+			if depth == 0 {
+				return ch == '{'
+			}
+			depth--
+		}
+	}
+	return false
+}
+
+// coerceToSchema converts value to the types declared by schema where the conversion is
+// lossless, reporting whether anything changed
+func coerceToSchema(value interface{}, schema, root map[string]interface{}, depth int) (interface{}, bool) {
+	if schema == nil || depth > maxSchemaDepth {
+		return value, false
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		if resolved, err := (schemaValidator{root: root}).resolveRef(ref); err == nil {
+			return coerceToSchema(value, resolved, root, depth+1)
+		}
+	}
+
+	types := schemaTypes(schema["type"])
+	if len(types) > 0 && !typeAllowed(jsonTypeOf(value), types) {
+		for _, t := range types {
+			if coerced, ok := coerceScalar(value, t); ok {
+				coerced, _ = coerceToSchema(coerced, schema, root, depth+1)
+				return coerced, true
+			}
+		}
+		return value, false
+	}
+
+	changed := false
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		for key, prop := range typed {
+			propSchema := asSchema(properties[key])
+			if propSchema == nil {
+				propSchema = asSchema(schema["additionalProperties"])
+			}
+			if coerced, ok := coerceToSchema(prop, propSchema, root, depth+1); ok {
+				typed[key] = coerced
+				changed = true
+			}
+		}
+	case []interface{}:
+		items := asSchema(schema["items"])
+		for i, item := range typed {
+			if coerced, ok := coerceToSchema(item, items, root, depth+1); ok {
+				typed[i] = coerced
+				changed = true
+			}
+		}
+	}
+	return value, changed
+}
+
+func schemaTypes(typeSpec interface{}) []string {
+	switch t := typeSpec.(type) {
+	case string:
+		return []string{strings.ToLower(t)}
+	case []interface{}:
+		var types []string
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, strings.ToLower(s))
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func typeAllowed(actual string, types []string) bool {
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// coerceScalar converts value to the JSON type target, reporting false when it cannot do so
+// without guessing
+func coerceScalar(value interface{}, target string) (interface{}, bool) {
+	switch target {
+	case "string":
+		switch v := value.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), true
+		case bool:
+			return strconv.FormatBool(v), true
+		}
+	case "number", "integer":
+		var n float64
+		switch v := value.(type) {
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, false
+			}
+			n = parsed
+		case float64:
+			n = v
+		default:
+			return nil, false
+		}
+		if target == "integer" && jsonTypeOf(n) != "integer" {
+			return nil, false
+		}
+		return n, true
+	case "boolean":
+		if s, ok := value.(string); ok {
+			switch strings.ToLower(strings.TrimSpace(s)) {
+			case "true", "yes", "1":
+				return true, true
+			case "false", "no", "0":
+				return false, true
+			}
+		}
+	case "array":
+		if s, ok := value.(string); ok {
+			var arr []interface{}
+			if json.Unmarshal([]byte(s), &arr) == nil {
+				return arr, true
+			}
+		}
+		if value != nil {
+			return []interface{}{value}, true
+		}
+	case "object":
+		if s, ok := value.(string); ok {
+			var obj map[string]interface{}
+			if json.Unmarshal([]byte(s), &obj) == nil {
+				return obj, true
+			}
+		}
+	case "null":
+		if s, ok := value.(string); ok && (s == "" || strings.EqualFold(s, "null")) {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+// ToolRepairStats counts tool repair outcomes for one provider
+type ToolRepairStats struct {
+	Provider string `json:"provider"`
+	Checked  int64  `json:"checked"`
+	Repaired int64  `json:"repaired"`
+	Coerced  int64  `json:"coerced"`
+	Failed   int64  `json:"failed"`
+}
+
+var (
+	toolRepairMu    sync.Mutex
+	toolRepairStats = make(map[string]*ToolRepairStats)
+)
+
+func recordToolRepair(provider string, outcome ToolRepairOutcome) {
+	toolRepairMu.Lock()
+	defer toolRepairMu.Unlock()
+	stats, ok := toolRepairStats[provider]
+	if !ok {
+		stats = &ToolRepairStats{Provider: provider}
+		toolRepairStats[provider] = stats
+	}
+	stats.Checked++
+	switch outcome {
+	case ToolRepairRepaired:
+		stats.Repaired++
+	case ToolRepairCoerced:
+		stats.Coerced++
+	case ToolRepairFailed:
+		stats.Failed++
+	}
+}
+
+// ToolRepairStatsSnapshot returns the tool repair counters of every provider, sorted by provider
+func ToolRepairStatsSnapshot() []ToolRepairStats {
+	toolRepairMu.Lock()
+	defer toolRepairMu.Unlock()
+	snapshot := make([]ToolRepairStats, 0, len(toolRepairStats))
+	for _, stats := range toolRepairStats {
+		snapshot = append(snapshot, *stats)
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].Provider < snapshot[j].Provider })
+	return snapshot
+}
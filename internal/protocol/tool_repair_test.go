@@ -0,0 +1,122 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"valid", `{"a":1}`, `{"a":1}`},
+		{"empty", "", `{}`},
+		{"trailing comma", `{"a":1,"b":[1,2,],}`, `{"a":1,"b":[1,2]}`},
+		{"single quotes", `{'city': 'Paris', 'note': 'say "hi"'}`, `{"city": "Paris", "note": "say \"hi\""}`},
+		{"escaped single quote", `{'text': 'it\'s here', 'path': 'C:\\tmp'}`, `{"text": "it's here", "path": "C:\\tmp"}`},
+		{"unterminated string", `{"city": "Par`, `{"city": "Par"}`},
+		{"dangling backslash", `{"city": "Par\`, `{"city": "Par"}`},
+		{"unbalanced brackets", `{"a": {"b": [1, 2`, `{"a": {"b": [1, 2]}}`},
+		{"dangling key", `{"a": 1, "b":`, `{"a": 1}`},
+		{"python literals", `{"ok": True, "x": None, "name": "True"}`, `{"ok": true, "x": null, "name": "True"}`},
+		{"code fence", "```json\n{\"a\": 1}\n```", `{"a": 1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RepairJSON(tt.input)
+			require.True(t, ok, "repaired JSON should be valid: %s", got)
+			assert.JSONEq(t, tt.want, got)
+		})
+	}
+
+	_, ok := RepairJSON(`{"a" 1}`)
+	assert.False(t, ok)
+}
+
+func TestToolRepairer(t *testing.T) {
+	tools := []openai.ChatCompletionToolUnionParam{
+		openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+			Name: "search",
+			Parameters: openai.FunctionParameters{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"query":  map[string]interface{}{"type": "string"},
+					"limit":  map[string]interface{}{"type": "integer"},
+					"exact":  map[string]interface{}{"type": "boolean"},
+					"tags":   map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+					"filter": map[string]interface{}{"type": "object"},
+				},
+			},
+		}),
+	}
+	repairer := NewToolRepairer("test-tool-repair", tools)
+
+	args, outcome := repairer.Repair("search", `{"query": "go", "limit": 5}`)
+	assert.Equal(t, ToolRepairUnchanged, outcome)
+	assert.Equal(t, `{"query": "go", "limit": 5}`, args)
+
+	args, outcome = repairer.Repair("search", `{"query": 42, "limit": "5", "exact": "true", "tags": "go", "filter": "{\"lang\":\"en\"}"}`)
+	assert.Equal(t, ToolRepairCoerced, outcome)
+	assert.JSONEq(t, `{"query": "42", "limit": 5, "exact": true, "tags": ["go"], "filter": {"lang": "en"}}`, args)
+
+	args, outcome = repairer.Repair("search", `{'query': 'go', 'limit': '3',}`)
+	assert.Equal(t, ToolRepairRepaired, outcome)
+	assert.JSONEq(t, `{"query": "go", "limit": 3}`, args)
+
+	args, outcome = repairer.Repair("search", `"{\"query\":\"go\"}"`)
+	assert.Equal(t, ToolRepairRepaired, outcome)
+	assert.JSONEq(t, `{"query": "go"}`, args)
+
+	// A fractional value is not silently truncated to an integer
+	args, outcome = repairer.Repair("search", `{"limit": "2.5"}`)
+	assert.Equal(t, ToolRepairUnchanged, outcome)
+	assert.Equal(t, `{"limit": "2.5"}`, args)
+
+	args, outcome = repairer.Repair("search", `not json at all`)
+	assert.Equal(t, ToolRepairFailed, outcome)
+	assert.Equal(t, `not json at all`, args)
+
+	var stats ToolRepairStats
+	for _, s := range ToolRepairStatsSnapshot() {
+		if s.Provider == "test-tool-repair" {
+			stats = s
+		}
+	}
+	assert.Equal(t, ToolRepairStats{Provider: "test-tool-repair", Checked: 6, Repaired: 2, Coerced: 1, Failed: 1}, stats)
+}
+
+func TestOpenAIToolCallBuffer(t *testing.T) {
+	repairer := NewToolRepairer("test", []openai.ChatCompletionToolUnionParam{
+		openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{Name: "search"}),
+	})
+	buffer := repairer.NewOpenAIToolCallBuffer()
+
+	delta := func(id, name, arguments string) openai.ChatCompletionChunkChoiceDeltaToolCall {
+		return openai.ChatCompletionChunkChoiceDeltaToolCall{
+			ID:       id,
+			Function: openai.ChatCompletionChunkChoiceDeltaToolCallFunction{Name: name, Arguments: arguments},
+		}
+	}
+
+	// The call's start is sent without its arguments; argument-only deltas are held back
+	sent := buffer.Hold([]openai.ChatCompletionChunkChoiceDeltaToolCall{delta("call_1", "search", "{'query':")})
+	require.Len(t, sent, 1)
+	assert.Equal(t, "call_1", sent[0].ID)
+	assert.Empty(t, sent[0].Function.Arguments)
+	assert.Empty(t, buffer.Hold([]openai.ChatCompletionChunkChoiceDeltaToolCall{delta("", "", " 'go',}")}))
+
+	flushed := buffer.Flush()
+	require.Len(t, flushed, 1)
+	assert.Equal(t, `{"query":"go"}`, flushed[0].Function.Arguments)
+	assert.Nil(t, buffer.Flush())
+
+	// Without a repairer the deltas pass through
+	var none *ToolRepairer
+	passthrough := []openai.ChatCompletionChunkChoiceDeltaToolCall{delta("", "", "{'a':1}")}
+	assert.Equal(t, passthrough, none.NewOpenAIToolCallBuffer().Hold(passthrough))
+}
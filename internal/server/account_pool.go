@@ -0,0 +1,60 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+// resolveServiceProvider returns the provider a load balancer service points at. When the
+// service references an OAuth account pool, one of the pool's member accounts is selected,
+// skipping accounts whose usage window is exhausted.
+func (s *Server) resolveServiceProvider(uuid string) (*typ.Provider, error) {
+	target, err := s.config.ResolveService(uuid)
+	if err != nil {
+		return nil, fmt.Errorf("provider '%s' not found: %w", uuid, err)
+	}
+
+	if pool := target.Pool; pool != nil {
+		if !pool.Enabled {
+			return nil, fmt.Errorf("provider '%s' is not enabled", uuid)
+		}
+		return s.accountPools.Select(pool, target.Accounts)
+	}
+
+	provider := target.Provider
+	if !provider.Enabled {
+		return nil, fmt.Errorf("provider '%s' is not enabled", uuid)
+	}
+
+	return provider, nil
+}
+
+// accountFailover finds another account for a request a pool member was rate limited on
+type accountFailover struct {
+	server *Server
+}
+
+// FailoverAccount selects an available account from a pool the rate limited account belongs to
+func (f accountFailover) FailoverAccount(providerUUID string) *typ.Provider {
+	c := f.server.config
+	for _, pool := range c.ListAccountPools() {
+		if !pool.Enabled || !pool.HasAccount(providerUUID) {
+			continue
+		}
+		target, err := c.ResolveService(pool.UUID)
+		if err != nil {
+			continue
+		}
+		siblings := make([]*typ.Provider, 0, len(target.Accounts))
+		for _, account := range target.Accounts {
+			if account.UUID != providerUUID {
+				siblings = append(siblings, account)
+			}
+		}
+		if provider, err := f.server.accountPools.Select(pool, siblings); err == nil {
+			return provider
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tingly-dev/tingly-box/internal/alert"
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+// alertFeedBuffer is how many completed requests may queue for alert evaluation
+const alertFeedBuffer = 1024
+
+// AlertsResponse lists recently fired alerts
+type AlertsResponse struct {
+	Success bool          `json:"success" example:"true"`
+	Data    []alert.Alert `json:"data"`
+}
+
+// AlertTestRequest selects the sink a test alert is sent to
+type AlertTestRequest struct {
+	Sink string `json:"sink,omitempty" example:"slack"` // Sink name; empty sends to every sink
+}
+
+// AlertTestResponse is the outcome of a test alert per sink
+type AlertTestResponse struct {
+	Success bool                   `json:"success" example:"true"` // Whether every sink accepted the alert
+	Data    []alert.DeliveryResult `json:"data"`
+}
+
+// setupAlerting loads the alert rules and reports OAuth refresh failures and accounts that hit
+// their quota to them
+func (s *Server) setupAlerting() {
+	s.alerts = alert.NewManager()
+	if err := s.alerts.Reload(s.config.GetAlertingConfig()); err != nil {
+		log.Printf("Failed to load some alert rules or sinks: %v", err)
+	}
+
+	s.oauthRefresher.SetOnRefreshError(func(provider *typ.Provider, err error) {
+		s.alerts.Emit(alert.Event{
+			Kind:     alert.EventOAuthRefreshFailed,
+			Provider: provider.Name,
+			Message:  err.Error(),
+		})
+	})
+	s.accountPools.SetOnLimited(func(providerUUID string, statusCode int, until time.Time) {
+		name := providerUUID
+		if provider, err := s.config.GetProviderByUUID(providerUUID); err == nil {
+			name = provider.Name
+		}
+		s.alerts.Emit(alert.Event{
+			Kind:       alert.EventQuotaExhausted,
+			Provider:   name,
+			StatusCode: statusCode,
+			Message:    fmt.Sprintf("cooling down until %s", until.Format(time.RFC3339)),
+		})
+	})
+}
+
+// forwardRequestAlerts evaluates the alert rules over every completed proxied request until ctx
+// is done
+func (s *Server) forwardRequestAlerts(ctx context.Context) {
+	sub := s.requestFeed.Subscribe(alertFeedBuffer)
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.C():
+			if !ok {
+				return
+			}
+			if dropped := sub.TakeDropped(); dropped > 0 {
+				logrus.Warnf("Alert evaluation fell behind, skipped %d requests", dropped)
+			}
+			message := ""
+			if event.Status != "success" {
+				message = fmt.Sprintf("%s %s returned %d", event.Method, event.Path, event.StatusCode)
+				if event.ErrorCode != "" {
+					message += " (" + event.ErrorCode + ")"
+				}
+			}
+			s.alerts.Emit(alert.Event{
+				Kind:       alert.EventRequest,
+				Provider:   event.Provider,
+				Model:      event.Model,
+				StatusCode: event.StatusCode,
+				Status:     event.Status,
+				ErrorCode:  event.ErrorCode,
+				Tokens:     int64(event.InputTokens + event.OutputTokens),
+				Message:    message,
+			})
+		}
+	}
+}
+
+// GetAlerts returns the most recently fired alerts, newest first
+func (s *Server) GetAlerts(c *gin.Context) {
+	c.JSON(http.StatusOK, AlertsResponse{Success: true, Data: s.alerts.Recent()})
+}
+
+// TestAlert sends a test alert to one sink or all of them and reports each delivery
+func (s *Server) TestAlert(c *gin.Context) {
+	var req AlertTestRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request body: " + err.Error()})
+			return
+		}
+	}
+
+	results, err := s.alerts.Test(c.Request.Context(), req.Sink)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	success := true
+	for _, result := range results {
+		success = success && result.Success
+	}
+	c.JSON(http.StatusOK, AlertTestResponse{Success: success, Data: results})
+}
@@ -101,7 +101,7 @@ func (s *Server) AnthropicMessages(c *gin.Context) {
 		rule            *typ.Rule
 	)
 	if scenario == "" {
-		provider, selectedService, rule, err = s.DetermineProviderAndModel(model)
+		provider, selectedService, rule, err = s.DetermineProviderAndModel(c.Request.Context(), model)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, ErrorResponse{
 				Error: ErrorDetail{
@@ -123,7 +123,7 @@ func (s *Server) AnthropicMessages(c *gin.Context) {
 			})
 			return
 		}
-		provider, selectedService, rule, err = s.DetermineProviderAndModelWithScenario(scenarioType, model)
+		provider, selectedService, rule, err = s.DetermineProviderAndModelWithScenario(c.Request.Context(), scenarioType, model)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, ErrorResponse{
 				Error: ErrorDetail{
@@ -184,9 +184,9 @@ func (s *Server) AnthropicListModels(c *gin.Context) {
 			for i := range services {
 				svc := &services[i]
 				if svc.Active {
-					provider, err := cfg.GetProviderByUUID(svc.Provider)
+					target, err := cfg.ResolveService(svc.Provider)
 					if err == nil {
-						providerNames = append(providerNames, provider.Name)
+						providerNames = append(providerNames, target.Name())
 					}
 				}
 			}
@@ -267,7 +267,7 @@ func (s *Server) AnthropicCountTokens(c *gin.Context) {
 	}
 
 	// Determine provider and model based on request
-	provider, selectedService, _, err := s.DetermineProviderAndModel(model)
+	provider, selectedService, _, err := s.DetermineProviderAndModel(c.Request.Context(), model)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error: ErrorDetail{
@@ -373,12 +373,12 @@ func (s *Server) forwardAnthropicRequestRaw(provider *typ.Provider, rawReq map[s
 
 // ForwardAnthropicRequest forwards request using Anthropic SDK with proper types
 // This is a public utility function used by other handlers (e.g., openai.go)
-func (s *Server) ForwardAnthropicRequest(provider *typ.Provider, req anthropic.MessageNewParams) (*anthropic.Message, error) {
-	return s.forwardAnthropicRequestV1(provider, req)
+func (s *Server) ForwardAnthropicRequest(ctx context.Context, provider *typ.Provider, req anthropic.MessageNewParams) (*anthropic.Message, error) {
+	return s.forwardAnthropicRequestV1(ctx, provider, req)
 }
 
 // ForwardAnthropicStreamRequest forwards streaming request using Anthropic SDK
 // This is a public utility function used by other handlers (e.g., openai.go)
-func (s *Server) ForwardAnthropicStreamRequest(provider *typ.Provider, req anthropic.MessageNewParams) (*anthropicstream.Stream[anthropic.MessageStreamEventUnion], error) {
-	return s.forwardAnthropicStreamRequestV1(provider, req)
+func (s *Server) ForwardAnthropicStreamRequest(ctx context.Context, provider *typ.Provider, req anthropic.MessageNewParams) (*anthropicstream.Stream[anthropic.MessageStreamEventUnion], error) {
+	return s.forwardAnthropicStreamRequestV1(ctx, provider, req)
 }
@@ -7,6 +7,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
 )
 
 // SSEEventWriter is an interface for writing SSE events
@@ -119,7 +121,9 @@ func SendFinishEvent(c *gin.Context) {
 // ParseAndSendStreamError handles stream errors and sends appropriate error events
 func ParseAndSendStreamError(c *gin.Context, err error) {
 	logrus.Debugf("Anthropic stream error: %v", err)
-	MarshalAndSendErrorEvent(c, err.Error(), "stream_error", "stream_failed")
+	if sendErr := SendSSEEvent(c, "error", upstreamErrorBody(protocol.APIStyleAnthropic, err)); sendErr != nil {
+		SendSSErrorEvent(c, "Failed to marshal error", "internal_error")
+	}
 }
 
 // =============================================
@@ -136,24 +140,25 @@ func SendInvalidRequestBodyError(c *gin.Context, err error) {
 	})
 }
 
-// SendStreamingError sends an error response for streaming request failures
-func SendStreamingError(c *gin.Context, err error) {
-	c.JSON(http.StatusInternalServerError, ErrorResponse{
+// SendUnsupportedModalityError sends an error response when the target model cannot read the request content
+func SendUnsupportedModalityError(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, ErrorResponse{
 		Error: ErrorDetail{
-			Message: "Failed to create streaming request: " + err.Error(),
-			Type:    "api_error",
+			Message: err.Error(),
+			Type:    "invalid_request_error",
+			Code:    "unsupported_modality",
 		},
 	})
 }
 
+// SendStreamingError sends an error response for streaming request failures
+func SendStreamingError(c *gin.Context, err error) {
+	SendUpstreamError(c, protocol.APIStyleAnthropic, err)
+}
+
 // SendForwardingError sends an error response for request forwarding failures
 func SendForwardingError(c *gin.Context, err error) {
-	c.JSON(http.StatusInternalServerError, ErrorResponse{
-		Error: ErrorDetail{
-			Message: "Failed to forward request: " + err.Error(),
-			Type:    "api_error",
-		},
-	})
+	SendUpstreamError(c, protocol.APIStyleAnthropic, err)
 }
 
 // SendAdapterDisabledError sends an error response when adapter is disabled
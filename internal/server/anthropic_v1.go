@@ -12,6 +12,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/tingly-dev/tingly-box/internal/loadbalance"
+	"github.com/tingly-dev/tingly-box/internal/obs"
 	"github.com/tingly-dev/tingly-box/internal/protocol"
 	nonstream2 "github.com/tingly-dev/tingly-box/internal/protocol/nonstream"
 	request2 "github.com/tingly-dev/tingly-box/internal/protocol/request"
@@ -72,7 +73,7 @@ func (s *Server) anthropicMessagesV1(c *gin.Context, req protocol.AnthropicMessa
 		// Use direct Anthropic SDK call
 		if isStreaming {
 			// Handle streaming request
-			stream, err := s.forwardAnthropicStreamRequestV1(provider, req.MessageNewParams)
+			stream, err := s.forwardAnthropicStreamRequestV1(c.Request.Context(), provider, req.MessageNewParams)
 			if err != nil {
 				s.trackUsage(c, rule, provider, actualModel, proxyModel, 0, 0, false, "error", "stream_creation_failed")
 				SendStreamingError(c, err)
@@ -82,7 +83,7 @@ func (s *Server) anthropicMessagesV1(c *gin.Context, req protocol.AnthropicMessa
 			s.handleAnthropicStreamResponseV1(c, req.MessageNewParams, stream, proxyModel, actualModel, rule, provider)
 		} else {
 			// Handle non-streaming request
-			anthropicResp, err := s.forwardAnthropicRequestV1(provider, req.MessageNewParams)
+			anthropicResp, err := s.forwardAnthropicRequestV1(c.Request.Context(), provider, req.MessageNewParams)
 			if err != nil {
 				s.trackUsage(c, rule, provider, actualModel, proxyModel, 0, 0, false, "error", "forward_failed")
 				SendForwardingError(c, err)
@@ -92,6 +93,7 @@ func (s *Server) anthropicMessagesV1(c *gin.Context, req protocol.AnthropicMessa
 			// Track usage from response
 			inputTokens := int(anthropicResp.Usage.InputTokens)
 			outputTokens := int(anthropicResp.Usage.OutputTokens)
+			setCachedTokens(c, anthropicResp.Usage.CacheReadInputTokens)
 			s.trackUsage(c, rule, provider, actualModel, proxyModel, inputTokens, outputTokens, false, "success", "")
 
 			// FIXME: now we use req model as resp model
@@ -108,11 +110,14 @@ func (s *Server) anthropicMessagesV1(c *gin.Context, req protocol.AnthropicMessa
 		}
 
 		// Convert Anthropic request to Google format
+		translation := startTranslationSpan(c, "anthropic", "google")
 		model, googleReq, cfg := request2.ConvertAnthropicToGoogleRequest(&req.MessageNewParams, 0)
+		translation.End()
+		s.inlineGoogleRemoteMedia(c, provider, googleReq)
 
 		if isStreaming {
 			// Create streaming request
-			streamResp, err := s.forwardGoogleStreamRequest(provider, model, googleReq, cfg)
+			streamResp, err := s.forwardGoogleStreamRequest(c.Request.Context(), provider, model, googleReq, cfg)
 			if err != nil {
 				SendStreamingError(c, err)
 				return
@@ -120,7 +125,7 @@ func (s *Server) anthropicMessagesV1(c *gin.Context, req protocol.AnthropicMessa
 
 			// Handle the streaming response
 			err = stream2.HandleGoogleToAnthropicStreamResponse(c, streamResp, proxyModel)
-			if err != nil {
+			if err != nil && !c.Writer.Written() {
 				SendInternalError(c, err.Error())
 			}
 
@@ -129,7 +134,7 @@ func (s *Server) anthropicMessagesV1(c *gin.Context, req protocol.AnthropicMessa
 
 		} else {
 			// Handle non-streaming request
-			response, err := s.forwardGoogleRequest(provider, model, googleReq, cfg)
+			response, err := s.forwardGoogleRequest(c.Request.Context(), provider, model, googleReq, cfg)
 			if err != nil {
 				SendForwardingError(c, err)
 				return
@@ -157,32 +162,53 @@ func (s *Server) anthropicMessagesV1(c *gin.Context, req protocol.AnthropicMessa
 			return
 		}
 
+		// Responses-only models (and models the probe found prefer it) go through the Responses API
+		if s.useResponsesAPI(provider, selectedService) {
+			s.handleAnthropicV1ViaResponsesAPI(c, req, proxyModel, actualModel, provider, rule, isStreaming)
+			return
+		}
+
 		// Use OpenAI conversion path (default behavior)
 		if isStreaming {
 			// Convert Anthropic request to OpenAI format for streaming
+			translation := startTranslationSpan(c, "anthropic", "openai")
 			openaiReq := request2.ConvertAnthropicToOpenAIRequestWithProvider(&req.MessageNewParams, true, provider, actualModel)
+			translation.End()
+			s.applyChatServerTools(provider, actualModel, protocol.AnthropicServerTools(req.Tools), openaiReq)
+			if err := request2.CheckModelModalities(openaiReq, actualModel); err != nil {
+				SendUnsupportedModalityError(c, err)
+				return
+			}
 
 			// Create streaming request
-			streamResp, err := s.forwardOpenAIStreamRequest(provider, openaiReq)
+			streamResp, err := s.forwardOpenAIStreamRequest(c.Request.Context(), provider, openaiReq)
 			if err != nil {
 				SendStreamingError(c, err)
 				return
 			}
 
 			// Handle the streaming response
-			err = stream2.HandleOpenAIToAnthropicStreamResponse(c, openaiReq, streamResp, proxyModel)
-			if err != nil {
+			err = stream2.HandleOpenAIToAnthropicStreamResponse(c, openaiReq, streamResp, proxyModel, toolRepairer(provider, openaiReq))
+			if err != nil && !c.Writer.Written() {
 				SendInternalError(c, err.Error())
 			}
 
 		} else {
 			// Handle non-streaming request
+			translation := startTranslationSpan(c, "anthropic", "openai")
 			openaiReq, _ := request2.ConvertAnthropicToOpenAIRequest(&req.MessageNewParams, true)
-			response, err := s.forwardOpenAIRequest(provider, openaiReq)
+			translation.End()
+			s.applyChatServerTools(provider, actualModel, protocol.AnthropicServerTools(req.Tools), openaiReq)
+			if err := request2.CheckModelModalities(openaiReq, actualModel); err != nil {
+				SendUnsupportedModalityError(c, err)
+				return
+			}
+			response, err := s.forwardOpenAIRequest(c.Request.Context(), provider, openaiReq)
 			if err != nil {
 				SendForwardingError(c, err)
 				return
 			}
+			toolRepairer(provider, openaiReq).RepairOpenAIToolCalls(response)
 			// Convert OpenAI response back to Anthropic format
 			anthropicResp := nonstream2.ConvertOpenAIToAnthropicResponse(response, proxyModel)
 			c.JSON(http.StatusOK, anthropicResp)
@@ -192,16 +218,55 @@ func (s *Server) anthropicMessagesV1(c *gin.Context, req protocol.AnthropicMessa
 	}
 }
 
+// handleAnthropicV1ViaResponsesAPI handles Anthropic v1 request using OpenAI Responses API
+func (s *Server) handleAnthropicV1ViaResponsesAPI(c *gin.Context, req protocol.AnthropicMessagesRequest, proxyModel string, actualModel string, provider *typ.Provider, rule *typ.Rule, isStreaming bool) {
+	translation := startTranslationSpan(c, "anthropic", "responses")
+	serverTools := supportedServerTools(provider, actualModel, protocol.ServerToolBackendResponses, protocol.AnthropicServerTools(req.Tools))
+	responsesReq := request2.ConvertAnthropicToResponsesRequestWithProvider(&req.MessageNewParams, provider, actualModel, serverTools)
+	translation.End()
+
+	if !isStreaming {
+		response, err := s.forwardResponsesRequest(c.Request.Context(), provider, responsesReq)
+		if err != nil {
+			s.trackUsage(c, rule, provider, actualModel, proxyModel, 0, 0, false, "error", "forward_failed")
+			SendForwardingError(c, err)
+			return
+		}
+		s.trackUsage(c, rule, provider, actualModel, proxyModel, int(response.Usage.InputTokens), int(response.Usage.OutputTokens), false, "success", "")
+
+		// Convert Responses API response back to Anthropic format
+		c.JSON(http.StatusOK, nonstream2.ConvertResponsesToAnthropicResponse(response, proxyModel))
+		return
+	}
+
+	streamResp, cancel, err := s.forwardResponsesStreamRequest(c.Request.Context(), provider, responsesReq)
+	if err != nil {
+		s.trackUsage(c, rule, provider, actualModel, proxyModel, 0, 0, true, "error", "stream_creation_failed")
+		SendStreamingError(c, err)
+		return
+	}
+	defer cancel()
+
+	inputTokens, outputTokens, err := stream2.HandleResponsesToAnthropicStreamResponse(c, streamResp, proxyModel)
+	if err != nil {
+		s.trackStreamFailure(c, rule, provider, actualModel, proxyModel, inputTokens, outputTokens)
+		return
+	}
+	s.trackUsage(c, rule, provider, actualModel, proxyModel, inputTokens, outputTokens, true, "success", "")
+}
+
 // forwardAnthropicRequestV1 forwards request using Anthropic SDK with proper types (v1)
-func (s *Server) forwardAnthropicRequestV1(provider *typ.Provider, req anthropic.MessageNewParams) (*anthropic.Message, error) {
+func (s *Server) forwardAnthropicRequestV1(ctx context.Context, provider *typ.Provider, req anthropic.MessageNewParams) (*anthropic.Message, error) {
 	// Get or create Anthropic client wrapper from pool
 	wrapper := s.clientPool.GetAnthropicClient(provider, string(req.Model))
 
 	// Make the request using Anthropic SDK with timeout (provider.Timeout is in seconds)
+	ctx, span := startUpstreamSpan(ctx, provider, string(req.Model))
 	timeout := time.Duration(provider.Timeout) * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	message, err := wrapper.MessagesNew(ctx, req)
+	obs.EndSpan(span, err)
 	if err != nil {
 		return nil, err
 	}
@@ -210,17 +275,23 @@ func (s *Server) forwardAnthropicRequestV1(provider *typ.Provider, req anthropic
 }
 
 // forwardAnthropicStreamRequestV1 forwards streaming request using Anthropic SDK (v1)
-func (s *Server) forwardAnthropicStreamRequestV1(provider *typ.Provider, req anthropic.MessageNewParams) (*anthropicstream.Stream[anthropic.MessageStreamEventUnion], error) {
+func (s *Server) forwardAnthropicStreamRequestV1(ctx context.Context, provider *typ.Provider, req anthropic.MessageNewParams) (*anthropicstream.Stream[anthropic.MessageStreamEventUnion], error) {
 	// Get or create Anthropic client wrapper from pool
 	wrapper := s.clientPool.GetAnthropicClient(provider, string(req.Model))
 
 	logrus.Debugln("Creating Anthropic streaming request")
 
-	// Use background context for streaming
+	// Use a context without deadline for streaming
 	// The stream will manage its own lifecycle and timeout
 	// We don't use a timeout here because streaming responses can take longer
-	ctx := context.Background()
+	ctx, span := startUpstreamSpan(ctx, provider, string(req.Model))
 	stream := wrapper.MessagesNewStreaming(ctx, req)
+	err := stream.Err()
+	obs.EndSpan(span, err)
+	// A rejected request fails before the first event; report it while an HTTP status can still be sent
+	if err != nil {
+		return nil, err
+	}
 
 	return stream, nil
 }
@@ -241,11 +312,16 @@ func (s *Server) handleAnthropicStreamResponseV1(c *gin.Context, req anthropic.M
 
 	flusher, _ := c.Writer.(http.Flusher)
 
-	// Process the stream
-	for stream.Next() {
-		event := stream.Current()
+	// Process the stream, pinging the client while upstream is quiet
+	events := stream2.KeepAlive(c, stream, protocol.APIStyleAnthropic)
+	defer events.Close()
+	for events.Next() {
+		event := events.Current()
 		event.Message.Model = anthropic.Model(respModel)
 
+		// Cache reads are reported once, with the input usage in message_start
+		setCachedTokens(c, event.Message.Usage.CacheReadInputTokens)
+
 		// Accumulate usage from message_stop event
 		if event.Usage.InputTokens > 0 {
 			inputTokens = int(event.Usage.InputTokens)
@@ -264,13 +340,13 @@ func (s *Server) handleAnthropicStreamResponseV1(c *gin.Context, req anthropic.M
 		flusher.Flush()
 	}
 
-	// Check for stream errors
-	if err := stream.Err(); err != nil {
-		// Track usage with error status
+	// Check for stream errors, including an upstream that went silent past the idle timeout
+	if err := events.Err(); err != nil {
+		// Track what was used before the failure
 		if hasUsage {
-			s.trackUsage(c, rule, provider, actualModel, respModel, inputTokens, outputTokens, true, "error", "stream_error")
+			s.trackStreamFailure(c, rule, provider, actualModel, respModel, inputTokens, outputTokens)
 		}
-		MarshalAndSendErrorEvent(c, err.Error(), "stream_error", "stream_failed")
+		ParseAndSendStreamError(c, err)
 		flusher.Flush()
 		return
 	}
@@ -323,7 +399,7 @@ func (s *Server) anthropicCountTokensV1(c *gin.Context, bodyBytes []byte, rawReq
 	if apiStyle == "anthropic" {
 		message, err := wrapper.MessagesCountTokens(ctx, req)
 		if err != nil {
-			SendInvalidRequestBodyError(c, err)
+			SendForwardingError(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, message)
@@ -344,3 +420,13 @@ func (s *Server) trackUsage(c *gin.Context, rule *typ.Rule, provider *typ.Provid
 	tracker := s.NewUsageTracker()
 	tracker.RecordUsage(c, rule, provider, model, requestModel, inputTokens, outputTokens, streamed, status, errorCode)
 }
+
+// trackStreamFailure records a stream that broke off after it started. Tokens already reported
+// upstream were billed, so they are kept as a partial record instead of being dropped.
+func (s *Server) trackStreamFailure(c *gin.Context, rule *typ.Rule, provider *typ.Provider, model, requestModel string, inputTokens, outputTokens int) {
+	status := "error"
+	if inputTokens > 0 || outputTokens > 0 {
+		status = "partial"
+	}
+	s.trackUsage(c, rule, provider, model, requestModel, inputTokens, outputTokens, true, status, "stream_error")
+}
@@ -16,6 +16,7 @@ import (
 	"google.golang.org/genai"
 
 	"github.com/tingly-dev/tingly-box/internal/loadbalance"
+	"github.com/tingly-dev/tingly-box/internal/obs"
 	"github.com/tingly-dev/tingly-box/internal/protocol"
 	"github.com/tingly-dev/tingly-box/internal/protocol/nonstream"
 	"github.com/tingly-dev/tingly-box/internal/protocol/request"
@@ -65,7 +66,7 @@ func (s *Server) anthropicMessagesV1Beta(c *gin.Context, req protocol.AnthropicB
 		// Use direct Anthropic SDK call
 		if isStreaming {
 			// Handle streaming request
-			stream, err := s.forwardAnthropicStreamRequestV1Beta(provider, req.BetaMessageNewParams)
+			stream, err := s.forwardAnthropicStreamRequestV1Beta(c.Request.Context(), provider, req.BetaMessageNewParams)
 			if err != nil {
 				s.trackUsage(c, rule, provider, actualModel, proxyModel, 0, 0, false, "error", "stream_creation_failed")
 				SendStreamingError(c, err)
@@ -75,7 +76,7 @@ func (s *Server) anthropicMessagesV1Beta(c *gin.Context, req protocol.AnthropicB
 			s.handleAnthropicStreamResponseV1Beta(c, req.BetaMessageNewParams, stream, proxyModel, actualModel, rule, provider)
 		} else {
 			// Handle non-streaming request
-			anthropicResp, err := s.forwardAnthropicRequestV1Beta(provider, req.BetaMessageNewParams)
+			anthropicResp, err := s.forwardAnthropicRequestV1Beta(c.Request.Context(), provider, req.BetaMessageNewParams)
 			if err != nil {
 				s.trackUsage(c, rule, provider, actualModel, proxyModel, 0, 0, false, "error", "forward_failed")
 				SendForwardingError(c, err)
@@ -85,6 +86,7 @@ func (s *Server) anthropicMessagesV1Beta(c *gin.Context, req protocol.AnthropicB
 			// Track usage from response
 			inputTokens := int(anthropicResp.Usage.InputTokens)
 			outputTokens := int(anthropicResp.Usage.OutputTokens)
+			setCachedTokens(c, anthropicResp.Usage.CacheReadInputTokens)
 			s.trackUsage(c, rule, provider, actualModel, proxyModel, inputTokens, outputTokens, false, "success", "")
 
 			// FIXME: now we use req model as resp model
@@ -99,11 +101,14 @@ func (s *Server) anthropicMessagesV1Beta(c *gin.Context, req protocol.AnthropicB
 		}
 
 		// Convert Anthropic beta request to Google format
+		translation := startTranslationSpan(c, "anthropic", "google")
 		model, googleReq, cfg := request.ConvertAnthropicBetaToGoogleRequest(&req.BetaMessageNewParams, 0)
+		translation.End()
+		s.inlineGoogleRemoteMedia(c, provider, googleReq)
 
 		if isStreaming {
 			// Create streaming request
-			streamResp, err := s.forwardGoogleStreamRequest(provider, model, googleReq, cfg)
+			streamResp, err := s.forwardGoogleStreamRequest(c.Request.Context(), provider, model, googleReq, cfg)
 			if err != nil {
 				SendStreamingError(c, err)
 				return
@@ -111,7 +116,7 @@ func (s *Server) anthropicMessagesV1Beta(c *gin.Context, req protocol.AnthropicB
 
 			// Handle the streaming response
 			err = stream.HandleGoogleToAnthropicBetaStreamResponse(c, streamResp, proxyModel)
-			if err != nil {
+			if err != nil && !c.Writer.Written() {
 				SendInternalError(c, err.Error())
 			}
 
@@ -120,7 +125,7 @@ func (s *Server) anthropicMessagesV1Beta(c *gin.Context, req protocol.AnthropicB
 
 		} else {
 			// Handle non-streaming request
-			resp, err := s.forwardGoogleRequest(provider, model, googleReq, cfg)
+			resp, err := s.forwardGoogleRequest(c.Request.Context(), provider, model, googleReq, cfg)
 			if err != nil {
 				SendForwardingError(c, err)
 				return
@@ -149,9 +154,7 @@ func (s *Server) anthropicMessagesV1Beta(c *gin.Context, req protocol.AnthropicB
 
 		// Check if the model supports Responses API and use it if preferred
 		// For streaming v1beta, Responses API has the highest priority
-		preferredEndpoint := s.GetPreferredEndpointForModel(provider, actualModel)
-
-		if preferredEndpoint == "responses" {
+		if s.useResponsesAPI(provider, selectedService) {
 			// Use Responses API path (prioritized for streaming v1beta)
 			s.handleAnthropicV1BetaViaResponsesAPI(c, req, proxyModel, actualModel, provider, selectedService, rule, isStreaming)
 		} else {
@@ -164,15 +167,17 @@ func (s *Server) anthropicMessagesV1Beta(c *gin.Context, req protocol.AnthropicB
 }
 
 // forwardAnthropicRequestV1Beta forwards request using Anthropic SDK with proper types (beta)
-func (s *Server) forwardAnthropicRequestV1Beta(provider *typ.Provider, req anthropic.BetaMessageNewParams) (*anthropic.BetaMessage, error) {
+func (s *Server) forwardAnthropicRequestV1Beta(ctx context.Context, provider *typ.Provider, req anthropic.BetaMessageNewParams) (*anthropic.BetaMessage, error) {
 	// Get or create Anthropic client wrapper from pool
 	wrapper := s.clientPool.GetAnthropicClient(provider, string(req.Model))
 
 	// Make the request using Anthropic SDK with timeout (provider.Timeout is in seconds)
+	ctx, span := startUpstreamSpan(ctx, provider, string(req.Model))
 	timeout := time.Duration(provider.Timeout) * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	message, err := wrapper.BetaMessagesNew(ctx, req)
+	obs.EndSpan(span, err)
 	if err != nil {
 		return nil, err
 	}
@@ -181,23 +186,29 @@ func (s *Server) forwardAnthropicRequestV1Beta(provider *typ.Provider, req anthr
 }
 
 // forwardAnthropicStreamRequestV1Beta forwards streaming request using Anthropic SDK (beta)
-func (s *Server) forwardAnthropicStreamRequestV1Beta(provider *typ.Provider, req anthropic.BetaMessageNewParams) (*anthropicstream.Stream[anthropic.BetaRawMessageStreamEventUnion], error) {
+func (s *Server) forwardAnthropicStreamRequestV1Beta(ctx context.Context, provider *typ.Provider, req anthropic.BetaMessageNewParams) (*anthropicstream.Stream[anthropic.BetaRawMessageStreamEventUnion], error) {
 	// Get or create Anthropic client wrapper from pool
 	wrapper := s.clientPool.GetAnthropicClient(provider, string(req.Model))
 
 	logrus.Debugln("Creating Anthropic beta streaming request")
 
-	// Use background context for streaming
+	// Use a context without deadline for streaming
 	// The stream will manage its own lifecycle and timeout
 	// We don't use a timeout here because streaming responses can take longer
-	ctx := context.Background()
+	ctx, span := startUpstreamSpan(ctx, provider, string(req.Model))
 	stream := wrapper.BetaMessagesNewStreaming(ctx, req)
+	err := stream.Err()
+	obs.EndSpan(span, err)
+	// A rejected request fails before the first event; report it while an HTTP status can still be sent
+	if err != nil {
+		return nil, err
+	}
 
 	return stream, nil
 }
 
 // handleAnthropicStreamResponseV1Beta processes the Anthropic beta streaming response and sends it to the client
-func (s *Server) handleAnthropicStreamResponseV1Beta(c *gin.Context, req anthropic.BetaMessageNewParams, stream *anthropicstream.Stream[anthropic.BetaRawMessageStreamEventUnion], respModel, actualModel string, rule *typ.Rule, provider *typ.Provider) {
+func (s *Server) handleAnthropicStreamResponseV1Beta(c *gin.Context, req anthropic.BetaMessageNewParams, upstream *anthropicstream.Stream[anthropic.BetaRawMessageStreamEventUnion], respModel, actualModel string, rule *typ.Rule, provider *typ.Provider) {
 	// Accumulate usage from stream
 	var inputTokens, outputTokens int
 	var hasUsage bool
@@ -212,11 +223,16 @@ func (s *Server) handleAnthropicStreamResponseV1Beta(c *gin.Context, req anthrop
 
 	flusher, _ := c.Writer.(http.Flusher)
 
-	// Process the stream
-	for stream.Next() {
-		event := stream.Current()
+	// Process the stream, pinging the client while upstream is quiet
+	events := stream.KeepAlive(c, upstream, protocol.APIStyleAnthropic)
+	defer events.Close()
+	for events.Next() {
+		event := events.Current()
 		event.Message.Model = anthropic.Model(respModel)
 
+		// Cache reads are reported once, with the input usage in message_start
+		setCachedTokens(c, event.Message.Usage.CacheReadInputTokens)
+
 		// Accumulate usage from message_stop event
 		if event.Usage.InputTokens > 0 {
 			inputTokens = int(event.Usage.InputTokens)
@@ -235,13 +251,13 @@ func (s *Server) handleAnthropicStreamResponseV1Beta(c *gin.Context, req anthrop
 		flusher.Flush()
 	}
 
-	// Check for stream errors
-	if err := stream.Err(); err != nil {
-		// Track usage with error status
+	// Check for stream errors, including an upstream that went silent past the idle timeout
+	if err := events.Err(); err != nil {
+		// Track what was used before the failure
 		if hasUsage {
-			s.trackUsage(c, rule, provider, actualModel, respModel, inputTokens, outputTokens, true, "error", "stream_error")
+			s.trackStreamFailure(c, rule, provider, actualModel, respModel, inputTokens, outputTokens)
 		}
-		MarshalAndSendErrorEvent(c, err.Error(), "stream_error", "stream_failed")
+		ParseAndSendStreamError(c, err)
 		flusher.Flush()
 		return
 	}
@@ -257,7 +273,7 @@ func (s *Server) handleAnthropicStreamResponseV1Beta(c *gin.Context, req anthrop
 }
 
 // forwardGoogleRequest forwards request to Google API
-func (s *Server) forwardGoogleRequest(provider *typ.Provider, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+func (s *Server) forwardGoogleRequest(ctx context.Context, provider *typ.Provider, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
 	// Get or create Google client wrapper from pool
 	wrapper := s.clientPool.GetGoogleClient(provider, model)
 	if wrapper == nil {
@@ -265,11 +281,13 @@ func (s *Server) forwardGoogleRequest(provider *typ.Provider, model string, cont
 	}
 
 	// Make the request with timeout
+	ctx, span := startUpstreamSpan(ctx, provider, model)
 	timeout := time.Duration(provider.Timeout) * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	response, err := wrapper.GenerateContent(ctx, model, contents, config)
+	obs.EndSpan(span, err)
 	if err != nil {
 		return nil, err
 	}
@@ -278,7 +296,7 @@ func (s *Server) forwardGoogleRequest(provider *typ.Provider, model string, cont
 }
 
 // forwardGoogleStreamRequest forwards streaming request to Google API
-func (s *Server) forwardGoogleStreamRequest(provider *typ.Provider, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+func (s *Server) forwardGoogleStreamRequest(ctx context.Context, provider *typ.Provider, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
 	// Get or create Google client wrapper from pool
 	wrapper := s.clientPool.GetGoogleClient(provider, model)
 	if wrapper == nil {
@@ -287,11 +305,38 @@ func (s *Server) forwardGoogleStreamRequest(provider *typ.Provider, model string
 
 	logrus.Debugln("Creating Google streaming request")
 
-	// Use background context for streaming
-	ctx := context.Background()
+	// Use a context without deadline for streaming
+	ctx, span := startUpstreamSpan(ctx, provider, model)
 	stream := wrapper.GenerateContentStream(ctx, model, contents, config)
 
-	return stream, nil
+	stream, err := peekGoogleStream(stream)
+	obs.EndSpan(span, err)
+	return stream, err
+}
+
+// peekGoogleStream reads the first chunk of a Gemini stream. The genai client only sends the
+// request once iteration starts, so a rejected request would otherwise surface mid-stream
+// instead of as an HTTP error. The returned stream replays the first chunk.
+func peekGoogleStream(stream iter.Seq2[*genai.GenerateContentResponse, error]) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+	next, stop := iter.Pull2(stream)
+	first, err, ok := next()
+	if ok && err != nil {
+		stop()
+		return nil, err
+	}
+
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		defer stop()
+		if !ok || !yield(first, nil) {
+			return
+		}
+		for {
+			resp, err, ok := next()
+			if !ok || !yield(resp, err) {
+				return
+			}
+		}
+	}, nil
 }
 
 // anthropicCountTokensV1Beta implements beta count_tokens
@@ -332,7 +377,7 @@ func (s *Server) anthropicCountTokensV1Beta(c *gin.Context, bodyBytes []byte, ra
 	if apiStyle == "anthropic" {
 		message, err := wrapper.BetaMessagesCountTokens(ctx, req)
 		if err != nil {
-			SendInvalidRequestBodyError(c, err)
+			SendForwardingError(c, err)
 			return
 		}
 
@@ -352,29 +397,37 @@ func (s *Server) anthropicCountTokensV1Beta(c *gin.Context, bodyBytes []byte, ra
 // handleAnthropicV1BetaViaChatCompletions handles Anthropic v1beta request using OpenAI Chat Completions API
 func (s *Server) handleAnthropicV1BetaViaChatCompletions(c *gin.Context, req protocol.AnthropicBetaMessagesRequest, proxyModel string, actualModel string, provider *typ.Provider, selectedService *loadbalance.Service, rule *typ.Rule, isStreaming bool) {
 	// Convert Anthropic beta request to OpenAI format
+	translation := startTranslationSpan(c, "anthropic", "openai")
 	openaiReq := request.ConvertAnthropicBetaToOpenAIRequestWithProvider(&req.BetaMessageNewParams, true, provider, actualModel)
+	translation.End()
+	s.applyChatServerTools(provider, actualModel, protocol.AnthropicBetaServerTools(req.Tools), openaiReq)
+	if err := request.CheckModelModalities(openaiReq, actualModel); err != nil {
+		SendUnsupportedModalityError(c, err)
+		return
+	}
 
 	// Use OpenAI Chat Completions path
 	if isStreaming {
 		// Create streaming request
-		streamResp, err := s.forwardOpenAIStreamRequest(provider, openaiReq)
+		streamResp, err := s.forwardOpenAIStreamRequest(c.Request.Context(), provider, openaiReq)
 		if err != nil {
 			SendStreamingError(c, err)
 			return
 		}
 
 		// Handle the streaming response
-		err = stream.HandleOpenAIToAnthropicV1BetaStreamResponse(c, openaiReq, streamResp, proxyModel)
-		if err != nil {
+		err = stream.HandleOpenAIToAnthropicV1BetaStreamResponse(c, openaiReq, streamResp, proxyModel, toolRepairer(provider, openaiReq))
+		if err != nil && !c.Writer.Written() {
 			SendInternalError(c, err.Error())
 		}
 
 	} else {
-		resp, err := s.forwardOpenAIRequest(provider, openaiReq)
+		resp, err := s.forwardOpenAIRequest(c.Request.Context(), provider, openaiReq)
 		if err != nil {
 			SendForwardingError(c, err)
 			return
 		}
+		toolRepairer(provider, openaiReq).RepairOpenAIToolCalls(resp)
 		// Convert OpenAI response back to Anthropic beta format
 		anthropicResp := nonstream.ConvertOpenAIToAnthropicBetaResponse(resp, proxyModel)
 		c.JSON(http.StatusOK, anthropicResp)
@@ -384,7 +437,10 @@ func (s *Server) handleAnthropicV1BetaViaChatCompletions(c *gin.Context, req pro
 // handleAnthropicV1BetaViaResponsesAPI handles Anthropic v1beta request using OpenAI Responses API
 func (s *Server) handleAnthropicV1BetaViaResponsesAPI(c *gin.Context, req protocol.AnthropicBetaMessagesRequest, proxyModel string, actualModel string, provider *typ.Provider, selectedService *loadbalance.Service, rule *typ.Rule, isStreaming bool) {
 	// Convert Anthropic beta request to Responses API format
-	responsesReq := request.ConvertAnthropicBetaToResponsesRequestWithProvider(&req.BetaMessageNewParams, provider, actualModel)
+	translation := startTranslationSpan(c, "anthropic", "responses")
+	serverTools := supportedServerTools(provider, actualModel, protocol.ServerToolBackendResponses, protocol.AnthropicBetaServerTools(req.Tools))
+	responsesReq := request.ConvertAnthropicBetaToResponsesRequestWithProvider(&req.BetaMessageNewParams, provider, actualModel, serverTools)
+	translation.End()
 
 	// Set the rule and provider in context so middleware can use the same rule
 	if rule != nil {
@@ -405,7 +461,7 @@ func (s *Server) handleAnthropicV1BetaViaResponsesAPI(c *gin.Context, req protoc
 // handleAnthropicV1BetaViaResponsesAPINonStreaming handles non-streaming Responses API request
 func (s *Server) handleAnthropicV1BetaViaResponsesAPINonStreaming(c *gin.Context, req protocol.AnthropicBetaMessagesRequest, proxyModel string, actualModel string, provider *typ.Provider, selectedService *loadbalance.Service, rule *typ.Rule, responsesReq responses.ResponseNewParams) {
 	// Forward request to provider
-	response, err := s.forwardResponsesRequest(provider, responsesReq)
+	response, err := s.forwardResponsesRequest(c.Request.Context(), provider, responsesReq)
 	if err != nil {
 		s.trackUsage(c, rule, provider, actualModel, proxyModel, 0, 0, false, "error", "forward_failed")
 		SendForwardingError(c, err)
@@ -427,7 +483,7 @@ func (s *Server) handleAnthropicV1BetaViaResponsesAPINonStreaming(c *gin.Context
 // handleAnthropicV1BetaViaResponsesAPIStreaming handles streaming Responses API request
 func (s *Server) handleAnthropicV1BetaViaResponsesAPIStreaming(c *gin.Context, req protocol.AnthropicBetaMessagesRequest, proxyModel string, actualModel string, provider *typ.Provider, selectedService *loadbalance.Service, rule *typ.Rule, responsesReq responses.ResponseNewParams) {
 	// Create streaming request
-	streamResp, cancel, err := s.forwardResponsesStreamRequest(provider, responsesReq)
+	streamResp, cancel, err := s.forwardResponsesStreamRequest(c.Request.Context(), provider, responsesReq)
 	if err != nil {
 		s.trackUsage(c, rule, provider, actualModel, proxyModel, 0, 0, false, "error", "stream_creation_failed")
 		SendStreamingError(c, err)
@@ -437,15 +493,10 @@ func (s *Server) handleAnthropicV1BetaViaResponsesAPIStreaming(c *gin.Context, r
 
 	// Handle the streaming response
 	// Use the dedicated stream handler to convert Responses API to Anthropic beta format
-	err = stream.HandleResponsesToAnthropicV1BetaStreamResponse(c, streamResp, proxyModel)
-
-	// Track usage from stream (would be accumulated in handler)
-	// For now, we'll track minimal usage since the handler manages it
+	inputTokens, outputTokens, err := stream.HandleResponsesToAnthropicV1BetaStreamResponse(c, streamResp, proxyModel)
 	if err != nil {
-		s.trackUsage(c, rule, provider, actualModel, proxyModel, 0, 0, false, "error", "stream_error")
+		s.trackStreamFailure(c, rule, provider, actualModel, proxyModel, inputTokens, outputTokens)
 		return
 	}
-
-	// Success - usage tracking is handled inside the stream handler
-	// Note: The handler tracks usage when response.completed event is received
+	s.trackUsage(c, rule, provider, actualModel, proxyModel, inputTokens, outputTokens, true, "success", "")
 }
@@ -0,0 +1,126 @@
+package background
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tingly-dev/tingly-box/internal/db"
+	"github.com/tingly-dev/tingly-box/internal/server/config"
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+// ModelSyncer periodically fetches the model lists of enabled providers from their APIs, recording
+// the models each provider added and removed
+type ModelSyncer struct {
+	serverConfig  *config.Config
+	checkInterval time.Duration // How often providers due for a sync are looked for
+	stopChan      chan struct{}
+	mu            sync.RWMutex
+	running       bool
+
+	onChange func(provider *typ.Provider, diff db.ModelDiff)
+}
+
+// NewModelSyncer creates a model list syncer
+func NewModelSyncer(serverConfig *config.Config) *ModelSyncer {
+	return &ModelSyncer{
+		serverConfig:  serverConfig,
+		checkInterval: time.Hour,
+		stopChan:      make(chan struct{}),
+	}
+}
+
+// SetOnChange sets a callback invoked when a sync found added or removed models
+func (ms *ModelSyncer) SetOnChange(fn func(provider *typ.Provider, diff db.ModelDiff)) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.onChange = fn
+}
+
+// Start begins the background sync loop. Providers are synced once their last sync is older than
+// the configured interval, so restarts do not refetch every list.
+func (ms *ModelSyncer) Start(ctx context.Context) {
+	ms.mu.Lock()
+	if ms.running {
+		ms.mu.Unlock()
+		return
+	}
+	ms.running = true
+	stopChan := ms.stopChan
+	ms.mu.Unlock()
+
+	defer func() {
+		ms.mu.Lock()
+		ms.running = false
+		ms.mu.Unlock()
+	}()
+
+	ticker := time.NewTicker(ms.checkInterval)
+	defer ticker.Stop()
+
+	// Initial check on start
+	ms.SyncDue(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stopChan:
+			return
+		case <-ticker.C:
+			ms.SyncDue(ctx)
+		}
+	}
+}
+
+// Stop stops the background sync loop
+func (ms *ModelSyncer) Stop() {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if ms.running {
+		close(ms.stopChan)
+		ms.stopChan = make(chan struct{})
+	}
+}
+
+// SyncDue syncs the enabled providers whose model list was not fetched from their API within the
+// sync interval, and returns how many it synced. Nothing is synced while the sync is disabled.
+func (ms *ModelSyncer) SyncDue(ctx context.Context) int {
+	syncConfig := ms.serverConfig.GetModelSyncConfig()
+	if syncConfig.Disabled {
+		return 0
+	}
+	modelManager := ms.serverConfig.GetModelManager()
+	if modelManager == nil {
+		return 0
+	}
+
+	synced := 0
+	for _, provider := range ms.serverConfig.ListProviders() {
+		if ctx.Err() != nil {
+			break
+		}
+		if !provider.Enabled || time.Since(modelManager.GetLastSynced(provider.UUID)) < syncConfig.IntervalDuration() {
+			continue
+		}
+
+		diff, err := ms.serverConfig.SyncProviderModels(provider.UUID)
+		if err != nil {
+			logrus.Debugf("Model sync of provider %s failed: %v", provider.Name, err)
+			continue
+		}
+		synced++
+
+		ms.mu.RLock()
+		onChange := ms.onChange
+		ms.mu.RUnlock()
+		if onChange != nil && !diff.Empty() {
+			onChange(provider, diff)
+		}
+	}
+	return synced
+}
@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	"golang.org/x/sync/singleflight"
+
 	"github.com/tingly-dev/tingly-box/internal/server/config"
 	"github.com/tingly-dev/tingly-box/internal/typ"
 	oauth2 "github.com/tingly-dev/tingly-box/pkg/oauth"
@@ -25,6 +27,11 @@ type OAuthRefresher struct {
 	stopChan      chan struct{}
 	mu            sync.RWMutex
 	running       bool
+
+	// on-demand refreshes share one upstream call per provider
+	inflight       singleflight.Group
+	onRefresh      func(provider *typ.Provider)
+	onRefreshError func(provider *typ.Provider, err error)
 }
 
 // NewTokenRefresher creates a new token refresher
@@ -52,6 +59,20 @@ func (tr *OAuthRefresher) SetRefreshBuffer(buffer time.Duration) {
 	tr.refreshBuffer = buffer
 }
 
+// SetOnRefresh sets a callback invoked after a provider token was refreshed and persisted
+func (tr *OAuthRefresher) SetOnRefresh(fn func(provider *typ.Provider)) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.onRefresh = fn
+}
+
+// SetOnRefreshError sets a callback invoked when refreshing a provider token failed
+func (tr *OAuthRefresher) SetOnRefreshError(fn func(provider *typ.Provider, err error)) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.onRefreshError = fn
+}
+
 // Start begins the background token refresh loop
 func (tr *OAuthRefresher) Start(ctx context.Context) {
 	tr.mu.Lock()
@@ -128,8 +149,9 @@ func (tr *OAuthRefresher) CheckAndRefreshTokens() {
 
 		// Check if token needs refresh (sequential, not concurrent)
 		if expiresAt.Before(now.Add(tr.refreshBuffer)) {
-			tr.refreshProviderToken(provider)
-			refreshCount++
+			if tr.refreshProviderToken(context.Background(), provider) == nil {
+				refreshCount++
+			}
 		}
 	}
 
@@ -138,16 +160,50 @@ func (tr *OAuthRefresher) CheckAndRefreshTokens() {
 	}
 }
 
+// RefreshAccessToken refreshes a provider's token on demand, after the upstream rejected staleToken.
+// Concurrent calls for the same provider share a single refresh. If the stored token already differs
+// from staleToken, another request refreshed it in the meantime and it is returned without refreshing.
+func (tr *OAuthRefresher) RefreshAccessToken(ctx context.Context, providerUUID, staleToken string) (string, error) {
+	token, err, _ := tr.inflight.Do(providerUUID, func() (interface{}, error) {
+		provider, err := tr.serverConfig.GetProviderByUUID(providerUUID)
+		if err != nil {
+			return "", err
+		}
+		if provider.AuthType != typ.AuthTypeOAuth || provider.OAuthDetail == nil {
+			return "", fmt.Errorf("provider %s does not use OAuth", provider.Name)
+		}
+		if provider.OAuthDetail.AccessToken != staleToken {
+			return provider.OAuthDetail.AccessToken, nil
+		}
+		if provider.OAuthDetail.RefreshToken == "" {
+			return "", fmt.Errorf("provider %s has no refresh token", provider.Name)
+		}
+
+		// Detach from the request context so one cancelled caller does not fail the shared refresh
+		refreshCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+		if err := tr.refreshProviderToken(refreshCtx, provider); err != nil {
+			return "", err
+		}
+		return provider.OAuthDetail.AccessToken, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return token.(string), nil
+}
+
 // refreshProviderToken refreshes a single provider's token
-func (tr *OAuthRefresher) refreshProviderToken(provider *typ.Provider) {
+func (tr *OAuthRefresher) refreshProviderToken(ctx context.Context, provider *typ.Provider) error {
 	providerType, err := oauth2.ParseProviderType(provider.OAuthDetail.ProviderType)
 	if err != nil {
 		fmt.Printf("[OAuthRefresher] Invalid provider type for %s: %v\n", provider.Name, err)
-		return
+		tr.refreshFailed(provider, err)
+		return err
 	}
 
 	token, err := tr.manager.RefreshToken(
-		context.Background(),
+		ctx,
 		provider.OAuthDetail.UserID,
 		providerType,
 		provider.OAuthDetail.RefreshToken,
@@ -155,7 +211,8 @@ func (tr *OAuthRefresher) refreshProviderToken(provider *typ.Provider) {
 
 	if err != nil {
 		fmt.Printf("[OAuthRefresher] Failed to refresh %s: %v\n", provider.Name, err)
-		return
+		tr.refreshFailed(provider, err)
+		return err
 	}
 
 	// Update provider with new token
@@ -167,8 +224,27 @@ func (tr *OAuthRefresher) refreshProviderToken(provider *typ.Provider) {
 
 	if err := tr.serverConfig.UpdateProvider(provider.UUID, provider); err != nil {
 		fmt.Printf("[OAuthRefresher] Failed to update %s: %v\n", provider.Name, err)
-		return
+		tr.refreshFailed(provider, err)
+		return err
 	}
 
 	fmt.Printf("[OAuthRefresher] Refreshed token for %s (expires at %s)\n", provider.Name, provider.OAuthDetail.ExpiresAt)
+
+	tr.mu.RLock()
+	onRefresh := tr.onRefresh
+	tr.mu.RUnlock()
+	if onRefresh != nil {
+		onRefresh(provider)
+	}
+	return nil
+}
+
+// refreshFailed reports a failed refresh to the error callback, if any
+func (tr *OAuthRefresher) refreshFailed(provider *typ.Provider, err error) {
+	tr.mu.RLock()
+	onRefreshError := tr.onRefreshError
+	tr.mu.RUnlock()
+	if onRefreshError != nil {
+		onRefreshError(provider, err)
+	}
 }
@@ -2,6 +2,7 @@ package background
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -341,3 +342,62 @@ func TestOAuthRefresherSkipValidTokens(t *testing.T) {
 		t.Error("Expected RefreshToken NOT to be called for valid token")
 	}
 }
+
+// TestOAuthRefresherRefreshAccessToken tests the on-demand refresh after a rejected token
+func TestOAuthRefresherRefreshAccessToken(t *testing.T) {
+	cfg := &config.Config{ConfigFile: filepath.Join(t.TempDir(), "config.json")}
+	mockMgr := &mockTokenRefresher{}
+
+	provider := &typ.Provider{
+		UUID:     "test-provider-uuid",
+		Name:     "TestOAuthProvider",
+		APIBase:  "https://api.test.com",
+		APIStyle: protocol.APIStyleAnthropic,
+		AuthType: typ.AuthTypeOAuth,
+		OAuthDetail: &typ.OAuthDetail{
+			AccessToken:  "revoked_access_token",
+			RefreshToken: "refresh_token_123",
+			ProviderType: "claude_code",
+			UserID:       "test-user",
+			ExpiresAt:    time.Now().Add(1 * time.Hour).Format(time.RFC3339), // Not expired, but rejected upstream
+		},
+	}
+	cfg.Providers = append(cfg.Providers, provider)
+
+	refresher := &OAuthRefresher{
+		manager:       mockMgr,
+		serverConfig:  cfg,
+		checkInterval: 10 * time.Minute,
+		refreshBuffer: 5 * time.Minute,
+		stopChan:      make(chan struct{}),
+	}
+
+	var refreshed string
+	refresher.SetOnRefresh(func(p *typ.Provider) {
+		refreshed = p.UUID
+	})
+
+	token, err := refresher.RefreshAccessToken(context.Background(), provider.UUID, "revoked_access_token")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if token != "new_access_token" {
+		t.Errorf("Expected access token 'new_access_token', got '%s'", token)
+	}
+	if refreshed != provider.UUID {
+		t.Error("Expected refresh callback to be invoked")
+	}
+
+	// A caller that saw the old token after the refresh gets the new one without another refresh
+	mockMgr.refreshCalled = false
+	token, err = refresher.RefreshAccessToken(context.Background(), provider.UUID, "revoked_access_token")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if token != "new_access_token" {
+		t.Errorf("Expected access token 'new_access_token', got '%s'", token)
+	}
+	if mockMgr.refreshCalled {
+		t.Error("Expected RefreshToken NOT to be called when the token was already refreshed")
+	}
+}
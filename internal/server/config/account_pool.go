@@ -0,0 +1,180 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+// ListAccountPools returns all OAuth account pools
+func (c *Config) ListAccountPools() []*typ.AccountPool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.AccountPools
+}
+
+// GetAccountPoolByUUID returns an account pool by UUID
+func (c *Config) GetAccountPoolByUUID(uuid string) (*typ.AccountPool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, p := range c.AccountPools {
+		if p.UUID == uuid {
+			return p, nil
+		}
+	}
+
+	return nil, fmt.Errorf("account pool '%s' not found", uuid)
+}
+
+// ServiceTarget is what the provider UUID of a load balancer service references: a provider, or
+// an account pool and its member accounts
+type ServiceTarget struct {
+	Provider *typ.Provider
+	Pool     *typ.AccountPool
+	Accounts []*typ.Provider // the pool's existing member accounts
+}
+
+// Name returns the name of the provider or account pool
+func (t *ServiceTarget) Name() string {
+	if t.Pool != nil {
+		return t.Pool.Name
+	}
+	return t.Provider.Name
+}
+
+// Providers returns the provider, or every member account of the pool
+func (t *ServiceTarget) Providers() []*typ.Provider {
+	if t.Pool != nil {
+		return t.Accounts
+	}
+	return []*typ.Provider{t.Provider}
+}
+
+// ResolveService resolves the provider UUID of a load balancer service, which names either a
+// provider or an account pool
+func (c *Config) ResolveService(uuid string) (*ServiceTarget, error) {
+	if pool, err := c.GetAccountPoolByUUID(uuid); err == nil {
+		target := &ServiceTarget{Pool: pool, Accounts: make([]*typ.Provider, 0, len(pool.Accounts))}
+		for _, accountUUID := range pool.Accounts {
+			if account, err := c.GetProviderByUUID(accountUUID); err == nil {
+				target.Accounts = append(target.Accounts, account)
+			}
+		}
+		return target, nil
+	}
+
+	provider, err := c.GetProviderByUUID(uuid)
+	if err != nil {
+		return nil, err
+	}
+	return &ServiceTarget{Provider: provider}, nil
+}
+
+// AddAccountPool adds a new account pool after validating its members
+func (c *Config) AddAccountPool(pool *typ.AccountPool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if pool.UUID == "" {
+		pool.UUID = GenerateUUID()
+	}
+	if err := c.validateAccountPool(pool); err != nil {
+		return err
+	}
+
+	c.AccountPools = append(c.AccountPools, pool)
+
+	return c.Save()
+}
+
+// UpdateAccountPool replaces an existing account pool by UUID
+func (c *Config) UpdateAccountPool(uuid string, pool *typ.AccountPool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, p := range c.AccountPools {
+		if p.UUID == uuid {
+			// Preserve the UUID
+			pool.UUID = uuid
+			if err := c.validateAccountPool(pool); err != nil {
+				return err
+			}
+			c.AccountPools[i] = pool
+			return c.Save()
+		}
+	}
+
+	return fmt.Errorf("account pool with UUID '%s' not found", uuid)
+}
+
+// DeleteAccountPool removes an account pool by UUID. Member providers are kept.
+func (c *Config) DeleteAccountPool(uuid string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, p := range c.AccountPools {
+		if p.UUID == uuid {
+			c.AccountPools = append(c.AccountPools[:i], c.AccountPools[i+1:]...)
+			return c.Save()
+		}
+	}
+
+	return fmt.Errorf("account pool with UUID '%s' not found", uuid)
+}
+
+// validateAccountPool checks that every member is an existing OAuth provider of the pool's provider type.
+// Caller must hold c.mu.
+func (c *Config) validateAccountPool(pool *typ.AccountPool) error {
+	if pool.Name == "" {
+		return errors.New("account pool name cannot be empty")
+	}
+	if pool.ProviderType == "" {
+		return errors.New("account pool provider type cannot be empty")
+	}
+	if len(pool.Accounts) == 0 {
+		return errors.New("account pool must contain at least one account")
+	}
+
+	seen := make(map[string]bool, len(pool.Accounts))
+	for _, uuid := range pool.Accounts {
+		if seen[uuid] {
+			return fmt.Errorf("account '%s' is listed more than once", uuid)
+		}
+		seen[uuid] = true
+
+		var member *typ.Provider
+		for _, p := range c.Providers {
+			if p.UUID == uuid {
+				member = p
+				break
+			}
+		}
+		if member == nil {
+			return fmt.Errorf("provider '%s' not found", uuid)
+		}
+		if member.AuthType != typ.AuthTypeOAuth || member.OAuthDetail == nil {
+			return fmt.Errorf("provider '%s' is not an OAuth provider", member.Name)
+		}
+		if member.OAuthDetail.ProviderType != pool.ProviderType {
+			return fmt.Errorf("provider '%s' has type '%s', expected '%s'", member.Name, member.OAuthDetail.ProviderType, pool.ProviderType)
+		}
+	}
+
+	return nil
+}
+
+// removeAccountFromPools drops a provider UUID from every pool. Caller must hold c.mu.
+func (c *Config) removeAccountFromPools(providerUUID string) {
+	for _, pool := range c.AccountPools {
+		accounts := pool.Accounts[:0]
+		for _, uuid := range pool.Accounts {
+			if uuid != providerUUID {
+				accounts = append(accounts, uuid)
+			}
+		}
+		pool.Accounts = accounts
+	}
+}
@@ -13,10 +13,14 @@ import (
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
+	"github.com/tingly-dev/tingly-box/internal/alert"
+	"github.com/tingly-dev/tingly-box/internal/client"
 	"github.com/tingly-dev/tingly-box/internal/constant"
 	"github.com/tingly-dev/tingly-box/internal/db"
 	"github.com/tingly-dev/tingly-box/internal/loadbalance"
+	"github.com/tingly-dev/tingly-box/internal/obs"
 	"github.com/tingly-dev/tingly-box/internal/protocol"
+	"github.com/tingly-dev/tingly-box/internal/protocol/stream"
 	"github.com/tingly-dev/tingly-box/internal/template"
 	"github.com/tingly-dev/tingly-box/internal/typ"
 	"github.com/tingly-dev/tingly-box/pkg/auth"
@@ -37,15 +41,45 @@ type Config struct {
 	ServerPort  int                      `json:"server_port"`
 	JWTSecret   string                   `json:"jwt_secret"`
 
+	// OAuth account pools: several subscription accounts exposed as one logical provider
+	AccountPools []*typ.AccountPool `json:"account_pools,omitempty"`
+
 	// Server settings
 	DefaultMaxTokens int  `json:"default_max_tokens"` // Default max_tokens for anthropic API requests
 	Verbose          bool `json:"verbose"`            // Verbose mode for detailed logging
 	Debug            bool `json:"-"`                  // Debug mode for Gin debug level logging
 	OpenBrowser      bool `yaml:"-" json:"-"`         // Auto-open browser in web UI mode (default: true)
 
+	// Require the user token to scrape /metrics (default: open, like a typical Prometheus target)
+	MetricsRequireAuth bool `json:"metrics_require_auth,omitempty"`
+
 	// Error log settings
 	ErrorLogFilterExpression string `json:"error_log_filter_expression"` // Expression for filtering error log entries (default: "StatusCode >= 400 && Path matches '^/api/'")
 
+	// Redaction of secrets and PII in recorded traffic and the error log (built-in rules always apply unless disabled)
+	Redaction *obs.RedactionConfig `json:"redaction,omitempty"`
+
+	// Download limits and allow-lists for remote media inlined for backends that reject URLs (e.g. Gemini)
+	MediaFetch *client.MediaFetchConfig `json:"media_fetch,omitempty"`
+
+	// Batched usage record writes, rollups and raw record retention
+	UsageStorage *db.UsageStoreConfig `json:"usage_storage,omitempty"`
+
+	// Background health probes of the provider models used by active rules (off unless enabled)
+	HealthProbe *typ.HealthProbeConfig `json:"health_probe,omitempty"`
+
+	// Scheduled sync of provider model lists, which detects removed models (on unless disabled)
+	ModelSync *typ.ModelSyncConfig `json:"model_sync,omitempty"`
+
+	// Alert rules over request failures, OAuth refresh failures, exhausted quota and failed probes
+	Alerting *alert.Config `json:"alerting,omitempty"`
+
+	// OpenTelemetry trace export (off unless a collector endpoint is set)
+	Tracing *obs.TracingConfig `json:"tracing,omitempty"`
+
+	// Keep-alive pings and the upstream idle timeout of streamed responses
+	Streaming *stream.KeepAliveConfig `json:"streaming,omitempty"`
+
 	ConfigFile string `yaml:"-" json:"-"` // Not serialized to YAML (exported to preserve field)
 	ConfigDir  string `yaml:"-" json:"-"`
 
@@ -746,6 +780,9 @@ func (c *Config) DeleteProvider(uuid string) error {
 		if p.UUID == uuid {
 			c.Providers = append(c.Providers[:i], c.Providers[i+1:]...)
 
+			// Drop the provider from any account pool it belonged to
+			c.removeAccountFromPools(uuid)
+
 			// Delete the associated model file
 			if c.modelManager != nil {
 				_ = c.modelManager.RemoveProvider(uuid)
@@ -817,6 +854,13 @@ func (c *Config) SetVerbose(verbose bool) error {
 	return c.Save()
 }
 
+// GetMetricsRequireAuth reports whether /metrics requires the user token
+func (c *Config) GetMetricsRequireAuth() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.MetricsRequireAuth
+}
+
 // GetDebug returns the debug setting
 func (c *Config) GetDebug() bool {
 	c.mu.RLock()
@@ -854,6 +898,83 @@ func (c *Config) GetErrorLogFilterExpression() string {
 	return c.ErrorLogFilterExpression
 }
 
+// GetRedactionConfig returns the redaction settings, or nil for the built-in rules only
+func (c *Config) GetRedactionConfig() *obs.RedactionConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Redaction
+}
+
+// GetMediaFetchConfig returns the remote media fetch settings; zero values fall back to defaults
+func (c *Config) GetMediaFetchConfig() client.MediaFetchConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.MediaFetch == nil {
+		return client.MediaFetchConfig{}
+	}
+	return *c.MediaFetch
+}
+
+// GetUsageStorageConfig returns the usage record write and retention settings; zero values use the defaults
+func (c *Config) GetUsageStorageConfig() db.UsageStoreConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.UsageStorage == nil {
+		return db.UsageStoreConfig{}
+	}
+	return *c.UsageStorage
+}
+
+// GetHealthProbeConfig returns the background health probe settings with defaults filled in
+func (c *Config) GetHealthProbeConfig() typ.HealthProbeConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.HealthProbe == nil {
+		return typ.HealthProbeConfig{}.WithDefaults()
+	}
+	return c.HealthProbe.WithDefaults()
+}
+
+// GetModelSyncConfig returns the scheduled model list sync settings
+func (c *Config) GetModelSyncConfig() typ.ModelSyncConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.ModelSync == nil {
+		return typ.ModelSyncConfig{}
+	}
+	return *c.ModelSync
+}
+
+// GetAlertingConfig returns the alert rules and sinks
+func (c *Config) GetAlertingConfig() alert.Config {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Alerting == nil {
+		return alert.Config{}
+	}
+	return *c.Alerting
+}
+
+// GetTracingConfig returns the trace export settings; zero values leave tracing off
+func (c *Config) GetTracingConfig() obs.TracingConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Tracing == nil {
+		return obs.TracingConfig{}
+	}
+	return *c.Tracing
+}
+
+// GetStreamingConfig returns the stream keep-alive settings; zero values fall back to defaults
+func (c *Config) GetStreamingConfig() stream.KeepAliveConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Streaming == nil {
+		return stream.KeepAliveConfig{}
+	}
+	return *c.Streaming
+}
+
 // SetErrorLogFilterExpression updates the error log filter expression
 func (c *Config) SetErrorLogFilterExpression(expr string) error {
 	c.mu.Lock()
@@ -983,8 +1104,9 @@ func (c *Config) FetchAndSaveProviderModels(uid string) error {
 	if err != nil {
 		logrus.Errorf("Failed to fetch models from API: %v", err)
 	} else {
-		// Save models to local storage
-		return c.modelManager.SaveModels(provider, provider.APIBase, models)
+		// Save models to local storage, recording what changed since the last fetch
+		_, err := c.saveSyncedModels(provider, models)
+		return err
 	}
 
 	// API failed, try template fallback
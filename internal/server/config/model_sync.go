@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tingly-dev/tingly-box/internal/db"
+	"github.com/tingly-dev/tingly-box/internal/template"
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+// SyncProviderModels fetches a provider's model list from its API, saves it and returns the models
+// added and removed since the previous fetch. Unlike FetchAndSaveProviderModels it does not fall
+// back to template models, which would show up as changes on the next sync.
+func (c *Config) SyncProviderModels(uid string) (db.ModelDiff, error) {
+	provider, err := c.GetProviderByUUID(uid)
+	if err != nil {
+		return db.ModelDiff{}, err
+	}
+	models, err := template.GetProviderModelsFromAPI(provider)
+	if err != nil {
+		return db.ModelDiff{}, err
+	}
+	return c.saveSyncedModels(provider, models)
+}
+
+// saveSyncedModels saves a model list fetched from the provider API and logs what changed
+func (c *Config) saveSyncedModels(provider *typ.Provider, models []string) (db.ModelDiff, error) {
+	if c.modelManager == nil {
+		return db.ModelDiff{}, fmt.Errorf("model manager not initialized")
+	}
+	diff, err := c.modelManager.SyncModels(provider, provider.APIBase, models)
+	if err != nil {
+		return db.ModelDiff{}, err
+	}
+	if len(diff.Added) > 0 {
+		logrus.Infof("Provider %s added models: %v", provider.Name, diff.Added)
+	}
+	if len(diff.Removed) > 0 {
+		logrus.Warnf("Provider %s removed models: %v", provider.Name, diff.Removed)
+	}
+	return diff, nil
+}
+
+// GetModelWarnings flags the services of the given rules whose model their provider no longer
+// lists or whose provider template marks the model deprecated. Services of account pools are
+// checked against each member account.
+func (c *Config) GetModelWarnings(rules []typ.Rule) []typ.ModelWarning {
+	tm := c.GetTemplateManager()
+	removedByProvider := make(map[string]map[string]typ.ModelWarning)
+
+	var warnings []typ.ModelWarning
+	for _, rule := range rules {
+		for i := range rule.Services {
+			service := &rule.Services[i]
+			target, err := c.ResolveService(service.Provider)
+			if err != nil {
+				continue // dangling services
+			}
+
+			for _, provider := range target.Providers() {
+				removed, ok := removedByProvider[provider.UUID]
+				if !ok {
+					removed = make(map[string]typ.ModelWarning)
+					if c.modelManager != nil {
+						for model, detectedAt := range c.modelManager.GetRemovedModels(provider.UUID) {
+							since := detectedAt
+							removed[model] = typ.ModelWarning{
+								Reason:  typ.ModelWarningRemoved,
+								Since:   &since,
+								Message: fmt.Sprintf("%s no longer lists model %s (since %s)", provider.Name, model, since.Format("2006-01-02")),
+							}
+						}
+					}
+					removedByProvider[provider.UUID] = removed
+				}
+
+				warning, ok := removed[service.Model]
+				if !ok && tm.IsModelDeprecated(provider, service.Model) {
+					warning = typ.ModelWarning{
+						Reason:  typ.ModelWarningDeprecated,
+						Message: fmt.Sprintf("%s has deprecated model %s", provider.Name, service.Model),
+					}
+					ok = true
+				}
+				if !ok {
+					continue
+				}
+
+				warning.RuleUUID = rule.UUID
+				warning.RequestModel = rule.RequestModel
+				warning.ProviderUUID = provider.UUID
+				warning.ProviderName = provider.Name
+				warning.Model = service.Model
+				warnings = append(warnings, warning)
+			}
+		}
+	}
+	return warnings
+}
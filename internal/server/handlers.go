@@ -1,13 +1,16 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/tingly-dev/tingly-box/internal/loadbalance"
+	"github.com/tingly-dev/tingly-box/internal/obs"
 	"github.com/tingly-dev/tingly-box/internal/typ"
 )
 
@@ -158,8 +161,27 @@ func (s *Server) determineProvider(model, explicitProvider string) (*typ.Provide
 	return nil, fmt.Errorf("no enabled providers available")
 }
 
-// DetermineProviderAndModelWithScenario
-func (s *Server) DetermineProviderAndModelWithScenario(scenario typ.RuleScenario, modelName string) (*typ.Provider, *loadbalance.Service, *typ.Rule, error) {
+// DetermineProviderAndModelWithScenario resolves the rule for a model in a scenario and selects
+// the service to forward to, recording the decision as a "route" span
+func (s *Server) DetermineProviderAndModelWithScenario(ctx context.Context, scenario typ.RuleScenario, modelName string) (provider *typ.Provider, selectedService *loadbalance.Service, rule *typ.Rule, err error) {
+	_, span := obs.StartSpan(ctx, "route",
+		obs.AttrScenario.String(string(scenario)),
+		obs.AttrGenAIRequestModel.String(modelName))
+	defer func() {
+		if rule != nil && provider != nil && selectedService != nil {
+			span.SetAttributes(
+				obs.AttrRule.String(rule.RequestModel),
+				obs.AttrProvider.String(provider.Name),
+				obs.AttrGenAIResponseModel.String(selectedService.Model))
+		}
+		recordRouting(ctx, string(scenario), modelName, provider, selectedService, rule, err)
+		obs.EndSpan(span, err)
+	}()
+
+	return s.determineProviderAndModelWithScenario(scenario, modelName)
+}
+
+func (s *Server) determineProviderAndModelWithScenario(scenario typ.RuleScenario, modelName string) (*typ.Provider, *loadbalance.Service, *typ.Rule, error) {
 	// Check if this is the request model name first
 	c := s.config
 	if c != nil && c.IsRequestModelInScenario(modelName, scenario) {
@@ -191,14 +213,10 @@ func (s *Server) DetermineProviderAndModelWithScenario(scenario typ.RuleScenario
 				return nil, nil, nil, fmt.Errorf("no available service for request model '%s'", modelName)
 			}
 
-			// Verify the provider exists and is enabled
-			provider, err := c.GetProviderByUUID(selectedService.Provider)
+			// Verify the provider (or account pool) exists and is enabled
+			provider, err := s.resolveServiceProvider(selectedService.Provider)
 			if err != nil {
-				return nil, nil, nil, fmt.Errorf("provider '%s' not found: %w", selectedService.Provider, err)
-			}
-
-			if !provider.Enabled {
-				return nil, nil, nil, fmt.Errorf("provider '%s' is not enabled", selectedService.Provider)
+				return nil, nil, nil, err
 			}
 
 			// Update the current service index for the rule
@@ -222,8 +240,42 @@ func (s *Server) DetermineProviderAndModelWithScenario(scenario typ.RuleScenario
 	return nil, nil, nil, fmt.Errorf("provider or model not configured for request model '%s'", modelName)
 }
 
+// recordRouting adds the outcome of resolving a request model to the detail of the request ctx
+// belongs to
+func recordRouting(ctx context.Context, scenario, modelName string, provider *typ.Provider, selectedService *loadbalance.Service, rule *typ.Rule, err error) {
+	detail := obs.RequestDetailFromContext(ctx)
+	if detail == nil {
+		return
+	}
+
+	decision := obs.RoutingDecision{Time: time.Now(), Scenario: scenario, RequestModel: modelName}
+	if rule != nil && provider != nil && selectedService != nil {
+		decision.RuleUUID = rule.UUID
+		decision.Tactic = rule.LBTactic.Type.String()
+		decision.Provider = provider.Name
+		decision.Model = selectedService.Model
+		services := rule.GetServices()
+		for i := range services {
+			if services[i].Active {
+				decision.Candidates++
+			}
+		}
+	}
+	if err != nil {
+		decision.Error = err.Error()
+	}
+	detail.AddRouting(decision)
+}
+
 // DetermineProviderAndModel resolves the model name and finds the appropriate provider using load balancing
-func (s *Server) DetermineProviderAndModel(modelName string) (*typ.Provider, *loadbalance.Service, *typ.Rule, error) {
+func (s *Server) DetermineProviderAndModel(ctx context.Context, modelName string) (provider *typ.Provider, selectedService *loadbalance.Service, rule *typ.Rule, err error) {
+	defer func() {
+		recordRouting(ctx, "", modelName, provider, selectedService, rule, err)
+	}()
+	return s.determineProviderAndModel(modelName)
+}
+
+func (s *Server) determineProviderAndModel(modelName string) (*typ.Provider, *loadbalance.Service, *typ.Rule, error) {
 	// Check if this is the request model name first
 	c := s.config
 	if c != nil && c.IsRequestModel(modelName) {
@@ -255,14 +307,10 @@ func (s *Server) DetermineProviderAndModel(modelName string) (*typ.Provider, *lo
 				return nil, nil, nil, fmt.Errorf("no available service for request model '%s'", modelName)
 			}
 
-			// Verify the provider exists and is enabled
-			provider, err := c.GetProviderByUUID(selectedService.Provider)
+			// Verify the provider (or account pool) exists and is enabled
+			provider, err := s.resolveServiceProvider(selectedService.Provider)
 			if err != nil {
-				return nil, nil, nil, fmt.Errorf("provider '%s' not found: %w", selectedService.Provider, err)
-			}
-
-			if !provider.Enabled {
-				return nil, nil, nil, fmt.Errorf("provider '%s' is not enabled", selectedService.Provider)
+				return nil, nil, nil, err
 			}
 
 			// Update the current service index for the rule
@@ -0,0 +1,373 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	anthropicoption "github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/openai/openai-go/v3"
+	openaioption "github.com/openai/openai-go/v3/option"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tingly-dev/tingly-box/internal/alert"
+	"github.com/tingly-dev/tingly-box/internal/db"
+	"github.com/tingly-dev/tingly-box/internal/loadbalance"
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+const (
+	// healthProbeConcurrency limits the probes of one round in flight at once
+	healthProbeConcurrency = 4
+
+	// healthPruneInterval is how often probe history past its retention is deleted
+	healthPruneInterval = time.Hour
+)
+
+// probeTarget is a provider model used by an active rule
+type probeTarget struct {
+	serviceID string
+	provider  *typ.Provider
+	model     string
+}
+
+// serviceHealth is the probe state of one service
+type serviceHealth struct {
+	lastChecked         time.Time
+	consecutiveFailures int
+}
+
+// HealthProber periodically probes the provider models used by active rules with a minimal chat
+// request. Outcomes go to the probe history of the capability store, and services whose recent
+// probes all failed are avoided by service selection.
+type HealthProber struct {
+	server *Server
+
+	mu         sync.RWMutex
+	states     map[string]*serviceHealth // by service ID (provider:model)
+	sent       []time.Time               // probe times within the last hour, for the cost cap
+	lastPruned time.Time
+
+	stopChan chan struct{}
+	running  bool
+}
+
+// NewHealthProber creates a health prober for the server
+func NewHealthProber(s *Server) *HealthProber {
+	return &HealthProber{
+		server:   s,
+		states:   make(map[string]*serviceHealth),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start runs probe rounds at the configured interval until ctx is done or Stop is called. Rounds
+// are skipped while probing is disabled; the settings are reread every round so config reloads
+// take effect.
+func (hp *HealthProber) Start(ctx context.Context) {
+	hp.mu.Lock()
+	if hp.running {
+		hp.mu.Unlock()
+		return
+	}
+	hp.running = true
+	stopChan := hp.stopChan
+	hp.mu.Unlock()
+
+	defer func() {
+		hp.mu.Lock()
+		hp.running = false
+		hp.mu.Unlock()
+	}()
+
+	for {
+		cfg := hp.server.config.GetHealthProbeConfig()
+		if cfg.Enabled {
+			hp.ProbeOnce(ctx)
+		}
+
+		timer := time.NewTimer(cfg.IntervalDuration())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-stopChan:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Stop stops the probe loop
+func (hp *HealthProber) Stop() {
+	hp.mu.Lock()
+	defer hp.mu.Unlock()
+
+	if hp.running {
+		close(hp.stopChan)
+		hp.stopChan = make(chan struct{})
+	}
+}
+
+// ProbeOnce runs one probe round and returns the number of probes sent. Targets probed within the
+// interval are skipped, and the rest are probed least recently checked first until the hourly cap
+// is reached.
+func (hp *HealthProber) ProbeOnce(ctx context.Context) int {
+	cfg := hp.server.config.GetHealthProbeConfig()
+	now := time.Now()
+
+	targets := hp.targets()
+	hp.mu.Lock()
+	hp.sent = pruneBefore(hp.sent, now.Add(-time.Hour))
+	budget := cfg.MaxProbesPerHour - len(hp.sent)
+
+	due := targets[:0]
+	for _, target := range targets {
+		if state := hp.states[target.serviceID]; state == nil || now.Sub(state.lastChecked) >= cfg.IntervalDuration() {
+			due = append(due, target)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return hp.lastCheckedLocked(due[i].serviceID).Before(hp.lastCheckedLocked(due[j].serviceID))
+	})
+	if budget < len(due) {
+		if budget < 0 {
+			budget = 0
+		}
+		logrus.Debugf("Health probe cap reached, deferring %d of %d probes", len(due)-budget, len(due))
+		due = due[:budget]
+	}
+	for range due {
+		hp.sent = append(hp.sent, now)
+	}
+	hp.mu.Unlock()
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, healthProbeConcurrency)
+	for _, target := range due {
+		wg.Add(1)
+		go func(target probeTarget) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			hp.probe(ctx, target, cfg)
+		}(target)
+	}
+	wg.Wait()
+
+	hp.pruneHistory(cfg)
+	return len(due)
+}
+
+// probe sends one probe and records its outcome
+func (hp *HealthProber) probe(ctx context.Context, target probeTarget, cfg typ.HealthProbeConfig) {
+	probeCtx, cancel := context.WithTimeout(ctx, DefaultProbeTimeout)
+	defer cancel()
+
+	status := hp.send(probeCtx, target.provider, target.model)
+	if ctx.Err() != nil {
+		return // shutting down; the failure says nothing about the provider
+	}
+
+	checkedAt := time.Now()
+	hp.mu.Lock()
+	state := hp.states[target.serviceID]
+	if state == nil {
+		state = &serviceHealth{}
+		hp.states[target.serviceID] = state
+	}
+	wasHealthy := state.consecutiveFailures < cfg.FailureThreshold
+	state.lastChecked = checkedAt
+	if status.Available {
+		state.consecutiveFailures = 0
+	} else {
+		state.consecutiveFailures++
+	}
+	isHealthy := state.consecutiveFailures < cfg.FailureThreshold
+	hp.mu.Unlock()
+
+	if wasHealthy && !isHealthy {
+		logrus.Warnf("Health probe: %s/%s failed %d probes in a row, selection will avoid it: %s",
+			target.provider.Name, target.model, cfg.FailureThreshold, status.ErrorMessage)
+	} else if !wasHealthy && isHealthy {
+		logrus.Infof("Health probe: %s/%s recovered", target.provider.Name, target.model)
+	}
+	if !status.Available {
+		hp.server.alerts.Emit(alert.Event{
+			Kind:     alert.EventProbeFailed,
+			Provider: target.provider.Name,
+			Model:    target.model,
+			Message:  status.ErrorMessage,
+		})
+	}
+
+	if store := hp.server.capabilityStore; store != nil {
+		err := store.RecordProbe(&db.ProbeRecord{
+			ProviderUUID: target.provider.UUID,
+			ModelID:      target.model,
+			Available:    status.Available,
+			LatencyMs:    status.LatencyMs,
+			ErrorMessage: status.ErrorMessage,
+			CheckedAt:    checkedAt,
+		})
+		if err != nil {
+			logrus.Warnf("Failed to record health probe: %v", err)
+		}
+	}
+}
+
+// send sends a minimal chat request through the client pool, so the probe carries the proxy,
+// OAuth credentials and request hooks real traffic uses. A rate-limited provider counts as
+// available; the SDK's retries are disabled so each probe costs one request.
+func (hp *HealthProber) send(ctx context.Context, provider *typ.Provider, model string) EndpointStatus {
+	start := time.Now()
+	var err error
+	switch provider.APIStyle {
+	case protocol.APIStyleOpenAI:
+		wrapper := hp.server.clientPool.GetOpenAIClient(provider, model)
+		_, err = wrapper.Client().Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+			Model:     model,
+			Messages:  []openai.ChatCompletionMessageParamUnion{openai.UserMessage("Hi")},
+			MaxTokens: openai.Int(5),
+		}, openaioption.WithMaxRetries(0))
+	case protocol.APIStyleAnthropic:
+		var system []anthropic.TextBlockParam
+		if provider.AuthType == typ.AuthTypeOAuth && provider.OAuthDetail != nil &&
+			provider.OAuthDetail.ProviderType == "claude_code" {
+			system = append(system, anthropic.TextBlockParam{Text: ClaudeCodeSystemHeader})
+		}
+		wrapper := hp.server.clientPool.GetAnthropicClient(provider, model)
+		_, err = wrapper.Client().Messages.New(ctx, anthropic.MessageNewParams{
+			Model:     anthropic.Model(model),
+			System:    system,
+			Messages:  []anthropic.MessageParam{anthropic.NewUserMessage(anthropic.NewTextBlock("Hi"))},
+			MaxTokens: 5,
+		}, anthropicoption.WithMaxRetries(0))
+	default:
+		err = fmt.Errorf("unsupported API style: %s", provider.APIStyle)
+	}
+
+	status := EndpointStatus{LatencyMs: int(time.Since(start).Milliseconds()), LastChecked: time.Now()}
+	var openaiErr *openai.Error
+	var anthropicErr *anthropic.Error
+	switch {
+	case err == nil,
+		errors.As(err, &openaiErr) && openaiErr.StatusCode == http.StatusTooManyRequests,
+		errors.As(err, &anthropicErr) && anthropicErr.StatusCode == http.StatusTooManyRequests:
+		status.Available = true
+	default:
+		status.ErrorMessage = err.Error()
+	}
+	return status
+}
+
+// probeable reports whether the prober can probe providers of the API style. Google-style
+// providers are not probed, so their services always count as healthy.
+func probeable(style protocol.APIStyle) bool {
+	return style == protocol.APIStyleOpenAI || style == protocol.APIStyleAnthropic
+}
+
+// targets returns the enabled provider models used by active services of active rules. Services
+// of account pools are not probed: a probe would only reach whichever member account is selected.
+func (hp *HealthProber) targets() []probeTarget {
+	c := hp.server.config
+	seen := make(map[string]bool)
+	var targets []probeTarget
+
+	for _, rule := range c.GetRequestConfigs() {
+		if !rule.Active {
+			continue
+		}
+		for i := range rule.Services {
+			service := &rule.Services[i]
+			if !service.Active || seen[service.ServiceID()] {
+				continue
+			}
+			seen[service.ServiceID()] = true
+
+			target, err := c.ResolveService(service.Provider)
+			if err != nil || target.Pool != nil {
+				continue
+			}
+			provider := target.Provider
+			if !provider.Enabled || !probeable(provider.APIStyle) {
+				continue
+			}
+			targets = append(targets, probeTarget{serviceID: service.ServiceID(), provider: provider, model: service.Model})
+		}
+	}
+	return targets
+}
+
+// Healthy reports whether selection may route to the service. A service is healthy unless probing
+// is enabled and its last FailureThreshold probes failed; unprobed services are healthy.
+func (hp *HealthProber) Healthy(service *loadbalance.Service) bool {
+	return hp.healthy(service.ServiceID())
+}
+
+// healthy is Healthy by service ID
+func (hp *HealthProber) healthy(serviceID string) bool {
+	cfg := hp.server.config.GetHealthProbeConfig()
+	if !cfg.Enabled {
+		return true
+	}
+	return hp.ConsecutiveFailures(serviceID) < cfg.FailureThreshold
+}
+
+// ConsecutiveFailures returns how many probes of the service failed in a row since the last
+// success; services not probed since the server started have none
+func (hp *HealthProber) ConsecutiveFailures(serviceID string) int {
+	hp.mu.RLock()
+	defer hp.mu.RUnlock()
+	if state := hp.states[serviceID]; state != nil {
+		return state.consecutiveFailures
+	}
+	return 0
+}
+
+// pruneHistory deletes probe history past its retention, at most once per healthPruneInterval
+func (hp *HealthProber) pruneHistory(cfg typ.HealthProbeConfig) {
+	store := hp.server.capabilityStore
+	if store == nil {
+		return
+	}
+
+	hp.mu.Lock()
+	if time.Since(hp.lastPruned) < healthPruneInterval {
+		hp.mu.Unlock()
+		return
+	}
+	hp.lastPruned = time.Now()
+	hp.mu.Unlock()
+
+	cutoff := time.Now().AddDate(0, 0, -cfg.RetentionDays)
+	if deleted, err := store.PruneProbeHistory(cutoff); err != nil {
+		logrus.Warnf("Failed to prune health probe history: %v", err)
+	} else if deleted > 0 {
+		logrus.Debugf("Pruned %d health probes older than %d days", deleted, cfg.RetentionDays)
+	}
+}
+
+// lastCheckedLocked returns when the service was last probed; the caller holds hp.mu
+func (hp *HealthProber) lastCheckedLocked(serviceID string) time.Time {
+	if state := hp.states[serviceID]; state != nil {
+		return state.lastChecked
+	}
+	return time.Time{}
+}
+
+// pruneBefore drops the leading times before cutoff from times, which is in ascending order
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	return times[i:]
+}
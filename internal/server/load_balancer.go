@@ -18,6 +18,9 @@ type LoadBalancer struct {
 	statsMW *middleware.StatsMiddleware
 	config  *config.Config
 	mutex   sync.RWMutex
+
+	// healthy reports whether a service passes its health probes; nil treats every service as healthy
+	healthy func(service *loadbalance.Service) bool
 }
 
 // NewLoadBalancer creates a new load balancer
@@ -50,6 +53,13 @@ func (lb *LoadBalancer) RegisterTactic(tacticType loadbalance.TacticType, tactic
 	lb.tactics[tacticType] = tactic
 }
 
+// SetHealthCheck sets the check selection uses to steer away from services failing health probes
+func (lb *LoadBalancer) SetHealthCheck(healthy func(service *loadbalance.Service) bool) {
+	lb.mutex.Lock()
+	defer lb.mutex.Unlock()
+	lb.healthy = healthy
+}
+
 // SelectService selects the best service for a rule based on the configured tactic
 func (lb *LoadBalancer) SelectService(rule *typ.Rule) (*loadbalance.Service, error) {
 	if rule == nil {
@@ -89,9 +99,41 @@ func (lb *LoadBalancer) SelectService(rule *typ.Rule) (*loadbalance.Service, err
 		return &activeServices[0], nil
 	}
 
+	// Steer away from a service failing its health probes
+	if healthy := lb.nextHealthyService(rule, selectedService); healthy != nil {
+		return healthy, nil
+	}
+
 	return selectedService, nil
 }
 
+// nextHealthyService returns the first healthy active service of the rule, starting at selected
+// and wrapping around. It returns nil when there is no health check or no service is healthy, in
+// which case the tactic's choice stands.
+func (lb *LoadBalancer) nextHealthyService(rule *typ.Rule, selected *loadbalance.Service) *loadbalance.Service {
+	lb.mutex.RLock()
+	healthy := lb.healthy
+	lb.mutex.RUnlock()
+	if healthy == nil || healthy(selected) {
+		return nil
+	}
+
+	activeServices := rule.GetActiveServices()
+	start := 0
+	for i, service := range activeServices {
+		if service.ServiceID() == selected.ServiceID() {
+			start = i
+			break
+		}
+	}
+	for i := 1; i < len(activeServices); i++ {
+		if service := activeServices[(start+i)%len(activeServices)]; healthy(service) {
+			return service
+		}
+	}
+	return nil
+}
+
 // getTactic retrieves a tactic by type
 func (lb *LoadBalancer) getTactic(tacticType loadbalance.TacticType) (typ.LoadBalancingTactic, bool) {
 	lb.mutex.RLock()
@@ -54,6 +54,15 @@ func convertLogrusEntry(entry *logrus.Entry) LogEntry {
 	if userAgent, ok := data["user_agent"].(string); ok {
 		fields["user_agent"] = userAgent
 	}
+	if requestID, ok := data["request_id"].(string); ok && requestID != "" {
+		fields["request_id"] = requestID
+	}
+	if provider, ok := data["provider"].(string); ok {
+		fields["provider"] = provider
+	}
+	if model, ok := data["model"].(string); ok {
+		fields["model"] = model
+	}
 
 	return LogEntry{
 		Time:    entry.Time,
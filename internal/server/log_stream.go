@@ -0,0 +1,177 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tingly-dev/tingly-box/internal/server/middleware"
+)
+
+const (
+	// liveStreamBuffer is how many events a live stream subscriber holds before events are
+	// dropped for it
+	liveStreamBuffer = 256
+
+	// liveStreamKeepAlive is how often an idle live stream sends a comment to keep proxies from
+	// closing it
+	liveStreamKeepAlive = 15 * time.Second
+)
+
+// liveFilter selects the events of a live stream. Empty fields match everything.
+type liveFilter struct {
+	level    logrus.Level // least severe level let through
+	provider string
+	model    string
+	status   string // success, error or partial; an HTTP status code; or a class such as 5xx
+}
+
+// parseLiveFilter reads the level, provider, model and status query parameters
+func parseLiveFilter(c *gin.Context) (liveFilter, error) {
+	filter := liveFilter{
+		level:    logrus.TraceLevel,
+		provider: c.Query("provider"),
+		model:    c.Query("model"),
+		status:   strings.ToLower(c.Query("status")),
+	}
+	if value := c.Query("level"); value != "" {
+		level, err := logrus.ParseLevel(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid level %q", value)
+		}
+		filter.level = level
+	}
+	return filter, nil
+}
+
+// match reports whether an event with these attributes passes the filter
+func (f liveFilter) match(level logrus.Level, provider, model string, statusCode int, status string) bool {
+	if level > f.level {
+		return false
+	}
+	if f.provider != "" && !strings.EqualFold(f.provider, provider) {
+		return false
+	}
+	if f.model != "" && f.model != model {
+		return false
+	}
+	if f.status == "" {
+		return true
+	}
+	code := strconv.Itoa(statusCode)
+	if len(f.status) == 3 && strings.HasSuffix(f.status, "xx") {
+		return code[:1] == f.status[:1]
+	}
+	return f.status == code || f.status == status
+}
+
+// levelOfStatus is the level the memory log uses for an HTTP status code
+func levelOfStatus(statusCode int) logrus.Level {
+	switch {
+	case statusCode >= http.StatusInternalServerError:
+		return logrus.ErrorLevel
+	case statusCode >= http.StatusBadRequest:
+		return logrus.WarnLevel
+	default:
+		return logrus.InfoLevel
+	}
+}
+
+// StreamLogs pushes request log entries as Server-Sent "log" events as they are written.
+// Query parameters:
+//   - level: least severe level to send (debug, info, warn, error)
+//   - provider, model: only entries of proxied requests routed there
+//   - status: an HTTP status code, or a class such as 5xx
+func (s *Server) StreamLogs(c *gin.Context) {
+	if s.memoryLogMW == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Memory log middleware not available"})
+		return
+	}
+	filter, err := parseLiveFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sub := s.memoryLogMW.Subscribe(liveStreamBuffer)
+	defer sub.Close()
+	streamLive(c, "log", sub.C(), sub.TakeDropped, func(entry *logrus.Entry) (any, bool) {
+		provider, _ := entry.Data["provider"].(string)
+		model, _ := entry.Data["model"].(string)
+		statusCode, _ := entry.Data["status"].(int)
+		if !filter.match(entry.Level, provider, model, statusCode, "") {
+			return nil, false
+		}
+		return convertLogrusEntry(entry), true
+	})
+}
+
+// StreamRequests pushes a summary of every completed proxied request as a Server-Sent "request"
+// event. It takes the same filters as StreamLogs, and status also matches the usage status
+// (success, error or partial). A request's level follows its HTTP status.
+func (s *Server) StreamRequests(c *gin.Context) {
+	if s.requestFeed == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Request feed not available"})
+		return
+	}
+	filter, err := parseLiveFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sub := s.requestFeed.Subscribe(liveStreamBuffer)
+	defer sub.Close()
+	streamLive(c, "request", sub.C(), sub.TakeDropped, func(event middleware.RequestEvent) (any, bool) {
+		if !filter.match(levelOfStatus(event.StatusCode), event.Provider, event.Model, event.StatusCode, event.Status) {
+			return nil, false
+		}
+		return event, true
+	})
+}
+
+// streamLive writes the values received on events as Server-Sent events named event until the
+// client goes away. convert filters and shapes each value. When values were dropped because the
+// client fell behind, a "dropped" event with their count comes first.
+func streamLive[T any](c *gin.Context, event string, events <-chan T, takeDropped func() int64, convert func(T) (any, bool)) {
+	if !CheckSSESupport(c) {
+		return
+	}
+	SetupSSEHeaders(c)
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(liveStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := c.Writer.Write([]byte(": keep-alive\n\n")); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case value, ok := <-events:
+			if !ok {
+				return
+			}
+			if dropped := takeDropped(); dropped > 0 {
+				c.SSEvent("dropped", gin.H{"count": dropped})
+			}
+			if data, ok := convert(value); ok {
+				if payload, err := json.Marshal(data); err == nil {
+					c.SSEvent(event, string(payload))
+				}
+			}
+			c.Writer.Flush()
+		}
+	}
+}
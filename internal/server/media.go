@@ -0,0 +1,20 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genai"
+
+	"github.com/tingly-dev/tingly-box/internal/client"
+	"github.com/tingly-dev/tingly-box/internal/protocol/request"
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+// inlineGoogleRemoteMedia downloads remote image URLs in a converted Gemini request and inlines
+// them. Downloads go through the provider's proxy and are cached for the duration of the request.
+func (s *Server) inlineGoogleRemoteMedia(c *gin.Context, provider *typ.Provider, contents []*genai.Content) {
+	fetcher := client.NewMediaFetcher(s.config.GetMediaFetchConfig(), provider.ProxyURL)
+	if err := request.InlineGoogleRemoteMedia(c.Request.Context(), contents, fetcher.Resolve); err != nil {
+		logrus.Warnf("Sending image references as text for provider %s: %v", provider.Name, err)
+	}
+}
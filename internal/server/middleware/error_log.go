@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
@@ -15,6 +16,8 @@ import (
 	"github.com/expr-lang/expr/vm"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+
+	"github.com/tingly-dev/tingly-box/internal/obs"
 )
 
 // FilterContext provides the context for filter expression evaluation
@@ -37,6 +40,9 @@ type ErrorLogMiddleware struct {
 	// Compiled expression program for filtering
 	filterProgram  *vm.Program
 	filterCompiled bool
+
+	// Masks secrets and PII before entries are written
+	redactor *obs.Redactor
 }
 
 // NewErrorLogMiddleware creates a new debug middleware
@@ -46,6 +52,7 @@ func NewErrorLogMiddleware(logPath string, maxSizeMB int) *ErrorLogMiddleware {
 		maxSize:   int64(maxSizeMB) * 1024 * 1024, // Convert MB to bytes
 		rotateLog: true,
 		enabled:   true, // Debug middleware is enabled when created
+		redactor:  obs.DefaultRedactor(),
 	}
 
 	// Compile default filter expression
@@ -126,6 +133,13 @@ func (dm *ErrorLogMiddleware) SetFilterExpression(expression string) error {
 	return nil
 }
 
+// SetRedactor replaces the redactor applied to logged headers, query strings and bodies
+func (dm *ErrorLogMiddleware) SetRedactor(redactor *obs.Redactor) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.redactor = redactor
+}
+
 // openLogFile opens or creates the log file
 func (dm *ErrorLogMiddleware) openLogFile() error {
 	if dm.logFile != nil {
@@ -193,10 +207,11 @@ func (dm *ErrorLogMiddleware) Middleware() gin.HandlerFunc {
 			return
 		}
 
-		// Skip logging for health checks and static assets
+		// Skip logging for health checks, static assets and live streams, whose bodies never end
 		if c.Request.URL.Path == "/health" ||
 			c.Request.URL.Path == "/favicon.ico" ||
-			c.Request.URL.Path == "/robots.txt" {
+			c.Request.URL.Path == "/robots.txt" ||
+			strings.HasSuffix(c.Request.URL.Path, "/stream") {
 			c.Next()
 			return
 		}
@@ -223,6 +238,7 @@ func (dm *ErrorLogMiddleware) Middleware() gin.HandlerFunc {
 		// Log the request and response
 		dm.logEntry(&logEntry{
 			Timestamp:    start,
+			RequestID:    GetRequestID(c),
 			Method:       c.Request.Method,
 			Path:         c.Request.URL.Path,
 			Query:        c.Request.URL.RawQuery,
@@ -240,6 +256,7 @@ func (dm *ErrorLogMiddleware) Middleware() gin.HandlerFunc {
 // logEntry represents a single log entry
 type logEntry struct {
 	Timestamp    time.Time         `json:"timestamp"`
+	RequestID    string            `json:"request_id,omitempty"`
 	Method       string            `json:"method"`
 	Path         string            `json:"path"`
 	Query        string            `json:"query,omitempty"`
@@ -294,9 +311,16 @@ func (dm *ErrorLogMiddleware) logEntry(entry *logEntry) {
 		}
 	}
 
+	// Mask secrets and PII before anything reaches the file
+	entry.Query = dm.redactQuery(entry.Query)
+	entry.Headers = dm.redactor.Headers(entry.Headers)
+	entry.RequestBody = dm.redactor.Bytes(entry.RequestBody)
+	entry.ResponseBody = dm.redactor.Bytes(entry.ResponseBody)
+
 	// Format for JSON output
 	logData := map[string]interface{}{
 		"timestamp":   entry.Timestamp.Format(time.RFC3339Nano),
+		"request_id":  entry.RequestID,
 		"method":      entry.Method,
 		"path":        entry.Path,
 		"query":       entry.Query,
@@ -357,6 +381,51 @@ func (dm *ErrorLogMiddleware) logEntry(entry *logEntry) {
 	}
 }
 
+// FindByRequestID returns the entries of the current log file written for a request
+func (dm *ErrorLogMiddleware) FindByRequestID(requestID string) ([]json.RawMessage, error) {
+	dm.mu.RLock()
+	logPath := dm.logPath
+	dm.mu.RUnlock()
+	if logPath == "" || requestID == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(logPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	key, _ := json.Marshal(requestID)
+	needle := append([]byte(`"request_id":`), key...)
+
+	var entries []json.RawMessage
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); bytes.Contains(line, needle) && json.Valid(line) {
+			entries = append(entries, json.RawMessage(line))
+		}
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, err
+		}
+	}
+}
+
+// redactQuery masks credential parameters and secrets in a raw query string
+func (dm *ErrorLogMiddleware) redactQuery(query string) string {
+	if query == "" {
+		return query
+	}
+	return strings.TrimPrefix(dm.redactor.URL("?"+query), "?")
+}
+
 // getHeaders extracts relevant headers from the request
 func getHeaders(c *gin.Context) map[string]string {
 	headers := make(map[string]string)
@@ -374,12 +443,8 @@ func getHeaders(c *gin.Context) map[string]string {
 
 	for _, header := range relevantHeaders {
 		if value := c.GetHeader(header); value != "" {
-			// Mask sensitive headers
-			if header == "Authorization" && len(value) > 10 {
-				headers[header] = value[:7] + "..."
-			} else {
-				headers[header] = value
-			}
+			// Sensitive values are masked by the redactor when the entry is written
+			headers[header] = value
 		}
 	}
 
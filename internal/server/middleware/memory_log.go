@@ -54,7 +54,7 @@ func (m *MemoryLogMiddleware) Middleware() gin.HandlerFunc {
 			path = path + "?" + raw
 		}
 
-		entry := m.logger.WithFields(logrus.Fields{
+		fields := logrus.Fields{
 			"status":     statusCode,
 			"latency":    latency,
 			"client_ip":  clientIP,
@@ -62,7 +62,16 @@ func (m *MemoryLogMiddleware) Middleware() gin.HandlerFunc {
 			"path":       path,
 			"body_size":  bodySize,
 			"user_agent": c.Request.UserAgent(),
-		})
+			"request_id": GetRequestID(c),
+		}
+		// Proxied requests also carry where they were routed
+		if usage, ok := c.Get(ContextKeyUsage); ok {
+			if info, ok := usage.(*UsageInfo); ok {
+				fields["provider"] = info.Provider
+				fields["model"] = info.Model
+			}
+		}
+		entry := m.logger.WithFields(fields)
 
 		msg := fmt.Sprintf("%s %s %d %v %s %d",
 			method,
@@ -103,6 +112,11 @@ func (m *MemoryLogMiddleware) GetEntriesByLevel(level logrus.Level) []*logrus.En
 	return m.hook.GetEntriesByLevel(level)
 }
 
+// Subscribe returns a subscription to entries logged from now on, see obs.MemoryLogHook.Subscribe
+func (m *MemoryLogMiddleware) Subscribe(buffer int) *obs.Subscription[*logrus.Entry] {
+	return m.hook.Subscribe(buffer)
+}
+
 // Clear removes all log entries
 func (m *MemoryLogMiddleware) Clear() {
 	m.hook.Clear()
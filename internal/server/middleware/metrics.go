@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tingly-dev/tingly-box/internal/obs"
+	"github.com/tingly-dev/tingly-box/internal/protocol/stream"
+	"github.com/tingly-dev/tingly-box/internal/server/config"
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+// requestLabels partition the per-request metrics
+var requestLabels = []string{"scenario", "rule", "provider", "model", "dialect"}
+
+// MetricsMiddleware exports Prometheus metrics for proxied model requests. Token counts and the
+// outcome come from the UsageInfo the usage tracker publishes; requests that fail before a
+// provider is picked are still counted with the labels known at that point.
+type MetricsMiddleware struct {
+	config   *config.Config
+	registry *obs.Registry
+
+	requests   *obs.CounterVec
+	errors     *obs.CounterVec
+	tokens     *obs.CounterVec
+	latency    *obs.HistogramVec
+	firstToken *obs.HistogramVec
+	inFlight   *obs.GaugeVec
+}
+
+// NewMetricsMiddleware creates the metrics middleware and registers its metric families
+func NewMetricsMiddleware(cfg *config.Config) *MetricsMiddleware {
+	r := obs.NewRegistry()
+	withLabels := func(extra ...string) []string {
+		return append(append([]string(nil), requestLabels...), extra...)
+	}
+	return &MetricsMiddleware{
+		config:   cfg,
+		registry: r,
+		requests: r.Counter("tingly_box_requests_total",
+			"Proxied model requests by outcome (success, error or partial).", withLabels("status")...),
+		errors: r.Counter("tingly_box_request_errors_total",
+			"Failed proxied requests by the status returned to the client, or \"stream\" when the stream failed after it started.", withLabels("status_code")...),
+		tokens: r.Counter("tingly_box_tokens_total",
+			"Tokens reported by upstream providers, by type (input, output or cached).", withLabels("type")...),
+		latency: r.Histogram("tingly_box_request_duration_seconds",
+			"Time from receiving a proxied request to finishing its response.", obs.DefaultLatencyBuckets, requestLabels...),
+		firstToken: r.Histogram("tingly_box_time_to_first_token_seconds",
+			"Time from receiving a streamed request to the first upstream event.", obs.DefaultLatencyBuckets, requestLabels...),
+		inFlight: r.Gauge("tingly_box_requests_in_flight",
+			"Proxied model requests currently being served.", "scenario", "dialect"),
+	}
+}
+
+// Middleware returns the Gin middleware function
+func (mm *MetricsMiddleware) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dialect := inboundDialect(c.Request.URL.Path)
+		if c.Request.Method != http.MethodPost || dialect == "" {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		scenario := ScenarioFromPath(c.Request.URL.Path)
+		mm.inFlight.Inc(scenario, dialect)
+		defer mm.inFlight.Dec(scenario, dialect)
+
+		c.Next()
+
+		mm.observe(c, scenario, dialect, start)
+	}
+}
+
+// Handler serves the metrics in the Prometheus text exposition format
+func (mm *MetricsMiddleware) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", obs.MetricsContentType)
+		c.Status(http.StatusOK)
+		_ = mm.registry.WriteText(c.Writer)
+	}
+}
+
+// observe records the finished request
+func (mm *MetricsMiddleware) observe(c *gin.Context, scenario, dialect string, start time.Time) {
+	usage, _ := c.Value(ContextKeyUsage).(*UsageInfo)
+	if usage == nil {
+		usage = &UsageInfo{Status: "success"}
+		if c.Writer.Status() >= http.StatusBadRequest {
+			usage.Status = "error"
+		}
+	}
+	labels := mm.labels(c, usage, scenario, dialect)
+
+	mm.requests.Inc(append(labels, usage.Status)...)
+	if usage.Status != "success" {
+		statusCode := "stream"
+		if c.Writer.Status() >= http.StatusBadRequest {
+			statusCode = strconv.Itoa(c.Writer.Status())
+		}
+		mm.errors.Inc(append(labels, statusCode)...)
+	}
+
+	mm.tokens.Add(float64(usage.InputTokens), append(labels, "input")...)
+	mm.tokens.Add(float64(usage.OutputTokens), append(labels, "output")...)
+	mm.tokens.Add(float64(usage.CachedTokens), append(labels, "cached")...)
+
+	mm.latency.Observe(time.Since(start).Seconds(), labels...)
+	if stats := stream.StatsFromContext(c); stats != nil && !stats.FirstEvent.IsZero() {
+		mm.firstToken.Observe(stats.FirstEvent.Sub(start).Seconds(), labels...)
+	}
+}
+
+// labels resolves the request labels, preferring what the usage tracker published and falling
+// back to the routing state handlers leave in the context
+func (mm *MetricsMiddleware) labels(c *gin.Context, usage *UsageInfo, scenario, dialect string) []string {
+	rule, provider, model := usage.Rule, usage.Provider, usage.Model
+	if rule == "" {
+		if r, ok := c.Value("rule").(*typ.Rule); ok && r != nil {
+			rule = r.RequestModel
+		}
+	}
+	if provider == "" {
+		if uuid := c.GetString("provider"); uuid != "" {
+			provider = uuid
+			if target, err := mm.config.ResolveService(uuid); err == nil {
+				provider = target.Name()
+			}
+		}
+	}
+	if model == "" {
+		model = c.GetString("model")
+	}
+	return []string{scenario, rule, provider, model, dialect}
+}
+
+// inboundDialect names the API dialect of a proxied model request path, or "" for other paths
+func inboundDialect(path string) string {
+	switch {
+	case strings.HasSuffix(path, "/chat/completions"):
+		return "openai"
+	case strings.HasSuffix(path, "/messages"):
+		return "anthropic"
+	case strings.HasSuffix(path, "/responses"):
+		return "responses"
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tingly-dev/tingly-box/pkg/obs"
+)
+
+// RequestEvent summarizes a completed proxied request for live views
+type RequestEvent struct {
+	ID           string    `json:"id"`
+	Time         time.Time `json:"time"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	StatusCode   int       `json:"status_code"`
+	LatencyMs    int64     `json:"latency_ms"`
+	Scenario     string    `json:"scenario,omitempty"`
+	Rule         string    `json:"rule,omitempty"`
+	Provider     string    `json:"provider,omitempty"`
+	Model        string    `json:"model,omitempty"`
+	RequestModel string    `json:"request_model,omitempty"`
+	InputTokens  int       `json:"input_tokens"`
+	OutputTokens int       `json:"output_tokens"`
+	Streamed     bool      `json:"streamed,omitempty"`
+	Status       string    `json:"status"`
+	ErrorCode    string    `json:"error_code,omitempty"`
+}
+
+// RequestFeed publishes a RequestEvent for every proxied request as it completes
+type RequestFeed struct {
+	events *obs.Broadcaster[RequestEvent]
+}
+
+// NewRequestFeed creates a feed without subscribers
+func NewRequestFeed() *RequestFeed {
+	return &RequestFeed{events: obs.NewBroadcaster[RequestEvent]()}
+}
+
+// Subscribe returns a subscription to requests completing from now on. Events are dropped for
+// the subscriber while its buffer is full. Close the subscription when done.
+func (f *RequestFeed) Subscribe(buffer int) *obs.Subscription[RequestEvent] {
+	return f.events.Subscribe(buffer)
+}
+
+// publish sends the summary of a completed request; requests that did not reach a provider, such
+// as control panel API calls, have no usage and are skipped
+func (f *RequestFeed) publish(c *gin.Context, id string, start time.Time, latency time.Duration) {
+	if f == nil || f.events.Subscribers() == 0 {
+		return
+	}
+	value, ok := c.Get(ContextKeyUsage)
+	if !ok {
+		return
+	}
+	usage, ok := value.(*UsageInfo)
+	if !ok {
+		return
+	}
+
+	f.events.Publish(RequestEvent{
+		ID:           id,
+		Time:         start,
+		Method:       c.Request.Method,
+		Path:         c.Request.URL.Path,
+		StatusCode:   c.Writer.Status(),
+		LatencyMs:    latency.Milliseconds(),
+		Scenario:     usage.Scenario,
+		Rule:         usage.Rule,
+		Provider:     usage.Provider,
+		Model:        usage.Model,
+		RequestModel: usage.RequestModel,
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		Streamed:     usage.Streamed,
+		Status:       usage.Status,
+		ErrorCode:    usage.ErrorCode,
+	})
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tingly-dev/tingly-box/internal/obs"
+)
+
+// ContextKeyRequestID is the gin context key holding the request ID
+const ContextKeyRequestID = "request_id"
+
+// RequestID returns middleware that assigns each request an ID, reusing a valid incoming
+// X-Request-Id, and echoes it in the response header. The request's detail is carried in the
+// request context, where routing and upstream calls add to it. Only proxied model requests are
+// added to journal, so control panel traffic, such as polling the detail endpoint, does not evict
+// them. Completed proxied requests are published to feed.
+func RequestID(journal *obs.RequestJournal, feed *RequestFeed) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(obs.RequestIDHeader)
+		if !obs.ValidRequestID(id) {
+			id = obs.NewRequestID()
+		}
+
+		detail := &obs.RequestDetail{
+			ID:     id,
+			Time:   time.Now(),
+			Method: c.Request.Method,
+			Path:   c.Request.URL.Path,
+		}
+		if journal != nil && c.Request.Method == http.MethodPost && inboundDialect(c.Request.URL.Path) != "" {
+			journal.Add(detail)
+		}
+
+		c.Set(ContextKeyRequestID, id)
+		c.Header(obs.RequestIDHeader, id)
+		c.Request = c.Request.WithContext(obs.WithRequestDetail(c.Request.Context(), detail))
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(obs.AttrRequestID.String(id))
+
+		c.Next()
+
+		latency := time.Since(detail.Time)
+		detail.Finish(c.Writer.Status(), latency)
+		feed.publish(c, id, detail.Time, latency)
+	}
+}
+
+// GetRequestID returns the ID RequestID assigned to the request, or ""
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(ContextKeyRequestID)
+}
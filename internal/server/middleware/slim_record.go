@@ -0,0 +1,200 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tingly-dev/tingly-box/internal/obs"
+	"github.com/tingly-dev/tingly-box/internal/server/config"
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+// ContextKeyUsage is the gin context key under which handlers publish the UsageInfo of a request
+const ContextKeyUsage = "usage"
+
+// ContextKeyCachedTokens is the gin context key under which handlers report prompt tokens served
+// from the provider's cache, when the upstream response carries them
+const ContextKeyCachedTokens = "cached_tokens"
+
+// maxSlimResponseCapture bounds how much of a response is kept to find tool calls
+const maxSlimResponseCapture = 256 * 1024
+
+// UsageInfo is the outcome of a proxied request as recorded by the usage tracker
+type UsageInfo struct {
+	Rule         string // request model of the matched rule
+	Provider     string // provider name
+	Model        string // model sent upstream
+	RequestModel string
+	InputTokens  int
+	OutputTokens int
+	CachedTokens int
+	Streamed     bool
+	Status       string // success, error, or partial
+	ErrorCode    string
+	Scenario     string
+	LatencyMs    int
+}
+
+// SlimRecordMiddleware writes one compact obs.SlimRecord per proxied request
+type SlimRecordMiddleware struct {
+	config *config.Config
+	sink   *obs.Sink
+}
+
+// NewSlimRecordMiddleware creates a new slim record middleware
+func NewSlimRecordMiddleware(cfg *config.Config, sink *obs.Sink) *SlimRecordMiddleware {
+	return &SlimRecordMiddleware{
+		config: cfg,
+		sink:   sink,
+	}
+}
+
+// slimResponseWriter hashes the full response and keeps a bounded prefix of it
+type slimResponseWriter struct {
+	gin.ResponseWriter
+	hash hash.Hash
+	body *bytes.Buffer
+	size int
+}
+
+func (w *slimResponseWriter) Write(b []byte) (int, error) {
+	w.hash.Write(b)
+	w.size += len(b)
+	if room := maxSlimResponseCapture - w.body.Len(); room > 0 {
+		w.body.Write(b[:min(room, len(b))])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *slimResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Middleware returns the Gin middleware function
+func (sm *SlimRecordMiddleware) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !sm.sink.IsSlim() || !sm.shouldRecordEndpoint(c.Request.URL.Path, c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+
+		var requestBody []byte
+		if c.Request.Body != nil {
+			body, err := io.ReadAll(c.Request.Body)
+			if err == nil {
+				requestBody = body
+			}
+			// Restore the body for subsequent handlers
+			c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+		}
+
+		writer := &slimResponseWriter{
+			ResponseWriter: c.Writer,
+			hash:           sha256.New(),
+			body:           &bytes.Buffer{},
+		}
+		c.Writer = writer
+
+		c.Next()
+
+		sm.sink.RecordSlim(sm.buildRecord(c, requestBody, writer, time.Since(start)))
+	}
+}
+
+// shouldRecordEndpoint checks if the endpoint is a proxied model request
+func (sm *SlimRecordMiddleware) shouldRecordEndpoint(path, method string) bool {
+	if method != http.MethodPost {
+		return false
+	}
+
+	return strings.HasSuffix(path, "/chat/completions") ||
+		strings.HasSuffix(path, "/messages") ||
+		strings.HasSuffix(path, "/responses")
+}
+
+// buildRecord assembles the slim record from the request body, the response and the handler context
+func (sm *SlimRecordMiddleware) buildRecord(c *gin.Context, requestBody []byte, writer *slimResponseWriter, elapsed time.Duration) *obs.SlimRecord {
+	summary := obs.SummarizeRequest(requestBody)
+
+	record := &obs.SlimRecord{
+		RequestID:     GetRequestID(c),
+		Model:         c.GetString("model"),
+		RequestModel:  summary.Model,
+		StatusCode:    writer.Status(),
+		LatencyMs:     elapsed.Milliseconds(),
+		MessageCount:  summary.MessageCount,
+		FirstUserText: summary.FirstUserText,
+		LastUserText:  summary.LastUserText,
+		RequestHash:   summary.Hash,
+		Tools:         mergeNames(summary.Tools, obs.ToolCallNames(writer.body.Bytes())),
+	}
+	if writer.size > 0 {
+		record.ResponseHash = hex.EncodeToString(writer.hash.Sum(nil))
+	}
+
+	if uuid := c.GetString("provider"); uuid != "" {
+		record.Provider = uuid
+		if target, err := sm.config.ResolveService(uuid); err == nil {
+			record.Provider = target.Name()
+		}
+	}
+	if rule, exists := c.Get("rule"); exists {
+		if rulePtr, ok := rule.(*typ.Rule); ok && rulePtr != nil {
+			record.Rule = rulePtr.UUID
+		}
+	}
+
+	if v, exists := c.Get(ContextKeyUsage); exists {
+		if usage, ok := v.(*UsageInfo); ok && usage != nil {
+			record.InputTokens = usage.InputTokens
+			record.OutputTokens = usage.OutputTokens
+			record.Streamed = usage.Streamed
+			record.Status = usage.Status
+			record.ErrorCode = usage.ErrorCode
+			record.Scenario = usage.Scenario
+			if usage.RequestModel != "" {
+				record.RequestModel = usage.RequestModel
+			}
+			if usage.LatencyMs > 0 {
+				record.LatencyMs = int64(usage.LatencyMs)
+			}
+		}
+	}
+
+	// Requests rejected before reaching the usage tracker only have the HTTP status
+	if record.Status == "" {
+		if record.StatusCode >= http.StatusBadRequest {
+			record.Status = "error"
+		} else {
+			record.Status = "success"
+		}
+	}
+
+	return record
+}
+
+// mergeNames returns the union of two name lists, keeping first-seen order
+func mergeNames(a, b []string) []string {
+	if len(b) == 0 {
+		return a
+	}
+	seen := make(map[string]bool, len(a)+len(b))
+	var merged []string
+	for _, name := range append(append([]string{}, a...), b...) {
+		if !seen[name] {
+			seen[name] = true
+			merged = append(merged, name)
+		}
+	}
+	return merged
+}
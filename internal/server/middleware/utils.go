@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"bytes"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,3 +17,26 @@ func (r *responseBodyWriter) Write(b []byte) (int, error) {
 	r.body.Write(b)
 	return r.ResponseWriter.Write(b)
 }
+
+// ScenarioFromPath extracts the scenario from the request path
+func ScenarioFromPath(path string) string {
+	if strings.Contains(path, "/openai/") {
+		return "openai"
+	}
+	if strings.Contains(path, "/anthropic/") {
+		return "anthropic"
+	}
+	if strings.Contains(path, "/claude_code/") || strings.Contains(path, "/claude-code/") {
+		return "claude_code"
+	}
+	if strings.Contains(path, "/tingly/") {
+		// Extract scenario from tingly path
+		parts := strings.Split(path, "/")
+		for i, part := range parts {
+			if part == "tingly" && i+1 < len(parts) {
+				return parts[i+1]
+			}
+		}
+	}
+	return "unknown"
+}
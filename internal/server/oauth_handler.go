@@ -378,6 +378,39 @@ func (s *Server) useOAuthEndpoints(manager *swagger.RouteManager) {
 		swagger.WithResponseModel(OAuthSessionStatusResponse{}),
 	)
 
+	// OAuth Account Pools
+	apiV1.GET("/oauth/pools", s.ListAccountPools,
+		swagger.WithTags("oauth"),
+		swagger.WithDescription("List OAuth account pools with per-account quota"),
+		swagger.WithResponseModel(AccountPoolsResponse{}),
+	)
+
+	apiV1.POST("/oauth/pools", s.CreateAccountPool,
+		swagger.WithTags("oauth"),
+		swagger.WithDescription("Create an OAuth account pool from existing OAuth providers"),
+		swagger.WithRequestModel(AccountPoolRequest{}),
+		swagger.WithResponseModel(AccountPoolResponse{}),
+	)
+
+	apiV1.GET("/oauth/pools/:uuid", s.GetAccountPool,
+		swagger.WithTags("oauth"),
+		swagger.WithDescription("Get an OAuth account pool with per-account quota"),
+		swagger.WithResponseModel(AccountPoolResponse{}),
+	)
+
+	apiV1.PUT("/oauth/pools/:uuid", s.UpdateAccountPool,
+		swagger.WithTags("oauth"),
+		swagger.WithDescription("Update an OAuth account pool"),
+		swagger.WithRequestModel(AccountPoolRequest{}),
+		swagger.WithResponseModel(AccountPoolResponse{}),
+	)
+
+	apiV1.DELETE("/oauth/pools/:uuid", s.DeleteAccountPool,
+		swagger.WithTags("oauth"),
+		swagger.WithDescription("Delete an OAuth account pool (member providers are kept)"),
+		swagger.WithResponseModel(OAuthMessageResponse{}),
+	)
+
 	// OAuth Callback (no authentication required - called by OAuth provider)
 	manager.GetEngine().GET("/oauth/callback", s.OAuthCallback)
 	manager.GetEngine().GET("/callback", s.OAuthCallback)
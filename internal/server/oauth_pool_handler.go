@@ -0,0 +1,198 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tingly-dev/tingly-box/internal/accountpool"
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+// =============================================
+// OAuth Account Pool Models
+// =============================================
+
+// AccountPoolRequest represents the request to create or update an account pool
+type AccountPoolRequest struct {
+	Name         string   `json:"name" binding:"required" description:"Pool name" example:"claude-team"`
+	ProviderType string   `json:"provider_type" binding:"required" description:"OAuth provider type shared by all accounts" example:"claude_code"`
+	Accounts     []string `json:"accounts" binding:"required" description:"Member OAuth provider UUIDs"`
+	Enabled      *bool    `json:"enabled,omitempty" description:"Whether the pool is enabled (default true)" example:"true"`
+}
+
+// AccountPoolAccount is the quota view of one pool member
+type AccountPoolAccount struct {
+	accountpool.AccountState
+	Name      string `json:"name" example:"claude-alice"`
+	Enabled   bool   `json:"enabled" example:"true"`
+	Available bool   `json:"available" example:"true"`
+}
+
+// AccountPoolInfo represents an account pool with per-account quota
+type AccountPoolInfo struct {
+	UUID         string               `json:"uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name         string               `json:"name" example:"claude-team"`
+	ProviderType string               `json:"provider_type" example:"claude_code"`
+	Enabled      bool                 `json:"enabled" example:"true"`
+	Accounts     []AccountPoolAccount `json:"accounts"`
+}
+
+// AccountPoolResponse represents the response for a single account pool
+type AccountPoolResponse struct {
+	Success bool            `json:"success" example:"true"`
+	Data    AccountPoolInfo `json:"data"`
+}
+
+// AccountPoolsResponse represents the response for listing account pools
+type AccountPoolsResponse struct {
+	Success bool              `json:"success" example:"true"`
+	Data    []AccountPoolInfo `json:"data"`
+}
+
+// ListAccountPools lists all account pools with per-account quota
+func (s *Server) ListAccountPools(c *gin.Context) {
+	pools := s.config.ListAccountPools()
+
+	data := make([]AccountPoolInfo, 0, len(pools))
+	for _, pool := range pools {
+		data = append(data, s.accountPoolInfo(pool))
+	}
+
+	c.JSON(http.StatusOK, AccountPoolsResponse{
+		Success: true,
+		Data:    data,
+	})
+}
+
+// GetAccountPool returns one account pool with per-account quota
+func (s *Server) GetAccountPool(c *gin.Context) {
+	pool, err := s.config.GetAccountPoolByUUID(c.Param("uuid"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, OAuthErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AccountPoolResponse{
+		Success: true,
+		Data:    s.accountPoolInfo(pool),
+	})
+}
+
+// CreateAccountPool creates an account pool from existing OAuth providers
+func (s *Server) CreateAccountPool(c *gin.Context) {
+	var req AccountPoolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, OAuthErrorResponse{
+			Success: false,
+			Error:   "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	pool := req.toAccountPool()
+	if err := s.config.AddAccountPool(pool); err != nil {
+		c.JSON(http.StatusBadRequest, OAuthErrorResponse{
+			Success: false,
+			Error:   "Failed to create account pool: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AccountPoolResponse{
+		Success: true,
+		Data:    s.accountPoolInfo(pool),
+	})
+}
+
+// UpdateAccountPool replaces an account pool's settings and members
+func (s *Server) UpdateAccountPool(c *gin.Context) {
+	uuid := c.Param("uuid")
+	if _, err := s.config.GetAccountPoolByUUID(uuid); err != nil {
+		c.JSON(http.StatusNotFound, OAuthErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req AccountPoolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, OAuthErrorResponse{
+			Success: false,
+			Error:   "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	pool := req.toAccountPool()
+	if err := s.config.UpdateAccountPool(uuid, pool); err != nil {
+		c.JSON(http.StatusBadRequest, OAuthErrorResponse{
+			Success: false,
+			Error:   "Failed to update account pool: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AccountPoolResponse{
+		Success: true,
+		Data:    s.accountPoolInfo(pool),
+	})
+}
+
+// DeleteAccountPool removes an account pool; member providers are kept
+func (s *Server) DeleteAccountPool(c *gin.Context) {
+	if err := s.config.DeleteAccountPool(c.Param("uuid")); err != nil {
+		c.JSON(http.StatusNotFound, OAuthErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, OAuthMessageResponse{
+		Success: true,
+		Message: "Account pool deleted",
+	})
+}
+
+func (req *AccountPoolRequest) toAccountPool() *typ.AccountPool {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return &typ.AccountPool{
+		Name:         req.Name,
+		ProviderType: req.ProviderType,
+		Accounts:     req.Accounts,
+		Enabled:      enabled,
+	}
+}
+
+// accountPoolInfo combines a pool's configuration with the tracked quota of its accounts
+func (s *Server) accountPoolInfo(pool *typ.AccountPool) AccountPoolInfo {
+	info := AccountPoolInfo{
+		UUID:         pool.UUID,
+		Name:         pool.Name,
+		ProviderType: pool.ProviderType,
+		Enabled:      pool.Enabled,
+		Accounts:     make([]AccountPoolAccount, 0, len(pool.Accounts)),
+	}
+
+	now := time.Now()
+	for _, state := range s.accountPools.Snapshot(pool.Accounts) {
+		account := AccountPoolAccount{AccountState: state}
+		if provider, err := s.config.GetProviderByUUID(state.ProviderUUID); err == nil {
+			account.Name = provider.Name
+			account.Enabled = provider.Enabled
+		}
+		account.Available = account.Enabled && !state.CoolingDown(now)
+		info.Accounts = append(info.Accounts, account)
+	}
+
+	return info
+}
@@ -13,9 +13,11 @@ import (
 	"github.com/openai/openai-go/v3/packages/param"
 	"github.com/openai/openai-go/v3/packages/ssestream"
 	"github.com/openai/openai-go/v3/responses"
+	"github.com/openai/openai-go/v3/shared"
 	"github.com/sirupsen/logrus"
 
 	"github.com/tingly-dev/tingly-box/internal/loadbalance"
+	"github.com/tingly-dev/tingly-box/internal/obs"
 	"github.com/tingly-dev/tingly-box/internal/protocol"
 	"github.com/tingly-dev/tingly-box/internal/protocol/nonstream"
 	"github.com/tingly-dev/tingly-box/internal/protocol/request"
@@ -54,9 +56,9 @@ func (s *Server) OpenAIListModels(c *gin.Context) {
 			for i := range services {
 				svc := &services[i]
 				if svc.Active {
-					provider, err := cfg.GetProviderByUUID(svc.Provider)
+					target, err := cfg.ResolveService(svc.Provider)
 					if err == nil {
-						providerDesc = append(providerDesc, provider.Name)
+						providerDesc = append(providerDesc, target.Name())
 					} else {
 						providerDesc = append(providerDesc, svc.Provider)
 					}
@@ -141,7 +143,7 @@ func (s *Server) OpenAIChatCompletions(c *gin.Context) {
 		rule            *typ.Rule
 	)
 	if scenario == "" {
-		provider, selectedService, rule, err = s.DetermineProviderAndModel(req.Model)
+		provider, selectedService, rule, err = s.DetermineProviderAndModel(c.Request.Context(), req.Model)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, ErrorResponse{
 				Error: ErrorDetail{
@@ -163,7 +165,7 @@ func (s *Server) OpenAIChatCompletions(c *gin.Context) {
 			})
 			return
 		}
-		provider, selectedService, rule, err = s.DetermineProviderAndModelWithScenario(scenarioType, req.Model)
+		provider, selectedService, rule, err = s.DetermineProviderAndModelWithScenario(c.Request.Context(), scenarioType, req.Model)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, ErrorResponse{
 				Error: ErrorDetail{
@@ -216,39 +218,29 @@ func (s *Server) OpenAIChatCompletions(c *gin.Context) {
 			return
 		}
 
+		translation := startTranslationSpan(c, "openai", "anthropic")
 		anthropicReq := request.ConvertOpenAIToAnthropicRequest(&req.ChatCompletionNewParams, int64(maxAllowed))
+		translation.End()
 
-		// 🔥 REQUIRED: forward tool_choice
-		if req.ToolChoice.OfAuto.Value != "" || req.ToolChoice.OfAllowedTools != nil || req.ToolChoice.OfFunctionToolChoice != nil || req.ToolChoice.OfCustomToolChoice != nil {
+		// 🔥 REQUIRED: forward tool_choice, unless response_format already forced the structured output tool
+		if request.StructuredOutputFromOpenAI(&req.ChatCompletionNewParams) == nil &&
+			(req.ToolChoice.OfAuto.Value != "" || req.ToolChoice.OfAllowedTools != nil || req.ToolChoice.OfFunctionToolChoice != nil || req.ToolChoice.OfCustomToolChoice != nil) {
 			anthropicReq.ToolChoice = request.ConvertOpenAIToAnthropicToolChoice(&req.ToolChoice)
 		}
 
 		if isStreaming {
-			streamResp, err := s.ForwardAnthropicStreamRequest(provider, anthropicReq)
+			streamResp, err := s.ForwardAnthropicStreamRequest(c.Request.Context(), provider, anthropicReq)
 			if err != nil {
 				// Track error with no usage
 				s.trackUsage(c, rule, provider, actualModel, responseModel, 0, 0, true, "error", "stream_creation_failed")
-				c.JSON(http.StatusInternalServerError, ErrorResponse{
-					Error: ErrorDetail{
-						Message: "Failed to create streaming request: " + err.Error(),
-						Type:    "api_error",
-					},
-				})
+				SendUpstreamError(c, protocol.APIStyleOpenAI, err)
 				return
 			}
 
 			inputTokens, outputTokens, err := stream.HandleAnthropicToOpenAIStreamResponse(c, &anthropicReq, streamResp, responseModel)
 			if err != nil {
-				// Track usage with error status
-				if inputTokens > 0 || outputTokens > 0 {
-					s.trackUsage(c, rule, provider, actualModel, responseModel, inputTokens, outputTokens, true, "error", "stream_handler_failed")
-				}
-				c.JSON(http.StatusInternalServerError, ErrorResponse{
-					Error: ErrorDetail{
-						Message: "Failed to create streaming request: " + err.Error(),
-						Type:    "api_error",
-					},
-				})
+				// The handler already ended the stream with an error chunk and [DONE]
+				s.trackStreamFailure(c, rule, provider, actualModel, responseModel, inputTokens, outputTokens)
 				return
 			}
 
@@ -258,26 +250,25 @@ func (s *Server) OpenAIChatCompletions(c *gin.Context) {
 			}
 			return
 		} else {
-			anthropicResp, err := s.ForwardAnthropicRequest(provider, anthropicReq)
+			anthropicResp, err := s.ForwardAnthropicRequest(c.Request.Context(), provider, anthropicReq)
 			if err != nil {
 				// Track error with no usage
 				s.trackUsage(c, rule, provider, actualModel, responseModel, 0, 0, false, "error", "forward_failed")
-				c.JSON(http.StatusInternalServerError, ErrorResponse{
-					Error: ErrorDetail{
-						Message: "Failed to forward Anthropic request: " + err.Error(),
-						Type:    "api_error",
-					},
-				})
+				SendUpstreamError(c, protocol.APIStyleOpenAI, err)
 				return
 			}
 
 			// Track usage from response
 			inputTokens := int(anthropicResp.Usage.InputTokens)
 			outputTokens := int(anthropicResp.Usage.OutputTokens)
+			setCachedTokens(c, anthropicResp.Usage.CacheReadInputTokens)
 			s.trackUsage(c, rule, provider, actualModel, responseModel, inputTokens, outputTokens, false, "success", "")
 
 			// Use provider-aware conversion for provider-specific handling
 			openaiResp := nonstream.ConvertAnthropicToOpenAIResponseWithProvider(anthropicResp, responseModel, provider, actualModel)
+			if !checkStructuredOutput(c, &req.ChatCompletionNewParams, openaiResp) {
+				return
+			}
 			c.JSON(http.StatusOK, openaiResp)
 			return
 		}
@@ -293,22 +284,20 @@ func (s *Server) OpenAIChatCompletions(c *gin.Context) {
 // handleNonStreamingRequest handles non-streaming chat completion requests
 func (s *Server) handleNonStreamingRequest(c *gin.Context, provider *typ.Provider, req *openai.ChatCompletionNewParams, responseModel, actualModel string, rule *typ.Rule) {
 	// Forward request to provider
-	response, err := s.forwardOpenAIRequest(provider, req)
+	response, err := s.forwardOpenAIRequest(c.Request.Context(), provider, req)
 	if err != nil {
 		// Track error with no usage
 		s.trackUsage(c, rule, provider, actualModel, responseModel, 0, 0, false, "error", "forward_failed")
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error: ErrorDetail{
-				Message: "Failed to forward request: " + err.Error(),
-				Type:    "api_error",
-			},
-		})
+		SendUpstreamError(c, protocol.APIStyleOpenAI, err)
 		return
 	}
 
+	toolRepairer(provider, req).RepairOpenAIToolCalls(response)
+
 	// Extract usage from response
 	inputTokens := int(response.Usage.PromptTokens)
 	outputTokens := int(response.Usage.CompletionTokens)
+	setCachedTokens(c, response.Usage.PromptTokensDetails.CachedTokens)
 
 	// Track usage
 	s.trackUsage(c, rule, provider, actualModel, responseModel, inputTokens, outputTokens, false, "success", "")
@@ -339,12 +328,16 @@ func (s *Server) handleNonStreamingRequest(c *gin.Context, provider *typ.Provide
 	// Update response model if configured
 	responseMap["model"] = responseModel
 
+	if !checkStructuredOutput(c, req, responseMap) {
+		return
+	}
+
 	// Return modified response
 	c.JSON(http.StatusOK, responseMap)
 }
 
 // forwardOpenAIRequest forwards the request to the selected provider using OpenAI library
-func (s *Server) forwardOpenAIRequest(provider *typ.Provider, req *openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+func (s *Server) forwardOpenAIRequest(ctx context.Context, provider *typ.Provider, req *openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
 	logrus.Infof("provider: %s, model: %s", provider.Name, req.Model)
 
 	// Apply provider-specific transformations before forwarding
@@ -355,11 +348,13 @@ func (s *Server) forwardOpenAIRequest(provider *typ.Provider, req *openai.ChatCo
 	wrapper := s.clientPool.GetOpenAIClient(provider, req.Model)
 
 	// Make the request using wrapper method with provider timeout
+	ctx, span := startUpstreamSpan(ctx, provider, req.Model)
 	timeout := time.Duration(provider.Timeout) * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	chatCompletion, err := wrapper.ChatCompletionsNew(ctx, *req)
+	obs.EndSpan(span, err)
 	if err != nil {
 		logrus.Error(err)
 		return nil, fmt.Errorf("failed to create chat completion: %w", err)
@@ -369,7 +364,7 @@ func (s *Server) forwardOpenAIRequest(provider *typ.Provider, req *openai.ChatCo
 }
 
 // forwardOpenAIStreamRequest forwards the streaming request to the selected provider using OpenAI library
-func (s *Server) forwardOpenAIStreamRequest(provider *typ.Provider, req *openai.ChatCompletionNewParams) (*ssestream.Stream[openai.ChatCompletionChunk], error) {
+func (s *Server) forwardOpenAIStreamRequest(ctx context.Context, provider *typ.Provider, req *openai.ChatCompletionNewParams) (*ssestream.Stream[openai.ChatCompletionChunk], error) {
 	logrus.Debugf("provider: %s (streaming)", provider.Name)
 
 	// Apply provider-specific transformations before forwarding
@@ -380,11 +375,18 @@ func (s *Server) forwardOpenAIStreamRequest(provider *typ.Provider, req *openai.
 	wrapper := s.clientPool.GetOpenAIClient(provider, req.Model)
 
 	// Make the streaming request using wrapper method with provider timeout
+	ctx, span := startUpstreamSpan(ctx, provider, req.Model)
 	timeout := time.Duration(provider.Timeout) * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	stream := wrapper.ChatCompletionsNewStreaming(ctx, *req)
+	err := stream.Err()
+	obs.EndSpan(span, err)
+	// A rejected request fails before the first chunk; report it while an HTTP status can still be sent
+	if err != nil {
+		return nil, err
+	}
 
 	return stream, nil
 }
@@ -406,22 +408,23 @@ func (s *Server) buildOpenAIConfig(req *openai.ChatCompletionNewParams) *transfo
 		}
 	}
 
+	// An explicit reasoning_effort wins over the default
+	if reasoning := protocol.ReasoningFromEffort(string(req.ReasoningEffort)); reasoning != nil {
+		config.HasThinking = reasoning.Enabled()
+		config.ReasoningEffort = req.ReasoningEffort
+	}
+
 	return config
 }
 
 // handleStreamingRequest handles streaming chat completion requests
 func (s *Server) handleStreamingRequest(c *gin.Context, provider *typ.Provider, req *openai.ChatCompletionNewParams, responseModel, actualModel string, rule *typ.Rule) {
 	// Create streaming request
-	stream, err := s.forwardOpenAIStreamRequest(provider, req)
+	stream, err := s.forwardOpenAIStreamRequest(c.Request.Context(), provider, req)
 	if err != nil {
 		// Track error with no usage
 		s.trackUsage(c, rule, provider, actualModel, responseModel, 0, 0, false, "error", "stream_creation_failed")
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error: ErrorDetail{
-				Message: "Failed to create streaming request: " + err.Error(),
-				Type:    "api_error",
-			},
-		})
+		SendUpstreamError(c, protocol.APIStyleOpenAI, err)
 		return
 	}
 
@@ -430,12 +433,14 @@ func (s *Server) handleStreamingRequest(c *gin.Context, provider *typ.Provider,
 }
 
 // handleOpenAIStreamResponse processes the streaming response and sends it to the client
-func (s *Server) handleOpenAIStreamResponse(c *gin.Context, stream *ssestream.Stream[openai.ChatCompletionChunk], req *openai.ChatCompletionNewParams, responseModel, actualModel string, rule *typ.Rule, provider *typ.Provider) {
+func (s *Server) handleOpenAIStreamResponse(c *gin.Context, upstream *ssestream.Stream[openai.ChatCompletionChunk], req *openai.ChatCompletionNewParams, responseModel, actualModel string, rule *typ.Rule, provider *typ.Provider) {
 	// Accumulate usage from stream chunks
 	var inputTokens, outputTokens int
 	var hasUsage bool
 	var contentBuilder strings.Builder
 	var firstChunkID string // Store the first chunk ID for usage estimation
+	// With tool repair, tool-call arguments are held back and sent repaired once the calls finish
+	toolCalls := toolRepairer(provider, req).NewOpenAIToolCallBuffer()
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -459,8 +464,8 @@ func (s *Server) handleOpenAIStreamResponse(c *gin.Context, stream *ssestream.St
 			}
 		}
 		// Ensure stream is always closed
-		if stream != nil {
-			if err := stream.Close(); err != nil {
+		if upstream != nil {
+			if err := upstream.Close(); err != nil {
 				logrus.Errorf("Error closing stream: %v", err)
 			}
 		}
@@ -486,9 +491,11 @@ func (s *Server) handleOpenAIStreamResponse(c *gin.Context, stream *ssestream.St
 		return
 	}
 
-	// Process the stream
-	for stream.Next() {
-		chatChunk := stream.Current()
+	// Process the stream, pinging the client while upstream is quiet
+	events := stream.KeepAlive(c, upstream, protocol.APIStyleOpenAI)
+	defer events.Close()
+	for events.Next() {
+		chatChunk := events.Current()
 
 		// Store the first chunk ID for usage estimation
 		if firstChunkID == "" && chatChunk.ID != "" {
@@ -505,6 +512,7 @@ func (s *Server) handleOpenAIStreamResponse(c *gin.Context, stream *ssestream.St
 			outputTokens = int(chatChunk.Usage.CompletionTokens)
 			hasUsage = true
 		}
+		setCachedTokens(c, chatChunk.Usage.PromptTokensDetails.CachedTokens)
 
 		// Check if we have choices and they're not empty
 		if len(chatChunk.Choices) == 0 {
@@ -518,6 +526,11 @@ func (s *Server) handleOpenAIStreamResponse(c *gin.Context, stream *ssestream.St
 			contentBuilder.WriteString(choice.Delta.Content)
 		}
 
+		choice.Delta.ToolCalls = toolCalls.Hold(choice.Delta.ToolCalls)
+		if choice.FinishReason != "" {
+			sendRepairedToolCalls(c, flusher, toolCalls, chatChunk.ID, chatChunk.Created, responseModel)
+		}
+
 		// Build delta map - include all fields, JSON marshaling will handle empty values
 		delta := map[string]interface{}{
 			"role":          choice.Delta.Role,
@@ -565,8 +578,8 @@ func (s *Server) handleOpenAIStreamResponse(c *gin.Context, stream *ssestream.St
 		flusher.Flush()
 	}
 
-	// Check for stream errors
-	if err := stream.Err(); err != nil {
+	// Check for stream errors, including an upstream that went silent past the idle timeout
+	if err := events.Err(); err != nil {
 		logrus.Errorf("Stream error: %v", err)
 
 		// If no usage from stream, estimate it
@@ -576,16 +589,10 @@ func (s *Server) handleOpenAIStreamResponse(c *gin.Context, stream *ssestream.St
 		}
 
 		// Track usage with error status
-		s.trackUsage(c, rule, provider, actualModel, responseModel, inputTokens, outputTokens, true, "error", "stream_error")
+		s.trackStreamFailure(c, rule, provider, actualModel, responseModel, inputTokens, outputTokens)
 
 		// Send error event
-		errorChunk := map[string]interface{}{
-			"error": map[string]interface{}{
-				"message": err.Error(),
-				"type":    "stream_error",
-				"code":    "stream_failed",
-			},
-		}
+		errorChunk := upstreamErrorBody(protocol.APIStyleOpenAI, err)
 
 		errorJSON, marshalErr := json.Marshal(errorChunk)
 		if marshalErr == nil {
@@ -598,10 +605,14 @@ func (s *Server) handleOpenAIStreamResponse(c *gin.Context, stream *ssestream.St
 				},
 			})
 		}
+		c.SSEvent("", "[DONE]")
 		flusher.Flush()
 		return
 	}
 
+	// The stream ended without a finish_reason; still send the held back tool-call arguments
+	sendRepairedToolCalls(c, flusher, toolCalls, firstChunkID, 0, responseModel)
+
 	// Track successful streaming completion
 	// If no usage from stream, estimate it and send to client
 	if !hasUsage {
@@ -648,6 +659,33 @@ func (s *Server) handleOpenAIStreamResponse(c *gin.Context, stream *ssestream.St
 	flusher.Flush()
 }
 
+// sendRepairedToolCalls sends the tool-call arguments held back by buffer, repaired, as one chunk
+func sendRepairedToolCalls(c *gin.Context, flusher http.Flusher, buffer *protocol.OpenAIToolCallBuffer, id string, created int64, responseModel string) {
+	calls := buffer.Flush()
+	if len(calls) == 0 {
+		return
+	}
+	chunkJSON, err := json.Marshal(map[string]interface{}{
+		"id":      id,
+		"object":  "chat.completion.chunk",
+		"created": created,
+		"model":   responseModel,
+		"choices": []map[string]interface{}{
+			{
+				"index":         0,
+				"delta":         map[string]interface{}{"tool_calls": calls},
+				"finish_reason": nil,
+			},
+		},
+	})
+	if err != nil {
+		logrus.Errorf("Failed to marshal repaired tool calls: %v", err)
+		return
+	}
+	c.SSEvent("", string(chunkJSON))
+	flusher.Flush()
+}
+
 // ListModelsByScenario handles the /v1/models endpoint for scenario-based routing
 func (s *Server) ListModelsByScenario(c *gin.Context) {
 	scenario := c.Param("scenario")
@@ -705,6 +743,14 @@ func (s *Server) convertChatCompletionToResponsesParams(req *protocol.OpenAIChat
 		}(),
 	}
 
+	// Carry reasoning_effort over, asking for summaries so reasoning can be surfaced
+	if reasoning := protocol.ReasoningFromEffort(string(req.ReasoningEffort)); reasoning != nil {
+		params.Reasoning = shared.ReasoningParam{Effort: req.ReasoningEffort}
+		if reasoning.Enabled() {
+			params.Reasoning.Summary = shared.ReasoningSummaryAuto
+		}
+	}
+
 	// Add instructions from system message if present
 	for _, msg := range req.Messages {
 		if !param.IsOmitted(msg.OfSystem) {
@@ -14,6 +14,9 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/tingly-dev/tingly-box/internal/loadbalance"
+	"github.com/tingly-dev/tingly-box/internal/obs"
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+	"github.com/tingly-dev/tingly-box/internal/protocol/stream"
 	"github.com/tingly-dev/tingly-box/internal/typ"
 )
 
@@ -78,7 +81,7 @@ func (s *Server) ResponsesCreate(c *gin.Context) {
 	)
 
 	if scenario == "" {
-		provider, selectedService, rule, err = s.DetermineProviderAndModel(responseModel)
+		provider, selectedService, rule, err = s.DetermineProviderAndModel(c.Request.Context(), responseModel)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, ErrorResponse{
 				Error: ErrorDetail{
@@ -99,7 +102,7 @@ func (s *Server) ResponsesCreate(c *gin.Context) {
 			})
 			return
 		}
-		provider, selectedService, rule, err = s.DetermineProviderAndModelWithScenario(scenarioType, responseModel)
+		provider, selectedService, rule, err = s.DetermineProviderAndModelWithScenario(c.Request.Context(), scenarioType, responseModel)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, ErrorResponse{
 				Error: ErrorDetail{
@@ -157,22 +160,18 @@ func (s *Server) ResponsesCreate(c *gin.Context) {
 // handleResponsesNonStreamingRequest handles non-streaming Responses API requests
 func (s *Server) handleResponsesNonStreamingRequest(c *gin.Context, provider *typ.Provider, params responses.ResponseNewParams, responseModel, actualModel string, rule *typ.Rule) {
 	// Forward request to provider
-	response, err := s.forwardResponsesRequest(provider, params)
+	response, err := s.forwardResponsesRequest(c.Request.Context(), provider, params)
 	if err != nil {
 		// Track error with no usage
 		s.trackUsage(c, rule, provider, actualModel, responseModel, 0, 0, false, "error", "forward_failed")
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error: ErrorDetail{
-				Message: "Failed to forward request: " + err.Error(),
-				Type:    "api_error",
-			},
-		})
+		SendUpstreamError(c, protocol.APIStyleOpenAI, err)
 		return
 	}
 
 	// Extract usage from response
 	inputTokens := int64(response.Usage.InputTokens)
 	outputTokens := int64(response.Usage.OutputTokens)
+	setCachedTokens(c, response.Usage.InputTokensDetails.CachedTokens)
 
 	// Track usage
 	s.trackUsage(c, rule, provider, actualModel, responseModel, int(inputTokens), int(outputTokens), false, "success", "")
@@ -196,16 +195,11 @@ func (s *Server) handleResponsesNonStreamingRequest(c *gin.Context, provider *ty
 // handleResponsesStreamingRequest handles streaming Responses API requests
 func (s *Server) handleResponsesStreamingRequest(c *gin.Context, provider *typ.Provider, params responses.ResponseNewParams, responseModel, actualModel string, rule *typ.Rule) {
 	// Create streaming request
-	stream, _, err := s.forwardResponsesStreamRequest(provider, params)
+	stream, _, err := s.forwardResponsesStreamRequest(c.Request.Context(), provider, params)
 	if err != nil {
 		// Track error with no usage
 		s.trackUsage(c, rule, provider, actualModel, responseModel, 0, 0, false, "error", "stream_creation_failed")
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error: ErrorDetail{
-				Message: "Failed to create streaming request: " + err.Error(),
-				Type:    "api_error",
-			},
-		})
+		SendUpstreamError(c, protocol.APIStyleOpenAI, err)
 		return
 	}
 
@@ -214,7 +208,7 @@ func (s *Server) handleResponsesStreamingRequest(c *gin.Context, provider *typ.P
 }
 
 // handleResponsesStreamResponse processes the streaming response and sends it to the client
-func (s *Server) handleResponsesStreamResponse(c *gin.Context, stream *ssestream.Stream[responses.ResponseStreamEventUnion], responseModel, actualModel string, rule *typ.Rule, provider *typ.Provider) {
+func (s *Server) handleResponsesStreamResponse(c *gin.Context, upstream *ssestream.Stream[responses.ResponseStreamEventUnion], responseModel, actualModel string, rule *typ.Rule, provider *typ.Provider) {
 	// Accumulate usage from stream chunks
 	var inputTokens, outputTokens int64
 	var hasUsage bool
@@ -233,8 +227,8 @@ func (s *Server) handleResponsesStreamResponse(c *gin.Context, stream *ssestream
 				}
 			}
 		}
-		if stream != nil {
-			if err := stream.Close(); err != nil {
+		if upstream != nil {
+			if err := upstream.Close(); err != nil {
 				logrus.Errorf("Error closing stream: %v", err)
 			}
 		}
@@ -259,9 +253,11 @@ func (s *Server) handleResponsesStreamResponse(c *gin.Context, stream *ssestream
 		return
 	}
 
-	// Process the stream
-	for stream.Next() {
-		event := stream.Current()
+	// Process the stream, pinging the client while upstream is quiet
+	events := stream.KeepAlive(c, upstream, protocol.APIStyleOpenAI)
+	defer events.Close()
+	for events.Next() {
+		event := events.Current()
 		event.Response.Model = responseModel
 
 		// Accumulate usage from completed events
@@ -272,25 +268,20 @@ func (s *Server) handleResponsesStreamResponse(c *gin.Context, stream *ssestream
 		if event.Response.Usage.OutputTokens > 0 {
 			outputTokens = event.Response.Usage.OutputTokens
 		}
+		setCachedTokens(c, event.Response.Usage.InputTokensDetails.CachedTokens)
 
 		c.SSEvent("", event)
 		flusher.Flush()
 	}
 
-	// Check for stream errors
-	if err := stream.Err(); err != nil {
+	// Check for stream errors, including an upstream that went silent past the idle timeout
+	if err := events.Err(); err != nil {
 		logrus.Errorf("Stream error: %v", err)
 		if hasUsage {
-			s.trackUsage(c, rule, provider, actualModel, responseModel, int(inputTokens), int(outputTokens), true, "error", "stream_error")
+			s.trackStreamFailure(c, rule, provider, actualModel, responseModel, int(inputTokens), int(outputTokens))
 		}
 
-		errorChunk := map[string]any{
-			"error": map[string]any{
-				"message": err.Error(),
-				"type":    "stream_error",
-				"code":    "stream_failed",
-			},
-		}
+		errorChunk := upstreamErrorBody(protocol.APIStyleOpenAI, err)
 
 		errorJSON, marshalErr := json.Marshal(errorChunk)
 		if marshalErr != nil {
@@ -299,6 +290,7 @@ func (s *Server) handleResponsesStreamResponse(c *gin.Context, stream *ssestream
 		} else {
 			c.Writer.Write([]byte(fmt.Sprintf("data: %s\n\n", string(errorJSON))))
 		}
+		c.Writer.Write([]byte("data: [DONE]\n\n"))
 		flusher.Flush()
 		return
 	}
@@ -314,16 +306,18 @@ func (s *Server) handleResponsesStreamResponse(c *gin.Context, stream *ssestream
 }
 
 // forwardResponsesRequest forwards a Responses API request to the provider
-func (s *Server) forwardResponsesRequest(provider *typ.Provider, params responses.ResponseNewParams) (*responses.Response, error) {
+func (s *Server) forwardResponsesRequest(ctx context.Context, provider *typ.Provider, params responses.ResponseNewParams) (*responses.Response, error) {
 	wrapper := s.clientPool.GetOpenAIClient(provider, params.Model)
 	logrus.Infof("provider: %s (responses)", provider.Name)
 
 	// Make the request using wrapper method with provider timeout
+	ctx, span := startUpstreamSpan(ctx, provider, params.Model)
 	timeout := time.Duration(provider.Timeout) * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	resp, err := wrapper.Client().Responses.New(ctx, params)
+	obs.EndSpan(span, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create response: %w", err)
 	}
@@ -332,15 +326,23 @@ func (s *Server) forwardResponsesRequest(provider *typ.Provider, params response
 }
 
 // forwardResponsesStreamRequest forwards a streaming Responses API request to the provider
-func (s *Server) forwardResponsesStreamRequest(provider *typ.Provider, params responses.ResponseNewParams) (*ssestream.Stream[responses.ResponseStreamEventUnion], context.CancelFunc, error) {
+func (s *Server) forwardResponsesStreamRequest(ctx context.Context, provider *typ.Provider, params responses.ResponseNewParams) (*ssestream.Stream[responses.ResponseStreamEventUnion], context.CancelFunc, error) {
 	wrapper := s.clientPool.GetOpenAIClient(provider, params.Model)
 	logrus.Infof("provider: %s (responses streaming)", provider.Name)
 
 	// Make the request using wrapper method with provider timeout
+	ctx, span := startUpstreamSpan(ctx, provider, params.Model)
 	timeout := time.Duration(provider.Timeout) * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 
 	stream := wrapper.Client().Responses.NewStreaming(ctx, params)
+	err := stream.Err()
+	obs.EndSpan(span, err)
+	// A rejected request fails before the first event; report it while an HTTP status can still be sent
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
 
 	return stream, cancel, nil
 }
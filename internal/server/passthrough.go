@@ -13,6 +13,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 
+	"github.com/tingly-dev/tingly-box/internal/client"
 	"github.com/tingly-dev/tingly-box/internal/constant"
 	"github.com/tingly-dev/tingly-box/internal/typ"
 )
@@ -68,7 +69,7 @@ func (s *Server) handlePassthroughRequest(c *gin.Context, apiStyle string) {
 	}
 
 	// Determine provider and service via load balancing
-	provider, selectedService, rule, err := s.DetermineProviderAndModel(requestModel)
+	provider, selectedService, rule, err := s.DetermineProviderAndModel(c.Request.Context(), requestModel)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -137,11 +138,12 @@ func (s *Server) proxyPassthroughRequest(c *gin.Context, provider *typ.Provider,
 	// Create HTTP client with timeout for passthrough
 	// Note: We don't use the pooled client's HTTP client because it may have no timeout
 	httpClient := &http.Client{
-		Timeout: timeout,
+		Timeout:   timeout,
+		Transport: client.NewTraceRoundTripper(nil, provider.Name),
 	}
 
-	// Create context with timeout for all requests
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	// Create context with timeout for all requests, keeping the request's trace
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), timeout)
 	defer cancel()
 
 	// Create the proxy request
@@ -3,6 +3,8 @@ package server
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -22,6 +24,7 @@ func maskProviderForResponse(provider *typ.Provider) ProviderResponse {
 		NoKeyRequired: provider.NoKeyRequired,
 		Enabled:       provider.Enabled,
 		ProxyURL:      provider.ProxyURL,
+		ToolRepair:    provider.ToolRepair,
 		AuthType:      string(provider.AuthType),
 	}
 
@@ -139,6 +142,7 @@ func (s *Server) CreateProvider(c *gin.Context) {
 		NoKeyRequired: req.NoKeyRequired,
 		Enabled:       req.Enabled,
 		ProxyURL:      req.ProxyURL,
+		ToolRepair:    req.ToolRepair,
 	}
 
 	err = s.config.AddProvider(provider)
@@ -283,6 +287,9 @@ func (s *Server) UpdateProvider(c *gin.Context) {
 	if req.ProxyURL != nil {
 		provider.ProxyURL = *req.ProxyURL
 	}
+	if req.ToolRepair != nil {
+		provider.ToolRepair = *req.ToolRepair
+	}
 
 	err = s.config.UpdateProvider(uid, provider)
 	if err != nil {
@@ -488,3 +495,45 @@ func (s *Server) GetProviderModelsByUUID(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// GetProviderModelChanges returns the models a provider added and removed within the last days
+// (default 30), as detected by model list syncs
+func (s *Server) GetProviderModelChanges(c *gin.Context) {
+	uid := c.Param("uuid")
+
+	providerModelManager := s.config.GetModelManager()
+	if providerModelManager == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Provider model manager not available",
+		})
+		return
+	}
+
+	days := 30
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "days must be a positive integer",
+			})
+			return
+		}
+		days = parsed
+	}
+
+	changes, err := providerModelManager.GetModelChanges(uid, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ModelChangesResponse{
+		Success: true,
+		Data:    changes,
+	})
+}
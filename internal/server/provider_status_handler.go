@@ -0,0 +1,183 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tingly-dev/tingly-box/internal/db"
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+// Health statuses of providers and models on the status page
+const (
+	HealthStatusUp       = "up"       // the last probe succeeded and recent uptime is good
+	HealthStatusDegraded = "degraded" // the last probe succeeded but recent probes failed, or some models are down
+	HealthStatusDown     = "down"     // the last probe failed, or every model is down
+	HealthStatusUnknown  = "unknown"  // not probed yet
+)
+
+// degradedUptime is the 24h uptime in percent below which a model that is up counts as degraded
+const degradedUptime = 99.0
+
+// ModelHealthStatus is the probe status of one model of a provider
+type ModelHealthStatus struct {
+	Model               string     `json:"model" example:"gpt-4o"`
+	Status              string     `json:"status" example:"up"`
+	Healthy             bool       `json:"healthy" example:"true"` // Whether service selection routes to it
+	ConsecutiveFailures int        `json:"consecutive_failures" example:"0"`
+	LastChecked         *time.Time `json:"last_checked,omitempty"`
+	LatencyMs           int        `json:"latency_ms" example:"420"` // Of the last probe
+	LastError           string     `json:"last_error,omitempty"`
+	Uptime24h           *float64   `json:"uptime_24h" example:"100"` // Percent of successful probes; null without probes
+	Uptime7d            *float64   `json:"uptime_7d" example:"99.5"`
+	AvgLatencyMs24h     int        `json:"avg_latency_ms_24h" example:"450"` // Of the successful probes
+	Probes24h           int64      `json:"probes_24h" example:"288"`
+}
+
+// ProviderHealthStatus is the probe status of a provider across the models active rules use
+type ProviderHealthStatus struct {
+	UUID      string              `json:"uuid"`
+	Name      string              `json:"name" example:"openai"`
+	Status    string              `json:"status" example:"up"`
+	Uptime24h *float64            `json:"uptime_24h" example:"100"`
+	Uptime7d  *float64            `json:"uptime_7d" example:"99.5"`
+	Models    []ModelHealthStatus `json:"models"`
+}
+
+// ProviderStatusResponse is the status page of the providers used by active rules
+type ProviderStatusResponse struct {
+	Success bool                   `json:"success" example:"true"`
+	Probing typ.HealthProbeConfig  `json:"probing"`
+	Data    []ProviderHealthStatus `json:"data"`
+}
+
+// GetProviderStatus returns the health of every enabled provider model used by an active rule,
+// from the background probe history
+func (s *Server) GetProviderStatus(c *gin.Context) {
+	if s.capabilityStore == nil || s.healthProber == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"success": false, "error": "Capability store not available"})
+		return
+	}
+
+	now := time.Now()
+	latest, err := s.capabilityStore.GetLatestProbes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	day, err := s.capabilityStore.GetProbeUptime(now.Add(-24 * time.Hour))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	week, err := s.capabilityStore.GetProbeUptime(now.AddDate(0, 0, -7))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	dayByService := uptimeByService(day)
+	weekByService := uptimeByService(week)
+
+	var providers []*ProviderHealthStatus
+	providerTotals := make(map[string]*[2]db.ProbeUptime) // 24h and 7d totals per provider
+	byUUID := make(map[string]*ProviderHealthStatus)
+	for _, target := range s.healthProber.targets() {
+		provider := byUUID[target.provider.UUID]
+		if provider == nil {
+			provider = &ProviderHealthStatus{UUID: target.provider.UUID, Name: target.provider.Name}
+			byUUID[target.provider.UUID] = provider
+			providerTotals[target.provider.UUID] = &[2]db.ProbeUptime{}
+			providers = append(providers, provider)
+		}
+
+		model := ModelHealthStatus{
+			Model:               target.model,
+			Healthy:             s.healthProber.healthy(target.serviceID),
+			ConsecutiveFailures: s.healthProber.ConsecutiveFailures(target.serviceID),
+			Status:              HealthStatusUnknown,
+		}
+		key := target.provider.UUID + "\x00" + target.model
+		if uptime, ok := dayByService[key]; ok {
+			model.Uptime24h = uptimePercent(uptime)
+			model.AvgLatencyMs24h = int(uptime.AvgLatencyMs)
+			model.Probes24h = uptime.Probes
+			addUptime(&providerTotals[target.provider.UUID][0], uptime)
+		}
+		if uptime, ok := weekByService[key]; ok {
+			model.Uptime7d = uptimePercent(uptime)
+			addUptime(&providerTotals[target.provider.UUID][1], uptime)
+		}
+		if record, ok := latest[target.provider.UUID][target.model]; ok {
+			checked := record.CheckedAt
+			model.LastChecked = &checked
+			model.LatencyMs = record.LatencyMs
+			model.LastError = record.ErrorMessage
+			switch {
+			case !record.Available:
+				model.Status = HealthStatusDown
+			case model.Uptime24h != nil && *model.Uptime24h < degradedUptime:
+				model.Status = HealthStatusDegraded
+			default:
+				model.Status = HealthStatusUp
+			}
+		}
+		provider.Models = append(provider.Models, model)
+	}
+
+	data := make([]ProviderHealthStatus, 0, len(providers))
+	for _, provider := range providers {
+		totals := providerTotals[provider.UUID]
+		provider.Uptime24h = uptimePercent(totals[0])
+		provider.Uptime7d = uptimePercent(totals[1])
+		provider.Status = providerHealthStatus(provider.Models)
+		data = append(data, *provider)
+	}
+
+	c.JSON(http.StatusOK, ProviderStatusResponse{
+		Success: true,
+		Probing: s.config.GetHealthProbeConfig(),
+		Data:    data,
+	})
+}
+
+// providerHealthStatus combines the statuses of a provider's models: up or down when all probed
+// models agree, degraded otherwise
+func providerHealthStatus(models []ModelHealthStatus) string {
+	status := HealthStatusUnknown
+	for _, model := range models {
+		switch {
+		case model.Status == HealthStatusUnknown:
+		case status == HealthStatusUnknown:
+			status = model.Status
+		case status != model.Status:
+			return HealthStatusDegraded
+		}
+	}
+	return status
+}
+
+// uptimeByService indexes probe uptimes by provider UUID and model ID
+func uptimeByService(uptimes []db.ProbeUptime) map[string]db.ProbeUptime {
+	result := make(map[string]db.ProbeUptime, len(uptimes))
+	for _, uptime := range uptimes {
+		result[uptime.ProviderUUID+"\x00"+uptime.ModelID] = uptime
+	}
+	return result
+}
+
+// addUptime adds the probe counts of uptime to total
+func addUptime(total *db.ProbeUptime, uptime db.ProbeUptime) {
+	total.Probes += uptime.Probes
+	total.Successes += uptime.Successes
+}
+
+// uptimePercent returns the uptime in percent, or nil without probes
+func uptimePercent(uptime db.ProbeUptime) *float64 {
+	if uptime.Probes == 0 {
+		return nil
+	}
+	percent := uptime.Percent()
+	return &percent
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tingly-dev/tingly-box/internal/obs"
+	"github.com/tingly-dev/tingly-box/internal/server/middleware"
+)
+
+// requestJournalSize is how many recent requests keep their routing decisions and upstream attempts
+const requestJournalSize = 1000
+
+// RequestDetailResponse joins what the proxy kept about one request, looked up by its request ID.
+// Each part is empty when its store has nothing for the request: the journal only holds recent
+// requests, and recordings and error log entries exist only when those features are enabled.
+type RequestDetailResponse struct {
+	RequestID  string                `json:"request_id"`
+	Request    *obs.RequestDetail    `json:"request,omitempty"`
+	Usage      []UsageRecordResponse `json:"usage"`
+	Logs       []LogEntry            `json:"logs"`
+	ErrorLog   []json.RawMessage     `json:"error_log"`
+	Recordings []json.RawMessage     `json:"recordings"`
+}
+
+// GetRequestDetail returns the usage, routing decisions, upstream attempts, log entries and
+// recorded payloads of a request
+func (s *Server) GetRequestDetail(c *gin.Context) {
+	id := c.Param("id")
+	response := RequestDetailResponse{
+		RequestID:  id,
+		Usage:      []UsageRecordResponse{},
+		Logs:       []LogEntry{},
+		ErrorLog:   []json.RawMessage{},
+		Recordings: []json.RawMessage{},
+	}
+
+	// When the request happened, to narrow the search of the hourly recording files
+	var at time.Time
+	if s.requestJournal != nil {
+		if detail, ok := s.requestJournal.Get(id); ok {
+			response.Request = detail
+			at = detail.Time
+		}
+	}
+
+	if usageStore := s.config.GetUsageStore(); usageStore != nil {
+		records, err := usageStore.GetRecordsByRequestID(id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for _, record := range records {
+			response.Usage = append(response.Usage, newUsageRecordResponse(record))
+			if at.IsZero() {
+				at = record.Timestamp
+			}
+		}
+	}
+
+	if s.memoryLogMW != nil {
+		for _, entry := range s.memoryLogMW.GetEntries() {
+			if entry.Data[middleware.ContextKeyRequestID] == id {
+				response.Logs = append(response.Logs, convertLogrusEntry(entry))
+			}
+		}
+	}
+
+	if s.errorMW != nil {
+		entries, err := s.errorMW.FindByRequestID(id)
+		if err != nil {
+			logrus.Warnf("Failed to search the error log for request %s: %v", id, err)
+		}
+		response.ErrorLog = append(response.ErrorLog, entries...)
+	}
+
+	if s.recordSink != nil && !at.IsZero() {
+		entries, err := s.recordSink.FindEntries(id, at)
+		if err != nil {
+			logrus.Warnf("Failed to search recordings for request %s: %v", id, err)
+		}
+		response.Recordings = append(response.Recordings, entries...)
+	}
+
+	if response.Request == nil && len(response.Usage) == 0 && len(response.Logs) == 0 &&
+		len(response.ErrorLog) == 0 && len(response.Recordings) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
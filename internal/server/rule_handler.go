@@ -43,8 +43,9 @@ func (s *Server) GetRules(c *gin.Context) {
 	}
 
 	response := RulesResponse{
-		Success: true,
-		Data:    rules,
+		Success:  true,
+		Data:     rules,
+		Warnings: cfg.GetModelWarnings(rules),
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -80,8 +81,9 @@ func (s *Server) GetRule(c *gin.Context) {
 	}
 
 	response := RuleResponse{
-		Success: true,
-		Data:    rule,
+		Success:  true,
+		Data:     rule,
+		Warnings: cfg.GetModelWarnings([]typ.Rule{*rule}),
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -13,10 +13,14 @@ import (
 	"github.com/pkg/browser"
 	"github.com/sirupsen/logrus"
 
+	"github.com/tingly-dev/tingly-box/internal/accountpool"
+	"github.com/tingly-dev/tingly-box/internal/alert"
 	"github.com/tingly-dev/tingly-box/internal/client"
 	"github.com/tingly-dev/tingly-box/internal/constant"
 	"github.com/tingly-dev/tingly-box/internal/db"
+	"github.com/tingly-dev/tingly-box/internal/loadbalance"
 	"github.com/tingly-dev/tingly-box/internal/obs"
+	"github.com/tingly-dev/tingly-box/internal/protocol/stream"
 	"github.com/tingly-dev/tingly-box/internal/server/background"
 	"github.com/tingly-dev/tingly-box/internal/server/config"
 	"github.com/tingly-dev/tingly-box/internal/server/middleware"
@@ -42,6 +46,9 @@ type Server struct {
 	errorMW         *middleware.ErrorLogMiddleware
 	authMW          *middleware.AuthMiddleware
 	memoryLogMW     *middleware.MemoryLogMiddleware
+	slimRecordMW    *middleware.SlimRecordMiddleware
+	metricsMW       *middleware.MetricsMiddleware
+	shutdownTracing func(context.Context) error
 	loadBalancer    *LoadBalancer
 	loadBalancerAPI *LoadBalancerAPI
 	usageAPI        *UsageAPI
@@ -49,6 +56,18 @@ type Server struct {
 	// client pool for caching
 	clientPool *client.ClientPool
 
+	// record sink for request recording (nil when recording is disabled)
+	recordSink *obs.Sink
+
+	// routing decisions and upstream attempts of recent requests, by request ID
+	requestJournal *obs.RequestJournal
+
+	// live summaries of completed proxied requests
+	requestFeed *middleware.RequestFeed
+
+	// account pool tracker for OAuth multi-account rotation
+	accountPools *accountpool.Tracker
+
 	// OAuth manager
 	oauthManager *oauth2.Manager
 
@@ -64,6 +83,15 @@ type Server struct {
 	// capability store for persistent model capabilities
 	capabilityStore *db.ModelCapabilityStore
 
+	// background health probes of the provider models used by active rules
+	healthProber *HealthProber
+
+	// scheduled sync of provider model lists
+	modelSyncer *background.ModelSyncer
+
+	// alert rules over request failures, OAuth refresh failures, exhausted quota and failed probes
+	alerts *alert.Manager
+
 	// options
 	enableUI      bool
 	enableAdaptor bool
@@ -254,17 +282,37 @@ func NewServer(cfg *config.Config, opts ...ServerOption) *Server {
 		log.Printf("ErrorLog middleware initialized with default filter, logging to: %s", errorLogPath)
 	}
 
+	// Redact secrets and PII from everything written to disk
+	redactor, err := obs.NewRedactor(cfg.GetRedactionConfig())
+	if err != nil {
+		log.Printf("Warning: Failed to load redaction rules: %v, using built-in rules", err)
+		redactor = obs.DefaultRedactor()
+	}
+	errorMW.SetRedactor(redactor)
+
 	// Create server struct first with applied options
 	server.jwtManager = jwtManager
 	server.engine = gin.New()
 	server.logger = memoryLogger
 	server.clientPool = client.NewClientPool() // Initialize client pool
 	server.errorMW = errorMW
+	server.requestJournal = obs.NewRequestJournal(requestJournalSize)
+	server.requestFeed = middleware.NewRequestFeed()
+
+	// Track OAuth account quota from upstream rate limit headers
+	server.accountPools = accountpool.NewTracker()
+	server.clientPool.SetResponseObserver(server.accountPools)
+	server.clientPool.SetAccountFailover(accountFailover{server: server})
 
 	// Initialize record sink if recording is enabled
 	if server.recordMode != "" {
 		recordSink := obs.NewSink(server.recordDir, server.recordMode)
+		recordSink.SetRedactor(redactor)
 		server.clientPool.SetRecordSink(recordSink)
+		server.recordSink = recordSink
+		if recordSink.IsSlim() {
+			server.slimRecordMW = middleware.NewSlimRecordMiddleware(cfg, recordSink)
+		}
 		log.Printf("Request recording enabled, mode: %s, directory: %s", server.recordMode, server.recordDir)
 	}
 
@@ -274,6 +322,9 @@ func NewServer(cfg *config.Config, opts ...ServerOption) *Server {
 	// Initialize memory log middleware for HTTP request logging
 	memoryLogMW := middleware.NewMemoryLogMiddleware(1000) // Store up to 1000 entries
 
+	// Initialize Prometheus metrics for the proxy data plane
+	metricsMW := middleware.NewMetricsMiddleware(cfg)
+
 	// Initialize auth middleware
 	authMW := middleware.NewAuthMiddleware(cfg, jwtManager)
 
@@ -306,10 +357,17 @@ func NewServer(cfg *config.Config, opts ...ServerOption) *Server {
 	// Initialize token refresher for OAuth auto-refresh
 	tokenRefresher := background.NewTokenRefresher(oauthManager, cfg)
 
+	// Refreshed tokens invalidate pooled clients; rejected tokens are refreshed on demand and replayed once
+	tokenRefresher.SetOnRefresh(func(provider *typ.Provider) {
+		server.clientPool.RemoveProviderClients(provider.UUID)
+	})
+	server.clientPool.SetTokenRefresher(tokenRefresher)
+
 	// Update server with dependencies
 	server.statsMW = statsMW
 	server.authMW = authMW
 	server.memoryLogMW = memoryLogMW
+	server.metricsMW = metricsMW
 	server.loadBalancer = loadBalancer
 	server.loadBalancerAPI = loadBalancerAPI
 	server.usageAPI = usageAPI
@@ -324,6 +382,7 @@ func NewServer(cfg *config.Config, opts ...ServerOption) *Server {
 		log.Printf("Provider templates initialized (version: %s)", templateManager.GetVersion())
 	}
 	server.templateManager = templateManager
+	server.applyTemplateRequestHooks()
 
 	// Set template manager in config for model fetching fallback
 	server.config.SetTemplateManager(templateManager)
@@ -344,6 +403,26 @@ func NewServer(cfg *config.Config, opts ...ServerOption) *Server {
 		log.Printf("Model capability store initialized")
 	}
 
+	// Health probes steer service selection away from failing services
+	server.healthProber = NewHealthProber(server)
+	server.loadBalancer.SetHealthCheck(server.healthProber.Healthy)
+
+	// Alerts fire on the events the refresher, account pools and probes report
+	server.setupAlerting()
+
+	// Model list syncs detect models providers removed, which rules may still use
+	server.modelSyncer = background.NewModelSyncer(cfg)
+	server.modelSyncer.SetOnChange(func(provider *typ.Provider, diff db.ModelDiff) {
+		for _, model := range diff.Removed {
+			server.alerts.Emit(alert.Event{
+				Kind:     alert.EventModelRemoved,
+				Provider: provider.Name,
+				Model:    model,
+				Message:  fmt.Sprintf("%s no longer lists model %s", provider.Name, model),
+			})
+		}
+	})
+
 	// Setup middleware
 	server.setupMiddleware()
 
@@ -384,6 +463,23 @@ func (s *Server) setupConfigWatcher() {
 				}
 			}
 		}
+
+		// Reload redaction rules; keep the current ones if the new rules are invalid
+		if redactor, err := obs.NewRedactor(newConfig.GetRedactionConfig()); err != nil {
+			logrus.Errorf("Failed to update redaction rules: %v", err)
+		} else {
+			if s.errorMW != nil {
+				s.errorMW.SetRedactor(redactor)
+			}
+			if s.recordSink != nil {
+				s.recordSink.SetRedactor(redactor)
+			}
+		}
+
+		// Reload alert rules and sinks; invalid ones are skipped
+		if err := s.alerts.Reload(newConfig.GetAlertingConfig()); err != nil {
+			logrus.Errorf("Failed to load some alert rules or sinks: %v", err)
+		}
 	})
 }
 
@@ -392,6 +488,17 @@ func (s *Server) setupMiddleware() {
 	// Recovery middleware
 	s.engine.Use(gin.Recovery())
 
+	// Tracing middleware starts the server span the other middleware and handlers run in
+	s.engine.Use(middleware.Tracing())
+
+	// Request ID middleware tags the request, its logs, usage and recordings with one ID
+	s.engine.Use(middleware.RequestID(s.requestJournal, s.requestFeed))
+
+	// Metrics middleware for the /metrics endpoint
+	if s.metricsMW != nil {
+		s.engine.Use(s.metricsMW.Middleware())
+	}
+
 	// Memory log middleware for HTTP request logging
 	if s.memoryLogMW != nil {
 		s.engine.Use(s.memoryLogMW.Middleware())
@@ -407,6 +514,11 @@ func (s *Server) setupMiddleware() {
 		s.engine.Use(s.statsMW.Middleware())
 	}
 
+	// Slim record middleware writes one summary line per proxied request
+	if s.slimRecordMW != nil {
+		s.engine.Use(s.slimRecordMW.Middleware())
+	}
+
 	// CORS middleware
 	s.engine.Use(middleware.CORS())
 }
@@ -421,9 +533,34 @@ func (s *Server) setupRoutes() {
 	s.UseAIEndpoints()
 
 	s.UseLoadBalanceEndpoints()
+
+	s.UseMetricsEndpoint()
+}
+
+// UseMetricsEndpoint serves Prometheus metrics, behind the user token when the config asks for it
+func (s *Server) UseMetricsEndpoint() {
+	if s.metricsMW == nil {
+		return
+	}
+	userAuth := s.authMW.UserAuthMiddleware()
+	s.engine.GET("/metrics", func(c *gin.Context) {
+		if s.config.GetMetricsRequireAuth() {
+			userAuth(c)
+			if c.IsAborted() {
+				return
+			}
+		}
+		c.Next()
+	}, s.metricsMW.Handler())
 }
 
 func (s *Server) UseAIEndpoints() {
+	// Streamed responses read their keep-alive settings from the request context
+	s.engine.Use(func(c *gin.Context) {
+		c.Set(stream.KeepAliveContextKey, s.config.GetStreamingConfig())
+		c.Next()
+	})
+
 	// OpenAI v1 API group
 	openaiV1 := s.engine.Group("/openai/v1")
 	s.SetupOpenAIEndpoints(openaiV1)
@@ -536,6 +673,22 @@ func (s *Server) Start(port int) error {
 		log.Println("OAuth token auto-refresh started")
 	}
 
+	// Start background health probes; rounds are skipped while probing is disabled
+	if s.healthProber != nil {
+		go s.healthProber.Start(ctx)
+		if probeConfig := s.config.GetHealthProbeConfig(); probeConfig.Enabled {
+			log.Printf("Health probes every %s, at most %d per hour", probeConfig.IntervalDuration(), probeConfig.MaxProbesPerHour)
+		}
+	}
+
+	// Start scheduled model list syncs
+	if s.modelSyncer != nil {
+		go s.modelSyncer.Start(ctx)
+	}
+
+	// Evaluate alert rules over completed requests
+	go s.forwardRequestAlerts(ctx)
+
 	// Start configuration watcher
 	if s.watcher != nil {
 		if err := s.watcher.Start(); err != nil {
@@ -545,6 +698,25 @@ func (s *Server) Start(port int) error {
 		}
 	}
 
+	// Move usage writes off the request path and start the usage rollups
+	if usageStore := s.config.GetUsageStore(); usageStore != nil {
+		usageStore.Start(s.config.GetUsageStorageConfig())
+	}
+	if statsStore := s.config.GetStatsStore(); statsStore != nil {
+		statsStore.Start(db.DefaultStatsFlushInterval)
+	}
+
+	// Install tracing; spans are exported only when a collector endpoint is configured
+	tracingConfig := s.config.GetTracingConfig()
+	if shutdown, err := obs.InitTracing(ctx, tracingConfig); err != nil {
+		log.Printf("Failed to initialize tracing: %v", err)
+	} else {
+		s.shutdownTracing = shutdown
+		if tracingConfig.Endpoint != "" {
+			log.Printf("Exporting traces to %s", tracingConfig.Endpoint)
+		}
+	}
+
 	// Determine scheme and handle HTTPS setup
 	scheme := "http"
 	if s.httpsEnabled {
@@ -664,6 +836,21 @@ func (s *Server) GetLoadBalancer() *LoadBalancer {
 	return s.loadBalancer
 }
 
+// GetHealthProber returns the background health prober
+func (s *Server) GetHealthProber() *HealthProber {
+	return s.healthProber
+}
+
+// GetModelSyncer returns the scheduled model list syncer
+func (s *Server) GetModelSyncer() *background.ModelSyncer {
+	return s.modelSyncer
+}
+
+// GetAlertManager returns the alert manager, e.g. for the desktop app to register its notifier
+func (s *Server) GetAlertManager() *alert.Manager {
+	return s.alerts
+}
+
 // GetPreferredEndpointForModel returns the preferred endpoint (chat or responses) for a model
 // Returns "responses" if the model supports the Responses API, otherwise returns "chat"
 func (s *Server) GetPreferredEndpointForModel(provider *typ.Provider, modelID string) string {
@@ -671,6 +858,15 @@ func (s *Server) GetPreferredEndpointForModel(provider *typ.Provider, modelID st
 	return adaptiveProbe.GetPreferredEndpoint(provider, modelID)
 }
 
+// useResponsesAPI reports whether a request to an OpenAI-style provider should be sent to the
+// Responses API: Codex-family models only serve Responses, other models follow the adaptive probe
+func (s *Server) useResponsesAPI(provider *typ.Provider, selectedService *loadbalance.Service) bool {
+	if selectedService.PreferCompletions() {
+		return true
+	}
+	return s.GetPreferredEndpointForModel(provider, selectedService.Model) == "responses"
+}
+
 // Stop gracefully stops the HTTP server
 func (s *Server) Stop(ctx context.Context) error {
 	if s.httpServer == nil {
@@ -683,6 +879,16 @@ func (s *Server) Stop(ctx context.Context) error {
 		log.Println("OAuth token auto-refresh stopped")
 	}
 
+	// Stop health probes
+	if s.healthProber != nil {
+		s.healthProber.Stop()
+	}
+
+	// Stop model list syncs
+	if s.modelSyncer != nil {
+		s.modelSyncer.Stop()
+	}
+
 	// Stop debug middleware
 	if s.errorMW != nil {
 		s.errorMW.Stop()
@@ -695,5 +901,30 @@ func (s *Server) Stop(ctx context.Context) error {
 	}
 
 	fmt.Println("Shutting down server...")
-	return s.httpServer.Shutdown(ctx)
+	err := s.httpServer.Shutdown(ctx)
+
+	// Write the usage of the requests that just finished
+	if usageStore := s.config.GetUsageStore(); usageStore != nil {
+		if usageErr := usageStore.Close(ctx); usageErr != nil {
+			log.Printf("Failed to flush usage records: %v", usageErr)
+		}
+	}
+	if statsStore := s.config.GetStatsStore(); statsStore != nil {
+		if statsErr := statsStore.Close(); statsErr != nil {
+			log.Printf("Failed to flush service stats: %v", statsErr)
+		}
+	}
+
+	// Let alerts fired by the last requests reach their sinks
+	if alertErr := s.alerts.Close(ctx); alertErr != nil {
+		log.Printf("Failed to deliver pending alerts: %v", alertErr)
+	}
+
+	// Flush spans of the requests that just finished
+	if s.shutdownTracing != nil {
+		if tracingErr := s.shutdownTracing(ctx); tracingErr != nil {
+			log.Printf("Failed to flush traces: %v", tracingErr)
+		}
+	}
+	return err
 }
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/tingly-dev/tingly-box/internal/db"
 	smartrouting "github.com/tingly-dev/tingly-box/internal/smart_routing"
 	"github.com/tingly-dev/tingly-box/internal/typ"
 )
@@ -133,8 +134,9 @@ type ClearStatsResponse struct {
 
 // RuleResponse represents a rule configuration response
 type RuleResponse struct {
-	Success bool      `json:"success" example:"true"`
-	Data    *typ.Rule `json:"data"`
+	Success  bool               `json:"success" example:"true"`
+	Data     *typ.Rule          `json:"data"`
+	Warnings []typ.ModelWarning `json:"warnings,omitempty"` // Services whose model was removed or deprecated
 }
 
 // RuleSummaryResponse represents a rule summary response
@@ -189,6 +191,7 @@ type ProviderResponse struct {
 	NoKeyRequired bool             `json:"no_key_required" example:"false"`
 	Enabled       bool             `json:"enabled" example:"true"`
 	ProxyURL      string           `json:"proxy_url,omitempty" example:"http://127.0.0.1:7890"`
+	ToolRepair    bool             `json:"tool_repair,omitempty" example:"false"`
 	AuthType      string           `json:"auth_type,omitempty" example:"api_key"` // api_key or oauth
 	OAuthDetail   *typ.OAuthDetail `json:"oauth_detail,omitempty"`                // OAuth credentials (only for oauth auth type)
 }
@@ -227,8 +230,9 @@ type RequestConfig struct {
 
 // RulesResponse represents the response for getting all rules
 type RulesResponse struct {
-	Success bool        `json:"success" example:"true"`
-	Data    interface{} `json:"data"`
+	Success  bool               `json:"success" example:"true"`
+	Data     interface{}        `json:"data"`
+	Warnings []typ.ModelWarning `json:"warnings,omitempty"` // Services whose model was removed or deprecated
 }
 
 type CreateRuleRequest typ.Rule
@@ -268,6 +272,7 @@ type CreateProviderRequest struct {
 	NoKeyRequired bool   `json:"no_key_required" description:"Whether provider requires no API key" example:"false"`
 	Enabled       bool   `json:"enabled" description:"Whether provider is enabled" example:"true"`
 	ProxyURL      string `json:"proxy_url,omitempty" description:"HTTP or SOCKS proxy URL (e.g., http://127.0.0.1:7890 or socks5://127.0.0.1:1080)" example:"http://127.0.0.1:7890"`
+	ToolRepair    bool   `json:"tool_repair,omitempty" description:"Repair malformed tool-call arguments and coerce them to the tool schema" example:"false"`
 }
 
 // CreateProviderResponse represents the response for adding a provider
@@ -286,6 +291,7 @@ type UpdateProviderRequest struct {
 	NoKeyRequired *bool   `json:"no_key_required,omitempty" description:"Whether provider requires no API key"`
 	Enabled       *bool   `json:"enabled,omitempty" description:"New enabled status"`
 	ProxyURL      *string `json:"proxy_url,omitempty" description:"HTTP or SOCKS proxy URL"`
+	ToolRepair    *bool   `json:"tool_repair,omitempty" description:"Repair malformed tool-call arguments and coerce them to the tool schema"`
 }
 
 // UpdateProviderResponse represents the response for updating a provider
@@ -332,6 +338,12 @@ type ProviderModelsResponse struct {
 	Data    ProviderModelInfo `json:"data"`
 }
 
+// ModelChangesResponse lists the models providers added and removed, newest first
+type ModelChangesResponse struct {
+	Success bool                   `json:"success" example:"true"`
+	Data    []db.ModelChangeRecord `json:"data"`
+}
+
 // FetchProviderModelsResponse represents the response for fetching provider models
 type FetchProviderModelsResponse struct {
 	Success bool        `json:"success" example:"true"`
@@ -473,7 +485,7 @@ type UsageStatsQuery struct {
 	RuleUUID  string `json:"rule_uuid" form:"rule_uuid" description:"Filter by rule UUID"`
 	Status    string `json:"status" form:"status" description:"Filter by status: success, error, partial" example:"success"`
 	Limit     int    `json:"limit" form:"limit" description:"Max results to return" example:"100"`
-	SortBy    string `json:"sort_by" form:"sort_by" description:"Sort field: total_tokens, request_count, avg_latency" example:"total_tokens"`
+	SortBy    string `json:"sort_by" form:"sort_by" description:"Sort field: total_tokens, request_count, avg_latency, avg_ttft" example:"total_tokens"`
 	SortOrder string `json:"sort_order" form:"sort_order" description:"asc or desc" example:"desc"`
 }
 
@@ -495,6 +507,13 @@ type AggregatedStat struct {
 	ErrorRate       float64 `json:"error_rate" example:"0.0022"`
 	StreamedCount   int64   `json:"streamed_count" example:"4800"`
 	StreamedRate    float64 `json:"streamed_rate" example:"0.885"`
+
+	AvgTTFTMs          float64 `json:"avg_ttft_ms" example:"640"`
+	P50TTFTMs          float64 `json:"p50_ttft_ms" example:"520"`
+	P95TTFTMs          float64 `json:"p95_ttft_ms" example:"1480"`
+	AvgTokensPerSecond float64 `json:"avg_tokens_per_second" example:"58.3"`
+	P50TokensPerSecond float64 `json:"p50_tokens_per_second" example:"61.2"`
+	P95TokensPerSecond float64 `json:"p95_tokens_per_second" example:"94.7"`
 }
 
 // UsageStatsResponse represents the response for usage statistics
@@ -560,6 +579,7 @@ type UsageRecordsQuery struct {
 // UsageRecordResponse represents a single usage record
 type UsageRecordResponse struct {
 	ID           uint   `json:"id" example:"1"`
+	RequestID    string `json:"request_id,omitempty" example:"0b7c6f1e-5d1a-4f7e-9a51-3c2d8e4b6a10"`
 	ProviderUUID string `json:"provider_uuid" example:"uuid-123"`
 	ProviderName string `json:"provider_name" example:"openai"`
 	Model        string `json:"model" example:"gpt-4"`
@@ -574,6 +594,11 @@ type UsageRecordResponse struct {
 	ErrorCode    string `json:"error_code,omitempty"`
 	LatencyMs    int    `json:"latency_ms" example:"1200"`
 	Streamed     bool   `json:"streamed" example:"true"`
+
+	TTFTMs           int     `json:"ttft_ms,omitempty" example:"450"`
+	StreamDurationMs int     `json:"stream_duration_ms,omitempty" example:"8600"`
+	TokensPerSecond  float64 `json:"tokens_per_second,omitempty" example:"58.1"`
+	ChunkCount       int     `json:"chunk_count,omitempty" example:"512"`
 }
 
 // UsageRecordsResponse represents the response for usage records
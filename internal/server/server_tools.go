@@ -0,0 +1,43 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+	"github.com/tingly-dev/tingly-box/internal/protocol/request"
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+// supportedServerTools returns the Anthropic server tools the backend runs natively. The others
+// are dropped by the request conversion, which is logged so a missing search isn't a mystery.
+func supportedServerTools(provider *typ.Provider, model string, backend protocol.ServerToolBackend, tools []protocol.ServerTool) []protocol.ServerTool {
+	supported, unsupported := backend.Partition(tools)
+	if len(unsupported) > 0 {
+		types := make([]string, 0, len(unsupported))
+		for _, tool := range unsupported {
+			types = append(types, tool.Type)
+		}
+		logrus.Warnf("Stripping Anthropic server tools not supported by provider %s (model %s): %s",
+			provider.Name, model, strings.Join(types, ", "))
+	}
+	return supported
+}
+
+// applyChatServerTools maps Anthropic server tools onto a chat completions request. Only GLM
+// providers (by their template's web_search_schema) have a native web search; other backends
+// lose the server tools.
+func (s *Server) applyChatServerTools(provider *typ.Provider, model string, tools []protocol.ServerTool, openaiReq *openai.ChatCompletionNewParams) {
+	if len(tools) == 0 {
+		return
+	}
+	backend := protocol.ServerToolBackendNone
+	if s.templateManager.GetWebSearchSchema(provider) == protocol.WebSearchSchemaGLM {
+		backend = protocol.ServerToolBackendGLM
+	}
+	if supported := supportedServerTools(provider, model, backend, tools); len(supported) > 0 {
+		request.AddGLMWebSearchTool(openaiReq)
+	}
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openai/openai-go/v3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+	"github.com/tingly-dev/tingly-box/internal/protocol/request"
+)
+
+// checkStructuredOutput validates the assistant content of an OpenAI-format response against the
+// request's response_format. It writes an error response and returns false on mismatch.
+// Responses that end in tool calls are not final answers and are not checked.
+func checkStructuredOutput(c *gin.Context, req *openai.ChatCompletionNewParams, response map[string]interface{}) bool {
+	so := request.StructuredOutputFromOpenAI(req)
+	if so == nil {
+		return true
+	}
+
+	choices, _ := response["choices"].([]interface{})
+	if choices == nil {
+		if typed, ok := response["choices"].([]map[string]interface{}); ok {
+			for _, choice := range typed {
+				choices = append(choices, choice)
+			}
+		}
+	}
+	for _, raw := range choices {
+		choice, _ := raw.(map[string]interface{})
+		if choice == nil || choice["finish_reason"] == "tool_calls" || choice["finish_reason"] == "length" {
+			continue
+		}
+		message, _ := choice["message"].(map[string]interface{})
+		content, _ := message["content"].(string)
+		if err := protocol.ValidateStructuredOutput(content, so.Schema); err != nil {
+			logrus.Warnf("Structured output validation failed for %s: %v", so.Name, err)
+			c.JSON(http.StatusBadGateway, ErrorResponse{
+				Error: ErrorDetail{
+					Message: err.Error(),
+					Type:    "api_error",
+					Code:    "structured_output_mismatch",
+				},
+			})
+			return false
+		}
+	}
+	return true
+}
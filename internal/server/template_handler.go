@@ -5,8 +5,12 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 
+	"github.com/tingly-dev/tingly-box/internal/client"
 	"github.com/tingly-dev/tingly-box/internal/template"
+	"github.com/tingly-dev/tingly-box/internal/typ"
+	oauth2 "github.com/tingly-dev/tingly-box/pkg/oauth"
 )
 
 // TemplateResponse represents the response for provider template endpoints
@@ -97,6 +101,10 @@ func (s *Server) RefreshProviderTemplates(c *gin.Context) {
 		return
 	}
 
+	// Pick up request hook changes; clients built from now on use them
+	s.applyTemplateRequestHooks()
+	s.clientPool.Clear()
+
 	c.JSON(http.StatusOK, TemplateResponse{
 		Success: true,
 		Data:    registry.Providers,
@@ -122,3 +130,14 @@ func (s *Server) GetProviderTemplateVersion(c *gin.Context) {
 		"version": version,
 	})
 }
+
+// applyTemplateRequestHooks installs the OAuth request hooks defined by provider templates in the client layer
+func (s *Server) applyTemplateRequestHooks() {
+	hooks := make(map[oauth2.ProviderType]*typ.RequestHook)
+	for providerType, hook := range s.templateManager.GetOAuthRequestHooks() {
+		hooks[oauth2.ProviderType(providerType)] = hook
+	}
+	if err := client.SetOAuthHooks(hooks); err != nil {
+		logrus.Errorf("Failed to apply provider template request hooks: %v", err)
+	}
+}
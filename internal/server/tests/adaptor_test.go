@@ -407,6 +407,60 @@ func TestAdaptorFeature(t *testing.T) {
 		// This test verifies that the adaptor correctly forwards streaming requests
 		assert.Equal(t, 200, w.Code)
 	})
+
+	t.Run("Adaptor_Enabled_OpenAI_to_Anthropic_Structured_Output_Keeps_Forced_Tool", func(t *testing.T) {
+		ts := NewTestServerWithAdaptor(t, true)
+		defer Cleanup()
+
+		mockServer := NewMockProviderServer()
+		defer mockServer.Close()
+
+		ts.AddTestProviderWithURL(t, "anthropic-schema", mockServer.GetURL(), "anthropic", true)
+		ts.AddTestRule(t, "test-schema-rule", "anthropic-schema", "claude-3")
+
+		// A function tool_choice must not replace the choice that enforces the response schema; with
+		// client tools present the model must call any tool, so it can still answer through the schema tool
+		reqBody := map[string]interface{}{
+			"model": "test-schema-rule",
+			"messages": []map[string]string{
+				{"role": "user", "content": "What's the weather in New York?"},
+			},
+			"tools": []map[string]interface{}{
+				{
+					"type": "function",
+					"function": map[string]interface{}{
+						"name":       "get_weather",
+						"parameters": map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+					},
+				},
+			},
+			"tool_choice": map[string]interface{}{
+				"type":     "function",
+				"function": map[string]interface{}{"name": "get_weather"},
+			},
+			"response_format": map[string]interface{}{
+				"type": "json_schema",
+				"json_schema": map[string]interface{}{
+					"name": "weather",
+					"schema": map[string]interface{}{
+						"type":       "object",
+						"properties": map[string]interface{}{"temperature": map[string]interface{}{"type": "number"}},
+					},
+				},
+			},
+		}
+
+		req, _ := http.NewRequest("POST", "/openai/v1/chat/completions", CreateJSONBody(reqBody))
+		req.Header.Set("Authorization", "Bearer "+ts.appConfig.GetGlobalConfig().GetModelToken())
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		ts.ginEngine.ServeHTTP(w, req)
+
+		lastRequest := mockServer.GetLastRequest("v1/messages")
+		if assert.NotNil(t, lastRequest, w.Body.String()) {
+			assert.Equal(t, map[string]interface{}{"type": "any"}, lastRequest["tool_choice"])
+		}
+	})
 }
 
 // TestAdaptorFeatureWithRealConfig tests adaptor functionality against the real configuration
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tingly-dev/tingly-box/internal/alert"
+	"github.com/tingly-dev/tingly-box/internal/server"
+)
+
+func TestAlerts_TestDeliveryAndRecent(t *testing.T) {
+	ts := NewTestServer(t)
+
+	received := make(chan map[string]any, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]any
+		_ = json.Unmarshal(body, &payload)
+		received <- payload
+	}))
+	defer receiver.Close()
+
+	globalConfig := ts.appConfig.GetGlobalConfig()
+	globalConfig.Alerting = &alert.Config{
+		Rules: []alert.RuleConfig{{Name: "oauth", Condition: `Kind == "oauth_refresh_failed"`, Sinks: []string{"log"}}},
+		Sinks: []alert.SinkConfig{
+			{Name: "hook", Type: alert.SinkWebhook, URL: receiver.URL, Template: `{"text": {{ json .Title }}}`},
+			{Type: alert.SinkLog},
+		},
+	}
+	alerts := ts.server.GetAlertManager()
+	require.NoError(t, alerts.Reload(globalConfig.GetAlertingConfig()))
+
+	body, _ := json.Marshal(server.AlertTestRequest{Sink: "hook"})
+	req, _ := http.NewRequest("POST", "/api/v1/alerts/test", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+globalConfig.GetUserToken())
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	ts.ginEngine.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var result server.AlertTestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.True(t, result.Success)
+	require.Len(t, result.Data, 1)
+	assert.Equal(t, "hook", result.Data[0].Sink)
+	assert.Equal(t, "Tingly Box: test alert", (<-received)["text"])
+
+	alerts.Emit(alert.Event{Kind: alert.EventOAuthRefreshFailed, Provider: "claude", Message: "invalid_grant"})
+
+	req, _ = http.NewRequest("GET", "/api/v1/alerts", nil)
+	req.Header.Set("Authorization", "Bearer "+globalConfig.GetUserToken())
+	w = httptest.NewRecorder()
+	ts.ginEngine.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var recent server.AlertsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &recent))
+	require.Len(t, recent.Data, 1)
+	assert.Equal(t, "oauth", recent.Data[0].Rule)
+	assert.Contains(t, recent.Data[0].Message, "invalid_grant")
+
+	req, _ = http.NewRequest("POST", "/api/v1/alerts/test", bytes.NewReader([]byte(`{"sink": "missing"}`)))
+	req.Header.Set("Authorization", "Bearer "+globalConfig.GetUserToken())
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	ts.ginEngine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tingly-dev/tingly-box/internal/loadbalance"
+	"github.com/tingly-dev/tingly-box/internal/server"
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+func TestHealthProber_StatusAndSelection(t *testing.T) {
+	ts := NewTestServer(t)
+
+	failing := NewMockProviderServer()
+	defer failing.Close()
+	failing.SetResponse("chat/completions", MockResponse{StatusCode: 500, Error: "upstream down"})
+	healthy := NewMockProviderServer()
+	defer healthy.Close()
+
+	ts.AddTestProviderWithURL(t, "failing", failing.GetURL(), "openai", true)
+	ts.AddTestProviderWithURL(t, "healthy", healthy.GetURL(), "openai", true)
+
+	globalConfig := ts.appConfig.GetGlobalConfig()
+	rule := typ.Rule{
+		UUID:         "probe-rule",
+		Scenario:     typ.ScenarioOpenAI,
+		RequestModel: "probe-rule",
+		Services: []loadbalance.Service{
+			{Provider: "failing", Model: "gpt-4", Weight: 1, Active: true, TimeWindow: 300},
+			{Provider: "healthy", Model: "gpt-4", Weight: 1, Active: true, TimeWindow: 300},
+		},
+		LBTactic: typ.Tactic{Type: loadbalance.TacticRoundRobin, Params: typ.RoundRobinParams{RequestThreshold: 1}},
+		Active:   true,
+	}
+	require.NoError(t, globalConfig.AddRequestConfig(rule))
+
+	// The hourly cap defers the second probe; raising it lets the unprobed service through
+	globalConfig.HealthProbe = &typ.HealthProbeConfig{Enabled: true, MaxProbesPerHour: 1, FailureThreshold: 1}
+	prober := ts.server.GetHealthProber()
+	assert.Equal(t, 1, prober.ProbeOnce(context.Background()))
+	globalConfig.HealthProbe.MaxProbesPerHour = 10
+	assert.Equal(t, 1, prober.ProbeOnce(context.Background()))
+	assert.Equal(t, 0, prober.ProbeOnce(context.Background()), "services probed within the interval are skipped")
+
+	req, _ := http.NewRequest("GET", "/api/v1/providers/status", nil)
+	req.Header.Set("Authorization", "Bearer "+globalConfig.GetUserToken())
+	w := httptest.NewRecorder()
+	ts.ginEngine.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var status server.ProviderStatusResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.True(t, status.Probing.Enabled)
+	byName := make(map[string]server.ProviderHealthStatus)
+	for _, provider := range status.Data {
+		byName[provider.Name] = provider
+	}
+
+	require.Len(t, byName["failing"].Models, 1)
+	down := byName["failing"].Models[0]
+	assert.Equal(t, server.HealthStatusDown, byName["failing"].Status)
+	assert.False(t, down.Healthy)
+	assert.Equal(t, 1, down.ConsecutiveFailures)
+	require.NotNil(t, down.Uptime24h)
+	assert.Equal(t, 0.0, *down.Uptime24h)
+	assert.NotEmpty(t, down.LastError)
+
+	require.Len(t, byName["healthy"].Models, 1)
+	up := byName["healthy"].Models[0]
+	assert.Equal(t, server.HealthStatusUp, byName["healthy"].Status)
+	assert.True(t, up.Healthy)
+	require.NotNil(t, up.Uptime7d)
+	assert.Equal(t, 100.0, *up.Uptime7d)
+	assert.Equal(t, int64(1), up.Probes24h)
+
+	// Round robin would alternate; selection keeps away from the failing service
+	rules := globalConfig.GetRequestConfigs()
+	for i := 0; i < 4; i++ {
+		selected, err := ts.server.GetLoadBalancer().SelectService(&rules[len(rules)-1])
+		require.NoError(t, err)
+		assert.Equal(t, "healthy", selected.Provider)
+		ts.server.GetLoadBalancer().UpdateServiceIndex(&rules[len(rules)-1], selected)
+	}
+
+	// With probing disabled every service is selectable again
+	globalConfig.HealthProbe.Enabled = false
+	assert.True(t, prober.Healthy(&rules[len(rules)-1].Services[0]))
+}
+
+func TestHealthProber_SkipsUnprobeableStyles(t *testing.T) {
+	ts := NewTestServer(t)
+
+	anthropicMock := NewMockProviderServer()
+	defer anthropicMock.Close()
+	ts.AddTestProviderWithURL(t, "gemini", "http://127.0.0.1:1", "google", true)
+	ts.AddTestProviderWithURL(t, "claude", anthropicMock.GetURL(), "anthropic", true)
+
+	globalConfig := ts.appConfig.GetGlobalConfig()
+	rule := typ.Rule{
+		UUID:         "probe-styles",
+		Scenario:     typ.ScenarioOpenAI,
+		RequestModel: "probe-styles",
+		Services: []loadbalance.Service{
+			{Provider: "gemini", Model: "gemini-2.5-flash", Weight: 1, Active: true, TimeWindow: 300},
+			{Provider: "claude", Model: "claude-sonnet-4-5", Weight: 1, Active: true, TimeWindow: 300},
+		},
+		LBTactic: typ.Tactic{Type: loadbalance.TacticRoundRobin, Params: typ.RoundRobinParams{RequestThreshold: 1}},
+		Active:   true,
+	}
+	require.NoError(t, globalConfig.AddRequestConfig(rule))
+
+	globalConfig.HealthProbe = &typ.HealthProbeConfig{Enabled: true, MaxProbesPerHour: 10, FailureThreshold: 1}
+	prober := ts.server.GetHealthProber()
+	assert.Equal(t, 1, prober.ProbeOnce(context.Background()), "only the Anthropic service is probed")
+	assert.NotNil(t, anthropicMock.GetLastRequest("v1/messages"), "the probe goes through the client pool")
+
+	rules := globalConfig.GetRequestConfigs()
+	services := rules[len(rules)-1].Services
+	assert.True(t, prober.Healthy(&services[0]))
+	assert.Equal(t, 0, prober.ConsecutiveFailures(services[0].ServiceID()))
+	assert.True(t, prober.Healthy(&services[1]))
+}
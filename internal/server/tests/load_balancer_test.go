@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -921,6 +922,59 @@ func TestLoadBalancer_WeightedRandom(t *testing.T) {
 		provider1Count, provider2Count, provider1Ratio)
 }
 
+func TestLoadBalancer_LatencyBased(t *testing.T) {
+	// Create a minimal config for testing
+	appConfig, err := config.NewAppConfig(config.WithConfigDir(t.TempDir()))
+	require.NoError(t, err)
+
+	// Create stats middleware
+	statsMW := middleware.NewStatsMiddleware(appConfig.GetGlobalConfig())
+	defer statsMW.Stop()
+
+	lb := server.NewLoadBalancer(statsMW, appConfig.GetGlobalConfig())
+	defer lb.Stop()
+
+	rule := &typ.Rule{
+		Scenario:     typ.ScenarioOpenAI,
+		RequestModel: "test",
+		UUID:         uuid.New().String(),
+		Services: []loadbalance.Service{
+			{Provider: "slow", Model: "model1", Weight: 1, Active: true, TimeWindow: 300},
+			{Provider: "fast", Model: "model2", Weight: 1, Active: true, TimeWindow: 300},
+		},
+		LBTactic: typ.Tactic{
+			Type:   loadbalance.TacticLatencyBased,
+			Params: typ.NewLatencyBasedParams(),
+		},
+		Active: true,
+	}
+
+	// Unmeasured services are tried first, least used first
+	rule.Services[0].RecordUsage(100, 50)
+	rule.Services[0].RecordStreamTiming(1200*time.Millisecond, 4*time.Second, 200)
+	service, err := lb.SelectService(rule)
+	require.NoError(t, err)
+	assert.Equal(t, "fast", service.Provider)
+
+	// Once both are measured the lower average TTFT wins
+	rule.Services[1].RecordUsage(100, 50)
+	rule.Services[1].RecordStreamTiming(300*time.Millisecond, 2*time.Second, 200)
+	service, err = lb.SelectService(rule)
+	require.NoError(t, err)
+	assert.Equal(t, "fast", service.Provider)
+
+	rule.Services[1].RecordUsage(100, 50)
+	rule.Services[1].RecordStreamTiming(3*time.Second, 2*time.Second, 200)
+	service, err = lb.SelectService(rule)
+	require.NoError(t, err)
+	assert.Equal(t, "slow", service.Provider)
+
+	streams, avgTTFT, tokensPerSecond := rule.Services[1].GetWindowStreamStats()
+	assert.Equal(t, int64(2), streams)
+	assert.Equal(t, 1650*time.Millisecond, avgTTFT)
+	assert.InDelta(t, 100.0, tokensPerSecond, 0.001)
+}
+
 func TestLoadBalancer_WithMockProvider(t *testing.T) {
 	// Create a mock provider server for testing
 	mockServer := NewMockProviderServer()
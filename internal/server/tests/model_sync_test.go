@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tingly-dev/tingly-box/internal/db"
+	"github.com/tingly-dev/tingly-box/internal/server"
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+func TestModelSync_DiffAndRuleWarnings(t *testing.T) {
+	ts := NewTestServer(t)
+
+	var mu sync.Mutex
+	listed := []string{"gpt-4o", "gpt-old"}
+	models := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		data := make([]map[string]string, 0, len(listed))
+		for _, id := range listed {
+			data = append(data, map[string]string{"id": id})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer models.Close()
+
+	ts.AddTestProviderWithURL(t, "catalog", models.URL, "openai", true)
+	ts.AddTestRule(t, "old-rule", "catalog", "gpt-old")
+	ts.AddTestRule(t, "current-rule", "catalog", "gpt-4o")
+
+	// The first sync only records the baseline; a sync within the interval is skipped
+	syncer := ts.server.GetModelSyncer()
+	assert.Equal(t, 1, syncer.SyncDue(context.Background()))
+	assert.Equal(t, 0, syncer.SyncDue(context.Background()))
+
+	mu.Lock()
+	listed = []string{"gpt-4o", "gpt-new"}
+	mu.Unlock()
+	var changed db.ModelDiff
+	syncer.SetOnChange(func(provider *typ.Provider, diff db.ModelDiff) { changed = diff })
+	ts.appConfig.GetGlobalConfig().ModelSync = &typ.ModelSyncConfig{Interval: 1}
+	t.Cleanup(func() { ts.appConfig.GetGlobalConfig().ModelSync = nil })
+	require.Eventually(t, func() bool { return syncer.SyncDue(context.Background()) == 1 }, 3*time.Second, 100*time.Millisecond)
+	assert.Equal(t, db.ModelDiff{Added: []string{"gpt-new"}, Removed: []string{"gpt-old"}}, changed)
+
+	token := ts.appConfig.GetGlobalConfig().GetUserToken()
+	req, _ := http.NewRequest("GET", "/api/v1/rules?scenario=openai", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	ts.ginEngine.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var rules server.RulesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rules))
+	require.Len(t, rules.Warnings, 1)
+	warning := rules.Warnings[0]
+	assert.Equal(t, "old-rule", warning.RuleUUID)
+	assert.Equal(t, "gpt-old", warning.Model)
+	assert.Equal(t, typ.ModelWarningRemoved, warning.Reason)
+	assert.NotNil(t, warning.Since)
+
+	req, _ = http.NewRequest("GET", "/api/v1/provider-models/catalog/changes", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	ts.ginEngine.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var changes server.ModelChangesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &changes))
+	require.Len(t, changes.Data, 2)
+	byModel := map[string]string{}
+	for _, change := range changes.Data {
+		byModel[change.ModelID] = change.Change
+	}
+	assert.Equal(t, map[string]string{"gpt-new": db.ModelChangeAdded, "gpt-old": db.ModelChangeRemoved}, byModel)
+
+	// Listing the model again clears the warning
+	mu.Lock()
+	listed = []string{"gpt-4o", "gpt-new", "gpt-old"}
+	mu.Unlock()
+	_, err := ts.appConfig.GetGlobalConfig().SyncProviderModels("catalog")
+	require.NoError(t, err)
+	assert.Empty(t, ts.appConfig.GetGlobalConfig().GetModelWarnings(ts.appConfig.GetGlobalConfig().GetRequestConfigs()))
+}
+
+func TestModelSync_AccountPoolRuleWarnings(t *testing.T) {
+	ts := NewTestServer(t)
+
+	listed := []string{"gpt-4o", "gpt-old"}
+	models := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data := make([]map[string]string, 0, len(listed))
+		for _, id := range listed {
+			data = append(data, map[string]string{"id": id})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer models.Close()
+
+	globalConfig := ts.appConfig.GetGlobalConfig()
+	require.NoError(t, globalConfig.AddProvider(&typ.Provider{
+		UUID:        "member",
+		Name:        "member",
+		APIBase:     models.URL,
+		APIStyle:    "openai",
+		AuthType:    typ.AuthTypeOAuth,
+		OAuthDetail: &typ.OAuthDetail{AccessToken: "oauth-token", ProviderType: "codex"},
+		Enabled:     true,
+	}))
+	require.NoError(t, globalConfig.AddAccountPool(&typ.AccountPool{
+		UUID:         "pool",
+		Name:         "pool",
+		ProviderType: "codex",
+		Accounts:     []string{"member"},
+		Enabled:      true,
+	}))
+	ts.AddTestRule(t, "pool-rule", "pool", "gpt-old")
+
+	_, err := globalConfig.SyncProviderModels("member")
+	require.NoError(t, err)
+	listed = []string{"gpt-4o"}
+	_, err = globalConfig.SyncProviderModels("member")
+	require.NoError(t, err)
+
+	// The service points at the pool, so the member account's removals are checked
+	warnings := globalConfig.GetModelWarnings(globalConfig.GetRequestConfigs())
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "pool-rule", warnings[0].RuleUUID)
+	assert.Equal(t, "member", warnings[0].ProviderUUID)
+	assert.Equal(t, typ.ModelWarningRemoved, warnings[0].Reason)
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tingly-dev/tingly-box/internal/server"
+)
+
+func TestRequestDetail_JoinsRoutingAttemptsAndUsage(t *testing.T) {
+	ts := NewTestServer(t)
+	mockServer := NewMockProviderServer()
+	defer mockServer.Close()
+
+	mockServer.SetResponse("chat/completions", MockResponse{
+		StatusCode: 200,
+		Body: map[string]interface{}{
+			"id":      "chatcmpl-detail",
+			"object":  "chat.completion",
+			"created": 1234567890,
+			"model":   "gpt-4",
+			"choices": []map[string]interface{}{
+				{
+					"index":         0,
+					"message":       map[string]string{"role": "assistant", "content": "hi"},
+					"finish_reason": "stop",
+				},
+			},
+			"usage": map[string]int{"prompt_tokens": 12, "completion_tokens": 3, "total_tokens": 15},
+		},
+	})
+	ts.AddTestProviderWithURL(t, "openai-mock", mockServer.GetURL(), "openai", true)
+	ts.AddTestRule(t, "detail-rule", "openai-mock", "gpt-4")
+	globalConfig := ts.appConfig.GetGlobalConfig()
+
+	req, _ := http.NewRequest("POST", "/openai/v1/chat/completions", CreateJSONBody(map[string]interface{}{
+		"model":    "detail-rule",
+		"messages": []map[string]string{CreateTestMessage("user", "Hello")},
+	}))
+	req.Header.Set("Authorization", "Bearer "+globalConfig.GetModelToken())
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-Id", "client-request-1")
+	w := httptest.NewRecorder()
+	ts.ginEngine.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+	assert.Equal(t, "client-request-1", w.Header().Get("X-Request-Id"))
+
+	req, _ = http.NewRequest("GET", "/api/v1/requests/client-request-1", nil)
+	req.Header.Set("Authorization", "Bearer "+globalConfig.GetUserToken())
+	w = httptest.NewRecorder()
+	ts.ginEngine.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var detail server.RequestDetailResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &detail))
+	require.NotNil(t, detail.Request)
+	assert.Equal(t, 200, detail.Request.StatusCode)
+	require.Len(t, detail.Request.Routing, 1)
+	assert.Equal(t, "openai-mock", detail.Request.Routing[0].Provider)
+	assert.Equal(t, "gpt-4", detail.Request.Routing[0].Model)
+	assert.Equal(t, "round_robin", detail.Request.Routing[0].Tactic)
+	require.Len(t, detail.Request.Attempts, 1)
+	assert.Equal(t, 200, detail.Request.Attempts[0].StatusCode)
+	require.Len(t, detail.Usage, 1)
+	assert.Equal(t, 12, detail.Usage[0].InputTokens)
+	assert.NotEmpty(t, detail.Logs)
+
+	// An unusable client ID is replaced, and unknown IDs are not found
+	req, _ = http.NewRequest("GET", "/api/v1/requests/unknown", nil)
+	req.Header.Set("Authorization", "Bearer "+globalConfig.GetUserToken())
+	req.Header.Set("X-Request-Id", "has spaces")
+	w = httptest.NewRecorder()
+	ts.ginEngine.ServeHTTP(w, req)
+	assert.Equal(t, 404, w.Code)
+	assert.NotEqual(t, "has spaces", w.Header().Get("X-Request-Id"))
+	assert.NotEmpty(t, w.Header().Get("X-Request-Id"))
+}
+
+func TestRequestDetail_PollingDoesNotEvictProxiedRequests(t *testing.T) {
+	ts := NewTestServer(t)
+	mockServer := NewMockProviderServer()
+	defer mockServer.Close()
+
+	mockServer.SetResponse("chat/completions", MockResponse{
+		StatusCode: 200,
+		Body: map[string]interface{}{
+			"id":      "chatcmpl-poll",
+			"object":  "chat.completion",
+			"created": 1234567890,
+			"model":   "gpt-4",
+			"choices": []map[string]interface{}{
+				{
+					"index":         0,
+					"message":       map[string]string{"role": "assistant", "content": "hi"},
+					"finish_reason": "stop",
+				},
+			},
+		},
+	})
+	ts.AddTestProviderWithURL(t, "openai-mock", mockServer.GetURL(), "openai", true)
+	ts.AddTestRule(t, "poll-rule", "openai-mock", "gpt-4")
+	globalConfig := ts.appConfig.GetGlobalConfig()
+
+	req, _ := http.NewRequest("POST", "/openai/v1/chat/completions", CreateJSONBody(map[string]interface{}{
+		"model":    "poll-rule",
+		"messages": []map[string]string{CreateTestMessage("user", "Hello")},
+	}))
+	req.Header.Set("Authorization", "Bearer "+globalConfig.GetModelToken())
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-Id", "polled-request")
+	w := httptest.NewRecorder()
+	ts.ginEngine.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	// Poll more times than the journal holds (1000); control panel calls still get an ID but are
+	// not journaled, so the proxied request stays retrievable
+	for i := 0; i < 1001; i++ {
+		req, _ = http.NewRequest("GET", "/api/v1/requests/polled-request", nil)
+		req.Header.Set("Authorization", "Bearer "+globalConfig.GetUserToken())
+		w = httptest.NewRecorder()
+		ts.ginEngine.ServeHTTP(w, req)
+		require.Equal(t, 200, w.Code, "poll %d: %s", i, w.Body.String())
+		require.NotEmpty(t, w.Header().Get("X-Request-Id"))
+	}
+
+	var detail server.RequestDetailResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &detail))
+	require.NotNil(t, detail.Request)
+	assert.Equal(t, "polled-request", detail.Request.ID)
+}
@@ -0,0 +1,273 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/tingly-dev/tingly-box/internal/constant"
+	"github.com/tingly-dev/tingly-box/internal/db"
+)
+
+func TestUsageStore_BatchedWritesFlushOnClose(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Dir(constant.GetDBFile(dir)), 0700))
+	store, err := db.NewUsageStore(dir)
+	require.NoError(t, err)
+
+	// A long flush interval and large batches keep everything queued until Close
+	store.Start(db.UsageStoreConfig{BatchSize: 1000, FlushInterval: int(time.Hour / time.Millisecond)})
+	for i := 0; i < 250; i++ {
+		assert.True(t, store.Enqueue(&db.UsageRecord{
+			ProviderUUID: "provider-1",
+			ProviderName: "provider",
+			Model:        "model-a",
+			Scenario:     "openai",
+			InputTokens:  10,
+			OutputTokens: 5,
+			Status:       "success",
+		}))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, store.Close(ctx))
+
+	_, total, err := store.GetRecords(time.Time{}, time.Time{}, nil, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(250), total)
+	assert.Zero(t, store.Dropped())
+
+	// Rolling up the same days again replaces the totals rather than duplicating them
+	require.NoError(t, store.Rollup(time.Time{}))
+	require.NoError(t, store.Rollup(time.Now().Add(-24*time.Hour)))
+
+	// Once closed, records are written directly
+	assert.True(t, store.Enqueue(&db.UsageRecord{ProviderUUID: "provider-1", ProviderName: "provider", Model: "model-a", Scenario: "openai", Status: "success"}))
+	_, total, err = store.GetRecords(time.Time{}, time.Time{}, nil, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(251), total)
+}
+
+func TestUsageStore_CloseKeepsConcurrentRecords(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Dir(constant.GetDBFile(dir)), 0700))
+	store, err := db.NewUsageStore(dir)
+	require.NoError(t, err)
+	store.Start(db.UsageStoreConfig{BatchSize: 1000, FlushInterval: int(time.Hour / time.Millisecond)})
+
+	// Requests keep finishing while the store shuts down; every accepted record must be written
+	var accepted sync.WaitGroup
+	var mu sync.Mutex
+	count := 0
+	for g := 0; g < 8; g++ {
+		accepted.Add(1)
+		go func() {
+			defer accepted.Done()
+			for i := 0; i < 100; i++ {
+				if store.Enqueue(&db.UsageRecord{ProviderUUID: "provider-1", ProviderName: "provider", Model: "model-a", Scenario: "openai", Status: "success"}) {
+					mu.Lock()
+					count++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, store.Close(ctx))
+	accepted.Wait()
+
+	_, total, err := store.GetRecords(time.Time{}, time.Time{}, nil, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(count), total)
+}
+
+func TestUsageStore_MigratesDuplicateRollupRows(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := constant.GetDBFile(dir)
+	require.NoError(t, os.MkdirAll(filepath.Dir(dbPath), 0700))
+
+	// A database from before the rollup tables had unique keys, with a repeated daily rollup
+	legacy, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	require.NoError(t, err)
+	for _, stmt := range []string{
+		`CREATE TABLE usage_daily (id integer PRIMARY KEY AUTOINCREMENT, date datetime NOT NULL, provider_uuid text NOT NULL, provider_name text NOT NULL, model text NOT NULL, request_count integer NOT NULL, total_tokens integer NOT NULL, input_tokens integer NOT NULL, output_tokens integer NOT NULL, error_count integer DEFAULT 0)`,
+		`CREATE INDEX idx_date ON usage_daily(date)`,
+		`CREATE INDEX idx_date_provider_model ON usage_daily(date, provider_uuid, model)`,
+		`INSERT INTO usage_daily (date, provider_uuid, provider_name, model, request_count, total_tokens, input_tokens, output_tokens) VALUES ('2025-01-01', 'provider-1', 'provider', 'model-a', 1, 10, 5, 5)`,
+		`INSERT INTO usage_daily (date, provider_uuid, provider_name, model, request_count, total_tokens, input_tokens, output_tokens) VALUES ('2025-01-01', 'provider-1', 'provider', 'model-a', 2, 20, 10, 10)`,
+		`CREATE TABLE usage_monthly (id integer PRIMARY KEY AUTOINCREMENT, year integer NOT NULL, month integer NOT NULL, provider_uuid text NOT NULL, provider_name text NOT NULL, model text NOT NULL, request_count integer NOT NULL, total_tokens integer NOT NULL, input_tokens integer NOT NULL, output_tokens integer NOT NULL, error_count integer DEFAULT 0)`,
+		`INSERT INTO usage_monthly (year, month, provider_uuid, provider_name, model, request_count, total_tokens, input_tokens, output_tokens) VALUES (2025, 1, 'provider-1', 'provider', 'model-a', 1, 10, 5, 5)`,
+		`INSERT INTO usage_monthly (year, month, provider_uuid, provider_name, model, request_count, total_tokens, input_tokens, output_tokens) VALUES (2025, 1, 'provider-1', 'provider', 'model-a', 2, 20, 10, 10)`,
+	} {
+		require.NoError(t, legacy.Exec(stmt).Error)
+	}
+	sqlDB, err := legacy.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+
+	_, err = db.NewUsageStore(dir)
+	require.NoError(t, err)
+
+	migrated, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	require.NoError(t, err)
+	migrator := migrated.Migrator()
+	assert.False(t, migrator.HasIndex(&db.UsageDailyRecord{}, "idx_date_provider_model"))
+	assert.True(t, migrator.HasIndex(&db.UsageDailyRecord{}, "idx_usage_daily_key"))
+	assert.True(t, migrator.HasIndex(&db.UsageMonthlyRecord{}, "idx_usage_monthly_key"))
+
+	// The newest of each duplicate is kept
+	var daily []db.UsageDailyRecord
+	require.NoError(t, migrated.Find(&daily).Error)
+	require.Len(t, daily, 1)
+	assert.Equal(t, int64(2), daily[0].RequestCount)
+	var monthly []db.UsageMonthlyRecord
+	require.NoError(t, migrated.Find(&monthly).Error)
+	require.Len(t, monthly, 1)
+	assert.Equal(t, int64(2), monthly[0].RequestCount)
+}
+
+func TestUsageStore_StreamUsageReport(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Dir(constant.GetDBFile(dir)), 0700))
+	store, err := db.NewUsageStore(dir)
+	require.NoError(t, err)
+
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, r := range []db.UsageRecord{
+		{Model: "model-a", Timestamp: day, InputTokens: 10, OutputTokens: 5},
+		{Model: "model-a", Timestamp: day.Add(time.Hour), InputTokens: 20, OutputTokens: 5, Status: "error"},
+		{Model: "model-b", Timestamp: day, InputTokens: 1, OutputTokens: 1},
+		{Model: "model-a", Timestamp: day.Add(24 * time.Hour), InputTokens: 7, OutputTokens: 3},
+	} {
+		r.ProviderUUID, r.ProviderName, r.Scenario = "provider-1", "provider", "openai"
+		require.NoError(t, store.RecordUsage(&r))
+	}
+
+	groupBy, err := db.ParseReportGroupBy("day, model")
+	require.NoError(t, err)
+	query := db.UsageStatsQuery{StartTime: day.Add(-time.Hour), EndTime: day.Add(48 * time.Hour)}
+
+	var csvOut strings.Builder
+	encoder, err := db.NewUsageReportEncoder(&csvOut, db.ReportFormatCSV, groupBy)
+	require.NoError(t, err)
+	require.NoError(t, store.StreamUsageReport(query, groupBy, encoder.Encode))
+	require.NoError(t, encoder.Flush())
+	assert.Equal(t, "day,model,request_count,input_tokens,output_tokens,total_tokens,error_count\n"+
+		"2026-03-01,model-a,2,30,10,40,1\n"+
+		"2026-03-01,model-b,1,1,1,2,0\n"+
+		"2026-03-02,model-a,1,7,3,10,0\n", csvOut.String())
+
+	groupBy, err = db.ParseReportGroupBy("provider")
+	require.NoError(t, err)
+	var jsonOut strings.Builder
+	encoder, err = db.NewUsageReportEncoder(&jsonOut, db.ReportFormatJSONL, groupBy)
+	require.NoError(t, err)
+	require.NoError(t, store.StreamUsageReport(query, groupBy, encoder.Encode))
+	assert.Equal(t, `{"provider_uuid":"provider-1","provider_name":"provider","request_count":4,"input_tokens":38,"output_tokens":14,"total_tokens":52,"error_count":1}`+"\n", jsonOut.String())
+
+	_, err = db.ParseReportGroupBy("day,team")
+	assert.Error(t, err)
+	_, err = db.NewUsageReportEncoder(&jsonOut, "parquet", groupBy)
+	assert.Error(t, err)
+}
+
+func TestUsageStore_StreamUsageReportAfterRetention(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Dir(constant.GetDBFile(dir)), 0700))
+	store, err := db.NewUsageStore(dir)
+	require.NoError(t, err)
+
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, r := range []db.UsageRecord{
+		{Model: "model-a", Timestamp: day, InputTokens: 10, OutputTokens: 5},
+		{Model: "model-a", Timestamp: day.Add(time.Hour), InputTokens: 20, OutputTokens: 5, Status: "error"},
+		{Model: "model-a", Timestamp: day.Add(24 * time.Hour), InputTokens: 7, OutputTokens: 3},
+	} {
+		r.ProviderUUID, r.ProviderName, r.Scenario = "provider-1", "provider", "openai"
+		require.NoError(t, store.RecordUsage(&r))
+	}
+
+	// The first day is rolled up and its raw records expire
+	require.NoError(t, store.Rollup(time.Time{}))
+	_, err = store.DeleteOlderThan(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	query := db.UsageStatsQuery{StartTime: day.Add(-time.Hour), EndTime: day.Add(48 * time.Hour)}
+
+	// Day, provider and model reports read the expired day from the daily totals
+	groupBy, err := db.ParseReportGroupBy("day,model")
+	require.NoError(t, err)
+	coverage, err := store.UsageReportCoverage(query, groupBy)
+	require.NoError(t, err)
+	assert.False(t, coverage.Truncated())
+	var csvOut strings.Builder
+	encoder, err := db.NewUsageReportEncoder(&csvOut, db.ReportFormatCSV, groupBy)
+	require.NoError(t, err)
+	require.NoError(t, store.StreamUsageReport(query, groupBy, encoder.Encode))
+	require.NoError(t, encoder.Flush())
+	assert.Equal(t, "day,model,request_count,input_tokens,output_tokens,total_tokens,error_count\n"+
+		"2026-03-01,model-a,2,30,10,40,1\n"+
+		"2026-03-02,model-a,1,7,3,10,0\n", csvOut.String())
+
+	// Scenarios are not kept in the daily totals, so the report says where it starts
+	groupBy, err = db.ParseReportGroupBy("scenario")
+	require.NoError(t, err)
+	coverage, err = store.UsageReportCoverage(query, groupBy)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), coverage.TruncatedBefore)
+	var rows []db.UsageReportRow
+	require.NoError(t, store.StreamUsageReport(query, groupBy, func(row db.UsageReportRow) error {
+		rows = append(rows, row)
+		return nil
+	}))
+	require.Len(t, rows, 1)
+	assert.Equal(t, int64(1), rows[0].RequestCount)
+
+	// A range within the retained records is complete
+	coverage, err = store.UsageReportCoverage(db.UsageStatsQuery{StartTime: day.Add(20 * time.Hour)}, groupBy)
+	require.NoError(t, err)
+	assert.False(t, coverage.Truncated())
+}
+
+func TestUsageStore_TimingPercentilesSampleRecentRequests(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Dir(constant.GetDBFile(dir)), 0700))
+	store, err := db.NewUsageStore(dir)
+	require.NoError(t, err)
+
+	// 200 old slow requests, then 2000 recent fast ones for model-a, and one for model-b
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var records []*db.UsageRecord
+	for i := 0; i < 2200; i++ {
+		ttft := 100
+		if i < 200 {
+			ttft = 10000
+		}
+		records = append(records, &db.UsageRecord{ProviderUUID: "provider-1", ProviderName: "provider", Model: "model-a", Scenario: "openai",
+			Timestamp: start.Add(time.Duration(i) * time.Second), Streamed: true, TTFTMs: ttft, TokensPerSecond: 50})
+	}
+	records = append(records, &db.UsageRecord{ProviderUUID: "provider-1", ProviderName: "provider", Model: "model-b", Scenario: "openai",
+		Timestamp: start, Streamed: true, TTFTMs: 300, TokensPerSecond: 20})
+	require.NoError(t, store.RecordUsageBatch(records))
+
+	stats, err := store.GetAggregatedStats(db.UsageStatsQuery{GroupBy: "model", Limit: 1, SortBy: "request_count", SortOrder: "desc"})
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, "model-a", stats[0].Model)
+	assert.Equal(t, int64(2200), stats[0].RequestCount)
+	// Percentiles come from the most recent samples, averages from every request
+	assert.Equal(t, 100.0, stats[0].P95TTFTMs)
+	assert.Equal(t, 50.0, stats[0].P50TokensPerSecond)
+	assert.Greater(t, stats[0].AvgTTFTMs, 100.0)
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/openai/openai-go/v3"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+// toolRepairer returns the tool argument repairer for a request, or nil when the provider has
+// tool repair turned off or the request has no tools. It is used by the OpenAI and Anthropic
+// handlers of OpenAI-style backends; the Gemini paths do not repair tool arguments.
+func toolRepairer(provider *typ.Provider, req *openai.ChatCompletionNewParams) *protocol.ToolRepairer {
+	if provider == nil || !provider.ToolRepair || req == nil || len(req.Tools) == 0 {
+		return nil
+	}
+	return protocol.NewToolRepairer(provider.Name, req.Tools)
+}
+
+// ToolRepairStatsResponse lists how often tool-call arguments had to be repaired, per provider
+type ToolRepairStatsResponse struct {
+	Providers []protocol.ToolRepairStats `json:"providers"`
+}
+
+// GetToolRepairStats returns the tool repair counters since the server started
+func (api *UsageAPI) GetToolRepairStats(c *gin.Context) {
+	c.JSON(http.StatusOK, ToolRepairStatsResponse{Providers: protocol.ToolRepairStatsSnapshot()})
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tingly-dev/tingly-box/internal/obs"
+	"github.com/tingly-dev/tingly-box/internal/typ"
+)
+
+// startUpstreamSpan starts the GenAI client span of a call to a provider. The returned context
+// carries the request's trace but not its cancellation, so upstream calls keep their own
+// lifetime. For streamed calls the span ends once the stream is open.
+func startUpstreamSpan(ctx context.Context, provider *typ.Provider, model string) (context.Context, trace.Span) {
+	return obs.Tracer().Start(context.WithoutCancel(ctx), "chat "+model,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			obs.AttrGenAIOperationName.String("chat"),
+			obs.AttrGenAISystem.String(string(provider.APIStyle)),
+			obs.AttrGenAIRequestModel.String(model),
+			obs.AttrProvider.String(provider.Name),
+		))
+}
+
+// startTranslationSpan starts the span of converting a request from one API dialect to another
+func startTranslationSpan(c *gin.Context, from, to string) trace.Span {
+	_, span := obs.StartSpan(c.Request.Context(), "translate.request",
+		obs.AttrTranslationFrom.String(from),
+		obs.AttrTranslationTo.String(to))
+	return span
+}
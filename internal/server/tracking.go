@@ -1,12 +1,15 @@
 package server
 
 import (
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/tingly-dev/tingly-box/internal/db"
+	"github.com/tingly-dev/tingly-box/internal/obs"
+	"github.com/tingly-dev/tingly-box/internal/protocol/stream"
+	"github.com/tingly-dev/tingly-box/internal/server/middleware"
 	"github.com/tingly-dev/tingly-box/internal/typ"
 )
 
@@ -14,15 +17,17 @@ import (
 // It encapsulates the logic for recording token usage to both service stats
 // and detailed usage records.
 type UsageTracker struct {
-	statsStore *db.StatsStore
-	usageStore *db.UsageStore
+	statsStore   *db.StatsStore
+	usageStore   *db.UsageStore
+	accountPools []*typ.AccountPool
 }
 
 // NewUsageTracker creates a new UsageTracker
 func (s *Server) NewUsageTracker() *UsageTracker {
 	return &UsageTracker{
-		statsStore: s.config.GetStatsStore(),
-		usageStore: s.config.GetUsageStore(),
+		statsStore:   s.config.GetStatsStore(),
+		usageStore:   s.config.GetUsageStore(),
+		accountPools: s.config.ListAccountPools(),
 	}
 }
 
@@ -53,32 +58,114 @@ func (t *UsageTracker) RecordUsage(
 		return
 	}
 
+	// Publish the outcome for middleware that summarizes requests (slim recording)
+	c.Set(middleware.ContextKeyUsage, &middleware.UsageInfo{
+		Rule:         rule.RequestModel,
+		Provider:     provider.Name,
+		Model:        model,
+		RequestModel: requestModel,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		CachedTokens: c.GetInt(middleware.ContextKeyCachedTokens),
+		Streamed:     streamed,
+		Status:       status,
+		ErrorCode:    errorCode,
+		Scenario:     middleware.ScenarioFromPath(c.Request.URL.Path),
+		LatencyMs:    calculateLatency(c),
+	})
+
+	// Annotate the request's server span with the GenAI outcome
+	trace.SpanFromContext(c.Request.Context()).SetAttributes(
+		obs.AttrRule.String(rule.RequestModel),
+		obs.AttrProvider.String(provider.Name),
+		obs.AttrGenAISystem.String(string(provider.APIStyle)),
+		obs.AttrGenAIRequestModel.String(requestModel),
+		obs.AttrGenAIResponseModel.String(model),
+		obs.AttrGenAIUsageInputTokens.Int(inputTokens),
+		obs.AttrGenAIUsageOutputTokens.Int(outputTokens),
+	)
+
+	timing := streamTimingOf(c)
+
 	// 1. Record usage on the rule's service stats (for load balancing)
-	t.recordOnService(rule, provider, model, inputTokens, outputTokens)
+	t.recordOnService(rule, provider, model, inputTokens, outputTokens, timing)
 
 	// 2. Record detailed usage (for analytics/dashboard)
 	if t.usageStore != nil {
-		t.recordDetailed(c, rule, provider, model, requestModel, inputTokens, outputTokens, streamed, status, errorCode)
+		t.recordDetailed(c, rule, provider, model, requestModel, inputTokens, outputTokens, streamed, status, errorCode, timing)
+	}
+}
+
+// streamTiming is the responsiveness of a streamed response; zero when nothing was streamed
+type streamTiming struct {
+	ttft     time.Duration // Request start to the first upstream event
+	duration time.Duration // First to last upstream event
+	chunks   int
+}
+
+// streamTimingOf reads the timing of the request's upstream stream, if it received any event
+func streamTimingOf(c *gin.Context) streamTiming {
+	stats := stream.StatsFromContext(c)
+	if stats == nil || stats.FirstEvent.IsZero() {
+		return streamTiming{}
+	}
+	timing := streamTiming{duration: stats.Duration(), chunks: stats.Chunks}
+	if start, ok := requestStart(c); ok {
+		timing.ttft = stats.FirstEvent.Sub(start)
+	}
+	return timing
+}
+
+// tokensPerSecond is the output throughput over the stream duration
+func (t streamTiming) tokensPerSecond(outputTokens int) float64 {
+	if t.duration <= 0 {
+		return 0
+	}
+	return float64(outputTokens) / t.duration.Seconds()
+}
+
+// setCachedTokens reports prompt tokens the provider served from its cache; RecordUsage picks them
+// up for the request's usage info
+func setCachedTokens(c *gin.Context, cachedTokens int64) {
+	if cachedTokens > 0 {
+		c.Set(middleware.ContextKeyCachedTokens, int(cachedTokens))
 	}
 }
 
 // recordOnService updates the service-level statistics for load balancing
-func (t *UsageTracker) recordOnService(rule *typ.Rule, provider *typ.Provider, model string, inputTokens, outputTokens int) {
+func (t *UsageTracker) recordOnService(rule *typ.Rule, provider *typ.Provider, model string, inputTokens, outputTokens int, timing streamTiming) {
 	// Find the matching service in the rule and update its stats
 	for i := range rule.Services {
 		service := &rule.Services[i]
-		if service.Active && service.Provider == provider.UUID && service.Model == model {
+		if service.Active && t.servesProvider(service.Provider, provider.UUID) && service.Model == model {
 			service.RecordUsage(inputTokens, outputTokens)
+			if timing.ttft > 0 {
+				service.RecordStreamTiming(timing.ttft, timing.duration, outputTokens)
+			}
 
 			// Persist to stats store
 			if t.statsStore != nil {
-				_ = t.statsStore.UpdateFromService(service)
+				_ = t.statsStore.QueueUpdate(service)
 			}
 			return
 		}
 	}
 }
 
+// servesProvider reports whether a service's provider reference resolves to the provider,
+// either directly or through an account pool the provider belongs to
+func (t *UsageTracker) servesProvider(serviceProvider, providerUUID string) bool {
+	if serviceProvider == providerUUID {
+		return true
+	}
+	for _, pool := range t.accountPools {
+		if pool.UUID == serviceProvider && pool.HasAccount(providerUUID) {
+			return true
+		}
+	}
+	return false
+}
+
 // recordDetailed writes a detailed usage record to the database
 func (t *UsageTracker) recordDetailed(
 	c *gin.Context,
@@ -88,11 +175,13 @@ func (t *UsageTracker) recordDetailed(
 	inputTokens, outputTokens int,
 	streamed bool,
 	status, errorCode string,
+	timing streamTiming,
 ) {
-	scenario := extractScenarioFromPath(c.Request.URL.Path)
+	scenario := middleware.ScenarioFromPath(c.Request.URL.Path)
 	latencyMs := calculateLatency(c)
 
 	record := &db.UsageRecord{
+		RequestID:    middleware.GetRequestID(c),
 		ProviderUUID: provider.UUID,
 		ProviderName: provider.Name,
 		Model:        model,
@@ -105,46 +194,34 @@ func (t *UsageTracker) recordDetailed(
 		ErrorCode:    errorCode,
 		LatencyMs:    latencyMs,
 		Streamed:     streamed,
+
+		TTFTMs:           int(timing.ttft.Milliseconds()),
+		StreamDurationMs: int(timing.duration.Milliseconds()),
+		TokensPerSecond:  timing.tokensPerSecond(outputTokens),
+		ChunkCount:       timing.chunks,
 	}
 
 	if rule != nil {
 		record.RuleUUID = rule.UUID
 	}
 
-	_ = t.usageStore.RecordUsage(record)
+	t.usageStore.Enqueue(record)
 }
 
-// extractScenarioFromPath extracts the scenario from the request path
-func extractScenarioFromPath(path string) string {
-	if strings.Contains(path, "/openai/") {
-		return "openai"
-	}
-	if strings.Contains(path, "/anthropic/") {
-		return "anthropic"
-	}
-	if strings.Contains(path, "/claude_code/") || strings.Contains(path, "/claude-code/") {
-		return "claude_code"
-	}
-	if strings.Contains(path, "/tingly/") {
-		// Extract scenario from tingly path
-		parts := strings.Split(path, "/")
-		for i, part := range parts {
-			if part == "tingly" && i+1 < len(parts) {
-				return parts[i+1]
-			}
-		}
+// calculateLatency calculates the request processing time in milliseconds
+func calculateLatency(c *gin.Context) int {
+	if start, ok := requestStart(c); ok {
+		return int(time.Since(start).Milliseconds())
 	}
-	return "unknown"
+	return 0
 }
 
-// calculateLatency calculates the request processing time in milliseconds
-func calculateLatency(c *gin.Context) int {
-	// Try to get start time from context
+// requestStart returns when the stats middleware started handling the request
+func requestStart(c *gin.Context) (time.Time, bool) {
 	if start, exists := c.Get("start_time"); exists {
 		if startFloat, ok := start.(float64); ok {
-			elapsed := time.Now().UnixNano() - int64(startFloat)
-			return int(elapsed / 1000000) // Convert nanoseconds to milliseconds
+			return time.Unix(0, int64(startFloat)), true
 		}
 	}
-	return 0
+	return time.Time{}, false
 }
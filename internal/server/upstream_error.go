@@ -0,0 +1,27 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tingly-dev/tingly-box/internal/protocol"
+)
+
+// SendUpstreamError answers a failed provider call in the client's dialect. The status code,
+// error type and retry-after header follow the provider's error so client SDKs retry (or give up)
+// as they would talking to the provider directly.
+func SendUpstreamError(c *gin.Context, style protocol.APIStyle, err error) {
+	upstreamErr := protocol.ParseUpstreamError(err)
+	logrus.Debugf("Upstream error (%s): %v", upstreamErr.Source, err)
+
+	if retryAfter := upstreamErr.RetryAfterHeader(); retryAfter != "" {
+		c.Header("Retry-After", retryAfter)
+	}
+	c.JSON(upstreamErr.HTTPStatus(style), upstreamErr.Body(style))
+}
+
+// upstreamErrorBody renders a provider error in the client's dialect, for errors reported in the
+// middle of a stream
+func upstreamErrorBody(style protocol.APIStyle, err error) map[string]interface{} {
+	return protocol.ParseUpstreamError(err).Body(style)
+}
@@ -1,11 +1,13 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 
 	"github.com/tingly-dev/tingly-box/internal/db"
 	"github.com/tingly-dev/tingly-box/internal/server/config"
@@ -103,9 +105,9 @@ func (s *Server) RegisterUsageRoutes(manager *swagger.RouteManager) {
 			Name:        "sort_by",
 			Type:        "string",
 			Required:    false,
-			Description: "Sort field: total_tokens, request_count, avg_latency",
+			Description: "Sort field: total_tokens, request_count, avg_latency, avg_ttft",
 			Default:     "total_tokens",
-			Enum:        []interface{}{"total_tokens", "request_count", "avg_latency"},
+			Enum:        []interface{}{"total_tokens", "request_count", "avg_latency", "avg_ttft"},
 		}),
 		swagger.WithQueryConfig("sort_order", swagger.QueryParamConfig{
 			Name:        "sort_order",
@@ -232,6 +234,80 @@ func (s *Server) RegisterUsageRoutes(manager *swagger.RouteManager) {
 		),
 	)
 
+	// GET /api/v1/usage/export - Download a usage report
+	apiV1.GET("/usage/export", usageAPI.ExportUsage,
+		swagger.WithTags("usage"),
+		swagger.WithDescription("Streams usage totals over a time range as CSV or JSON Lines, grouped by any combination of day, provider, model, rule and scenario. Days whose raw records were removed by the retention policy come from the daily totals; when the grouping or filters need the raw records, the X-Usage-Truncated-Before header names the first day covered"),
+		swagger.WithQueryConfig("format", swagger.QueryParamConfig{
+			Name:        "format",
+			Type:        "string",
+			Required:    false,
+			Description: "Output format: csv or jsonl",
+			Default:     "csv",
+			Enum:        []interface{}{"csv", "jsonl"},
+		}),
+		swagger.WithQueryConfig("group_by", swagger.QueryParamConfig{
+			Name:        "group_by",
+			Type:        "string",
+			Required:    false,
+			Description: "Comma-separated dimensions: day, provider, model, rule, scenario (days are UTC)",
+			Default:     "day,provider,model",
+		}),
+		swagger.WithQueryConfig("start_time", swagger.QueryParamConfig{
+			Name:        "start_time",
+			Type:        "string",
+			Required:    false,
+			Description: "ISO 8601 start time (default 30 days ago)",
+		}),
+		swagger.WithQueryConfig("end_time", swagger.QueryParamConfig{
+			Name:        "end_time",
+			Type:        "string",
+			Required:    false,
+			Description: "ISO 8601 end time (default now)",
+		}),
+		swagger.WithQueryConfig("provider", swagger.QueryParamConfig{
+			Name:        "provider",
+			Type:        "string",
+			Required:    false,
+			Description: "Filter by provider UUID",
+		}),
+		swagger.WithQueryConfig("model", swagger.QueryParamConfig{
+			Name:        "model",
+			Type:        "string",
+			Required:    false,
+			Description: "Filter by model name",
+		}),
+		swagger.WithQueryConfig("scenario", swagger.QueryParamConfig{
+			Name:        "scenario",
+			Type:        "string",
+			Required:    false,
+			Description: "Filter by scenario",
+		}),
+		swagger.WithQueryConfig("rule_uuid", swagger.QueryParamConfig{
+			Name:        "rule_uuid",
+			Type:        "string",
+			Required:    false,
+			Description: "Filter by rule UUID",
+		}),
+		swagger.WithQueryConfig("status", swagger.QueryParamConfig{
+			Name:        "status",
+			Type:        "string",
+			Required:    false,
+			Description: "Filter by status: success, error, partial",
+		}),
+		swagger.WithErrorResponses(
+			swagger.ErrorResponseConfig{Code: 400, Message: "Invalid format or group_by"},
+			swagger.ErrorResponseConfig{Code: 503, Message: "Usage store not available"},
+		),
+	)
+
+	// GET /api/v1/usage/tool-repair - Get tool-call argument repair counters
+	apiV1.GET("/usage/tool-repair", usageAPI.GetToolRepairStats,
+		swagger.WithTags("usage"),
+		swagger.WithDescription("Returns per-provider counts of tool-call arguments that were repaired or coerced to the tool schema"),
+		swagger.WithResponseModel(ToolRepairStatsResponse{}),
+	)
+
 	// DELETE /api/v1/usage/records - Delete old usage records
 	apiV1.DELETE("/usage/records", usageAPI.DeleteOldRecords,
 		swagger.WithTags("usage"),
@@ -395,23 +471,7 @@ func (api *UsageAPI) GetRecords(c *gin.Context) {
 	// Convert db.UsageRecord to server.UsageRecordResponse
 	data := make([]UsageRecordResponse, len(records))
 	for i, r := range records {
-		data[i] = UsageRecordResponse{
-			ID:           r.ID,
-			ProviderUUID: r.ProviderUUID,
-			ProviderName: r.ProviderName,
-			Model:        r.Model,
-			Scenario:     r.Scenario,
-			RuleUUID:     r.RuleUUID,
-			RequestModel: r.RequestModel,
-			Timestamp:    r.Timestamp.Format(time.RFC3339),
-			InputTokens:  r.InputTokens,
-			OutputTokens: r.OutputTokens,
-			TotalTokens:  r.TotalTokens,
-			Status:       r.Status,
-			ErrorCode:    r.ErrorCode,
-			LatencyMs:    r.LatencyMs,
-			Streamed:     r.Streamed,
-		}
+		data[i] = newUsageRecordResponse(r)
 	}
 
 	response := UsageRecordsResponse{
@@ -426,6 +486,110 @@ func (api *UsageAPI) GetRecords(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// exportFlushRows is how many report rows are written between flushes to the client
+const exportFlushRows = 500
+
+// usageTruncatedBeforeHeader names the first day an export covers when its range starts before
+// the retained raw records and the grouping or filters need them
+const usageTruncatedBeforeHeader = "X-Usage-Truncated-Before"
+
+// ExportUsage streams a usage report as a CSV or JSON Lines download
+func (api *UsageAPI) ExportUsage(c *gin.Context) {
+	if api.usageStore == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Usage store not available"})
+		return
+	}
+
+	format := c.DefaultQuery("format", db.ReportFormatCSV)
+	groupBy, err := db.ParseReportGroupBy(c.Query("group_by"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query := db.UsageStatsQuery{
+		StartTime: parseTimeQuery(c, "start_time", time.Now().AddDate(0, 0, -30)),
+		EndTime:   parseTimeQuery(c, "end_time", time.Now()),
+		Provider:  c.Query("provider"),
+		Model:     c.Query("model"),
+		Scenario:  c.Query("scenario"),
+		RuleUUID:  c.Query("rule_uuid"),
+		Status:    c.Query("status"),
+	}
+
+	encoder, err := db.NewUsageReportEncoder(c.Writer, format, groupBy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == db.ReportFormatJSONL {
+		contentType = "application/x-ndjson"
+	}
+	coverage, err := api.usageStore.UsageReportCoverage(query, groupBy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if coverage.Truncated() {
+		// Raw records before this day are gone and the report cannot be built from daily totals
+		c.Header(usageTruncatedBeforeHeader, coverage.TruncatedBefore.Format("2006-01-02"))
+	}
+
+	filename := fmt.Sprintf("usage-%s-%s.%s", query.StartTime.Format("20060102"), query.EndTime.Format("20060102"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	rows := 0
+	err = api.usageStore.StreamUsageReport(query, groupBy, func(row db.UsageReportRow) error {
+		if err := encoder.Encode(row); err != nil {
+			return err
+		}
+		if rows++; rows%exportFlushRows == 0 {
+			if err := encoder.Flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = encoder.Flush()
+	}
+	if err != nil {
+		// The response has started, so the client only sees a truncated report
+		logrus.Errorf("Usage export failed after %d rows: %v", rows, err)
+	}
+}
+
+// newUsageRecordResponse converts a db.UsageRecord to its API form
+func newUsageRecordResponse(r db.UsageRecord) UsageRecordResponse {
+	return UsageRecordResponse{
+		ID:           r.ID,
+		RequestID:    r.RequestID,
+		ProviderUUID: r.ProviderUUID,
+		ProviderName: r.ProviderName,
+		Model:        r.Model,
+		Scenario:     r.Scenario,
+		RuleUUID:     r.RuleUUID,
+		RequestModel: r.RequestModel,
+		Timestamp:    r.Timestamp.Format(time.RFC3339),
+		InputTokens:  r.InputTokens,
+		OutputTokens: r.OutputTokens,
+		TotalTokens:  r.TotalTokens,
+		Status:       r.Status,
+		ErrorCode:    r.ErrorCode,
+		LatencyMs:    r.LatencyMs,
+		Streamed:     r.Streamed,
+
+		TTFTMs:           r.TTFTMs,
+		StreamDurationMs: r.StreamDurationMs,
+		TokensPerSecond:  r.TokensPerSecond,
+		ChunkCount:       r.ChunkCount,
+	}
+}
+
 // DeleteOldRecords deletes usage records older than the specified date
 func (api *UsageAPI) DeleteOldRecords(c *gin.Context) {
 	if api.usageStore == nil {
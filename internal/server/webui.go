@@ -388,6 +388,10 @@ func (s *Server) useWebAPIEndpoints(manager *swagger.RouteManager) {
 		swagger.WithTags("logs"),
 		swagger.WithResponseModel(LogsResponse{}),
 	)
+	apiV1.GET("/log/stream", s.StreamLogs,
+		swagger.WithDescription("Stream log entries as Server-Sent events, filtered by level, provider, model and status"),
+		swagger.WithTags("logs"),
+	)
 	apiV1.GET("/log/stats", s.GetLogStats,
 		swagger.WithDescription("Get log statistics"),
 		swagger.WithTags("logs"),
@@ -397,6 +401,21 @@ func (s *Server) useWebAPIEndpoints(manager *swagger.RouteManager) {
 		swagger.WithTags("logs"),
 	)
 
+	apiV1.GET("/requests/stream", s.StreamRequests,
+		swagger.WithDescription("Stream summaries of completed proxied requests as Server-Sent events, filtered by level, provider, model and status"),
+		swagger.WithTags("logs"),
+	)
+
+	// Request detail, joined by the X-Request-Id the proxy returned
+	apiV1.GET("/requests/:id", s.GetRequestDetail,
+		swagger.WithDescription("Get the usage, routing decisions, upstream attempts, logs and recorded payloads of a request"),
+		swagger.WithTags("logs"),
+		swagger.WithResponseModel(RequestDetailResponse{}),
+		swagger.WithErrorResponses(
+			swagger.ErrorResponseConfig{Code: 404, Message: "Request not found"},
+		),
+	)
+
 	// Provider Management
 	//apiV1.GET("/providers", (s.GetProviders),
 	//	swagger.WithDescription("Get all configured providers with masked tokens"),
@@ -543,6 +562,12 @@ func (s *Server) useWebAPIEndpoints(manager *swagger.RouteManager) {
 		swagger.WithResponseModel(ProviderModelsResponse{}),
 	)
 
+	apiV1.GET("/provider-models/:uuid/changes", s.GetProviderModelChanges,
+		swagger.WithDescription("Get the models a provider added and removed within the last days (default 30), as detected by model list syncs"),
+		swagger.WithTags("models"),
+		swagger.WithResponseModel(ModelChangesResponse{}),
+	)
+
 	// Probe endpoint
 	apiV1.POST("/probe", s.HandleProbeModel,
 		swagger.WithDescription("Test a rule configuration by sending a sample request"),
@@ -565,6 +590,25 @@ func (s *Server) useWebAPIEndpoints(manager *swagger.RouteManager) {
 		swagger.WithResponseModel(ProbeProviderResponse{}),
 	)
 
+	apiV1.GET("/providers/status", s.GetProviderStatus,
+		swagger.WithDescription("Get the health of the providers used by active rules from background probes, with uptime over 24h and 7d"),
+		swagger.WithTags("testing"),
+		swagger.WithResponseModel(ProviderStatusResponse{}),
+	)
+
+	apiV1.GET("/alerts", s.GetAlerts,
+		swagger.WithDescription("Get the most recently fired alerts, newest first"),
+		swagger.WithTags("alerts"),
+		swagger.WithResponseModel(AlertsResponse{}),
+	)
+
+	apiV1.POST("/alerts/test", s.TestAlert,
+		swagger.WithDescription("Send a test alert to one sink, or to every sink without a name, and report each delivery"),
+		swagger.WithTags("alerts"),
+		swagger.WithRequestModel(AlertTestRequest{}),
+		swagger.WithResponseModel(AlertTestResponse{}),
+	)
+
 	apiV1.POST("/probe/model/capability", s.HandleProbeModelEndpoints,
 		swagger.WithDescription("Probe model endpoints (chat and responses) concurrently"),
 		swagger.WithTags("testing"),
@@ -1,13 +1,16 @@
 package smartrouting
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
 
 	"github.com/gobwas/glob"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/tingly-dev/tingly-box/internal/loadbalance"
+	"github.com/tingly-dev/tingly-box/internal/obs"
 )
 
 // Router evaluates requests against smart routing rules
@@ -32,8 +35,17 @@ func NewRouter(rules []SmartRouting) (*Router, error) {
 // EvaluateRequest evaluates a request against smart routing rules
 // Returns the matched services and true if a rule matched, otherwise empty and false
 func (r *Router) EvaluateRequest(ctx *RequestContext) ([]loadbalance.Service, bool) {
-	for _, rule := range r.rules {
+	return r.EvaluateRequestContext(context.Background(), ctx)
+}
+
+// EvaluateRequestContext is EvaluateRequest recorded as a span under the trace in tc
+func (r *Router) EvaluateRequestContext(tc context.Context, ctx *RequestContext) ([]loadbalance.Service, bool) {
+	_, span := obs.StartSpan(tc, "smart_routing.evaluate", obs.AttrGenAIRequestModel.String(ctx.Model))
+	defer span.End()
+
+	for i, rule := range r.rules {
 		if r.evaluateRule(ctx, &rule) {
+			span.SetAttributes(attribute.Int("tingly.smart_routing.rule_index", i))
 			return rule.Services, true
 		}
 	}
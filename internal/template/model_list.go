@@ -3,6 +3,7 @@ package template
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/tingly-dev/tingly-box/internal/db"
 	"github.com/tingly-dev/tingly-box/internal/typ"
@@ -88,3 +89,37 @@ func (mm *ModelListManager) GetProviderInfo(uid string) (apiBase string, lastUpd
 	}
 	return mm.modelStore.GetProviderInfo(uid)
 }
+
+// SyncModels saves a model list fetched from the provider API and returns the models added and
+// removed since the previous fetch
+func (mm *ModelListManager) SyncModels(provider *typ.Provider, apiBase string, models []string) (db.ModelDiff, error) {
+	if mm.modelStore == nil {
+		return db.ModelDiff{}, fmt.Errorf("model store not initialized")
+	}
+	return mm.modelStore.SyncModels(provider, apiBase, models)
+}
+
+// GetModelChanges returns the model list changes of a provider, or of all providers when uid is
+// empty, detected since the given time
+func (mm *ModelListManager) GetModelChanges(uid string, since time.Time) ([]db.ModelChangeRecord, error) {
+	if mm.modelStore == nil {
+		return nil, fmt.Errorf("model store not initialized")
+	}
+	return mm.modelStore.GetModelChanges(uid, since)
+}
+
+// GetRemovedModels returns the models a provider no longer lists, with when that was detected
+func (mm *ModelListManager) GetRemovedModels(uid string) map[string]time.Time {
+	if mm.modelStore == nil {
+		return map[string]time.Time{}
+	}
+	return mm.modelStore.GetRemovedModels(uid)
+}
+
+// GetLastSynced returns when the provider's model list was last fetched from its API
+func (mm *ModelListManager) GetLastSynced(uid string) time.Time {
+	if mm.modelStore == nil {
+		return time.Time{}
+	}
+	return mm.modelStore.GetLastSynced(uid)
+}
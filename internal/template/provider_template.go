@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -27,6 +28,9 @@ const DefaultTemplateCacheTTL = 12 * time.Hour // Default TTL for template cache
 
 const TemplateCacheFileName = "provider_template.json"
 
+// TemplateStatusDeprecated marks a template whose provider service is deprecated as a whole
+const TemplateStatusDeprecated = "deprecated"
+
 const TemplateGitHubURL = "https://raw.githubusercontent.com/tingly-dev/tingly-box/main/internal/template/provider_templates.json"
 
 // ProviderTemplate represents a predefined provider configuration template
@@ -43,13 +47,16 @@ type ProviderTemplate struct {
 	PricingDoc             string            `json:"pricing_doc"`
 	BaseURLOpenAI          string            `json:"base_url_openai,omitempty"`
 	BaseURLAnthropic       string            `json:"base_url_anthropic,omitempty"`
-	Models                 []string          `json:"models"`                 // List of model IDs
-	ModelLimits            map[string]int    `json:"model_limits,omitempty"` // Model name -> max_tokens mapping
+	Models                 []string          `json:"models"`                      // List of model IDs
+	DeprecatedModels       []string          `json:"deprecated_models,omitempty"` // Model IDs the provider has deprecated
+	ModelLimits            map[string]int    `json:"model_limits,omitempty"`      // Model name -> max_tokens mapping
 	SupportsModelsEndpoint bool              `json:"supports_models_endpoint"`
 	Tags                   []string          `json:"tags,omitempty"`
 	Metadata               map[string]string `json:"metadata,omitempty"`
-	OAuthProvider          string            `json:"oauth_provider,omitempty"` // OAuth provider type for oauth type providers
-	AuthType               string            `json:"auth_type,omitempty"`      // "oauth", "key"
+	OAuthProvider          string            `json:"oauth_provider,omitempty"`    // OAuth provider type for oauth type providers
+	AuthType               string            `json:"auth_type,omitempty"`         // "oauth", "key"
+	RequestHook            *typ.RequestHook  `json:"request_hook,omitempty"`      // Request modifications for oauth providers of this type
+	WebSearchSchema        string            `json:"web_search_schema,omitempty"` // Key into capability_schemas describing the native web search
 }
 
 // ProviderTemplateRegistry represents the provider template registry structure from GitHub
@@ -456,6 +463,23 @@ func (tm *TemplateManager) findTemplateByProvider(provider *typ.Provider) *Provi
 	}
 }
 
+// GetOAuthRequestHooks returns the request hook of each OAuth provider type. A hook from the current
+// (GitHub) templates takes precedence; embedded templates fill in types the current ones lack.
+func (tm *TemplateManager) GetOAuthRequestHooks() map[string]*typ.RequestHook {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	hooks := make(map[string]*typ.RequestHook)
+	for _, source := range []map[string]*ProviderTemplate{tm.embedded, tm.templates} {
+		for _, tmpl := range source {
+			if tmpl.OAuthProvider != "" && tmpl.RequestHook != nil {
+				hooks[tmpl.OAuthProvider] = tmpl.RequestHook
+			}
+		}
+	}
+	return hooks
+}
+
 // searchTemplates searches for a template by matcher function in both templates and embedded maps
 func (tm *TemplateManager) searchTemplates(matcher func(*ProviderTemplate) bool) *ProviderTemplate {
 	// Search in current templates first
@@ -483,6 +507,11 @@ func deepCopyTemplate(tmpl *ProviderTemplate) *ProviderTemplate {
 		copy(result.Models, tmpl.Models)
 	}
 
+	if tmpl.DeprecatedModels != nil {
+		result.DeprecatedModels = make([]string, len(tmpl.DeprecatedModels))
+		copy(result.DeprecatedModels, tmpl.DeprecatedModels)
+	}
+
 	// Copy model limits map
 	if tmpl.ModelLimits != nil {
 		result.ModelLimits = make(map[string]int, len(tmpl.ModelLimits))
@@ -589,3 +618,28 @@ func (tm *TemplateManager) GetMaxTokensForModelByProvider(provider *typ.Provider
 	// Fallback to global default
 	return constant.DefaultMaxTokens
 }
+
+// GetWebSearchSchema returns the capability schema naming the provider's native web search
+// (e.g. "web_search_glm"), or "" when its template declares none
+func (tm *TemplateManager) GetWebSearchSchema(provider *typ.Provider) string {
+	if tm == nil || provider == nil {
+		return ""
+	}
+	if tmpl := tm.findTemplateByProvider(provider); tmpl != nil {
+		return tmpl.WebSearchSchema
+	}
+	return ""
+}
+
+// IsModelDeprecated reports whether the provider's template lists the model as deprecated, or is
+// itself deprecated
+func (tm *TemplateManager) IsModelDeprecated(provider *typ.Provider, model string) bool {
+	if tm == nil || provider == nil {
+		return false
+	}
+	tmpl := tm.findTemplateByProvider(provider)
+	if tmpl == nil {
+		return false
+	}
+	return tmpl.Status == TemplateStatusDeprecated || slices.Contains(tmpl.DeprecatedModels, model)
+}
@@ -453,3 +453,59 @@ func TestTemplateManagerHTTPTimeout(t *testing.T) {
 		t.Error("Expected positive timeout, got", tm.httpClient.Timeout)
 	}
 }
+
+// TestTemplateManagerGetOAuthRequestHooks tests that OAuth request hooks are read from templates
+func TestTemplateManagerGetOAuthRequestHooks(t *testing.T) {
+	tm := NewEmbeddedOnlyTemplateManager()
+	if err := tm.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	hooks := tm.GetOAuthRequestHooks()
+	claude, ok := hooks["claude_code"]
+	if !ok {
+		t.Fatal("expected a claude_code request hook in embedded templates")
+	}
+	if claude.Auth == nil || claude.Auth.To != "Authorization" {
+		t.Errorf("expected claude_code hook to rewrite auth to Authorization, got %+v", claude.Auth)
+	}
+	if claude.AddQuery["beta"] != "true" {
+		t.Errorf("expected claude_code hook to add beta=true, got %v", claude.AddQuery)
+	}
+
+	antigravity, ok := hooks["antigravity"]
+	if !ok {
+		t.Fatal("expected an antigravity request hook in embedded templates")
+	}
+	if !antigravity.ClearHeaders {
+		t.Error("expected antigravity hook to clear headers")
+	}
+
+	// A current template without a hook must not drop the embedded one
+	tm.mu.Lock()
+	tm.templates = map[string]*ProviderTemplate{
+		"claude_code": {ID: "claude_code", OAuthProvider: "claude_code"},
+	}
+	tm.mu.Unlock()
+	if _, ok := tm.GetOAuthRequestHooks()["claude_code"]; !ok {
+		t.Error("expected embedded claude_code hook as fallback")
+	}
+}
+
+// TestTemplateManagerGetWebSearchSchema tests that a provider's web search schema is matched by APIBase
+func TestTemplateManagerGetWebSearchSchema(t *testing.T) {
+	tm := NewEmbeddedOnlyTemplateManager()
+	if err := tm.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	glm := &typ.Provider{APIBase: "https://api.z.ai/api/paas/v4/", APIStyle: protocol.APIStyleOpenAI}
+	if got := tm.GetWebSearchSchema(glm); got != protocol.WebSearchSchemaGLM {
+		t.Errorf("expected %q for Z.ai, got %q", protocol.WebSearchSchemaGLM, got)
+	}
+
+	unknown := &typ.Provider{APIBase: "https://example.com/v1", APIStyle: protocol.APIStyleOpenAI}
+	if got := tm.GetWebSearchSchema(unknown); got != "" {
+		t.Errorf("expected no schema for an unknown provider, got %q", got)
+	}
+}
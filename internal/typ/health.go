@@ -0,0 +1,47 @@
+package typ
+
+import "time"
+
+const (
+	// DefaultHealthProbeInterval is the time between background health probe rounds
+	DefaultHealthProbeInterval = 5 * time.Minute
+	// DefaultHealthProbesPerHour caps the background health probes sent per hour
+	DefaultHealthProbesPerHour = 60
+	// DefaultHealthFailureThreshold is how many probes in a row must fail before selection avoids a service
+	DefaultHealthFailureThreshold = 2
+	// DefaultHealthRetentionDays is how long probe history is kept
+	DefaultHealthRetentionDays = 30
+)
+
+// HealthProbeConfig controls the background health probes of the provider models used by active
+// rules. Every probe is a small billed request, so probing is off unless enabled and the number of
+// probes per hour is capped.
+type HealthProbeConfig struct {
+	Enabled          bool `json:"enabled" yaml:"enabled"`
+	Interval         int  `json:"interval,omitempty" yaml:"interval,omitempty"`                       // Seconds between probe rounds (default 300)
+	MaxProbesPerHour int  `json:"max_probes_per_hour,omitempty" yaml:"max_probes_per_hour,omitempty"` // Cost cap across all models (default 60); models probed least recently go first
+	FailureThreshold int  `json:"failure_threshold,omitempty" yaml:"failure_threshold,omitempty"`     // Failed probes in a row before selection avoids a service (default 2)
+	RetentionDays    int  `json:"retention_days,omitempty" yaml:"retention_days,omitempty"`           // Days of probe history kept (default 30)
+}
+
+// WithDefaults fills unset fields
+func (c HealthProbeConfig) WithDefaults() HealthProbeConfig {
+	if c.Interval <= 0 {
+		c.Interval = int(DefaultHealthProbeInterval / time.Second)
+	}
+	if c.MaxProbesPerHour <= 0 {
+		c.MaxProbesPerHour = DefaultHealthProbesPerHour
+	}
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = DefaultHealthFailureThreshold
+	}
+	if c.RetentionDays <= 0 {
+		c.RetentionDays = DefaultHealthRetentionDays
+	}
+	return c
+}
+
+// IntervalDuration returns the time between probe rounds
+func (c HealthProbeConfig) IntervalDuration() time.Duration {
+	return time.Duration(c.Interval) * time.Second
+}
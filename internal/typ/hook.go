@@ -0,0 +1,28 @@
+package typ
+
+// RequestHook declares modifications applied to every upstream request of a provider.
+// Hooks come from provider templates (keyed by OAuth provider type) or from a provider's own config.
+//
+// Steps run in this order: clear_headers, auth, remove_headers, set_headers, add_query.
+// Header and query values may use Go templates over the original request:
+// {{.Token}} is the credential the SDK sent, {{.Header "Name"}} / {{.Query "name"}} read the
+// original request, and {{.Env "NAME"}} reads the environment.
+type RequestHook struct {
+	ClearHeaders  bool              `json:"clear_headers,omitempty" yaml:"clear_headers,omitempty"`   // Drop every header before applying the rest
+	Auth          *AuthRewrite      `json:"auth,omitempty" yaml:"auth,omitempty"`                     // Move the credential to another header/scheme
+	RemoveHeaders []string          `json:"remove_headers,omitempty" yaml:"remove_headers,omitempty"` // Headers to delete
+	SetHeaders    map[string]string `json:"set_headers,omitempty" yaml:"set_headers,omitempty"`       // Headers to set (overwrites)
+	AddQuery      map[string]string `json:"add_query,omitempty" yaml:"add_query,omitempty"`           // Query params added when not already present
+}
+
+// AuthRewrite moves the credential from the header the SDK writes to the header the upstream expects
+type AuthRewrite struct {
+	From   string `json:"from" yaml:"from"`                         // Header carrying the credential, e.g. X-Api-Key
+	To     string `json:"to" yaml:"to"`                             // Header to send it in, e.g. Authorization
+	Scheme string `json:"scheme,omitempty" yaml:"scheme,omitempty"` // Optional prefix, e.g. Bearer
+}
+
+// IsEmpty reports whether the hook has nothing to apply
+func (h *RequestHook) IsEmpty() bool {
+	return h == nil || (!h.ClearHeaders && h.Auth == nil && len(h.RemoveHeaders) == 0 && len(h.SetHeaders) == 0 && len(h.AddQuery) == 0)
+}
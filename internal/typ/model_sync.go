@@ -0,0 +1,39 @@
+package typ
+
+import "time"
+
+// DefaultModelSyncInterval is how often the model list of each provider is fetched from its API
+const DefaultModelSyncInterval = 24 * time.Hour
+
+// ModelSyncConfig controls the scheduled sync of provider model lists, which detects models a
+// provider added or removed
+type ModelSyncConfig struct {
+	Disabled bool `json:"disabled,omitempty" yaml:"disabled,omitempty"` // Only fetch model lists on demand
+	Interval int  `json:"interval,omitempty" yaml:"interval,omitempty"` // Seconds between syncs of a provider (default 86400)
+}
+
+// IntervalDuration returns the time between syncs of a provider
+func (c ModelSyncConfig) IntervalDuration() time.Duration {
+	if c.Interval <= 0 {
+		return DefaultModelSyncInterval
+	}
+	return time.Duration(c.Interval) * time.Second
+}
+
+// Reasons a rule service gets a model warning
+const (
+	ModelWarningRemoved    = "removed"    // the provider no longer lists the model
+	ModelWarningDeprecated = "deprecated" // the provider template marks the model deprecated
+)
+
+// ModelWarning flags a rule service whose model its provider removed or deprecated
+type ModelWarning struct {
+	RuleUUID     string     `json:"rule_uuid"`
+	RequestModel string     `json:"request_model"`
+	ProviderUUID string     `json:"provider_uuid"`
+	ProviderName string     `json:"provider_name"`
+	Model        string     `json:"model"`
+	Reason       string     `json:"reason"`          // removed or deprecated
+	Since        *time.Time `json:"since,omitempty"` // When the removal was detected
+	Message      string     `json:"message"`
+}
@@ -6,6 +6,7 @@ import (
 	"math/rand"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/tingly-dev/tingly-box/internal/constant"
 	"github.com/tingly-dev/tingly-box/internal/loadbalance"
@@ -45,6 +46,8 @@ func (tc *Tactic) UnmarshalJSON(data []byte) error {
 		tc.Params = &HybridParams{}
 	case loadbalance.TacticRandom:
 		tc.Params = &RandomParams{}
+	case loadbalance.TacticLatencyBased:
+		tc.Params = &LatencyBasedParams{}
 	default:
 		return nil
 	}
@@ -78,6 +81,8 @@ func ParseTacticFromMap(tacticType loadbalance.TacticType, params map[string]int
 		}
 	case loadbalance.TacticRandom:
 		tacticParams = DefaultRandomParams()
+	case loadbalance.TacticLatencyBased:
+		tacticParams = DefaultLatencyBasedParams()
 	case loadbalance.TacticTokenBased:
 		if params != nil {
 			tacticParams = &TokenBasedParams{
@@ -155,6 +160,11 @@ type RandomParams struct{}
 
 func (r RandomParams) isTacticParams() {}
 
+// LatencyBasedParams represents parameters for latency-based tactic (currently empty but extensible)
+type LatencyBasedParams struct{}
+
+func (l LatencyBasedParams) isTacticParams() {}
+
 // Helper constructors for creating tactic parameters
 func NewRoundRobinParams(threshold int64) TacticParams {
 	return RoundRobinParams{RequestThreshold: threshold}
@@ -175,6 +185,10 @@ func NewRandomParams() TacticParams {
 	return RandomParams{}
 }
 
+func NewLatencyBasedParams() TacticParams {
+	return LatencyBasedParams{}
+}
+
 // DefaultParams returns default parameters for each tactic type
 func DefaultRoundRobinParams() TacticParams {
 	return RoundRobinParams{RequestThreshold: constant.DefaultRequestThreshold}
@@ -195,6 +209,10 @@ func DefaultRandomParams() TacticParams {
 	return RandomParams{}
 }
 
+func DefaultLatencyBasedParams() TacticParams {
+	return LatencyBasedParams{}
+}
+
 // Type assertion helpers for TacticParams
 func AsRoundRobinParams(p TacticParams) (RoundRobinParams, bool) {
 	rp, ok := p.(RoundRobinParams)
@@ -216,6 +234,11 @@ func AsRandomParams(p TacticParams) (RandomParams, bool) {
 	return rp, ok
 }
 
+func AsLatencyBasedParams(p TacticParams) (LatencyBasedParams, bool) {
+	lp, ok := p.(LatencyBasedParams)
+	return lp, ok
+}
+
 // LoadBalancingTactic defines the interface for load balancing strategies
 type LoadBalancingTactic interface {
 	SelectService(rule *Rule) *loadbalance.Service
@@ -470,22 +493,75 @@ func (rt *RandomTactic) GetType() loadbalance.TacticType {
 	return loadbalance.TacticRandom
 }
 
+// LatencyBasedTactic routes to the service with the lowest average time to first token over the
+// streamed responses of the current window. Services without streamed responses in the window are
+// tried first, least used first, so every service gets measured.
+type LatencyBasedTactic struct{}
+
+// NewLatencyBasedTactic creates a new latency-based tactic
+func NewLatencyBasedTactic() *LatencyBasedTactic {
+	return &LatencyBasedTactic{}
+}
+
+// SelectService selects the service that starts streaming soonest
+func (lt *LatencyBasedTactic) SelectService(rule *Rule) *loadbalance.Service {
+	activeServices := rule.GetActiveServices()
+	if len(activeServices) == 0 {
+		return nil
+	}
+
+	var unmeasured, fastest *loadbalance.Service
+	var fewestRequests int64 = -1
+	var lowestTTFT time.Duration = -1
+
+	for _, service := range activeServices {
+		streams, avgTTFT, _ := service.GetWindowStreamStats()
+		if streams == 0 {
+			requests, _ := service.GetWindowStats()
+			if fewestRequests == -1 || requests < fewestRequests {
+				fewestRequests = requests
+				unmeasured = service
+			}
+			continue
+		}
+		if lowestTTFT == -1 || avgTTFT < lowestTTFT {
+			lowestTTFT = avgTTFT
+			fastest = service
+		}
+	}
+
+	if unmeasured != nil {
+		return unmeasured
+	}
+	return fastest
+}
+
+func (lt *LatencyBasedTactic) GetName() string {
+	return "Latency Based"
+}
+
+func (lt *LatencyBasedTactic) GetType() loadbalance.TacticType {
+	return loadbalance.TacticLatencyBased
+}
+
 // Pre-created singleton tactic instances
 var (
 	defaultRoundRobinTactic = NewRoundRobinTactic()
 	defaultTokenBasedTactic = NewTokenBasedTactic(constant.DefaultTokenThreshold)
 	defaultHybridTactic     = NewHybridTactic(constant.DefaultRequestThreshold, constant.DefaultTokenThreshold)
 	defaultRandomTactic     = NewRandomTactic()
+	defaultLatencyTactic    = NewLatencyBasedTactic()
 )
 
 // IsValidTactic checks if the given tactic string is valid
 func IsValidTactic(tacticStr string) bool {
 	// Map of valid tactic names
 	validTactics := map[string]bool{
-		"round_robin": true,
-		"token_based": true,
-		"hybrid":      true,
-		"random":      true,
+		"round_robin":   true,
+		"token_based":   true,
+		"hybrid":        true,
+		"random":        true,
+		"latency_based": true,
 	}
 
 	// Convert to lowercase for case-insensitive comparison
@@ -509,6 +585,8 @@ func CreateTacticWithTypedParams(tacticType loadbalance.TacticType, params Tacti
 		}
 	case loadbalance.TacticRandom:
 		return defaultRandomTactic
+	case loadbalance.TacticLatencyBased:
+		return defaultLatencyTactic
 	}
 	return GetDefaultTactic(tacticType)
 }
@@ -521,6 +599,8 @@ func GetDefaultTactic(tType loadbalance.TacticType) LoadBalancingTactic {
 		return defaultHybridTactic
 	case loadbalance.TacticRandom:
 		return defaultRandomTactic
+	case loadbalance.TacticLatencyBased:
+		return defaultLatencyTactic
 	default:
 		return defaultRoundRobinTactic
 	}
@@ -89,6 +89,15 @@ type Provider struct {
 	// Auth configuration
 	AuthType    AuthType     `json:"auth_type"`              // api_key or oauth
 	OAuthDetail *OAuthDetail `json:"oauth_detail,omitempty"` // OAuth credentials (only for oauth auth type)
+
+	// Request customization, applied after the provider type's template hook
+	RequestHook *RequestHook `json:"request_hook,omitempty"`
+
+	// ToolRepair repairs malformed tool-call arguments and coerces them to the tool schema,
+	// for backends whose models produce unreliable JSON. It applies to OpenAI-style backends
+	// serving OpenAI and Anthropic clients; Gemini client requests and Gemini backends, whose
+	// function call arguments arrive as structured objects, are passed through unrepaired.
+	ToolRepair bool `json:"tool_repair,omitempty"`
 }
 
 // GetAccessToken returns the access token based on auth type
@@ -113,6 +122,27 @@ func (p *Provider) IsOAuthExpired() bool {
 	return false
 }
 
+// AccountPool groups several OAuth providers of the same provider type into one logical provider.
+// Rule services reference the pool UUID in place of a provider UUID, and each request is served by
+// one of the member accounts, rotating away from accounts that hit their usage window.
+type AccountPool struct {
+	UUID         string   `json:"uuid"`
+	Name         string   `json:"name"`
+	ProviderType string   `json:"provider_type"` // OAuth provider type shared by every account (claude_code, codex, ...)
+	Accounts     []string `json:"accounts"`      // Member provider UUIDs
+	Enabled      bool     `json:"enabled"`
+}
+
+// HasAccount reports whether the provider UUID is a member of the pool
+func (p *AccountPool) HasAccount(providerUUID string) bool {
+	for _, uuid := range p.Accounts {
+		if uuid == providerUUID {
+			return true
+		}
+	}
+	return false
+}
+
 // Rule represents a request/response configuration with load balancing support
 type Rule struct {
 	UUID                string                `json:"uuid"`
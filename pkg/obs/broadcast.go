@@ -0,0 +1,86 @@
+package obs
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Broadcaster fans values out to subscribers without ever blocking the publisher. Each subscriber
+// has its own buffer; values published while it is full are dropped for that subscriber only and
+// counted, so a slow consumer loses values instead of holding up the others.
+type Broadcaster[T any] struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscription[T]]struct{}
+}
+
+// Subscription receives the values published after it was created
+type Subscription[T any] struct {
+	ch      chan T
+	dropped atomic.Int64
+	owner   *Broadcaster[T]
+	once    sync.Once
+}
+
+// NewBroadcaster creates a broadcaster without subscribers
+func NewBroadcaster[T any]() *Broadcaster[T] {
+	return &Broadcaster[T]{subscribers: make(map[*Subscription[T]]struct{})}
+}
+
+// Subscribe registers a subscriber buffering up to buffer values
+func (b *Broadcaster[T]) Subscribe(buffer int) *Subscription[T] {
+	if buffer <= 0 {
+		buffer = 1
+	}
+	sub := &Subscription[T]{ch: make(chan T, buffer), owner: b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Publish offers a value to every subscriber
+func (b *Broadcaster[T]) Publish(value T) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers {
+		select {
+		case sub.ch <- value:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribers returns the number of open subscriptions
+func (b *Broadcaster[T]) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
+
+// C returns the channel values are delivered on; it is closed by Close
+func (s *Subscription[T]) C() <-chan T {
+	return s.ch
+}
+
+// Dropped returns how many values were dropped because the buffer was full
+func (s *Subscription[T]) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// TakeDropped returns the dropped count and resets it
+func (s *Subscription[T]) TakeDropped() int64 {
+	return s.dropped.Swap(0)
+}
+
+// Close unsubscribes and closes the channel
+func (s *Subscription[T]) Close() {
+	s.once.Do(func() {
+		s.owner.mu.Lock()
+		delete(s.owner.subscribers, s)
+		s.owner.mu.Unlock()
+		close(s.ch)
+	})
+}
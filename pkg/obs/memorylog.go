@@ -20,7 +20,9 @@ type MemoryLogHook struct {
 	count int
 	// Output writers for tee functionality
 	writers []io.Writer
-	mu      sync.RWMutex
+	// Live subscribers to new entries
+	live *Broadcaster[*logrus.Entry]
+	mu   sync.RWMutex
 }
 
 // NewMemoryLogHook creates a new memory log hook with the specified maximum capacity.
@@ -29,9 +31,17 @@ func NewMemoryLogHook(maxEntries int) *MemoryLogHook {
 		entries:    make([]*logrus.Entry, maxEntries),
 		maxEntries: maxEntries,
 		writers:    make([]io.Writer, 0),
+		live:       NewBroadcaster[*logrus.Entry](),
 	}
 }
 
+// Subscribe returns a subscription to entries fired from now on. Entries are dropped for the
+// subscriber while its buffer is full, so a slow reader never blocks logging. Close the
+// subscription when done.
+func (h *MemoryLogHook) Subscribe(buffer int) *Subscription[*logrus.Entry] {
+	return h.live.Subscribe(buffer)
+}
+
 // AddWriter adds a writer for tee output functionality.
 func (h *MemoryLogHook) AddWriter(w io.Writer) {
 	h.mu.Lock()
@@ -76,6 +86,7 @@ func (h *MemoryLogHook) Fire(entry *logrus.Entry) error {
 		}
 	}
 
+	h.live.Publish(copied)
 	return nil
 }
 
@@ -256,3 +256,32 @@ func TestEmptyHook(t *testing.T) {
 	assert.Empty(t, hook.GetEntriesSince(time.Now()))
 	assert.Empty(t, hook.GetEntriesByLevel(logrus.InfoLevel))
 }
+
+// TestSubscribe verifies live delivery and that a full subscriber drops entries instead of blocking.
+func TestSubscribe(t *testing.T) {
+	hook := NewMemoryLogHook(10)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.AddHook(hook)
+
+	logger.Info("before")
+	sub := hook.Subscribe(2)
+
+	logger.Info("first")
+	logger.Info("second")
+	logger.Info("third") // buffer full, dropped
+
+	assert.Equal(t, "first", (<-sub.C()).Message)
+	assert.Equal(t, "second", (<-sub.C()).Message)
+	assert.Equal(t, int64(1), sub.TakeDropped())
+	assert.Equal(t, int64(0), sub.Dropped())
+
+	sub.Close()
+	sub.Close()
+	_, open := <-sub.C()
+	assert.False(t, open)
+
+	// Logging after the subscriber left still works
+	logger.Info("after")
+	assert.Equal(t, 5, hook.Size())
+}